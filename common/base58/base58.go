@@ -5,7 +5,7 @@
 package base58
 
 import (
-	"fmt"
+	"github.com/incognitochain/go-incognito-sdk/common"
 )
 
 //go:generate go run genalphabet.go
@@ -17,7 +17,7 @@ type Base58 struct {
 func (base58 Base58) Decode(b string) []byte {
 	d, err := Decode(b)
 	if err != nil {
-		fmt.Print(err)
+		common.Log.Debugf("base58 decode: %v", err)
 		d = nil
 	}
 	return d
@@ -4,8 +4,6 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
-	"fmt"
-	"log"
 	"math/big"
 	"net"
 	"os"
@@ -116,7 +114,7 @@ func AppDataDir(appName string, roaming bool) string {
 func InterfaceSlice(slice interface{}) []interface{} {
 	s := reflect.ValueOf(slice)
 	if s.Kind() != reflect.Slice {
-		log.Println("InterfaceSlice() given a non-slice type")
+		Log.Warnf("InterfaceSlice() given a non-slice type")
 		return nil
 	}
 
@@ -181,7 +179,6 @@ func ParseListener(addr string, netType string) (*SimpleAddr, error) {
 	// Parse the IP.
 	ip := net.ParseIP(host)
 	if ip == nil {
-		fmt.Printf("'%s' is not a valid IP address\n", host)
 		return nil, errors.New("IP address is invalid")
 	}
 
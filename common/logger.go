@@ -0,0 +1,130 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+)
+
+// LogLevel orders the messages of a Logger, a logger made by NewLogger writes the ones at or above its level
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelOff writes nothing
+	LevelOff
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LevelDebug:
+		return "DBG"
+	case LevelInfo:
+		return "INF"
+	case LevelWarn:
+		return "WRN"
+	case LevelError:
+		return "ERR"
+	case LevelOff:
+		return "OFF"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(level))
+}
+
+// Logger is the leveled logger every package of the SDK writes through.
+// Plug a structured logger in with a small adapter given to SetLogger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Log is the logger of the SDK. It discards every message until SetLogger is called,
+// so nothing is printed to the output of the application by default.
+var Log Logger = &sharedLogger{}
+
+// SetLogger sends the messages of the whole SDK to logger, nil discards them again
+func SetLogger(logger Logger) {
+	Log.(*sharedLogger).current.Store(loggerHolder{logger})
+}
+
+// loggerHolder lets atomic.Value store a nil or differently typed Logger
+type loggerHolder struct {
+	Logger
+}
+
+// sharedLogger forwards to the logger given to SetLogger, so packages can keep a reference to Log
+type sharedLogger struct {
+	current atomic.Value
+}
+
+func (s *sharedLogger) logger() Logger {
+	holder, _ := s.current.Load().(loggerHolder)
+	return holder.Logger
+}
+
+func (s *sharedLogger) Debugf(format string, args ...interface{}) {
+	if logger := s.logger(); logger != nil {
+		logger.Debugf(format, args...)
+	}
+}
+
+func (s *sharedLogger) Infof(format string, args ...interface{}) {
+	if logger := s.logger(); logger != nil {
+		logger.Infof(format, args...)
+	}
+}
+
+func (s *sharedLogger) Warnf(format string, args ...interface{}) {
+	if logger := s.logger(); logger != nil {
+		logger.Warnf(format, args...)
+	}
+}
+
+func (s *sharedLogger) Errorf(format string, args ...interface{}) {
+	if logger := s.logger(); logger != nil {
+		logger.Errorf(format, args...)
+	}
+}
+
+// NewLogger returns a Logger writing the messages at or above level to w, one line each,
+// prefixed with the time and the level
+func NewLogger(w io.Writer, level LogLevel) Logger {
+	return &stdLogger{
+		logger: log.New(w, "", log.LstdFlags),
+		level:  level,
+	}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l *stdLogger) printf(level LogLevel, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	l.logger.Printf("[%s] %s", level, fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Debugf(format string, args ...interface{}) {
+	l.printf(LevelDebug, format, args...)
+}
+
+func (l *stdLogger) Infof(format string, args ...interface{}) {
+	l.printf(LevelInfo, format, args...)
+}
+
+func (l *stdLogger) Warnf(format string, args ...interface{}) {
+	l.printf(LevelWarn, format, args...)
+}
+
+func (l *stdLogger) Errorf(format string, args ...interface{}) {
+	l.printf(LevelError, format, args...)
+}
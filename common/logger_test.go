@@ -0,0 +1,46 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(NewLogger(&buf, LevelInfo))
+	defer SetLogger(nil)
+
+	Log.Debugf("hidden %d", 1)
+	Log.Infof("shown %d", 2)
+	Log.Errorf("shown %d", 3)
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "[INF] shown 2\n")
+	assert.Contains(t, buf.String(), "[ERR] shown 3\n")
+
+	// a nil logger discards the messages
+	SetLogger(nil)
+	Log.Errorf("discarded")
+	assert.NotContains(t, buf.String(), "discarded")
+}
+
+func TestObserveStep(t *testing.T) {
+	// no observer, nothing happens
+	ObserveStep(StepRPC, "getblockcount", time.Now(), nil, 0)
+
+	var steps []Step
+	SetObserver(func(step Step) {
+		steps = append(steps, step)
+	})
+	defer SetObserver(nil)
+
+	start := time.Now().Add(-time.Second)
+	ObserveStep(StepProof, "oneoutofmany", start, errors.New("failed"), 0)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, "oneoutofmany", steps[0].Name)
+	assert.True(t, steps[0].Duration >= time.Second)
+	assert.EqualError(t, steps[0].Err, "failed")
+}
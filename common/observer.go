@@ -0,0 +1,52 @@
+package common
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of the steps reported to the Observer
+const (
+	StepRPC   = "rpc"
+	StepProof = "proof"
+)
+
+// Step is a finished RPC call or proof generation step
+type Step struct {
+	Kind string
+	// Name is the RPC method, or the proof built, e.g. "oneoutofmany"
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	// Err is the error the step failed with, nil on success
+	Err error
+	// Code is the error code answered by the node to an RPC, 0 when it answered none
+	Code int
+}
+
+// Observer is called after every RPC and proof generation step of the SDK, e.g. to export metrics.
+// It runs on the goroutine of the step and must not block.
+type Observer func(step Step)
+
+var observer atomic.Value
+
+// SetObserver calls o after every RPC and proof generation step, nil stops reporting
+func SetObserver(o Observer) {
+	observer.Store(o)
+}
+
+// ObserveStep reports the step of kind started at start to the observer, if any
+func ObserveStep(kind string, name string, start time.Time, err error, code int) {
+	o, _ := observer.Load().(Observer)
+	if o == nil {
+		return
+	}
+	o(Step{
+		Kind:     kind,
+		Name:     name,
+		Start:    start,
+		Duration: time.Since(start),
+		Err:      err,
+		Code:     code,
+	})
+}
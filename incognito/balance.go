@@ -1,17 +1,23 @@
 package incognito
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/incognitochain/go-incognito-sdk/transaction"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
 )
 
 func GetBalance(rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) (uint64, error) {
-	inputCoin, err := getUnspentOutputCoinsExceptSpendingUTXO(rpcClient, privateKey, tokenId)
+	return GetBalanceWithContext(context.Background(), rpcClient, privateKey, tokenId)
+}
+
+func GetBalanceWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) (uint64, error) {
+	inputCoin, err := getUnspentOutputCoinsExceptSpendingUTXO(ctx, rpcClient, privateKey, tokenId)
 	if err != nil {
 		return 0, err
 	}
@@ -24,29 +30,68 @@ func GetBalance(rpcClient *rpcclient.HttpClient, privateKey string, tokenId stri
 	return accountBalance, nil
 }
 
+// AccountBalance is the balance of an account split into what it can spend and what its pending txs spend
+type AccountBalance struct {
+	Available uint64
+	Pending   uint64
+}
+
+func GetAccountBalance(rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) (*AccountBalance, error) {
+	return GetAccountBalanceWithContext(context.Background(), rpcClient, privateKey, tokenId)
+}
+
+// GetAccountBalanceWithContext returns the balance of tokenId of the account, the coins reserved in
+// rpcservice.DefaultCoinLocker are Pending until their tx is confirmed
+func GetAccountBalanceWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) (*AccountBalance, error) {
+	available, pending, err := getUnspentOutputCoinsByReservation(ctx, rpcClient, privateKey, tokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &AccountBalance{}
+	for _, coin := range available {
+		balance.Available += coin.CoinDetails.GetValue()
+	}
+	for _, coin := range pending {
+		balance.Pending += coin.CoinDetails.GetValue()
+	}
+	return balance, nil
+}
+
+// GetUnspentOutputCoins return utxos of an account, except the ones reserved for pending txs
+func getUnspentOutputCoinsExceptSpendingUTXO(ctx context.Context, rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
+	available, _, err := getUnspentOutputCoinsByReservation(ctx, rpcClient, privateKey, tokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	inputCoins := transaction.ConvertOutputCoinToInputCoin(available)
+	return inputCoins, nil
+}
 
-// GetUnspentOutputCoins return utxos of an account
-func getUnspentOutputCoinsExceptSpendingUTXO(rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
+// getUnspentOutputCoinsByReservation splits the utxos of an account into the available ones and the ones reserved
+// in rpcservice.DefaultCoinLocker
+func getUnspentOutputCoinsByReservation(ctx context.Context, rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) ([]*privacy.OutputCoin, []*privacy.OutputCoin, error) {
 	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("Can not deserialize priavte key %v\n", err)
+		return nil, nil, fmt.Errorf("Can not deserialize priavte key %v\n", err)
 	}
 	err = keyWallet.KeySet.InitFromPrivateKey(&keyWallet.KeySet.PrivateKey)
 	if err != nil {
-		return nil, errors.New("sender private key is invalid")
+		return nil, nil, errors.New("sender private key is invalid")
 	}
 
 	tokenID, err := common.Hash{}.NewHashFromStr(tokenId)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// get unspent output coins from network
-	utxos, err := rpcclient.GetUnspentOutputCoins(rpcClient, keyWallet, tokenID)
+	utxos, err := rpcclient.GetUnspentOutputCoinsWithContext(ctx, rpcClient, keyWallet, tokenID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	inputCoins := transaction.ConvertOutputCoinToInputCoin(utxos)
-	return inputCoins, nil
-}
\ No newline at end of file
+	available, pending := rpcservice.DefaultCoinLocker.Available(keyWallet.Base58CheckSerialize(wallet.PaymentAddressType), tokenID.String(), utxos)
+	return available, pending, nil
+}
@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
@@ -55,7 +56,8 @@ func newBurningRequestMetadata(
 	return meta, nil
 }
 
-func handleCreateRawTxWithBurningForDepositToSCReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+// newBurningRequestMetadataFromParams reads the burning request of a burning tx from params
+func newBurningRequestMetadataFromParams(params interface{}) (*metadata.BurningRequest, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if arrayParams == nil || len(arrayParams) < 5 {
 		return nil, errors.New("param must be an array at least 5 elements")
@@ -102,7 +104,7 @@ func handleCreateRawTxWithBurningForDepositToSCReq(rpcClient *rpcclient.HttpClie
 		return nil, errors.New("remote address is invalid")
 	}
 
-	meta, err := newBurningRequestMetadata(
+	return newBurningRequestMetadata(
 		senderPrivateKeyParam,
 		tokenReceivers,
 		tokenID,
@@ -110,6 +112,10 @@ func handleCreateRawTxWithBurningForDepositToSCReq(rpcClient *rpcclient.HttpClie
 		remoteAddress,
 		metadata.BurningForDepositToSCRequestMeta,
 	)
+}
+
+func handleCreateRawTxWithBurningForDepositToSCReq(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	meta, err := newBurningRequestMetadataFromParams(params)
 	if err != nil {
 		return nil, err
 	}
@@ -119,12 +125,9 @@ func handleCreateRawTxWithBurningForDepositToSCReq(rpcClient *rpcclient.HttpClie
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(params, meta)
+	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(ctx, params, meta)
 	if rpcErr != nil {
 		return nil, rpcErr
 	}
@@ -143,8 +146,12 @@ func handleCreateRawTxWithBurningForDepositToSCReq(rpcClient *rpcclient.HttpClie
 
 //decentralized
 func CreateAndSendBurningForDepositToSCRequest(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendBurningForDepositToSCRequestWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendBurningForDepositToSCRequestWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawTxWithBurningForDepositToSCReq(rpcClient, params)
+	data, err := handleCreateRawTxWithBurningForDepositToSCReq(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -156,3 +163,24 @@ func CreateAndSendBurningForDepositToSCRequest(rpcClient *rpcclient.HttpClient,
 	return newParam, nil
 	//txId, err := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
 }
+
+func PlanBurningForDepositToSCRequest(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanBurningForDepositToSCRequestWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanBurningForDepositToSCRequestWithContext previews the tx CreateAndSendBurningForDepositToSCRequestWithContext
+// would create from params
+func PlanBurningForDepositToSCRequestWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	meta, err := newBurningRequestMetadataFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawPrivacyCustomTokenTransaction(ctx, params, meta)
+}
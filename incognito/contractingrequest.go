@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
@@ -44,7 +45,8 @@ func newContractingRequestMetadata(senderPrivateKeyStr string, tokenReceivers in
 }
 
 
-func handleCreateRawTxWithContractingReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+// newContractingRequestMetadataFromParams reads the contracting request of a centralized burning tx from params
+func newContractingRequestMetadataFromParams(params interface{}) (*metadata.ContractingRequest, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if arrayParams == nil || len(arrayParams) < 5 {
 		return nil, errors.New("param must be an array at least 5 elements")
@@ -82,22 +84,23 @@ func handleCreateRawTxWithContractingReq(rpcClient *rpcclient.HttpClient, params
 		return nil, errors.New("token ID is invalid")
 	}
 
-	keyWallet, err := bean.GetPrivateKey(params)
+	return newContractingRequestMetadata(senderPrivateKeyParam, tokenReceivers, tokenID)
+}
+
+func handleCreateRawTxWithContractingReq(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	meta, err := newContractingRequestMetadataFromParams(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
-
-	meta, err := newContractingRequestMetadata(senderPrivateKeyParam, tokenReceivers, tokenID)
+	keyWallet, err := bean.GetPrivateKey(params)
 	if err != nil {
 		return nil, err
 	}
 
-	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(params, meta)
+	txService := newTxService(rpcClient, keyWallet, opts)
+
+	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(ctx, params, meta)
 	if rpcErr != nil {
 		return nil, rpcErr
 	}
@@ -115,8 +118,12 @@ func handleCreateRawTxWithContractingReq(rpcClient *rpcclient.HttpClient, params
 
 
 func CreateAndSendContractingRequest(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendContractingRequestWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendContractingRequestWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawTxWithContractingReq(rpcClient, params)
+	data, err := handleCreateRawTxWithContractingReq(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,3 +135,23 @@ func CreateAndSendContractingRequest(rpcClient *rpcclient.HttpClient, params int
 	return newParam, nil
 	//txId, err := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
 }
+
+func PlanContractingRequest(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanContractingRequestWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanContractingRequestWithContext previews the tx CreateAndSendContractingRequestWithContext would create from params
+func PlanContractingRequestWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	meta, err := newContractingRequestMetadataFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawPrivacyCustomTokenTransaction(ctx, params, meta)
+}
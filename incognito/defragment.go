@@ -1,26 +1,23 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
-	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func createRawDefragmentAccountTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func createRawDefragmentAccountTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	keyWallet, err := bean.GetPrivateKey(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildDeFragmentRawTransaction(params, nil)
+	tx, err := txService.BuildDeFragmentRawTransaction(ctx, params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,18 +36,15 @@ func createRawDefragmentAccountTransaction(rpcClient *rpcclient.HttpClient, para
 	return result, nil
 }
 
-func createRawDeFragmentPTokenAccountTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func createRawDeFragmentPTokenAccountTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	keyWallet, err := bean.GetPrivateKey(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildDeFragmentPTokenRawTransaction(params, nil)
+	tx, err := txService.BuildDeFragmentPTokenRawTransaction(ctx, params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +67,11 @@ func createRawDeFragmentPTokenAccountTransaction(rpcClient *rpcclient.HttpClient
 }
 
 func DeFragmentAccount(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := createRawDefragmentAccountTransaction(rpcClient, params)
+	return DeFragmentAccountWithContext(context.Background(), rpcClient, params)
+}
+
+func DeFragmentAccountWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := createRawDefragmentAccountTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +87,11 @@ func DeFragmentAccount(rpcClient *rpcclient.HttpClient, params interface{}) (int
 }
 
 func DeFragmentPTokenAccount(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := createRawDeFragmentPTokenAccountTransaction(rpcClient, params)
+	return DeFragmentPTokenAccountWithContext(context.Background(), rpcClient, params)
+}
+
+func DeFragmentPTokenAccountWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := createRawDeFragmentPTokenAccountTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,75 @@
+package incognito
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// IncomingCoin is a coin received by an account with the memo its sender attached
+type IncomingCoin struct {
+	Coin *privacy.OutputCoin
+	// Memo is the info of the coin, decrypted when Encrypted
+	Memo []byte
+	// Encrypted is set when the memo was encrypted to the account, see privacy.EncryptCoinInfo
+	Encrypted bool
+}
+
+func GetIncomingCoins(rpcClient *rpcclient.HttpClient, paymentAddress string, readonlyKey string, tokenId string) ([]*IncomingCoin, error) {
+	return GetIncomingCoinsWithContext(context.Background(), rpcClient, paymentAddress, readonlyKey, tokenId)
+}
+
+// GetIncomingCoinsWithContext returns every coin of tokenId received by the account, spent or not, with its memo.
+// The readonly key is enough to decrypt the memos encrypted to the account.
+func GetIncomingCoinsWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, paymentAddress string, readonlyKey string, tokenId string) ([]*IncomingCoin, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+	if err != nil {
+		return nil, fmt.Errorf("Can not deserialize readonly key %v\n", err)
+	}
+	if len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, fmt.Errorf("%v is not a readonly key", readonlyKey)
+	}
+
+	tokenID, err := common.Hash{}.NewHashFromStr(tokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	outputCoins, err := rpcclient.ListOutputCoinsWithContext(ctx, rpcClient, paymentAddress, readonlyKey, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingCoins := make([]*IncomingCoin, len(outputCoins))
+	for i, coin := range outputCoins {
+		incomingCoins[i] = &IncomingCoin{Coin: coin, Memo: coin.CoinDetails.GetInfo()}
+		memo, err := DecryptMemo(coin.CoinDetails.GetInfo(), keyWallet.KeySet.ReadonlyKey.Rk)
+		if err != nil {
+			common.Log.Debugf("decrypt memo of coin %d: %v", i, err)
+			continue
+		}
+		if memo != nil {
+			incomingCoins[i].Memo = memo
+			incomingCoins[i].Encrypted = true
+		}
+	}
+	return incomingCoins, nil
+}
+
+// DecryptMemo decrypts info, the info of a coin, with the receiving key of the readonly key of its receiver.
+// It returns nil when info is not a memo encrypted to the key, the info is then the plain memo.
+func DecryptMemo(info []byte, receivingKey []byte) ([]byte, error) {
+	if !privacy.IsEncryptedCoinInfo(info) {
+		return nil, nil
+	}
+	memo, err := privacy.DecryptCoinInfo(info, receivingKey)
+	if errors.Is(err, privacy.ErrNotEncryptedCoinInfo) {
+		return nil, nil
+	}
+	return memo, err
+}
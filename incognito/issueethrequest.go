@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -8,10 +9,9 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/metadata"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
-	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func handleCreateRawTxWithIssuingETHReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateRawTxWithIssuingETHReq(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if arrayParams == nil || len(arrayParams) < 5 {
 		return nil, errors.New("param must be an array at least 5 elements")
@@ -39,12 +39,9 @@ func handleCreateRawTxWithIssuingETHReq(rpcClient *rpcclient.HttpClient, params
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildRawTransaction(createRawTxParam, meta)
+	tx, err := txService.BuildRawTransaction(ctx, createRawTxParam, meta)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +59,11 @@ func handleCreateRawTxWithIssuingETHReq(rpcClient *rpcclient.HttpClient, params
 }
 
 func CreateAndSendTxWithIssuingETHReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := handleCreateRawTxWithIssuingETHReq(rpcClient, params)
+	return CreateAndSendTxWithIssuingETHReqWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendTxWithIssuingETHReqWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := handleCreateRawTxWithIssuingETHReq(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1,17 +1,17 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
 	"github.com/incognitochain/go-incognito-sdk/metadata"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
-	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/pkg/errors"
 )
 
-func handleCreateIssuingRequest(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateIssuingRequest(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if arrayParams == nil || len(arrayParams) < 5 {
 		return nil, errors.New("param must be an array at least 5 elements")
@@ -39,12 +39,9 @@ func handleCreateIssuingRequest(rpcClient *rpcclient.HttpClient, params interfac
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildRawTransaction(createRawTxParam, meta)
+	tx, err := txService.BuildRawTransaction(ctx, createRawTxParam, meta)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +59,11 @@ func handleCreateIssuingRequest(rpcClient *rpcclient.HttpClient, params interfac
 }
 
 func CreateAndSendIssuingRequest(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := handleCreateIssuingRequest(rpcClient, params)
+	return CreateAndSendIssuingRequestWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendIssuingRequestWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := handleCreateIssuingRequest(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
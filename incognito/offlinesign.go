@@ -0,0 +1,84 @@
+package incognito
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+func CreateUnsignedTx(rpcClient *rpcclient.HttpClient, paymentAddress string, readonlyKey string, receivers map[string]uint64, estimateFeeCoinPerKb int64, hasPrivacy bool, info string) (*transaction.UnsignedTx, error) {
+	return CreateUnsignedTxWithContext(context.Background(), rpcClient, paymentAddress, readonlyKey, receivers, estimateFeeCoinPerKb, hasPrivacy, info)
+}
+
+// CreateUnsignedTxWithContext is the online step of a PRV transfer from a watch-only account: it chooses the coins
+// to spend and fetches the decoy commitments and SNDs, without the private key. Carry the returned tx, marshalled
+// to JSON, to the machine holding the key and sign it there with SignUnsignedTx.
+func CreateUnsignedTxWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, paymentAddress string, readonlyKey string, receivers map[string]uint64, estimateFeeCoinPerKb int64, hasPrivacy bool, info string, opts ...TxOption) (*transaction.UnsignedTx, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyWallet.KeySet.PaymentAddress.Pk) == 0 {
+		return nil, errors.New("payment address is invalid")
+	}
+	readonlyKeyWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(readonlyKeyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, errors.New("readonly key is invalid")
+	}
+	keyWallet.KeySet.ReadonlyKey = readonlyKeyWallet.KeySet.ReadonlyKey
+
+	paymentInfos, err := bean.NewPaymentInfos(receivers)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+
+	pk := keyWallet.KeySet.PaymentAddress.Pk
+	return txService.PrepareRawTransaction(ctx, &bean.CreateRawTxParam{
+		SenderKeySet:         &keyWallet.KeySet,
+		ShardIDSender:        common.GetShardIDFromLastByte(pk[len(pk)-1]),
+		PaymentInfos:         paymentInfos,
+		EstimateFeeCoinPerKb: estimateFeeCoinPerKb,
+		HasPrivacyCoin:       hasPrivacy,
+		Info:                 []byte(info),
+	})
+}
+
+// SignUnsignedTx is the offline step: it proves and signs unsignedTx with privateKey, with no network access.
+// Send the returned Base58CheckData with sendtransaction from an online machine.
+func SignUnsignedTx(unsignedTx *transaction.UnsignedTx, privateKey string) (*rpcclient.CreateTransactionResult, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyWallet.KeySet.PrivateKey) == 0 {
+		return nil, errors.New("private key is invalid")
+	}
+
+	tx, err := unsignedTx.Sign(&keyWallet.KeySet.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	byteArrays, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpcclient.CreateTransactionResult{
+		TxID:            tx.Hash().String(),
+		Base58CheckData: base58.Base58Check{}.Encode(byteArrays, 0x00),
+		ShardID:         common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte()),
+	}, nil
+}
@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -11,7 +12,8 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func handleCreateRawTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+// newPRVTradeRequestMetadata reads the trade request of a PRV sell from params
+func newPRVTradeRequestMetadata(params interface{}) (*metadata.PDETradeRequest, error) {
 	arrayParams := common.InterfaceSlice(params)
 
 	// get meta data from params
@@ -42,7 +44,7 @@ func handleCreateRawTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params in
 		return nil, errors.New("TradingFee is invalid")
 	}
 	tradingFee := uint64(tradingFeeData)
-	meta, _ := metadata.NewPDETradeRequest(
+	return metadata.NewPDETradeRequest(
 		tokenIDToBuyStr,
 		tokenIDToSellStr,
 		sellAmount,
@@ -51,24 +53,28 @@ func handleCreateRawTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params in
 		traderAddressStr,
 		metadata.PDETradeRequestMeta,
 	)
+}
 
-	keyWallet, err := bean.GetPrivateKey(params)
+func handleCreateRawTxWithPRVTradeReq(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	meta, err := newPRVTradeRequestMetadata(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
 	}
 
+	txService := newTxService(rpcClient, keyWallet, opts)
+
 	// create new param to build raw tx from param interface
 	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
 	if errNewParam != nil {
 		return nil, errNewParam
 	}
 
-	tx, err1 := txService.BuildRawTransaction(createRawTxParam, meta)
+	tx, err1 := txService.BuildRawTransaction(ctx, createRawTxParam, meta)
 	if err1 != nil {
 		return nil, err1
 	}
@@ -85,7 +91,11 @@ func handleCreateRawTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params in
 }
 
 func CreateAndSendTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := handleCreateRawTxWithPRVTradeReq(rpcClient, params)
+	return CreateAndSendTxWithPRVTradeReqWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendTxWithPRVTradeReqWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := handleCreateRawTxWithPRVTradeReq(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -97,4 +107,29 @@ func CreateAndSendTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params inte
 	newParam = append(newParam, base58CheckData)
 	return newParam, nil
 	//httpServer.handleSendRawTransaction(newParam, closeChan)
-}
\ No newline at end of file
+}
+
+func PlanTxWithPRVTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanTxWithPRVTradeReqWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanTxWithPRVTradeReqWithContext previews the tx CreateAndSendTxWithPRVTradeReqWithContext would create from params
+func PlanTxWithPRVTradeReqWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	meta, err := newPRVTradeRequestMetadata(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+
+	createRawTxParam, err := bean.NewCreateRawTxParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return txService.PlanRawTransaction(ctx, createRawTxParam, meta)
+}
@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -11,7 +12,8 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func handleCreateRawTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+// newPTokenTradeRequestMetadata reads the trade request of a token sell from params
+func newPTokenTradeRequestMetadata(params interface{}) (*metadata.PDETradeRequest, error) {
 	arrayParams := common.InterfaceSlice(params)
 
 	if len(arrayParams) >= 7 {
@@ -55,7 +57,7 @@ func handleCreateRawTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params
 	}
 	tradingFee := uint64(tradingFeeData)
 
-	meta, _ := metadata.NewPDETradeRequest(
+	return metadata.NewPDETradeRequest(
 		tokenIDToBuyStr,
 		tokenIDToSellStr,
 		sellAmount,
@@ -64,18 +66,22 @@ func handleCreateRawTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params
 		traderAddressStr,
 		metadata.PDETradeRequestMeta,
 	)
+}
 
-	keyWallet, err := bean.GetPrivateKey(params)
+func handleCreateRawTxWithPTokenTradeReq(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	meta, err := newPTokenTradeRequestMetadata(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
 	}
 
-	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(params, meta)
+	txService := newTxService(rpcClient, keyWallet, opts)
+
+	customTokenTx, rpcErr := txService.BuildRawPrivacyCustomTokenTransaction(ctx, params, meta)
 	if rpcErr != nil {
 		return nil, rpcErr
 	}
@@ -94,7 +100,11 @@ func handleCreateRawTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params
 }
 
 func CreateAndSendTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
-	data, err := handleCreateRawTxWithPTokenTradeReq(rpcClient, params)
+	return CreateAndSendTxWithPTokenTradeReqWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendTxWithPTokenTradeReqWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	data, err := handleCreateRawTxWithPTokenTradeReq(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,4 +116,24 @@ func CreateAndSendTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params i
 	newParam = append(newParam, base58CheckData)
 	return newParam, nil
 	//httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
-}
\ No newline at end of file
+}
+
+func PlanTxWithPTokenTradeReq(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanTxWithPTokenTradeReqWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanTxWithPTokenTradeReqWithContext previews the tx CreateAndSendTxWithPTokenTradeReqWithContext would create from params
+func PlanTxWithPTokenTradeReqWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	meta, err := newPTokenTradeRequestMetadata(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawPrivacyCustomTokenTransaction(ctx, params, meta)
+}
@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
@@ -9,18 +10,15 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func handleCreateRawPrivacyCustomTokenTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateRawPrivacyCustomTokenTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	keyWallet, err := bean.GetPrivateKey(params)
 	if err != nil {
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildRawPrivacyCustomTokenTransaction(params, nil)
+	tx, err := txService.BuildRawPrivacyCustomTokenTransaction(ctx, params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -42,8 +40,12 @@ func handleCreateRawPrivacyCustomTokenTransaction(rpcClient *rpcclient.HttpClien
 }
 
 func CreateAndSendPrivacyCustomTokenTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendPrivacyCustomTokenTransactionWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendPrivacyCustomTokenTransactionWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawPrivacyCustomTokenTransaction(rpcClient, params)
+	data, err := handleCreateRawPrivacyCustomTokenTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,3 +58,18 @@ func CreateAndSendPrivacyCustomTokenTransaction(rpcClient *rpcclient.HttpClient,
 	//txId, err := httpServer.handleSendRawPrivacyCustomTokenTransaction(newParam, closeChan)
 }
 
+func PlanPrivacyCustomTokenTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanPrivacyCustomTokenTransactionWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanPrivacyCustomTokenTransactionWithContext previews the tx CreateAndSendPrivacyCustomTokenTransactionWithContext
+// would create from params, the token coins it spends are in the Token of the plan
+func PlanPrivacyCustomTokenTransactionWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawPrivacyCustomTokenTransaction(ctx, params, nil)
+}
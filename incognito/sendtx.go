@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
@@ -9,7 +10,7 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 )
 
-func handleCreateRawTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateRawTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
 	if errNewParam != nil {
 		return nil, errNewParam
@@ -20,12 +21,9 @@ func handleCreateRawTransaction(rpcClient *rpcclient.HttpClient, params interfac
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildRawTransaction(createRawTxParam, nil)
+	tx, err := txService.BuildRawTransaction(ctx, createRawTxParam, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +44,12 @@ func handleCreateRawTransaction(rpcClient *rpcclient.HttpClient, params interfac
 }
 
 func CreateAndSendTx(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendTxWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendTxWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawTransaction(rpcClient, params)
+	data, err := handleCreateRawTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +61,24 @@ func CreateAndSendTx(rpcClient *rpcclient.HttpClient, params interface{}) (inter
 	return newParam, nil
 	//sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
 }
+
+func PlanTx(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanTxWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanTxWithContext previews the tx CreateAndSendTxWithContext would create from params, without proving or
+// sending it: the coins it spends, its change, size and fee, and whether it goes over the limits of a tx
+func PlanTxWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	createRawTxParam, err := bean.NewCreateRawTxParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawTransaction(ctx, createRawTxParam, nil)
+}
@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -14,15 +15,16 @@ import (
 	"github.com/pkg/errors"
 )
 
-func handleCreateRawStakingTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+// newStakingMetadataFromParams reads the tx params and the staking request of a staking tx from params
+func newStakingMetadataFromParams(params interface{}) (*bean.CreateRawTxParam, *metadata.StakingMetadata, error) {
 	paramsArray := common.InterfaceSlice(params)
 	if paramsArray == nil || len(paramsArray) < 5 {
-		return nil, errors.New("param must be an array at least 5 element")
+		return nil, nil, errors.New("param must be an array at least 5 element")
 	}
 
 	createRawTxParam, errNewParam := bean.NewCreateRawTxParam(params)
 	if errNewParam != nil {
-		return nil, errNewParam
+		return nil, nil, errNewParam
 	}
 
 	keyWallet := new(wallet.KeyWallet)
@@ -32,57 +34,57 @@ func handleCreateRawStakingTransaction(rpcClient *rpcclient.HttpClient, params i
 	// prepare meta data
 	data, ok := paramsArray[4].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("Invalid Data For Staking Transaction %+v", paramsArray[4])
+		return nil, nil, fmt.Errorf("Invalid Data For Staking Transaction %+v", paramsArray[4])
 	}
 
 	stakingType, ok := data["StakingType"].(int)
 	if !ok {
-		return nil, fmt.Errorf("Invalid Staking Type For Staking Transaction %+v", data["StakingType"])
+		return nil, nil, fmt.Errorf("Invalid Staking Type For Staking Transaction %+v", data["StakingType"])
 	}
 
 	candidatePaymentAddress, ok := data["CandidatePaymentAddress"].(string)
 	if !ok {
-		return nil, fmt.Errorf("Invalid Producer Payment Address for Staking Transaction %+v", data["CandidatePaymentAddress"])
+		return nil, nil, fmt.Errorf("Invalid Producer Payment Address for Staking Transaction %+v", data["CandidatePaymentAddress"])
 	}
 
 	// Get private seed, a.k.a mining key
 	privateSeed, ok := data["PrivateSeed"].(string)
 	if !ok {
-		return nil, fmt.Errorf("Invalid Private Seed For Staking Transaction %+v", data["PrivateSeed"])
+		return nil, nil, fmt.Errorf("Invalid Private Seed For Staking Transaction %+v", data["PrivateSeed"])
 	}
 
 	privateSeedBytes, ver, errDecode := base58.Base58Check{}.Decode(privateSeed)
 	if (errDecode != nil) || (ver != common.ZeroByte) {
-		return nil, errors.New("Decode privateseed failed!")
+		return nil, nil, errors.New("Decode privateseed failed!")
 	}
 
 	//Get RewardReceiver Payment Address
 	rewardReceiverPaymentAddress, ok := data["RewardReceiverPaymentAddress"].(string)
 	if !ok {
-		return nil, fmt.Errorf("Invalid Reward Receiver Payment Address For Staking Transaction %+v", data["RewardReceiverPaymentAddress"])
+		return nil, nil, fmt.Errorf("Invalid Reward Receiver Payment Address For Staking Transaction %+v", data["RewardReceiverPaymentAddress"])
 	}
 
 	//Get auto staking flag
 	autoReStaking, ok := data["AutoReStaking"].(bool)
 	if !ok {
-		return nil, fmt.Errorf("Invalid auto restaking flag %+v", data["AutoReStaking"])
+		return nil, nil, fmt.Errorf("Invalid auto restaking flag %+v", data["AutoReStaking"])
 	}
 
 	// Get candidate publickey
 	candidateWallet, err := wallet.Base58CheckDeserialize(candidatePaymentAddress)
 	if err != nil || candidateWallet == nil {
-		return nil, errors.New("Base58CheckDeserialize candidate Payment Address failed")
+		return nil, nil, errors.New("Base58CheckDeserialize candidate Payment Address failed")
 	}
 	pk := candidateWallet.KeySet.PaymentAddress.Pk
 
 	committeePK, err := incognitokey.NewCommitteeKeyFromSeed(privateSeedBytes, pk)
 	if err != nil {
-		return nil, errors.New("Cannot get payment address")
+		return nil, nil, errors.New("Cannot get payment address")
 	}
 
 	committeePKBytes, err := committeePK.Bytes()
 	if err != nil {
-		return nil, errors.New("Cannot import key set")
+		return nil, nil, errors.New("Cannot import key set")
 	}
 
 	stakingMetadata, err := metadata.NewStakingMetadata(
@@ -94,6 +96,14 @@ func handleCreateRawStakingTransaction(rpcClient *rpcclient.HttpClient, params i
 		autoReStaking,
 	)
 
+	if err != nil {
+		return nil, nil, err
+	}
+	return createRawTxParam, stakingMetadata, nil
+}
+
+func handleCreateRawStakingTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
+	createRawTxParam, stakingMetadata, err := newStakingMetadataFromParams(params)
 	if err != nil {
 		return nil, err
 	}
@@ -103,12 +113,9 @@ func handleCreateRawStakingTransaction(rpcClient *rpcclient.HttpClient, params i
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet1,
-	}
+	txService := newTxService(rpcClient, keyWallet1, opts)
 
-	txID, err := txService.BuildRawTransaction(createRawTxParam, stakingMetadata)
+	txID, err := txService.BuildRawTransaction(ctx, createRawTxParam, stakingMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -130,8 +137,12 @@ func handleCreateRawStakingTransaction(rpcClient *rpcclient.HttpClient, params i
 }
 
 func CreateAndSendStakingTx(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendStakingTxWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendStakingTxWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawStakingTransaction(rpcClient, params)
+	data, err := handleCreateRawStakingTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -143,3 +154,23 @@ func CreateAndSendStakingTx(rpcClient *rpcclient.HttpClient, params interface{})
 	return newParam, nil
 	//sendResult, err := httpServer.handleSendRawTransaction(newParam, closeChan)
 }
+
+func PlanStakingTx(rpcClient *rpcclient.HttpClient, params interface{}) (*rpcservice.TxPlan, error) {
+	return PlanStakingTxWithContext(context.Background(), rpcClient, params)
+}
+
+// PlanStakingTxWithContext previews the tx CreateAndSendStakingTxWithContext would create from params
+func PlanStakingTxWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (*rpcservice.TxPlan, error) {
+	createRawTxParam, stakingMetadata, err := newStakingMetadataFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := bean.GetPrivateKey(params)
+	if err != nil {
+		return nil, err
+	}
+
+	txService := newTxService(rpcClient, keyWallet, opts)
+	return txService.PlanRawTransaction(ctx, createRawTxParam, stakingMetadata)
+}
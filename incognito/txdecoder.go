@@ -0,0 +1,52 @@
+package incognito
+
+import (
+	"context"
+	"errors"
+
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// DecodeTransaction decodes the Base58CheckData of a created tx. With a readonlyKey, the values of the
+// output coins sent to it are decrypted; pass "" to only read what is public.
+func DecodeTransaction(base58CheckData string, readonlyKey string) (*transaction.DecodedTx, error) {
+	viewingKey, err := viewingKeyFromReadonlyKey(readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	return transaction.DecodeRawTx(base58CheckData, viewingKey)
+}
+
+func GetDecodedTransaction(rpcClient *rpcclient.HttpClient, txHash string, readonlyKey string) (*transaction.DecodedTx, error) {
+	return GetDecodedTransactionWithContext(context.Background(), rpcClient, txHash, readonlyKey)
+}
+
+// GetDecodedTransactionWithContext fetches the tx txHash with gettransactionbyhash and decodes it as DecodeTransaction does
+func GetDecodedTransactionWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, txHash string, readonlyKey string) (*transaction.DecodedTx, error) {
+	viewingKey, err := viewingKeyFromReadonlyKey(readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := rpcclient.GetTransactionByHashWithContext(ctx, rpcClient, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return transaction.DecodeTxDetail(detail, viewingKey)
+}
+
+func viewingKeyFromReadonlyKey(readonlyKey string) (*privacy.ViewingKey, error) {
+	if readonlyKey == "" {
+		return nil, nil
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return nil, errors.New("readonly key is invalid")
+	}
+	return &keyWallet.KeySet.ReadonlyKey, nil
+}
@@ -0,0 +1,69 @@
+package incognito
+
+import (
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// TxOption customizes how the functions of the package build a transaction
+type TxOption func(*rpcservice.TxService)
+
+/*
+WithCoinSelector chooses the coins the transaction spends with selector instead of rpcservice.DefaultCoinSelector
+
+Example:
+
+	result, err := incognito.CreateAndSendTxWithContext(ctx, rpcClient, params, incognito.WithCoinSelector(rpcservice.MinInputsSelector{}))
+*/
+func WithCoinSelector(selector rpcservice.CoinSelector) TxOption {
+	return func(txService *rpcservice.TxService) {
+		txService.CoinSelector = selector
+	}
+}
+
+// WithFeeEstimators estimates the fee of the transaction with the local fee estimator of the shard of the sender,
+// the fullnode is asked, with estimatefeewithestimator, when the shard has none or it has too little data
+func WithFeeEstimators(estimators map[byte]*mempool.FeeEstimator) TxOption {
+	return func(txService *rpcservice.TxService) {
+		txService.FeeEstimator = estimators
+	}
+}
+
+/*
+WithCoinReservation reserves the coins the transaction spends under reservation instead of under a new reservation
+in rpcservice.DefaultCoinLocker, so that they can be released when the transaction is not sent. A nil reservation
+reserves no coins.
+
+Example:
+
+	reservation := rpcservice.DefaultCoinLocker.NewReservation()
+	raw, err := incognito.CreateAndSendTxWithContext(ctx, rpcClient, params, incognito.WithCoinReservation(reservation))
+	if err != nil {
+		return err
+	}
+	if err := rpcClient.RPCCall("sendtransaction", raw, &result); err != nil {
+		reservation.Release()
+		return err
+	}
+*/
+func WithCoinReservation(reservation *rpcservice.CoinReservation) TxOption {
+	return func(txService *rpcservice.TxService) {
+		txService.CoinReservation = reservation
+	}
+}
+
+// newTxService returns the TxService building a tx of keyWallet, customized by opts. Unless opts say otherwise,
+// the coins of the tx are reserved in rpcservice.DefaultCoinLocker until it is confirmed or the reservation times out.
+func newTxService(rpcClient *rpcclient.HttpClient, keyWallet *wallet.KeyWallet, opts []TxOption) *rpcservice.TxService {
+	txService := &rpcservice.TxService{
+		RpcClient:       rpcClient,
+		KeyWallet:       keyWallet,
+		CoinReservation: rpcservice.DefaultCoinLocker.NewReservation(),
+	}
+	for _, opt := range opts {
+		opt(txService)
+	}
+	return txService
+}
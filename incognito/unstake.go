@@ -1,6 +1,7 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -9,12 +10,11 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/metadata"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
-	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
 	"github.com/pkg/errors"
 )
 
-func handleCreateRawStopAutoStakingTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateRawStopAutoStakingTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	// get component
 	paramsArray := common.InterfaceSlice(params)
 	if paramsArray == nil || len(paramsArray) < 5 {
@@ -85,12 +85,9 @@ func handleCreateRawStopAutoStakingTransaction(rpcClient *rpcclient.HttpClient,
 		return nil, err
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet1,
-	}
+	txService := newTxService(rpcClient, keyWallet1, opts)
 
-	txID, err := txService.BuildRawTransaction(createRawTxParam, stakingMetadata)
+	txID, err := txService.BuildRawTransaction(ctx, createRawTxParam, stakingMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +109,12 @@ func handleCreateRawStopAutoStakingTransaction(rpcClient *rpcclient.HttpClient,
 }
 
 func CreateAndSendStopAutoStakingTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendStopAutoStakingTransactionWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendStopAutoStakingTransactionWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawStopAutoStakingTransaction(rpcClient, params)
+	data, err := handleCreateRawStopAutoStakingTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
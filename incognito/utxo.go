@@ -1,15 +1,21 @@
 package incognito
 
 import (
+	"context"
+
 	"github.com/incognitochain/go-incognito-sdk/privacy"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 )
 
 func GetUTXO(rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
-	inputCoin, err := getUnspentOutputCoinsExceptSpendingUTXO(rpcClient, privateKey, tokenId)
+	return GetUTXOWithContext(context.Background(), rpcClient, privateKey, tokenId)
+}
+
+func GetUTXOWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
+	inputCoin, err := getUnspentOutputCoinsExceptSpendingUTXO(ctx, rpcClient, privateKey, tokenId)
 	if err != nil {
 		return nil, err
 	}
 
 	return inputCoin, nil
-}
\ No newline at end of file
+}
@@ -1,17 +1,17 @@
 package incognito
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
 	"github.com/incognitochain/go-incognito-sdk/metadata"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
-	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/pkg/errors"
 )
 
-func handleCreateRawWithDrawTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+func handleCreateRawWithDrawTransaction(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	arrayParams := common.InterfaceSlice(params)
 	if arrayParams == nil || len(arrayParams) < 5 {
 		return nil, errors.New("param must be an array at least 5 elements")
@@ -59,12 +59,9 @@ func handleCreateRawWithDrawTransaction(rpcClient *rpcclient.HttpClient, params
 		return nil, errNewParam
 	}
 
-	txService := &rpcservice.TxService{
-		RpcClient: rpcClient,
-		KeyWallet: keyWallet,
-	}
+	txService := newTxService(rpcClient, keyWallet, opts)
 
-	tx, err := txService.BuildRawTransaction(createRawTxParam, meta)
+	tx, err := txService.BuildRawTransaction(ctx, createRawTxParam, meta)
 	if err != nil {
 		return nil, err
 	}
@@ -82,8 +79,12 @@ func handleCreateRawWithDrawTransaction(rpcClient *rpcclient.HttpClient, params
 }
 
 func CreateAndSendWithDrawTransaction(rpcClient *rpcclient.HttpClient, params interface{}) (interface{}, error) {
+	return CreateAndSendWithDrawTransactionWithContext(context.Background(), rpcClient, params)
+}
+
+func CreateAndSendWithDrawTransactionWithContext(ctx context.Context, rpcClient *rpcclient.HttpClient, params interface{}, opts ...TxOption) (interface{}, error) {
 	var err error
-	data, err := handleCreateRawWithDrawTransaction(rpcClient, params)
+	data, err := handleCreateRawWithDrawTransaction(ctx, rpcClient, params, opts...)
 	if err != nil {
 		return nil, err
 	}
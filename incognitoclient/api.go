@@ -1,11 +1,13 @@
 package incognitoclient
 
 import (
+	"context"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/repository"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"math/big"
 	"net/http"
 )
@@ -25,6 +27,7 @@ Creat new PublicIncognito
 Input:
 	- c: init http client module (*http.Client)
 	- endpointUri: endpoint chain url (string)
+	- opts: optional settings, see WithTransport, WithFallbackEndpoints and WithTLSConfig (...Option)
 
 Output:
 	- result: return an object (*PublicIncognito)
@@ -34,13 +37,20 @@ Example:
 	publicIncognito := NewPublicIncognito(client, "https://testnet.incognito.org/fullnode")
 
 */
-func NewPublicIncognito(c *http.Client, endpointUri string) *PublicIncognito {
+func NewPublicIncognito(c *http.Client, endpointUri string, opts ...Option) *PublicIncognito {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	transport := o.buildTransport(c, endpointUri)
+
 	inc := &service.IncogClient{
 		Client:        c,
 		ChainEndpoint: endpointUri,
+		Transport:     transport,
 	}
 
-	rpcClient := rpcclient.NewHttpClient(endpointUri, "https", endpointUri, 0)
+	rpcClient := rpcclient.NewHttpClientWithTransport(transport)
 	incIntegration := repository.NewIncChainIntegration(rpcClient)
 
 	return &PublicIncognito{incClient: inc, incIntegration: incIntegration}
@@ -80,6 +90,13 @@ func (b *BlockInfo) GetBlockInfo(blockHeight int32, shardID int) (*entity.GetBlo
 	return b.block.GetBlockInfo(blockHeight, shardID)
 }
 
+/*
+GetBlockInfoWithContext is GetBlockInfo bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetBlockInfoWithContext(ctx context.Context, blockHeight int32, shardID int) (*entity.GetBlockInfo, error) {
+	return b.block.GetBlockInfoWithContext(ctx, blockHeight, shardID)
+}
+
 /*
 GetChainInfo return info of Incognito Chain
 */
@@ -87,6 +104,13 @@ func (b *BlockInfo) GetChainInfo() (*entity.GetBlockChainInfoResult, error) {
 	return b.block.GetBlockChainInfo()
 }
 
+/*
+GetChainInfoWithContext is GetChainInfo bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetChainInfoWithContext(ctx context.Context) (*entity.GetBlockChainInfoResult, error) {
+	return b.block.GetBlockChainInfoWithContext(ctx)
+}
+
 /*
 GetBestBlockHeight return block height current of any shard id
 */
@@ -94,6 +118,13 @@ func (b *BlockInfo) GetBestBlockHeight(shardID int) (uint64, error) {
 	return b.block.GetBestBlockHeight(shardID)
 }
 
+/*
+GetBestBlockHeightWithContext is GetBestBlockHeight bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetBestBlockHeightWithContext(ctx context.Context, shardID int) (uint64, error) {
+	return b.block.GetBestBlockHeightWithContext(ctx, shardID)
+}
+
 /*
 GetBeaconHeight return beacon height current
 */
@@ -101,6 +132,13 @@ func (b *BlockInfo) GetBeaconHeight() (int32, error) {
 	return b.block.GetBeaconHeight()
 }
 
+/*
+GetBeaconHeightWithContext is GetBeaconHeight bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetBeaconHeightWithContext(ctx context.Context) (int32, error) {
+	return b.block.GetBeaconHeightWithContext(ctx)
+}
+
 /*
 GetBeaconBestStateDetail return beacon stage detail current
 */
@@ -108,6 +146,13 @@ func (b *BlockInfo) GetBeaconBestStateDetail() (res *entity.BeaconBestStateResp,
 	return b.block.GetBeaconBestStateDetail()
 }
 
+/*
+GetBeaconBestStateDetailWithContext is GetBeaconBestStateDetail bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetBeaconBestStateDetailWithContext(ctx context.Context) (res *entity.BeaconBestStateResp, err error) {
+	return b.block.GetBeaconBestStateDetailWithContext(ctx)
+}
+
 /*
 GetBurningAddress return burn address of chain, burn address has burned token
 */
@@ -115,6 +160,13 @@ func (b *BlockInfo) GetBurningAddress() (string, error) {
 	return b.block.GetBurningAddress()
 }
 
+/*
+GetBurningAddressWithContext is GetBurningAddress bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *BlockInfo) GetBurningAddressWithContext(ctx context.Context) (string, error) {
+	return b.block.GetBurningAddressWithContext(ctx)
+}
+
 type PDex struct {
 	public *PublicIncognito
 	pdex   *repository.Pdex
@@ -132,9 +184,13 @@ type PDex struct {
 		wallet := NewWallet(publicIncognito, blockInfo)
 		pdex := NewPDex(publicIncognito, blockInfo)
 
+	opts are optional settings, see WithFeeEstimators and WithTxTracker
 */
-func NewPDex(public *PublicIncognito, block *BlockInfo) *PDex {
-	pdex := repository.NewPdex(public.incClient, public.GetPRVToken(), block.block)
+func NewPDex(public *PublicIncognito, block *BlockInfo, opts ...WalletOption) *PDex {
+	o := newWalletOptions(opts)
+	pdex := repository.NewPdex(public.incClient, public.GetPRVToken(), block.block, public.incIntegration)
+	pdex.FeeEstimators = o.feeEstimators
+	pdex.TxTracker = o.txTracker
 	return &PDex{public: public, pdex: pdex}
 }
 
@@ -145,6 +201,13 @@ func (b *PDex) GetPDexState(beaconHeight int32) (map[string]interface{}, error)
 	return b.pdex.GetPDexState(beaconHeight)
 }
 
+/*
+GetPDexStateWithContext is GetPDexState bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *PDex) GetPDexStateWithContext(ctx context.Context, beaconHeight int32) (map[string]interface{}, error) {
+	return b.pdex.GetPDexStateWithContext(ctx, beaconHeight)
+}
+
 /*
 TradePDex will trade pair token, sell this token and buy that token
 
@@ -157,7 +220,8 @@ Input:
 	- minimumAmount: minimum amount can receive (uint64)
 	- traderAddress: address of trader (string)
 	- networkFeeTokenID: amount network fee to pay for trade  (string)
-	- networkFee: amount of network fee (uint64)
+	- networkFee: amount of network fee (uint64), when the fee is paid in sellTokenId and networkFee is 0
+	  the PRV fee of the trade is converted into sellTokenId at the rate of its PRV pool, see ConvertPRVToToken
 
 Output:
 	- result: tx hash (string)
@@ -182,6 +246,68 @@ func (b *PDex) TradePDex(privateKey string, buyTokenId string, tradingFee uint64
 	return b.pdex.TradePDex(privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
 }
 
+/*
+TradePDexWithContext is TradePDex bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *PDex) TradePDexWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
+	return b.pdex.TradePDexWithContext(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+/*
+PlanTradePDex previews the trade TradePDex would send with the same input, without proving nor sending it: the coins
+it spends, its change, size and fee. When the network fee is paid in sellTokenId and networkFee is 0, the fee of
+the plan is the converted PRV fee TradePDex would pay.
+
+Output:
+	- result: the plan of the trade tx (*TxPlan), the sold token part is in its Token when sellTokenId is not PRV,
+	  it is Insufficient when the PRV of the trader can not pay it
+	- err: err (error)
+
+Example:
+
+	plan, err := pdex.PlanTradePDex(privateKey, pDaiTokenId, uint64(100), PRVToken, uint64(1000000000), uint64(0), traderAddress, PRVToken, uint64(100))
+	if err == nil && plan.ExceedsMaxTxSize {
+		// consolidate the coins of the trader first
+	}
+*/
+func (b *PDex) PlanTradePDex(privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (*TxPlan, error) {
+	return b.pdex.PlanTradePDex(privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+/*
+PlanTradePDexWithContext is PlanTradePDex bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *PDex) PlanTradePDexWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (*TxPlan, error) {
+	return b.pdex.PlanTradePDexWithContext(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+/*
+ConvertPRVToToken returns what prvAmount PRV is worth in tokenId at the rate of the PRV pool of the token on the pDEX,
+plus a margin of repository.TokenFeeMarginPercent percent. It is how fees paid in a token are set.
+
+Input:
+	- prvAmount: amount of PRV (uint64)
+	- tokenId: token id to convert into (string)
+
+Output:
+	- result: amount of token (uint64)
+	- err: err (error), when the token has no pool with PRV
+
+Example:
+
+	pDaiFee, err := pdex.ConvertPRVToToken(uint64(100), "c7545459764224a000a9b323850648acf271186238210ce474b505cd17cc93a0")
+*/
+func (b *PDex) ConvertPRVToToken(prvAmount uint64, tokenId string) (uint64, error) {
+	return b.pdex.ConvertPRVToToken(prvAmount, tokenId)
+}
+
+/*
+ConvertPRVToTokenWithContext is ConvertPRVToToken bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *PDex) ConvertPRVToTokenWithContext(ctx context.Context, prvAmount uint64, tokenId string) (uint64, error) {
+	return b.pdex.ConvertPRVToTokenWithContext(ctx, prvAmount, tokenId)
+}
+
 /*
 GetPDexTradeStatus return status of trade tx
 */
@@ -189,6 +315,13 @@ func (b *PDex) GetPDexTradeStatus(txId string) (constant.PDexTradeStatus, error)
 	return b.pdex.GetPDexTradeStatus(txId)
 }
 
+/*
+GetPDexTradeStatusWithContext is GetPDexTradeStatus bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *PDex) GetPDexTradeStatusWithContext(ctx context.Context, txId string) (constant.PDexTradeStatus, error) {
+	return b.pdex.GetPDexTradeStatusWithContext(ctx, txId)
+}
+
 type Stake struct {
 	public *PublicIncognito
 	stake  *repository.Stake
@@ -203,9 +336,13 @@ type Stake struct {
 		publicIncognito := NewPublicIncognito(client, "https://testnet.incognito.org/fullnode")
 		stake := NewStake(publicIncognito)
 
+	opts are optional settings, see WithFeeEstimators and WithTxTracker
 */
-func NewStake(public *PublicIncognito) *Stake {
+func NewStake(public *PublicIncognito, opts ...WalletOption) *Stake {
+	o := newWalletOptions(opts)
 	stake := repository.NewStake(public.incClient, public.incIntegration)
+	stake.FeeEstimators = o.feeEstimators
+	stake.TxTracker = o.txTracker
 	return &Stake{public: public, stake: stake}
 }
 
@@ -216,6 +353,13 @@ func (b *Stake) ListUnstake() ([]entity.Unstake, error) {
 	return b.stake.ListUnstake()
 }
 
+/*
+ListUnstakeWithContext is ListUnstake bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Stake) ListUnstakeWithContext(ctx context.Context) ([]entity.Unstake, error) {
+	return b.stake.ListUnstakeWithContext(ctx)
+}
+
 /*
 Staking is action to stake a node validator
 
@@ -235,6 +379,36 @@ func (b *Stake) Staking(receiveRewardAddress, privateKey, userPaymentAddress, us
 	return b.stake.Staking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
 }
 
+/*
+StakingWithContext is Staking bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Stake) StakingWithContext(ctx context.Context, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
+	return b.stake.StakingWithContext(ctx, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
+/*
+PlanStaking previews the tx Staking would send with the same input, without proving nor sending it: the coins it
+spends, its change, size and fee
+
+Output:
+	- result: the plan of the staking tx (*TxPlan), it is Insufficient when the PRV of the staker can not pay the stake
+	- err: err (error)
+
+Example:
+
+	plan, err := stake.PlanStaking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+*/
+func (b *Stake) PlanStaking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (*TxPlan, error) {
+	return b.stake.PlanStaking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
+/*
+PlanStakingWithContext is PlanStaking bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Stake) PlanStakingWithContext(ctx context.Context, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (*TxPlan, error) {
+	return b.stake.PlanStakingWithContext(ctx, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
 /*
 Unstaking is action to unstake node validator
 
@@ -253,6 +427,13 @@ func (b *Stake) Unstaking(privateKey, userPaymentAddress, userValidatorKey, burn
 	return b.stake.Unstaking(privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
 }
 
+/*
+UnstakingWithContext is Unstaking bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Stake) UnstakingWithContext(ctx context.Context, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
+	return b.stake.UnstakingWithContext(ctx, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
 /*
 WithDrawReward is action to withdraw all reward earn of node validator
 
@@ -270,6 +451,13 @@ func (b *Stake) WithDrawReward(privateKey, paymentAddress, tokenId string) (stri
 	return b.stake.WithDrawReward(privateKey, paymentAddress, tokenId)
 }
 
+/*
+WithDrawRewardWithContext is WithDrawReward bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Stake) WithDrawRewardWithContext(ctx context.Context, privateKey, paymentAddress, tokenId string) (string, error) {
+	return b.stake.WithDrawRewardWithContext(ctx, privateKey, paymentAddress, tokenId)
+}
+
 /*
 GetRewardAmount return list amount reward each token
 
@@ -285,6 +473,13 @@ func (b *Stake) GetRewardAmount(paymentAddress string) ([]entity.RewardItems, er
 	return b.stake.GetRewardAmount(paymentAddress)
 }
 
+/*
+GetRewardAmountWithContext is GetRewardAmount bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Stake) GetRewardAmountWithContext(ctx context.Context, paymentAddress string) ([]entity.RewardItems, error) {
+	return b.stake.GetRewardAmountWithContext(ctx, paymentAddress)
+}
+
 /*
 ListRewardAmounts return list reward Prv amount all node validator
 
@@ -298,6 +493,13 @@ func (b *Stake) ListRewardAmounts() ([]entity.RewardAmount, error) {
 	return b.stake.ListRewardAmounts()
 }
 
+/*
+ListRewardAmountsWithContext is ListRewardAmounts bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Stake) ListRewardAmountsWithContext(ctx context.Context) ([]entity.RewardAmount, error) {
+	return b.stake.ListRewardAmountsWithContext(ctx)
+}
+
 /*
 GetStatusNodeValidator return status of node validator
 
@@ -313,6 +515,13 @@ func (b *Stake) GetStatusNodeValidator(validatorKey string) (float64, error) {
 	return b.stake.GetNodeAvailable(validatorKey)
 }
 
+/*
+GetStatusNodeValidatorWithContext is GetStatusNodeValidator bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Stake) GetStatusNodeValidatorWithContext(ctx context.Context, validatorKey string) (float64, error) {
+	return b.stake.GetNodeAvailableWithContext(ctx, validatorKey)
+}
+
 /*
 GetTotalStaker return total staker
 
@@ -325,6 +534,13 @@ func (b *Stake) GetTotalStaker() (float64, error) {
 	return b.stake.GetTotalStaker()
 }
 
+/*
+GetTotalStakerWithContext is GetTotalStaker bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Stake) GetTotalStakerWithContext(ctx context.Context) (float64, error) {
+	return b.stake.GetTotalStakerWithContext(ctx)
+}
+
 type Wallet struct {
 	public *PublicIncognito
 	wallet *repository.Wallet
@@ -340,9 +556,13 @@ type Wallet struct {
 		block = NewBlockInfo(publicIncognito)
 		wallet := NewWallet(publicIncognito, block)
 
+	opts are optional settings, see WithFeeEstimators and WithTxTracker
 */
-func NewWallet(public *PublicIncognito, block *BlockInfo) *Wallet {
+func NewWallet(public *PublicIncognito, block *BlockInfo, opts ...WalletOption) *Wallet {
+	o := newWalletOptions(opts)
 	wallet := repository.NewWallet(public.incClient, public.GetPRVToken(), block.block, public.incIntegration)
+	wallet.FeeEstimators = o.feeEstimators
+	wallet.TxTracker = o.txTracker
 	return &Wallet{public: public, wallet: wallet}
 }
 
@@ -395,6 +615,13 @@ func (b *Wallet) ListPrivacyCustomToken() ([]entity.PCustomToken, error) {
 	return b.wallet.ListPrivacyCustomToken()
 }
 
+/*
+ListPrivacyCustomTokenWithContext is ListPrivacyCustomToken bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) ListPrivacyCustomTokenWithContext(ctx context.Context) ([]entity.PCustomToken, error) {
+	return b.wallet.ListPrivacyCustomTokenWithContext(ctx)
+}
+
 /*
 GetTransactionDetailByTxHash return info detail of a tx
 
@@ -410,6 +637,13 @@ func (b *Wallet) GetTransactionDetailByTxHash(txHash string) (*entity.Transactio
 	return b.wallet.GetTxByHash(txHash)
 }
 
+/*
+GetTransactionDetailByTxHashWithContext is GetTransactionDetailByTxHash bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetTransactionDetailByTxHashWithContext(ctx context.Context, txHash string) (*entity.TransactionDetail, error) {
+	return b.wallet.GetTxByHashWithContext(ctx, txHash)
+}
+
 /*
 GetMintStatusCentralized return status tx of mint action
 
@@ -425,6 +659,13 @@ func (b *Wallet) GetMintStatusCentralized(txHash string) (int, error) {
 	return b.wallet.GetBridgeReqWithStatus(txHash)
 }
 
+/*
+GetMintStatusCentralizedWithContext is GetMintStatusCentralized bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetMintStatusCentralizedWithContext(ctx context.Context, txHash string) (int, error) {
+	return b.wallet.GetBridgeReqWithStatusWithContext(ctx, txHash)
+}
+
 /*
 MintCentralizedToken is action to mint a coin with anything amount in incognito chain. Your individual chain need setup master private key, it's is only key of chain
 
@@ -456,6 +697,13 @@ func (b *Wallet) MintCentralizedToken(privateKey, receiveAddress string, deposit
 	return b.wallet.CreateAndSendIssuingRequest(privateKey, receiveAddress, depositedAmount, tokenId, tokenName)
 }
 
+/*
+MintCentralizedTokenWithContext is MintCentralizedToken bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) MintCentralizedTokenWithContext(ctx context.Context, privateKey, receiveAddress string, depositedAmount *big.Int, tokenId string, tokenName string) (string, error) {
+	return b.wallet.CreateAndSendIssuingRequestWithContext(ctx, privateKey, receiveAddress, depositedAmount, tokenId, tokenName)
+}
+
 /*
 BurnCentralizedToken is action to burn token
 
@@ -495,6 +743,37 @@ func (b *Wallet) BurnCentralizedToken(privateKey string, autoChargePRVFee int, m
 	return b.wallet.CreateAndSendContractingRequestForPrivacyToken(privateKey, autoChargePRVFee, metadata)
 }
 
+/*
+BurnCentralizedTokenWithContext is BurnCentralizedToken bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) BurnCentralizedTokenWithContext(ctx context.Context, privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (string, error) {
+	return b.wallet.CreateAndSendContractingRequestForPrivacyTokenWithContext(ctx, privateKey, autoChargePRVFee, metadata)
+}
+
+/*
+PlanBurnCentralizedToken previews the tx BurnCentralizedToken would send with the same input, without proving nor
+sending it: the coins it spends, its change, size and fee
+
+Output:
+	- result: the plan of the burning tx (*TxPlan), the burnt token part is in its Token, it is Insufficient
+	  when the PRV of the account can not pay the fee
+	- error: error (error)
+
+Example:
+
+	plan, err := wallet.PlanBurnCentralizedToken(masterPrivKey, autoChargePRVFee, metadata)
+*/
+func (b *Wallet) PlanBurnCentralizedToken(privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (*TxPlan, error) {
+	return b.wallet.PlanContractingRequestForPrivacyToken(privateKey, autoChargePRVFee, metadata)
+}
+
+/*
+PlanBurnCentralizedTokenWithContext is PlanBurnCentralizedToken bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Wallet) PlanBurnCentralizedTokenWithContext(ctx context.Context, privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (*TxPlan, error) {
+	return b.wallet.PlanContractingRequestForPrivacyTokenWithContext(ctx, privateKey, autoChargePRVFee, metadata)
+}
+
 /*
 MintDecentralizedToken is action to mint decentralized token as ETH, Erc20. To done this action, first you must deposit coin to Ethereum chain after that you need get proof deposit which to mint token
 
@@ -529,6 +808,13 @@ func (b *Wallet) MintDecentralizedToken(privateKey, burnerAddress string, metada
 	return b.wallet.CreateAndSendTxWithIssuingEth(privateKey, burnerAddress, metadata)
 }
 
+/*
+MintDecentralizedTokenWithContext is MintDecentralizedToken bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) MintDecentralizedTokenWithContext(ctx context.Context, privateKey, burnerAddress string, metadata map[string]interface{}) (txHash string, res []byte, err error) {
+	return b.wallet.CreateAndSendTxWithIssuingEthWithContext(ctx, privateKey, burnerAddress, metadata)
+}
+
 /*
 BurnDecentralizedToken is action to burn eth, erc20 token. Advantage, after burn token you can get proof burn to deposit amount to smart contract
 
@@ -557,6 +843,37 @@ func (b *Wallet) BurnDecentralizedToken(incPrivateKey string, amount *big.Int, r
 	return b.wallet.CreateAndSendBurningForDepositToSCRequest(incPrivateKey, amount, receiverAddress, tokenId)
 }
 
+/*
+BurnDecentralizedTokenWithContext is BurnDecentralizedToken bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) BurnDecentralizedTokenWithContext(ctx context.Context, incPrivateKey string, amount *big.Int, receiverAddress string, tokenId string) (*entity.BurningForDepositToSCRes, error) {
+	return b.wallet.CreateAndSendBurningForDepositToSCRequestWithContext(ctx, incPrivateKey, amount, receiverAddress, tokenId)
+}
+
+/*
+PlanBurnDecentralizedToken previews the tx BurnDecentralizedToken would send with the same input, without proving nor
+sending it: the coins it spends, its change, size and fee
+
+Output:
+	- result: the plan of the burning tx (*TxPlan), the burnt token part is in its Token, it is Insufficient
+	  when the PRV of the account can not pay the fee
+	- error: error (error)
+
+Example:
+
+	plan, err := wallet.PlanBurnDecentralizedToken(incPrivateKey, big.NewInt(1000000), addStr[2:], tokenId)
+*/
+func (b *Wallet) PlanBurnDecentralizedToken(incPrivateKey string, amount *big.Int, receiverAddress string, tokenId string) (*TxPlan, error) {
+	return b.wallet.PlanBurningForDepositToSCRequest(incPrivateKey, amount, receiverAddress, tokenId)
+}
+
+/*
+PlanBurnDecentralizedTokenWithContext is PlanBurnDecentralizedToken bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Wallet) PlanBurnDecentralizedTokenWithContext(ctx context.Context, incPrivateKey string, amount *big.Int, receiverAddress string, tokenId string) (*TxPlan, error) {
+	return b.wallet.PlanBurningForDepositToSCRequestWithContext(ctx, incPrivateKey, amount, receiverAddress, tokenId)
+}
+
 /*
 GenerateTokenID return new token id, input token info defined by yourself. Note you shouldn't generate new token which is exist token
 
@@ -577,6 +894,13 @@ func (b *Wallet) GenerateTokenID(symbol, pSymbol string) (string, error) {
 	return b.wallet.GenerateTokenID(symbol, pSymbol)
 }
 
+/*
+GenerateTokenIDWithContext is GenerateTokenID bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GenerateTokenIDWithContext(ctx context.Context, symbol, pSymbol string) (string, error) {
+	return b.wallet.GenerateTokenIDWithContext(ctx, symbol, pSymbol)
+}
+
 /*
 GetPublicKeyFromPaymentAddress return public key of payment address
 
@@ -592,7 +916,15 @@ func (b *Wallet) GetPublicKeyFromPaymentAddress(paymentAddress string) (string,
 }
 
 /*
-GetTransactionByReceiversAddress return list transaction detail of payment address
+GetPublicKeyFromPaymentAddressWithContext is GetPublicKeyFromPaymentAddress bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetPublicKeyFromPaymentAddressWithContext(ctx context.Context, paymentAddress string) (string, error) {
+	return b.wallet.GetPublickeyFromPaymentAddressWithContext(ctx, paymentAddress)
+}
+
+/*
+GetTransactionByReceiversAddress return list transaction detail of payment address, the memos encrypted to it
+are decrypted in the Memo of the coins
 
 Input:
 	- paymentAddress: payment address (string)
@@ -607,6 +939,45 @@ func (b *Wallet) GetTransactionByReceiversAddress(paymentAddress, readonlyKey st
 	return b.wallet.GetTransactionByReceivers(paymentAddress, readonlyKey)
 }
 
+/*
+GetTransactionByReceiversAddressWithContext is GetTransactionByReceiversAddress bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetTransactionByReceiversAddressWithContext(ctx context.Context, paymentAddress, readonlyKey string) (*entity.ReceivedTransactions, error) {
+	return b.wallet.GetTransactionByReceiversWithContext(ctx, paymentAddress, readonlyKey)
+}
+
+/*
+GetIncomingCoins return the coins of a token received by an account, spent or not, with the memo of each.
+A memo the sender encrypted to the account, see entity.Payout.EncryptMemo, is decrypted with its readonly key.
+
+Input:
+	- paymentAddress: payment address (string)
+	- readonlyKey: read only key (string)
+	- tokenId: token (string)
+
+Output:
+	- coins: commitment, value and memo of every coin received ([]entity.IncomingCoin)
+	- error: error (error)
+
+Example:
+	coins, err := wallet.GetIncomingCoins("12RqaTLErSnN88pGgXaKmw1PSQEaG86FA4uJsm32RZetAy7e5yEncqjTC6QJcMRjMfTSc48tcWRTyy8FoB9VkCHu56Vd9b86gd8Pq8k", "13hVWYbeNVnQGw7cHCRkjdArr5ERq7M1dxn7tQ88GUVnUP7Uf3G8S6WnGP6u3oX2oo8Yh6zyuy4Yh7aQcNwDHwzN7Su7cVM7ut2tjtg", PRVToken)
+	for _, coin := range coins {
+		if coin.EncryptedMemo {
+			fmt.Println(coin.Memo, coin.Value)
+		}
+	}
+*/
+func (b *Wallet) GetIncomingCoins(paymentAddress string, readonlyKey string, tokenId string) ([]entity.IncomingCoin, error) {
+	return b.wallet.GetIncomingCoins(paymentAddress, readonlyKey, tokenId)
+}
+
+/*
+GetIncomingCoinsWithContext is GetIncomingCoins bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetIncomingCoinsWithContext(ctx context.Context, paymentAddress string, readonlyKey string, tokenId string) ([]entity.IncomingCoin, error) {
+	return b.wallet.GetIncomingCoinsWithContext(ctx, paymentAddress, readonlyKey, tokenId)
+}
+
 /*
 GetBalance return current balance of wallet
 
@@ -629,6 +1000,13 @@ func (b *Wallet) GetBalance(privateKey string, tokenId string) (uint64, error) {
 	return b.wallet.GetBalance(privateKey, tokenId)
 }
 
+/*
+GetBalanceWithContext is GetBalance bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (uint64, error) {
+	return b.wallet.GetBalanceWithContext(ctx, privateKey, tokenId)
+}
+
 /*
 GetTransactionAmount return amount of transaction
 
@@ -646,6 +1024,13 @@ func (b *Wallet) GetTransactionAmount(txId string, walletAddress string, readOnl
 	return b.wallet.GetTransactionAmount(txId, walletAddress, readOnlyKey)
 }
 
+/*
+GetTransactionAmountWithContext is GetTransactionAmount bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetTransactionAmountWithContext(ctx context.Context, txId string, walletAddress string, readOnlyKey string) (uint64, error) {
+	return b.wallet.GetTransactionAmountWithContext(ctx, txId, walletAddress, readOnlyKey)
+}
+
 /*
 SendToken is action to send token or prv to receiver address
 
@@ -656,6 +1041,7 @@ Input:
 	- amount: amount to send (uint64)
 	- fee: amount fee (uint64)
 	- feeTokenId: token id of fee (string)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
 
 if you send Prv: you ignore fee and feeTokenId.
 
@@ -696,8 +1082,163 @@ Example:
 		100,
 		"ffd8d42dc40a8d166ea4848baf8b5f6e9fe0e9c30d60062eb7d44a8df9e00854")
 */
-func (b *Wallet) SendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string) (string, error) {
-	return b.wallet.SendToken(privateKey, receiverAddress, tokenId, amount, fee, feeTokenId)
+func (b *Wallet) SendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string, opts ...SendOption) (string, error) {
+	return b.wallet.SendToken(privateKey, receiverAddress, tokenId, amount, fee, feeTokenId, opts...)
+}
+
+/*
+SendTokenWithContext is SendToken bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) SendTokenWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string, opts ...SendOption) (string, error) {
+	return b.wallet.SendTokenWithContext(ctx, privateKey, receiverAddress, tokenId, amount, fee, feeTokenId, opts...)
+}
+
+// TxPlan previews a transaction without proving nor sending it: the coins it spends, its change, size and fee, and
+// whether it goes over the limits of a transaction, see rpcservice.TxPlan
+type TxPlan = rpcservice.TxPlan
+
+/*
+PlanSendToken previews the tx SendToken would send, paying the fee in PRV, without proving nor sending it
+
+Input:
+	- privateKey: private key of sender (string)
+	- receiverAddress: payment address of receiver (string)
+	- tokenId: token id to send (string)
+	- amount: amount to send (uint64)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: the plan of the tx (*TxPlan), the token part is in its Token when tokenId is not PRV, it is Insufficient
+	  when the PRV of the sender can not pay amount and the fee
+	- error: error (error)
+
+Example:
+
+	plan, err := wallet.PlanSendToken(privateKey, receiverAddress, PRVToken, 500000000000)
+	if err == nil && plan.ExceedsMaxInputs {
+		// send it with SendTokenWithPolicy
+	}
+*/
+func (b *Wallet) PlanSendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...SendOption) (*TxPlan, error) {
+	return b.wallet.PlanSendToken(privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+/*
+PlanSendTokenWithContext is PlanSendToken bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Wallet) PlanSendTokenWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...SendOption) (*TxPlan, error) {
+	return b.wallet.PlanSendTokenWithContext(ctx, privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+/*
+EstimateTokenFee returns the fee, paid in tokenId, of a tx sending receivers their amount of tokenId. It is the PRV fee
+of the tx converted at the rate of the PRV pool of the token on the pDEX, plus a margin, see PDex.ConvertPRVToToken
+
+Input:
+	- privateKey: private key of sender (string)
+	- tokenId: token id to send, not PRV (string)
+	- receivers: amount to send by payment address of receiver (map[string]uint64)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: fee in token (uint64)
+	- err: err (error)
+
+Example:
+
+	fee, err := wallet.EstimateTokenFee(
+		"112t8rnXVMJJZzfF1naXvfE9nkTKwUwFWFeh8cfEyViG1vpA8A9khJk3ApWGgzSyH3aqHgxxEsdyTzr7yJDzKLNE4KWcuN6JXdUfyLhm5Q9c",
+		"4584d5e9b2fc0337dfb17f4b5bb025e5b82c38cfa4f54e8a3d4fcdd03954ff82",
+		map[string]uint64{"12S5pBBRDf1GqfRHouvCV86sWaHzNfvakAWpVMvNnWu2k299xWCgQzLLc9wqPYUHfMYGDprPvQ794dbi6UU1hfRN4tPiU61txWWenhC": 1000})
+*/
+func (b *Wallet) EstimateTokenFee(privateKey string, tokenId string, receivers map[string]uint64, opts ...SendOption) (uint64, error) {
+	return b.wallet.EstimateTokenFee(privateKey, tokenId, receivers, opts...)
+}
+
+/*
+EstimateTokenFeeWithContext is EstimateTokenFee bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Wallet) EstimateTokenFeeWithContext(ctx context.Context, privateKey string, tokenId string, receivers map[string]uint64, opts ...SendOption) (uint64, error) {
+	return b.wallet.EstimateTokenFeeWithContext(ctx, privateKey, tokenId, receivers, opts...)
+}
+
+/*
+SendTokenWithTokenFee sends amount of tokenId to receiverAddress paying the network fee in tokenId, the fee is
+EstimateTokenFee. A sender holding no PRV can send its tokens this way.
+
+Input:
+	- privateKey: private key of sender (string)
+	- receiverAddress: payment address of receiver (string)
+	- tokenId: token id to send, not PRV (string)
+	- amount: amount to send (uint64)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: tx hash (string)
+	- err: err (error)
+
+Example:
+
+	tx, err := wallet.SendTokenWithTokenFee(
+		"112t8rnXVMJJZzfF1naXvfE9nkTKwUwFWFeh8cfEyViG1vpA8A9khJk3ApWGgzSyH3aqHgxxEsdyTzr7yJDzKLNE4KWcuN6JXdUfyLhm5Q9c",
+		"12S5pBBRDf1GqfRHouvCV86sWaHzNfvakAWpVMvNnWu2k299xWCgQzLLc9wqPYUHfMYGDprPvQ794dbi6UU1hfRN4tPiU61txWWenhC",
+		"4584d5e9b2fc0337dfb17f4b5bb025e5b82c38cfa4f54e8a3d4fcdd03954ff82",
+		uint64(1000))
+*/
+func (b *Wallet) SendTokenWithTokenFee(privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...SendOption) (string, error) {
+	return b.wallet.SendTokenWithTokenFee(privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+/*
+SendTokenWithTokenFeeWithContext is SendTokenWithTokenFee bound to ctx, in-flight RPC calls and proof building are
+cancelled once ctx is done
+*/
+func (b *Wallet) SendTokenWithTokenFeeWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...SendOption) (string, error) {
+	return b.wallet.SendTokenWithTokenFeeWithContext(ctx, privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+/*
+SendBatch pays many recipients of PRV or a pToken in as few txs as possible, up to 31 payouts by tx.
+A batch too large for a tx is split. A payout with an invalid address, a zero amount or a memo too large
+is not sent, the others still are. A memo is public unless EncryptMemo is set, it is then encrypted to the
+receiver, who reads it with GetIncomingCoins.
+
+Input:
+	- privateKey: incognito private key (string)
+	- tokenId: PRV or pToken (string)
+	- payouts: payment address, amount and memo of every payout ([]entity.Payout)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: status, tx hash and error of every payout, tx hashes, counts of failed and unknown payouts (*entity.BatchPayoutResult)
+	- error: error, set when a payout failed or its status is unknown (error)
+
+A payout whose tx was sent without an answer of the node has the status entity.PayoutUnknown and the hash of its tx:
+look the tx up before paying it again.
+
+Example:
+	result, err := wallet.SendBatch("112t8s4Pdng512MhHmLVJNYqzoEJQ1TG4XZduvjfwYZFJhmuNtGPhUYRko4jSPFBFmeRg6bumKQuhAEMriQ72cpp5SKAkRuXfLCv5xeZx3f5", PRVToken, []entity.Payout{
+		{PaymentAddress: "12RqaTLErSnN88pGgXaKmw1PSQEaG86FA4uJsm32RZetAy7e5yEncqjTC6QJcMRjMfTSc48tcWRTyy8FoB9VkCHu56Vd9b86gd8Pq8k", Amount: 1e9, Memo: "deposit 1042", EncryptMemo: true},
+		{PaymentAddress: "12S5Lrs1XeQLbqN4ySyKtjAjd2d7sBP2tjFijzmp6avrrkQCNFMpkXm3FPzj2Wcu2ZNqJEmh9JriVuRErVwhuQnLmWSaggobEWsBEci", Amount: 2e9},
+	})
+	if err != nil {
+		for _, payout := range result.Results {
+			if payout.Err != nil {
+				fmt.Println(payout.PaymentAddress, payout.Err)
+			}
+		}
+	}
+*/
+func (b *Wallet) SendBatch(privateKey string, tokenId string, payouts []entity.Payout, opts ...SendOption) (*entity.BatchPayoutResult, error) {
+	return b.wallet.SendBatch(privateKey, tokenId, payouts, opts...)
+}
+
+/*
+SendBatchWithContext is SendBatch bound to ctx. When the account runs out of coins, the txs already sent are waited for
+with the TxTracker of the wallet, see WithTxTracker, or one of its own.
+*/
+func (b *Wallet) SendBatchWithContext(ctx context.Context, privateKey string, tokenId string, payouts []entity.Payout, opts ...SendOption) (*entity.BatchPayoutResult, error) {
+	return b.wallet.SendBatchWithContext(ctx, privateKey, tokenId, payouts, opts...)
 }
 
 /*
@@ -708,6 +1249,7 @@ Input:
 	- maxValue: Max value (int64)
 		maxValue: it is useful with Prv token
 	- tokenId: token (string)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
 
 Output:
 	- result: tx hash (string)
@@ -722,12 +1264,98 @@ Example:
 
 
 */
-func (b *Wallet) Defragmentation(privateKey string, maxValue int64, tokenId string) (string, error) {
+func (b *Wallet) Defragmentation(privateKey string, maxValue int64, tokenId string, opts ...SendOption) (string, error) {
 	if tokenId == b.public.GetPRVToken() {
-		return b.wallet.DefragmentationPrv(privateKey, maxValue)
+		return b.wallet.DefragmentationPrv(privateKey, maxValue, opts...)
 	}
 
-	return b.wallet.DefragmentationPToken(privateKey, tokenId)
+	return b.wallet.DefragmentationPToken(privateKey, tokenId, opts...)
+}
+
+/*
+DefragmentationWithContext is Defragmentation bound to ctx, in-flight RPC calls and proof building are cancelled once ctx is done
+*/
+func (b *Wallet) DefragmentationWithContext(ctx context.Context, privateKey string, maxValue int64, tokenId string, opts ...SendOption) (string, error) {
+	if tokenId == b.public.GetPRVToken() {
+		return b.wallet.DefragmentationPrvWithContext(ctx, privateKey, maxValue, opts...)
+	}
+
+	return b.wallet.DefragmentationPTokenWithContext(ctx, privateKey, tokenId, opts...)
+}
+
+/*
+Consolidate merges the utxos of a token in as many rounds of defragmentation as needed, for accounts holding
+more coins than one tx can spend. Every round splits the coins in batches of config.BatchSize, sends a tx per
+batch, at once or one after another, and waits for them to be confirmed before the next round.
+
+Input:
+	- privateKey: incognito private key (string)
+	- tokenId: token (string)
+	- config: batch size, coins to keep, max value of the PRV coins merged, progress callback (entity.ConsolidationConfig)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: rounds, tx hashes, coins before and after, fee spent (*entity.ConsolidationResult)
+	- error: error (error)
+
+Example:
+	result, err := wallet.Consolidate("112t8s4Pdng512MhHmLVJNYqzoEJQ1TG4XZduvjfwYZFJhmuNtGPhUYRko4jSPFBFmeRg6bumKQuhAEMriQ72cpp5SKAkRuXfLCv5xeZx3f5", PRVToken, entity.ConsolidationConfig{
+		OnProgress: func(progress entity.ConsolidationProgress) {
+			fmt.Println(progress.Round, progress.Coins, progress.FeeSpent)
+		},
+	})
+*/
+func (b *Wallet) Consolidate(privateKey string, tokenId string, config entity.ConsolidationConfig, opts ...SendOption) (*entity.ConsolidationResult, error) {
+	return b.wallet.Consolidate(privateKey, tokenId, config, opts...)
+}
+
+/*
+ConsolidateWithContext is Consolidate bound to ctx. The txs are waited for with the TxTracker of the wallet,
+see WithTxTracker, or one of its own.
+*/
+func (b *Wallet) ConsolidateWithContext(ctx context.Context, privateKey string, tokenId string, config entity.ConsolidationConfig, opts ...SendOption) (*entity.ConsolidationResult, error) {
+	return b.wallet.ConsolidateWithContext(ctx, privateKey, tokenId, config, opts...)
+}
+
+/*
+SendTokenWithPolicy sends amount of tokenId, PRV or a pToken, to receiverAddress paying the fee in PRV. Unlike SendToken,
+the tx is planned first: when it would spend more than 255 coins or be larger than the maximum size of a tx, policy
+tells what to do.
+	- entity.OversizeFail fails with rpcclient.ErrTxTooLarge
+	- entity.OversizeConsolidate merges the coins of the account first, see Consolidate, then sends one tx
+	- entity.OversizeSplit pays the amount in as many txs as needed
+
+Input:
+	- privateKey: private key of sender (string)
+	- receiverAddress: payment address of receiver (string)
+	- tokenId: token id to send (string)
+	- amount: amount to send (uint64)
+	- policy: what to do with a tx over the limits (entity.OversizePolicy)
+	- opts: optional settings, see WithCoinSelector (...SendOption)
+
+Output:
+	- result: every tx created, the consolidation ones then the payment ones with their amounts (*entity.SendResult)
+	- err: err (error), the result then tells the txs sent so far
+
+Example:
+
+	result, err := wallet.SendTokenWithPolicy(
+		"112t8rnXVMJJZzfF1naXvfE9nkTKwUwFWFeh8cfEyViG1vpA8A9khJk3ApWGgzSyH3aqHgxxEsdyTzr7yJDzKLNE4KWcuN6JXdUfyLhm5Q9c",
+		"12S5pBBRDf1GqfRHouvCV86sWaHzNfvakAWpVMvNnWu2k299xWCgQzLLc9wqPYUHfMYGDprPvQ794dbi6UU1hfRN4tPiU61txWWenhC",
+		t.client.GetPRVToken(),
+		uint64(1000000000),
+		entity.OversizeSplit)
+*/
+func (b *Wallet) SendTokenWithPolicy(privateKey string, receiverAddress string, tokenId string, amount uint64, policy entity.OversizePolicy, opts ...SendOption) (*entity.SendResult, error) {
+	return b.wallet.SendTokenWithPolicy(privateKey, receiverAddress, tokenId, amount, policy, opts...)
+}
+
+/*
+SendTokenWithPolicyWithContext is SendTokenWithPolicy bound to ctx. The consolidation txs, and the split txs when the
+account runs out of coins, are waited for with the TxTracker of the wallet, see WithTxTracker, or one of its own.
+*/
+func (b *Wallet) SendTokenWithPolicyWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, policy entity.OversizePolicy, opts ...SendOption) (*entity.SendResult, error) {
+	return b.wallet.SendTokenWithPolicyWithContext(ctx, privateKey, receiverAddress, tokenId, amount, policy, opts...)
 }
 
 /*
@@ -744,3 +1372,36 @@ Output:
 func (b *Wallet) GetUTXO(privateKey string, tokenId string) ([]*entity.Utxo, error) {
 	return b.wallet.GetUTXO(privateKey, tokenId)
 }
+
+/*
+GetUTXOWithContext is GetUTXO bound to ctx, the RPC call is cancelled once ctx is done
+*/
+func (b *Wallet) GetUTXOWithContext(ctx context.Context, privateKey string, tokenId string) ([]*entity.Utxo, error) {
+	return b.wallet.GetUTXOWithContext(ctx, privateKey, tokenId)
+}
+
+/*
+GetAccountBalance return the balance of wallet computed from its unspent coins, split into the Available amount
+and the Pending amount spent by the txs sent by this process and not confirmed yet
+
+Input:
+	- privateKey: incognito private key (string)
+	- tokenId: token (string)
+
+Output:
+	- result: balance (*entity.AccountBalance)
+	- error: error (error)
+
+Example:
+	balance, err := wallet.GetAccountBalance("112t8s4Pdng512MhHmLVJNYqzoEJQ1TG4XZduvjfwYZFJhmuNtGPhUYRko4jSPFBFmeRg6bumKQuhAEMriQ72cpp5SKAkRuXfLCv5xeZx3f5", "0000000000000000000000000000000000000000000000000000000000000004")
+*/
+func (b *Wallet) GetAccountBalance(privateKey string, tokenId string) (*entity.AccountBalance, error) {
+	return b.wallet.GetAccountBalance(privateKey, tokenId)
+}
+
+/*
+GetAccountBalanceWithContext is GetAccountBalance bound to ctx, the RPC calls are cancelled once ctx is done
+*/
+func (b *Wallet) GetAccountBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (*entity.AccountBalance, error) {
+	return b.wallet.GetAccountBalanceWithContext(ctx, privateKey, tokenId)
+}
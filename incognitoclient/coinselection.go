@@ -0,0 +1,31 @@
+package incognitoclient
+
+import (
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+)
+
+// Coin selection strategies of the SDK, see rpcservice.CoinSelector
+type (
+	CoinSelector           = rpcservice.CoinSelector
+	DefaultCoinSelector    = rpcservice.DefaultCoinSelector
+	BranchAndBoundSelector = rpcservice.BranchAndBoundSelector
+	MinInputsSelector      = rpcservice.MinInputsSelector
+	ConsolidationSelector  = rpcservice.ConsolidationSelector
+	PrivacyAwareSelector   = rpcservice.PrivacyAwareSelector
+)
+
+// SendOption customizes how a send of Wallet builds its transactions, see WithCoinSelector
+type SendOption = incognito.TxOption
+
+/*
+WithCoinSelector makes a send of Wallet, of PRV or tokens, a batch, a defragmentation or a consolidation, spend the
+coins selector chooses instead of the ones of DefaultCoinSelector
+
+Example:
+
+	txID, err := wallet.SendToken(privateKey, receiver, tokenID, amount, 0, PRVToken, incognitoclient.WithCoinSelector(incognitoclient.PrivacyAwareSelector{}))
+*/
+func WithCoinSelector(selector CoinSelector) SendOption {
+	return incognito.WithCoinSelector(selector)
+}
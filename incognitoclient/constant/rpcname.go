@@ -1,5 +1,6 @@
 package constant
 
+// RPC methods of the fullnode, their typed requests and results are in the entity package
 const (
 	// blockchain network
 	Getblockchaininfo     = "getblockchaininfo"
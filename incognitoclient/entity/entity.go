@@ -8,8 +8,58 @@ type WalletSend struct {
 	TokenName        string
 	TokenSymbol      string
 	PaymentAddresses map[string]uint64
-	TokenAmount      uint64
-	TokenFee         uint64
+	// Payouts, when set, are paid in place of PaymentAddresses
+	Payouts     []Payout
+	TokenAmount uint64
+	TokenFee    uint64
+}
+
+// Payout is a payment of Amount to PaymentAddress, Memo is stored in the output coin
+type Payout struct {
+	PaymentAddress string
+	Amount         uint64
+	Memo           string
+	// EncryptMemo encrypts Memo to PaymentAddress, only its readonly key reads it
+	EncryptMemo bool
+}
+
+// IncomingCoin is a coin received by an account with the memo of its sender
+type IncomingCoin struct {
+	CoinCommitment string
+	Value          uint64
+	Memo           string
+	// EncryptedMemo is set when Memo was encrypted to the account, and so written by the sender only
+	EncryptedMemo bool
+}
+
+// PayoutStatus tells whether a payout of a batch is paid
+type PayoutStatus int
+
+const (
+	// PayoutPaid is paid by the tx TxID, which the node accepted
+	PayoutPaid PayoutStatus = iota
+	// PayoutFailed is not paid: the payout is invalid or the node rejected its tx, Err tells why
+	PayoutFailed
+	// PayoutUnknown may be paid: the send of the tx TxID failed, Err, without the node rejecting it.
+	// Look the tx up before paying the payout again.
+	PayoutUnknown
+)
+
+// PayoutResult is the outcome of a payout of a batch: the tx paying it, or why it was not paid
+type PayoutResult struct {
+	Payout
+	Status PayoutStatus
+	TxID   string
+	Err    error
+}
+
+// BatchPayoutResult is the outcome of a batch of payouts, Results are in the order of the payouts.
+// Failed counts the payouts not paid, Unknown the ones which may be paid, see PayoutStatus.
+type BatchPayoutResult struct {
+	Results []PayoutResult
+	TxIDs   []string
+	Failed  int
+	Unknown int
 }
 
 type ReportPdex struct {
@@ -110,6 +160,8 @@ type Coin struct {
 	SerialNumber   string
 	Value          uint64
 	Info           string
+	// Memo is the memo encrypted to the receiver in Info, decrypted with its readonly key
+	Memo string `json:"-"`
 }
 
 type TransactionDetail struct {
@@ -212,13 +264,9 @@ type CommitteeKeySetAutoStake struct {
 }
 
 type BeaconBestStateResp struct {
-	Result struct {
-		RewardReceiver map[string]string             `json:"RewardReceiver"`
-		ShardCommittee map[byte][]CommitteeKeyString `json:"ShardCommittee"`
-		AutoStaking    []CommitteeKeySetAutoStake    `json:"AutoStaking"`
-	} `json:"Result"`
-	Error *string `json:"Error"`
-	ID    int     `json:"Id"`
+	Result BeaconBestStateDetail `json:"Result"`
+	Error  *string               `json:"Error"`
+	ID     int                   `json:"Id"`
 }
 
 type DecrypTransactionPRV struct {
@@ -256,6 +304,69 @@ type Utxo struct {
 	SerialNumber string
 }
 
+// AccountBalance is the balance of an account split into what it can spend and what its pending txs spend
+type AccountBalance struct {
+	Available uint64
+	Pending   uint64
+}
+
+// ConsolidationConfig tunes a consolidation, the zero value merges every coin in parallel rounds
+type ConsolidationConfig struct {
+	// MaxValue leaves the PRV coins worth more out, zero for no limit. pToken coins are all merged.
+	MaxValue int64
+	// BatchSize is the most coins merged by one tx, default and maximum rpcservice.MaxDefragmentQuantity
+	BatchSize int
+	// TargetCoins is the number of coins under which the consolidation stops, default 1
+	TargetCoins int
+	// Sequential sends one tx at a time instead of every batch of a round at once
+	Sequential bool
+	// OnProgress, when set, is called once every tx of a round is confirmed
+	OnProgress func(ConsolidationProgress)
+}
+
+// ConsolidationProgress reports a consolidation round
+type ConsolidationProgress struct {
+	Round int
+	// TxIDs are the txs of the round
+	TxIDs []string
+	// Coins is the number of coins left to merge after the round
+	Coins int
+	// FeeSpent is the PRV fee paid by the txs of every round so far
+	FeeSpent uint64
+}
+
+// ConsolidationResult sums up a consolidation
+type ConsolidationResult struct {
+	Rounds int
+	TxIDs  []string
+	// CoinsBefore and CoinsAfter count the coins eligible to merge
+	CoinsBefore int
+	CoinsAfter  int
+	FeeSpent    uint64
+}
+
+// OversizePolicy tells a send what to do when its tx would spend more coins than a tx holds or be too large
+type OversizePolicy int
+
+const (
+	// OversizeFail fails the send with rpcclient.ErrTxTooLarge
+	OversizeFail OversizePolicy = iota
+	// OversizeConsolidate merges the coins of the account first, then sends in one tx
+	OversizeConsolidate
+	// OversizeSplit pays the amount in several txs, each within the limits of a tx
+	OversizeSplit
+)
+
+// SendResult reports every tx a send created, in the order they were sent
+type SendResult struct {
+	// ConsolidationTxIDs merged the coins of the account before the payment, see OversizeConsolidate
+	ConsolidationTxIDs []string
+	// PaymentTxIDs paid the receiver, more than one when the payment was split, see OversizeSplit
+	PaymentTxIDs []string
+	// Amounts are the amounts paid by PaymentTxIDs
+	Amounts []uint64
+}
+
 type TotalStaker struct {
 	TotalStaker  uint64
 }
\ No newline at end of file
@@ -0,0 +1,240 @@
+package entity
+
+import "github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+
+// Typed requests of the RPC calls made by the repository, one per method of constant/rpcname.go.
+// Method is the RPC name, Params the positional parameters the node expects.
+
+type RetrieveBlockByHeightReq struct {
+	BlockHeight int32
+	ShardID     int
+	Verbosity   string
+}
+
+func (r RetrieveBlockByHeightReq) Method() string { return constant.Retrieveblockbyheight }
+func (r RetrieveBlockByHeightReq) Params() interface{} {
+	return []interface{}{r.BlockHeight, r.ShardID, r.Verbosity}
+}
+
+type GetBlockChainInfoReq struct{}
+
+func (r GetBlockChainInfoReq) Method() string      { return constant.Getblockchaininfo }
+func (r GetBlockChainInfoReq) Params() interface{} { return []interface{}{} }
+
+// GetBlockCountReq asks for the best height of ShardID, -1 for the beacon
+type GetBlockCountReq struct {
+	ShardID int
+}
+
+func (r GetBlockCountReq) Method() string      { return constant.GetBlockCount }
+func (r GetBlockCountReq) Params() interface{} { return []interface{}{r.ShardID} }
+
+type GetBeaconBestStateDetailReq struct{}
+
+func (r GetBeaconBestStateDetailReq) Method() string      { return constant.GetBeaconBestStateDetail }
+func (r GetBeaconBestStateDetailReq) Params() interface{} { return []interface{}{} }
+
+// GetBurningAddressReq asks for the burning address at BeaconHeight, zero for the current one
+type GetBurningAddressReq struct {
+	BeaconHeight int32
+}
+
+func (r GetBurningAddressReq) Method() string { return constant.GetBurningAddress }
+func (r GetBurningAddressReq) Params() interface{} {
+	if r.BeaconHeight <= 0 {
+		return []interface{}{}
+	}
+	return []interface{}{r.BeaconHeight}
+}
+
+type GetTotalStakerReq struct{}
+
+func (r GetTotalStakerReq) Method() string      { return constant.GetTotalStaker }
+func (r GetTotalStakerReq) Params() interface{} { return []interface{}{} }
+
+type GetRewardAmountReq struct {
+	PaymentAddress string
+}
+
+func (r GetRewardAmountReq) Method() string      { return constant.RewardAmount }
+func (r GetRewardAmountReq) Params() interface{} { return []interface{}{r.PaymentAddress} }
+
+type GetRoleByValidatorKeyReq struct {
+	ValidatorKey string
+}
+
+func (r GetRoleByValidatorKeyReq) Method() string      { return constant.RoleByValidatorKey }
+func (r GetRoleByValidatorKeyReq) Params() interface{} { return []interface{}{r.ValidatorKey} }
+
+type ListRewardAmountReq struct{}
+
+func (r ListRewardAmountReq) Method() string      { return constant.ListRewardAmount }
+func (r ListRewardAmountReq) Params() interface{} { return []interface{}{} }
+
+type GetPdeStateReq struct {
+	BeaconHeight int32
+}
+
+func (r GetPdeStateReq) Method() string { return constant.GetPdeState }
+func (r GetPdeStateReq) Params() interface{} {
+	return []interface{}{map[string]interface{}{"BeaconHeight": r.BeaconHeight}}
+}
+
+type GetPDETradeStatusReq struct {
+	TxRequestIDStr string
+}
+
+func (r GetPDETradeStatusReq) Method() string { return constant.GetPDETradeStatus }
+func (r GetPDETradeStatusReq) Params() interface{} {
+	return []interface{}{map[string]interface{}{"TxRequestIDStr": r.TxRequestIDStr}}
+}
+
+// TradeReq is a pDEX trade built and sent by the node, TradeMethod is one of the
+// CreateAndSendTxWith*TradeReq methods and Args their positional parameters
+type TradeReq struct {
+	TradeMethod string
+	Args        []interface{}
+}
+
+func (r TradeReq) Method() string      { return r.TradeMethod }
+func (r TradeReq) Params() interface{} { return r.Args }
+
+// SendTransactionReq sends a PRV transaction built and signed locally
+type SendTransactionReq struct {
+	RawData interface{}
+}
+
+func (r SendTransactionReq) Method() string      { return constant.SendRawTransaction }
+func (r SendTransactionReq) Params() interface{} { return r.RawData }
+
+// SendRawPrivacyCustomTokenTransactionReq sends a token transaction built and signed locally
+type SendRawPrivacyCustomTokenTransactionReq struct {
+	RawData interface{}
+}
+
+func (r SendRawPrivacyCustomTokenTransactionReq) Method() string {
+	return constant.SendRawPrivacyCustomTokenTransaction
+}
+func (r SendRawPrivacyCustomTokenTransactionReq) Params() interface{} { return r.RawData }
+
+type GetBalanceByPaymentAddressReq struct {
+	PaymentAddress string
+}
+
+func (r GetBalanceByPaymentAddressReq) Method() string { return constant.GetBalanceByPaymentAddress }
+func (r GetBalanceByPaymentAddressReq) Params() interface{} {
+	return []interface{}{r.PaymentAddress}
+}
+
+type GetListCustomTokenBalanceReq struct {
+	PaymentAddress string
+}
+
+func (r GetListCustomTokenBalanceReq) Method() string { return constant.GetListCustomTokenBalance }
+func (r GetListCustomTokenBalanceReq) Params() interface{} {
+	return []interface{}{r.PaymentAddress}
+}
+
+type GetAmountVoteTokenReq struct {
+	PaymentAddress string
+}
+
+func (r GetAmountVoteTokenReq) Method() string      { return constant.GetAmountVoteToken }
+func (r GetAmountVoteTokenReq) Params() interface{} { return []interface{}{r.PaymentAddress, 0} }
+
+type EstimateFeeReq struct {
+	PrivateKey       string
+	PaymentAddresses map[string]uint64
+}
+
+func (r EstimateFeeReq) Method() string { return constant.GetEstimateFee }
+func (r EstimateFeeReq) Params() interface{} {
+	return []interface{}{r.PrivateKey, r.PaymentAddresses, -1, 0}
+}
+
+type ListPrivacyCustomTokenReq struct{}
+
+func (r ListPrivacyCustomTokenReq) Method() string      { return constant.ListPrivacyCustomToken }
+func (r ListPrivacyCustomTokenReq) Params() interface{} { return []interface{}{} }
+
+type GetTransactionByHashReq struct {
+	TxHash string
+}
+
+func (r GetTransactionByHashReq) Method() string      { return constant.GetTransactionByHash }
+func (r GetTransactionByHashReq) Params() interface{} { return []string{r.TxHash} }
+
+type DecryptOutputCoinByKeyOfTransactionReq struct {
+	TxHash         string
+	PaymentAddress string
+	ReadonlyKey    string
+}
+
+func (r DecryptOutputCoinByKeyOfTransactionReq) Method() string {
+	return constant.DecryptOutputCoinByKeyOfTransaction
+}
+func (r DecryptOutputCoinByKeyOfTransactionReq) Params() interface{} {
+	return []interface{}{r.TxHash, map[string]string{"PaymentAddress": r.PaymentAddress, "ReadonlyKey": r.ReadonlyKey}}
+}
+
+type GetIssuingStatusReq struct {
+	TxHash string
+}
+
+func (r GetIssuingStatusReq) Method() string      { return constant.GetIssuingStatus }
+func (r GetIssuingStatusReq) Params() interface{} { return []string{r.TxHash} }
+
+type GetContractingStatusReq struct {
+	TxHash string
+}
+
+func (r GetContractingStatusReq) Method() string      { return constant.GetContractingStatus }
+func (r GetContractingStatusReq) Params() interface{} { return []string{r.TxHash} }
+
+type GetBridgeReqWithStatusReq struct {
+	TxReqID string
+}
+
+func (r GetBridgeReqWithStatusReq) Method() string { return constant.GetBridgeReqWithStatus }
+func (r GetBridgeReqWithStatusReq) Params() interface{} {
+	return []interface{}{map[string]interface{}{"TxReqID": r.TxReqID}}
+}
+
+type WithDrawRewardReq struct {
+	PrivateKey string
+	TokenID    string
+}
+
+func (r WithDrawRewardReq) Method() string { return constant.WithDrawReward }
+func (r WithDrawRewardReq) Params() interface{} {
+	return []interface{}{r.PrivateKey, 0, 0, 0, map[string]interface{}{"TokenID": r.TokenID}}
+}
+
+type GenerateTokenIDReq struct {
+	Symbol  string
+	PSymbol string
+}
+
+func (r GenerateTokenIDReq) Method() string      { return constant.GenerateTokenID }
+func (r GenerateTokenIDReq) Params() interface{} { return []interface{}{r.Symbol, r.PSymbol} }
+
+type GetPublickeyFromPaymentAddressReq struct {
+	PaymentAddress string
+}
+
+func (r GetPublickeyFromPaymentAddressReq) Method() string {
+	return constant.GetPublickeyFromPaymentAddress
+}
+func (r GetPublickeyFromPaymentAddressReq) Params() interface{} {
+	return []interface{}{r.PaymentAddress}
+}
+
+type GetTransactionByReceiverReq struct {
+	PaymentAddress string
+	ReadonlyKey    string
+}
+
+func (r GetTransactionByReceiverReq) Method() string { return constant.GetTransactionByReceiver }
+func (r GetTransactionByReceiverReq) Params() interface{} {
+	return []interface{}{map[string]string{"PaymentAddress": r.PaymentAddress, "ReadonlyKey": r.ReadonlyKey}}
+}
@@ -0,0 +1,52 @@
+package entity
+
+// Typed results of the RPC calls made by the repository, decoded from the Result field of the node answer.
+// Results already modelled elsewhere in this package (TransactionDetail, ListCustomTokenBalance, ...) are reused as is.
+
+// TxIDResult is answered by every call sending a transaction
+type TxIDResult struct {
+	TxID    string `json:"TxID"`
+	ShardID byte   `json:"ShardID"`
+}
+
+// BeaconBestStateDetail is the Result of getbeaconbeststatedetail
+type BeaconBestStateDetail struct {
+	RewardReceiver map[string]string             `json:"RewardReceiver"`
+	ShardCommittee map[byte][]CommitteeKeyString `json:"ShardCommittee"`
+	AutoStaking    []CommitteeKeySetAutoStake    `json:"AutoStaking"`
+}
+
+type RoleByValidatorKeyResult struct {
+	Role int `json:"Role"`
+}
+
+// RewardAmountResult maps a token id to the reward of one payment address
+type RewardAmountResult map[string]float64
+
+// ListRewardAmountResult maps a public key to its rewards per token id
+type ListRewardAmountResult map[string]map[string]uint64
+
+type EstimateFeeResult struct {
+	EstimateFeeCoinPerKb uint64 `json:"EstimateFeeCoinPerKb"`
+	EstimateTxSizeInKb   uint64 `json:"EstimateTxSizeInKb"`
+}
+
+type ListPrivacyCustomTokenResult struct {
+	ListCustomToken []PCustomToken `json:"ListCustomToken"`
+}
+
+type IssuingStatusResult struct {
+	Status string `json:"Status"`
+	Amount uint64 `json:"Amount"`
+}
+
+type ContractingStatusResult struct {
+	Status string `json:"Status"`
+	Redeem string `json:"Redeem"`
+}
+
+type PublicKeyResult struct {
+	PublicKeyInBase58Check string `json:"PublicKeyInBase58Check"`
+	PublicKeyInBytes       []int  `json:"PublicKeyInBytes"`
+	PublicKeyInHex         string `json:"PublicKeyInHex"`
+}
@@ -0,0 +1,31 @@
+package incognitoclient
+
+import "github.com/incognitochain/go-incognito-sdk/rpcclient"
+
+// RPCError is the error answered by the fullnode, its Code is the one of the node
+type RPCError = rpcclient.RPCError
+
+// UnreachableError is returned when a call got no answer from the node
+type UnreachableError = rpcclient.UnreachableError
+
+/*
+Errors returned, wrapped, by every method of the facade for the common chain rejections, whether
+the node refused the call or the SDK refused to build the transaction.
+
+Example:
+
+	txID, err := wallet.SendToken(privateKey, receiver, PRVToken, amount, 0, PRVToken)
+	if errors.Is(err, incognitoclient.ErrNotEnoughCoin) {
+		// wait for more coins
+	}
+	var rpcErr *incognitoclient.RPCError
+	if errors.As(err, &rpcErr) {
+		fmt.Println(rpcErr.Code)
+	}
+*/
+var (
+	ErrNotEnoughCoin   = rpcclient.ErrNotEnoughCoin
+	ErrDoubleSpend     = rpcclient.ErrDoubleSpend
+	ErrTxTooLarge      = rpcclient.ErrTxTooLarge
+	ErrNodeUnreachable = rpcclient.ErrNodeUnreachable
+)
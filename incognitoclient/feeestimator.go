@@ -0,0 +1,43 @@
+package incognitoclient
+
+import (
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+)
+
+// Local fee estimation, see mempool.FeeEstimator
+type (
+	FeeEstimator    = mempool.FeeEstimator
+	FeeTxDesc       = mempool.TxDesc
+	FeeBlock        = mempool.Block
+	CoinPerKilobyte = mempool.CoinPerKilobyte
+)
+
+/*
+NewFeeEstimator returns a fee estimator answering once minRegisteredBlocks blocks of its shard are registered,
+see mempool.NewFeeEstimator. Its PRV estimates are never below limitFee per kb.
+
+Example:
+
+	estimator := incognitoclient.NewFeeEstimator(mempool.DefaultEstimateFeeMaxRollback, mempool.DefaultEstimateFeeMinRegisteredBlocks, 0)
+	for _, tx := range mempoolTxs {
+		estimator.ObserveTransaction(&incognitoclient.FeeTxDesc{Hash: tx.Hash, FeePerKb: mempool.NewCoinPerKilobyte(tx.Fee, tx.SizeInKb)})
+	}
+	err := estimator.RegisterBlock(&incognitoclient.FeeBlock{Hash: block.Hash, Height: block.Height, Txs: blockTxs})
+
+	wallet := incognitoclient.NewWallet(publicIncognito, blockInfo, incognitoclient.WithFeeEstimators(map[byte]*incognitoclient.FeeEstimator{0: estimator}))
+	txID, err := wallet.SendToken(privateKey, receiver, PRVToken, amount, 0, "")
+*/
+func NewFeeEstimator(maxRollback uint32, minRegisteredBlocks uint32, limitFee uint64) *FeeEstimator {
+	return mempool.NewFeeEstimator(maxRollback, minRegisteredBlocks, limitFee)
+}
+
+/*
+WithFeeEstimators makes the transactions built by a Wallet, Stake or PDex pay the fee estimated locally by the
+estimator of the shard of the sender, estimators are the fee estimators by shard. The fullnode is asked, with
+estimatefeewithestimator, when the shard has no estimator or its estimator has too little data.
+*/
+func WithFeeEstimators(estimators map[byte]*FeeEstimator) WalletOption {
+	return func(o *walletOptions) {
+		o.feeEstimators = estimators
+	}
+}
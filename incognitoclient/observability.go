@@ -0,0 +1,38 @@
+package incognitoclient
+
+import "github.com/incognitochain/go-incognito-sdk/common"
+
+// Logging and observability types of the SDK
+type (
+	Logger   = common.Logger
+	LogLevel = common.LogLevel
+	Step     = common.Step
+	Observer = common.Observer
+)
+
+/*
+SetLogger sends the log messages of every package of the SDK, transaction building included, to logger.
+The SDK logs nothing until it is called; nil discards the messages again.
+Keys and raw transactions are never logged, debug messages still carry amounts and shard ids.
+
+Example:
+
+	incognitoclient.SetLogger(common.NewLogger(os.Stderr, common.LevelInfo))
+*/
+func SetLogger(logger Logger) {
+	common.SetLogger(logger)
+}
+
+/*
+SetObserver calls observer after every RPC sent to a node and every proof generation step,
+with the method or proof name, the duration, the error and the error code answered by the node.
+
+Example:
+
+	incognitoclient.SetObserver(func(step incognitoclient.Step) {
+		rpcDuration.WithLabelValues(step.Kind, step.Name, strconv.Itoa(step.Code)).Observe(step.Duration.Seconds())
+	})
+*/
+func SetObserver(observer Observer) {
+	common.SetObserver(observer)
+}
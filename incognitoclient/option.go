@@ -0,0 +1,117 @@
+package incognitoclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+)
+
+// Option customizes how NewPublicIncognito talks to the chain
+type Option func(*options)
+
+type options struct {
+	transport rpcclient.Transport
+	fallbacks []string
+	failover  rpcclient.FailoverConfig
+	tls       *rpcclient.TLSConfig
+}
+
+/*
+WithTransport sends every call of the facade, both the plain RPC calls and the ones made while building transactions, through transport
+
+Example:
+
+	publicIncognito := NewPublicIncognito(nil, "", WithTransport(myTransport))
+*/
+func WithTransport(transport rpcclient.Transport) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+/*
+WithFallbackEndpoints adds endpoints tried in order after the one given to NewPublicIncognito.
+Read calls fail over and retry with jittered exponential backoff, calls sending a transaction only fail over when the node could not be reached.
+
+Example:
+
+	publicIncognito := NewPublicIncognito(
+		client,
+		"https://testnet.incognito.org/fullnode",
+		WithFallbackEndpoints(rpcclient.FailoverConfig{MaxAttempts: 5}, "https://testnet1.example.org/fullnode"),
+	)
+*/
+func WithFallbackEndpoints(config rpcclient.FailoverConfig, endpoints ...string) Option {
+	return func(o *options) {
+		o.fallbacks = append(o.fallbacks, endpoints...)
+		o.failover = config
+	}
+}
+
+/*
+WithTLSConfig sets the root CAs, pinned public keys and client certificates used for https endpoints.
+The http.Client given to NewPublicIncognito is copied, its *http.Transport is cloned and never modified.
+A custom RoundTripper can not be given the TLS config, every call then fails rather than ignoring it.
+
+Example:
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+	publicIncognito := NewPublicIncognito(client, "https://fullnode.example.org", WithTLSConfig(rpcclient.TLSConfig{RootCAs: pool}))
+*/
+func WithTLSConfig(config rpcclient.TLSConfig) Option {
+	return func(o *options) {
+		o.tls = &config
+	}
+}
+
+// WalletOption customizes how a Wallet, Stake or PDex builds its transactions, see WithFeeEstimators and WithTxTracker
+type WalletOption func(*walletOptions)
+
+type walletOptions struct {
+	feeEstimators map[byte]*FeeEstimator
+	txTracker     *TxTracker
+}
+
+func newWalletOptions(opts []WalletOption) *walletOptions {
+	o := &walletOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// failedTransport fails every call with err, the options could not build a transport
+type failedTransport struct {
+	err error
+}
+
+func (t failedTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	return nil, t.err
+}
+
+// buildTransport returns the transport every call goes through
+func (o *options) buildTransport(c *http.Client, endpointUri string) rpcclient.Transport {
+	if o.transport != nil {
+		return o.transport
+	}
+
+	if c == nil {
+		c = &http.Client{Timeout: 60 * time.Second}
+	}
+	if o.tls != nil {
+		tlsClient, err := o.tls.HTTPClient(c)
+		if err != nil {
+			return failedTransport{err}
+		}
+		c = tlsClient
+	}
+
+	if len(o.fallbacks) == 0 {
+		return rpcclient.NewHTTPTransport(c, endpointUri)
+	}
+	endpoints := append([]string{endpointUri}, o.fallbacks...)
+	return rpcclient.NewHTTPFailoverTransport(c, endpoints, o.failover)
+}
@@ -0,0 +1,68 @@
+package incognitoclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTransport struct {
+	mu        sync.Mutex
+	methods   []string
+	responses map[string]string
+}
+
+func (f *fakeTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.methods = append(f.methods, method)
+	return []byte(f.responses[method]), nil
+}
+
+func TestWithTransport(t *testing.T) {
+	transport := &fakeTransport{
+		responses: map[string]string{
+			"getblockcount":    `{"Id":1,"Result":42,"Error":null}`,
+			"listoutputcoins":  `{"Id":1,"Result":{"Outputs":{}},"Error":null}`,
+			"hasserialnumbers": `{"Id":1,"Result":[],"Error":null}`,
+		},
+	}
+	publicIncognito := NewPublicIncognito(nil, "", WithTransport(transport))
+	blockInfo := NewBlockInfo(publicIncognito)
+	wallet := NewWallet(publicIncognito, blockInfo)
+
+	height, err := blockInfo.GetBestBlockHeight(0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), height)
+
+	balance, err := wallet.GetBalance("112t8s4Pdng512MhHmLVJNYqzoEJQ1TG4XZduvjfwYZFJhmuNtGPhUYRko4jSPFBFmeRg6bumKQuhAEMriQ72cpp5SKAkRuXfLCv5xeZx3f5", PRVToken)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), balance)
+
+	assert.Equal(t, []string{"getblockcount", "listoutputcoins", "hasserialnumbers"}, transport.methods)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithTLSConfigCustomRoundTripper(t *testing.T) {
+	sent := false
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = true
+		return nil, errors.New("not sent")
+	})}
+	publicIncognito := NewPublicIncognito(client, "https://fullnode.example.org", WithTLSConfig(rpcclient.TLSConfig{InsecureSkipVerify: true}))
+
+	// the call fails without reaching a node through a transport ignoring the TLS config
+	_, err := NewBlockInfo(publicIncognito).GetBestBlockHeight(0)
+	assert.Error(t, err)
+	assert.False(t, sent)
+}
@@ -1,8 +1,7 @@
 package repository
 
 import (
-	"encoding/json"
-	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"context"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
 	"github.com/pkg/errors"
@@ -17,59 +16,30 @@ func NewBlock(inc *service.IncogClient) *Block {
 }
 
 func (b *Block) GetBlockInfo(blockHeight int32, shardID int) (*entity.GetBlockInfo, error) {
-	param := []interface{}{blockHeight, shardID, "2"}
-
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.Retrieveblockbyheight, param)
+	return b.GetBlockInfoWithContext(context.Background(), blockHeight, shardID)
+}
 
-	if err != nil {
-		return nil, err
-	}
-	data := resp.(map[string]interface{})
+func (b *Block) GetBlockInfoWithContext(ctx context.Context, blockHeight int32, shardID int) (*entity.GetBlockInfo, error) {
+	req := entity.RetrieveBlockByHeightReq{BlockHeight: blockHeight, ShardID: shardID, Verbosity: "2"}
 
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-	var result entity.GetBlockInfo
-	resultResp := data["Result"].([]interface{})
-	resultRespStr, err := json.Marshal(resultResp[0])
-	if err != nil {
+	var result []entity.GetBlockInfo
+	if err := b.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return nil, err
 	}
-
-	err = json.Unmarshal(resultRespStr, &result)
-	if err != nil {
-		return nil, err
+	if len(result) == 0 {
+		return nil, errors.Errorf("no block at height %d of shard %d", blockHeight, shardID)
 	}
 
-	return &result, nil
+	return &result[0], nil
 }
 
 func (b *Block) GetBlockChainInfo() (*entity.GetBlockChainInfoResult, error) {
-	param := []interface{}{}
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.Getblockchaininfo, param)
-
-	if err != nil {
-		return nil, err
-	}
-	data := resp.(map[string]interface{})
-	resultResp := data["Result"]
-
-	result := entity.GetBlockChainInfoResult{}
-
-	if resultResp == nil {
-		return nil, errors.New("Fail")
-	}
+	return b.GetBlockChainInfoWithContext(context.Background())
+}
 
-	resultInBytes, err := json.MarshalIndent(resultResp, "", "\t")
-	if err != nil {
-		return nil, err
-	}
-
-	err = json.Unmarshal(resultInBytes, &result)
-	if err != nil {
+func (b *Block) GetBlockChainInfoWithContext(ctx context.Context) (*entity.GetBlockChainInfoResult, error) {
+	var result entity.GetBlockChainInfoResult
+	if err := b.Inc.CallWithContext(ctx, entity.GetBlockChainInfoReq{}, &result); err != nil {
 		return nil, err
 	}
 
@@ -79,32 +49,24 @@ func (b *Block) GetBlockChainInfo() (*entity.GetBlockChainInfoResult, error) {
 // GetBestBlockHeight - get height of the highest block. it could be either shard or beacon.
 // Note: it would return the highest beacon block height if shardID = -1
 func (b *Block) GetBestBlockHeight(shardID int) (uint64, error) {
-	param := []interface{}{shardID}
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.GetBlockCount, param)
-	if err != nil {
-		return 0, errors.Wrapf(err, "b.blockchainAPI: param: %+v", param)
-	}
-
-	data, ok := resp.(map[string]interface{})
+	return b.GetBestBlockHeightWithContext(context.Background(), shardID)
+}
 
-	if !ok {
-		return 0, errors.Errorf("Wrong response format, it should be an object that contains Error or Result field")
-	}
-	if data["Error"] != nil {
-		return 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
+func (b *Block) GetBestBlockHeightWithContext(ctx context.Context, shardID int) (uint64, error) {
+	var blockHeight uint64
+	err := b.Inc.CallWithContext(ctx, entity.GetBlockCountReq{ShardID: shardID}, &blockHeight)
+	if err != nil && errors.Cause(err) != service.ErrNoResult {
+		return 0, errors.Wrapf(err, "b.blockchainAPI: shardID: %d", shardID)
 	}
-	if data["Result"] == nil {
-		return 0, nil
-	}
-	blockHeight, ok := data["Result"].(float64)
-	if !ok {
-		return 0, errors.Errorf("Wrong response format, the returned block height should be a number.")
-	}
-	return uint64(blockHeight), nil
+	return blockHeight, nil
 }
 
 func (b *Block) GetBeaconHeight() (int32, error) {
-	blockChainInfo, err := b.GetBlockChainInfo()
+	return b.GetBeaconHeightWithContext(context.Background())
+}
+
+func (b *Block) GetBeaconHeightWithContext(ctx context.Context) (int32, error) {
+	blockChainInfo, err := b.GetBlockChainInfoWithContext(ctx)
 
 	if err != nil {
 		return 0, errors.Wrap(err, "b.GetBlockChainInfo")
@@ -116,14 +78,12 @@ func (b *Block) GetBeaconHeight() (int32, error) {
 }
 
 func (b *Block) GetBeaconBestStateDetail() (res *entity.BeaconBestStateResp, err error) {
-	empty := []string{}
-	body, err := b.Inc.Post(constant.GetBeaconBestStateDetail, empty)
-	if err != nil {
-		return nil, err
-	}
+	return b.GetBeaconBestStateDetailWithContext(context.Background())
+}
 
+func (b *Block) GetBeaconBestStateDetailWithContext(ctx context.Context) (res *entity.BeaconBestStateResp, err error) {
 	var resp entity.BeaconBestStateResp
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := b.Inc.CallWithContext(ctx, entity.GetBeaconBestStateDetailReq{}, &resp.Result); err != nil {
 		return nil, err
 	}
 
@@ -131,31 +91,19 @@ func (b *Block) GetBeaconBestStateDetail() (res *entity.BeaconBestStateResp, err
 }
 
 func (b *Block) GetBurningAddress() (string, error) {
-	beaconHeight, err := b.GetBeaconHeight()
-
-	if err != nil {
-		return "", errors.Wrap(err, "w.GetBeaconHeight")
-	}
+	return b.GetBurningAddressWithContext(context.Background())
+}
 
-	paramArray := []interface{}{
-		beaconHeight,
-	}
+func (b *Block) GetBurningAddressWithContext(ctx context.Context) (string, error) {
+	beaconHeight, err := b.GetBeaconHeightWithContext(ctx)
 
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.GetBurningAddress, paramArray)
 	if err != nil {
-		return "", errors.Wrapf(err, "w.blockchainAPI: param: %+v", paramArray)
+		return "", errors.Wrap(err, "w.GetBeaconHeight")
 	}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", paramArray, data)
-	}
-	result, ok := data["Result"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: param: %+v, resp: %+v", paramArray, data)
+	var result string
+	if err := b.Inc.CallWithContext(ctx, entity.GetBurningAddressReq{BeaconHeight: beaconHeight}, &result); err != nil {
+		return "", errors.Wrapf(err, "w.blockchainAPI: beaconHeight: %d", beaconHeight)
 	}
 	return result, nil
 }
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"math"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/pkg/errors"
+)
+
+func (w *Wallet) Consolidate(privateKey string, tokenId string, config entity.ConsolidationConfig, opts ...incognito.TxOption) (*entity.ConsolidationResult, error) {
+	return w.ConsolidateWithContext(context.Background(), privateKey, tokenId, config, opts...)
+}
+
+// ConsolidateWithContext merges the coins of tokenId in rounds of defragment txs until at most config.TargetCoins
+// are left. The txs are waited for with the TxTracker of w, or one of its own.
+// On error the result tells what was done so far. opts customize how the defragment txs are built.
+func (w *Wallet) ConsolidateWithContext(ctx context.Context, privateKey string, tokenId string, config entity.ConsolidationConfig, opts ...incognito.TxOption) (*entity.ConsolidationResult, error) {
+	if config.BatchSize <= 1 || config.BatchSize > rpcservice.MaxDefragmentQuantity {
+		config.BatchSize = rpcservice.MaxDefragmentQuantity
+	}
+	if config.TargetCoins <= 0 {
+		config.TargetCoins = 1
+	}
+	isPRV := tokenId == common.PRVCoinID.String()
+	if config.MaxValue <= 0 || !isPRV {
+		config.MaxValue = math.MaxInt64
+	}
+
+	w, closeTracker := w.withTxTracker()
+	defer closeTracker()
+	tracker := w.TxTracker
+
+	coins, err := w.countConsolidatedCoins(ctx, privateKey, tokenId, config.MaxValue)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.ConsolidationResult{CoinsBefore: coins, CoinsAfter: coins}
+
+	for coins > config.TargetCoins {
+		numTxs := 1
+		if !config.Sequential {
+			numTxs = consolidationTxs(coins, config.BatchSize)
+		}
+
+		var txIDs []string
+		for i := 0; i < numTxs; i++ {
+			var txID string
+			if isPRV {
+				txID, err = w.defragmentationPrv(ctx, privateKey, config.MaxValue, config.BatchSize, opts...)
+			} else {
+				txID, err = w.defragmentationPToken(ctx, privateKey, tokenId, config.BatchSize, opts...)
+			}
+			if err != nil {
+				break
+			}
+			txIDs = append(txIDs, txID)
+		}
+		// the txs sent are waited for before failing, their coins are spent anyway
+		if len(txIDs) == 0 {
+			return result, errors.Wrapf(err, "round %d", result.Rounds+1)
+		}
+		if err != nil {
+			common.Log.Debugf("consolidation round %d sends %d txs of %d: %v", result.Rounds+1, len(txIDs), numTxs, err)
+		}
+
+		result.Rounds++
+		for _, txID := range txIDs {
+			if _, err := tracker.WaitForConfirmation(ctx, txID); err != nil {
+				return result, errors.Wrapf(err, "round %d: tx %s", result.Rounds, txID)
+			}
+			result.TxIDs = append(result.TxIDs, txID)
+			tx, err := w.GetTxByHashWithContext(ctx, txID)
+			if err != nil {
+				return result, errors.Wrap(err, "w.GetTxByHash")
+			}
+			result.FeeSpent += tx.Fee
+		}
+
+		left, err := w.countConsolidatedCoins(ctx, privateKey, tokenId, config.MaxValue)
+		if err != nil {
+			return result, err
+		}
+		result.CoinsAfter = left
+		if config.OnProgress != nil {
+			config.OnProgress(entity.ConsolidationProgress{
+				Round:    result.Rounds,
+				TxIDs:    txIDs,
+				Coins:    left,
+				FeeSpent: result.FeeSpent,
+			})
+		}
+		if left >= coins {
+			return result, errors.Errorf("round %d left %d coins of %d", result.Rounds, left, coins)
+		}
+		coins = left
+	}
+	return result, nil
+}
+
+// consolidationTxs returns how many txs of batchSize coins at most merge coins in one round, a coin left alone
+// waits for the next round
+func consolidationTxs(coins int, batchSize int) int {
+	numTxs := (coins + batchSize - 1) / batchSize
+	if numTxs > 1 && coins%batchSize == 1 {
+		numTxs--
+	}
+	return numTxs
+}
+
+// countConsolidatedCoins counts the spendable coins of tokenId worth maxValue at most
+func (w *Wallet) countConsolidatedCoins(ctx context.Context, privateKey string, tokenId string, maxValue int64) (int, error) {
+	utxos, err := w.GetUTXOWithContext(ctx, privateKey, tokenId)
+	if err != nil {
+		return 0, errors.Wrap(err, "w.GetUTXO")
+	}
+	count := 0
+	for _, utxo := range utxos {
+		if utxo.Value <= uint64(maxValue) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -1,10 +1,16 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
 )
 
 type IntegrationInterface interface {
@@ -23,6 +29,23 @@ type IntegrationInterface interface {
 	CreateWalletAddress() (*wallet.KeySerializedData, error)
 	CreateNewWalletByShardId(shardId int) (*wallet.KeySerializedData, error)
 	GetUTXO(privateKey string, tokenId string) ([]*privacy.InputCoin, error)
+	GetAccountBalance(privateKey string, tokenId string) (*incognito.AccountBalance, error)
+	CreateAndSendConstantTransactionWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error)
+	SendPrivacyCustomTokenTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendIssuingRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendTxWithIssuingEthWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendBurningForDepositToSCRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendContractingRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	GetBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (uint64, error)
+	CreateAndSendStakingTxWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendStopAutoStakingTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	CreateAndSendWithDrawTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error)
+	DefragmentationPrvWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error)
+	DefragmentationPTokenWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error)
+	GetUTXOWithContext(ctx context.Context, privateKey string, tokenId string) ([]*privacy.InputCoin, error)
+	GetAccountBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (*incognito.AccountBalance, error)
+	GetIncomingCoins(paymentAddress string, readonlyKey string, tokenId string) ([]*incognito.IncomingCoin, error)
+	GetIncomingCoinsWithContext(ctx context.Context, paymentAddress string, readonlyKey string, tokenId string) ([]*incognito.IncomingCoin, error)
 }
 
 type IncChainIntegration struct {
@@ -31,32 +54,60 @@ type IncChainIntegration struct {
 
 //prv, normal tx and privacy tx
 func (i IncChainIntegration) CreateAndSendConstantTransaction(param interface{}) (interface{}, error) {
-	return incognito.CreateAndSendTx(i.RpcClient, param)
+	return i.CreateAndSendConstantTransactionWithContext(context.Background(), param)
+}
+
+func (i IncChainIntegration) CreateAndSendConstantTransactionWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendTxWithContext(ctx, i.RpcClient, param, opts...)
 }
 
 //pETH, pBTC
 func (i IncChainIntegration) SendPrivacyCustomTokenTransaction(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendPrivacyCustomTokenTransaction(i.RpcClient, params)
+	return i.SendPrivacyCustomTokenTransactionWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) SendPrivacyCustomTokenTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendPrivacyCustomTokenTransactionWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendIssuingRequest(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendIssuingRequest(i.RpcClient, params)
+	return i.CreateAndSendIssuingRequestWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendIssuingRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendIssuingRequestWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendTxWithIssuingEth(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendTxWithIssuingETHReq(i.RpcClient, params)
+	return i.CreateAndSendTxWithIssuingEthWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendTxWithIssuingEthWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendTxWithIssuingETHReqWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendBurningForDepositToSCRequest(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendBurningForDepositToSCRequest(i.RpcClient, params)
+	return i.CreateAndSendBurningForDepositToSCRequestWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendBurningForDepositToSCRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendBurningForDepositToSCRequestWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendContractingRequest(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendContractingRequest(i.RpcClient, params)
+	return i.CreateAndSendContractingRequestWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendContractingRequestWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendContractingRequestWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) GetBalance(privateKey string, tokenId string) (uint64, error) {
-	return incognito.GetBalance(i.RpcClient, privateKey, tokenId)
+	return i.GetBalanceWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (i IncChainIntegration) GetBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (uint64, error) {
+	return incognito.GetBalanceWithContext(ctx, i.RpcClient, privateKey, tokenId)
 }
 
 func (i IncChainIntegration) CreateWalletAddress() (*wallet.KeySerializedData, error) {
@@ -64,15 +115,27 @@ func (i IncChainIntegration) CreateWalletAddress() (*wallet.KeySerializedData, e
 }
 
 func (i IncChainIntegration) CreateAndSendStakingTx(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendStakingTx(i.RpcClient, params)
+	return i.CreateAndSendStakingTxWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendStakingTxWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendStakingTxWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendStopAutoStakingTransaction(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendStopAutoStakingTransaction(i.RpcClient, params)
+	return i.CreateAndSendStopAutoStakingTransactionWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendStopAutoStakingTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendStopAutoStakingTransactionWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateAndSendWithDrawTransaction(params interface{}) (interface{}, error) {
-	return incognito.CreateAndSendWithDrawTransaction(i.RpcClient, params)
+	return i.CreateAndSendWithDrawTransactionWithContext(context.Background(), params)
+}
+
+func (i IncChainIntegration) CreateAndSendWithDrawTransactionWithContext(ctx context.Context, params interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.CreateAndSendWithDrawTransactionWithContext(ctx, i.RpcClient, params, opts...)
 }
 
 func (i IncChainIntegration) CreateNewWalletByShardId(shardId int) (*wallet.KeySerializedData, error) {
@@ -80,15 +143,43 @@ func (i IncChainIntegration) CreateNewWalletByShardId(shardId int) (*wallet.KeyS
 }
 
 func (i IncChainIntegration) DefragmentationPrv(param interface{}) (interface{}, error) {
-	return incognito.DeFragmentAccount(i.RpcClient, param)
+	return i.DefragmentationPrvWithContext(context.Background(), param)
+}
+
+func (i IncChainIntegration) DefragmentationPrvWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.DeFragmentAccountWithContext(ctx, i.RpcClient, param, opts...)
 }
 
 func (i IncChainIntegration) DefragmentationPToken(param interface{}) (interface{}, error) {
-	return incognito.DeFragmentPTokenAccount(i.RpcClient, param)
+	return i.DefragmentationPTokenWithContext(context.Background(), param)
+}
+
+func (i IncChainIntegration) DefragmentationPTokenWithContext(ctx context.Context, param interface{}, opts ...incognito.TxOption) (interface{}, error) {
+	return incognito.DeFragmentPTokenAccountWithContext(ctx, i.RpcClient, param, opts...)
 }
 
 func (i IncChainIntegration) GetUTXO(privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
-	return incognito.GetUTXO(i.RpcClient, privateKey, tokenId)
+	return i.GetUTXOWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (i IncChainIntegration) GetUTXOWithContext(ctx context.Context, privateKey string, tokenId string) ([]*privacy.InputCoin, error) {
+	return incognito.GetUTXOWithContext(ctx, i.RpcClient, privateKey, tokenId)
+}
+
+func (i IncChainIntegration) GetAccountBalance(privateKey string, tokenId string) (*incognito.AccountBalance, error) {
+	return i.GetAccountBalanceWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (i IncChainIntegration) GetAccountBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (*incognito.AccountBalance, error) {
+	return incognito.GetAccountBalanceWithContext(ctx, i.RpcClient, privateKey, tokenId)
+}
+
+func (i IncChainIntegration) GetIncomingCoins(paymentAddress string, readonlyKey string, tokenId string) ([]*incognito.IncomingCoin, error) {
+	return i.GetIncomingCoinsWithContext(context.Background(), paymentAddress, readonlyKey, tokenId)
+}
+
+func (i IncChainIntegration) GetIncomingCoinsWithContext(ctx context.Context, paymentAddress string, readonlyKey string, tokenId string) ([]*incognito.IncomingCoin, error) {
+	return incognito.GetIncomingCoinsWithContext(ctx, i.RpcClient, paymentAddress, readonlyKey, tokenId)
 }
 
 func NewIncChainIntegration(rpcClient *rpcclient.HttpClient) *IncChainIntegration {
@@ -96,3 +187,59 @@ func NewIncChainIntegration(rpcClient *rpcclient.HttpClient) *IncChainIntegratio
 		RpcClient: rpcClient,
 	}
 }
+
+// withCoinReservation returns opts followed by the option reserving the coins of the tx in rpcservice.DefaultCoinLocker
+// under the returned reservation, so that concurrent sends from one account spend different coins.
+// See settleFailedSend when the tx is not sent.
+func withCoinReservation(opts []incognito.TxOption) ([]incognito.TxOption, *rpcservice.CoinReservation) {
+	reservation := rpcservice.DefaultCoinLocker.NewReservation()
+	return append(opts[:len(opts):len(opts)], incognito.WithCoinReservation(reservation)), reservation
+}
+
+// settleFailedSend releases reservation when err, returned sending its tx, is the node rejecting the tx. On any other
+// error, a timeout or a lost connection, the tx may have been sent: its coins stay reserved until the rpcclient.TxTracker
+// or the timeout of rpcservice.DefaultCoinLocker settles it, and its id is returned to look it up with.
+func settleFailedSend(reservation *rpcservice.CoinReservation, err error) (txID string) {
+	var rpcErr *rpcclient.RPCError
+	if errors.As(err, &rpcErr) {
+		reservation.Release()
+		return ""
+	}
+	if txIDs := reservation.TxIDs(); len(txIDs) > 0 {
+		txID = txIDs[len(txIDs)-1]
+	}
+	return txID
+}
+
+// feeEstimatorOptions returns opts preceded by the option estimating fees with estimators, when there are any
+func feeEstimatorOptions(estimators map[byte]*mempool.FeeEstimator, opts []incognito.TxOption) []incognito.TxOption {
+	if estimators == nil {
+		return opts
+	}
+	return append([]incognito.TxOption{incognito.WithFeeEstimators(estimators)}, opts...)
+}
+
+// NewTxTracker returns an rpcclient.TxTracker polling rpcClient, which releases the coins reserved in
+// rpcservice.DefaultCoinLocker for a tx once it is confirmed, dropped or rejected
+func NewTxTracker(rpcClient *rpcclient.HttpClient, config rpcclient.TxTrackerConfig) *rpcclient.TxTracker {
+	tracker := rpcclient.NewTxTracker(rpcClient, config)
+	tracker.OnUpdate(func(update rpcclient.TxUpdate) {
+		if update.Status.Final() {
+			rpcservice.DefaultCoinLocker.ReleaseTx(update.TxID)
+		}
+	})
+	return tracker
+}
+
+// trackSentTx hands the tx txID sent with req to tracker, if any, so that it is sent again when the mempool drops it.
+// req is nil for a tx the node built, which can not be sent again.
+func trackSentTx(tracker *rpcclient.TxTracker, txID string, req service.Request) {
+	if tracker == nil {
+		return
+	}
+	var raw *rpcclient.RawTx
+	if req != nil {
+		raw = &rpcclient.RawTx{Method: req.Method(), Params: req.Params()}
+	}
+	tracker.Track(txID, raw)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
+)
+
+func (w *Wallet) GetIncomingCoins(paymentAddress string, readonlyKey string, tokenId string) ([]entity.IncomingCoin, error) {
+	return w.GetIncomingCoinsWithContext(context.Background(), paymentAddress, readonlyKey, tokenId)
+}
+
+// GetIncomingCoinsWithContext returns the coins of tokenId received by the account, spent or not, with their memos
+// decrypted when they were encrypted to it
+func (w *Wallet) GetIncomingCoinsWithContext(ctx context.Context, paymentAddress string, readonlyKey string, tokenId string) ([]entity.IncomingCoin, error) {
+	coins, err := w.IncChainIntegration.GetIncomingCoinsWithContext(ctx, paymentAddress, readonlyKey, tokenId)
+	if err != nil {
+		return nil, errors.Wrap(err, "w.IncChainIntegration")
+	}
+
+	incomingCoins := make([]entity.IncomingCoin, len(coins))
+	for i, coin := range coins {
+		incomingCoins[i] = entity.IncomingCoin{
+			CoinCommitment: base58.Base58Check{}.Encode(coin.Coin.CoinDetails.GetCoinCommitment().ToBytesS(), common.ZeroByte),
+			Value:          coin.Coin.CoinDetails.GetValue(),
+			Memo:           string(coin.Memo),
+			EncryptedMemo:  coin.Encrypted,
+		}
+	}
+	return incomingCoins, nil
+}
+
+// decryptReceivedMemos sets the memo of the coins of received that were encrypted to the account of readonlyKey
+func decryptReceivedMemos(received *entity.ReceivedTransactions, readonlyKey string) {
+	keyWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+	if err != nil || len(keyWallet.KeySet.ReadonlyKey.Rk) == 0 {
+		return
+	}
+	for _, receivedTransaction := range received.ReceivedTransactions {
+		for token, coinDetail := range receivedTransaction.ReceivedAmounts {
+			if coinDetail.CoinDetails == nil || coinDetail.CoinDetails.Info == "" {
+				continue
+			}
+			info, err := decodeCoinInfo(coinDetail.CoinDetails.Info)
+			if err != nil {
+				continue
+			}
+			memo, err := incognito.DecryptMemo(info, keyWallet.KeySet.ReadonlyKey.Rk)
+			if err != nil {
+				common.Log.Debugf("decrypt memo of tx %s, token %s: %v", receivedTransaction.Hash, token, err)
+				continue
+			}
+			if memo != nil {
+				coinDetail.CoinDetails.Memo = string(memo)
+			}
+		}
+	}
+}
+
+// decodeCoinInfo decodes the info of a coin as the node answers it, in base58 check or base64
+func decodeCoinInfo(info string) ([]byte, error) {
+	if decoded, _, err := (base58.Base58Check{}).Decode(info); err == nil {
+		return decoded, nil
+	}
+	return base64.StdEncoding.DecodeString(info)
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/pkg/errors"
+)
+
+func (w *Wallet) SendTokenWithPolicy(privateKey string, receiverAddress string, tokenId string, amount uint64, policy entity.OversizePolicy, opts ...incognito.TxOption) (*entity.SendResult, error) {
+	return w.SendTokenWithPolicyWithContext(context.Background(), privateKey, receiverAddress, tokenId, amount, policy, opts...)
+}
+
+// SendTokenWithPolicyWithContext sends amount of tokenId, paying the fee in PRV. The tx is planned first, when it would
+// spend more than transaction.MaxInputCoins coins or be larger than common.MaxTxSize, policy tells whether to fail,
+// consolidate the coins of the account first or split the payment in several txs. On error the result tells the txs
+// sent so far. opts customize how the txs are built and planned.
+func (w *Wallet) SendTokenWithPolicyWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, policy entity.OversizePolicy, opts ...incognito.TxOption) (*entity.SendResult, error) {
+	result := &entity.SendResult{}
+
+	plan, err := w.planSend(ctx, privateKey, receiverAddress, tokenId, amount, opts...)
+	if err != nil {
+		return result, err
+	}
+	oversized := oversizedTokens(plan, tokenId)
+	if len(oversized) > 0 {
+		switch policy {
+		case entity.OversizeConsolidate:
+			if err := w.consolidateOversized(ctx, privateKey, oversized, result, opts...); err != nil {
+				return result, err
+			}
+		case entity.OversizeSplit:
+			return result, w.sendSplit(ctx, privateKey, receiverAddress, tokenId, amount, result, opts...)
+		default:
+			return result, errors.Wrapf(rpcclient.ErrTxTooLarge, "the tx spends %d coins of %v, %d kb", numInputCoins(plan), oversized, plan.SizeInKb)
+		}
+	}
+
+	txID, err := w.SendTokenWithContext(ctx, privateKey, receiverAddress, tokenId, amount, 0, "", opts...)
+	if err != nil {
+		return result, err
+	}
+	result.PaymentTxIDs = append(result.PaymentTxIDs, txID)
+	result.Amounts = append(result.Amounts, amount)
+	return result, nil
+}
+
+// consolidateOversized merges the coins of tokenIds, the txs are waited for so that the merged coins are spendable
+func (w *Wallet) consolidateOversized(ctx context.Context, privateKey string, tokenIds []string, result *entity.SendResult, opts ...incognito.TxOption) error {
+	for _, tokenId := range tokenIds {
+		consolidation, err := w.ConsolidateWithContext(ctx, privateKey, tokenId, entity.ConsolidationConfig{}, opts...)
+		if consolidation != nil {
+			result.ConsolidationTxIDs = append(result.ConsolidationTxIDs, consolidation.TxIDs...)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "w.Consolidate: tokenId: %s", tokenId)
+		}
+	}
+	return nil
+}
+
+// sendSplit pays amount of tokenId in as many txs as the limits of a tx need. The amount of a tx is halved until its
+// plan fits, the next txs start from it. When the account runs out of spendable coins, the txs already sent are
+// waited for to spend their change.
+func (w *Wallet) sendSplit(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, result *entity.SendResult, opts ...incognito.TxOption) error {
+	var tracker *rpcclient.TxTracker
+	var unconfirmed []string
+	part := amount
+	for remaining := amount; remaining > 0; {
+		if part > remaining {
+			part = remaining
+		}
+		for {
+			plan, err := w.planSend(ctx, privateKey, receiverAddress, tokenId, part, opts...)
+			if errors.Is(err, rpcclient.ErrNotEnoughCoin) && len(unconfirmed) > 0 {
+				if closeTracker := w.waitForChange(ctx, &tracker, unconfirmed); closeTracker != nil {
+					defer closeTracker()
+				}
+				unconfirmed = nil
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			oversized := oversizedTokens(plan, tokenId)
+			if len(oversized) == 0 {
+				break
+			}
+			if oversized[0] != tokenId || part == 1 {
+				// the PRV coins paying the fee of a token tx are not fewer in a smaller payment
+				return errors.Wrapf(rpcclient.ErrTxTooLarge, "the tx spends %d coins of %v, %d kb", numInputCoins(plan), oversized, plan.SizeInKb)
+			}
+			part /= 2
+		}
+
+		txID, err := w.SendTokenWithContext(ctx, privateKey, receiverAddress, tokenId, part, 0, "", opts...)
+		if err != nil {
+			return errors.Wrapf(err, "tx %d: amount: %d", len(result.PaymentTxIDs)+1, part)
+		}
+		result.PaymentTxIDs = append(result.PaymentTxIDs, txID)
+		result.Amounts = append(result.Amounts, part)
+		unconfirmed = append(unconfirmed, txID)
+		remaining -= part
+	}
+	return nil
+}
+
+// planSend previews the tx SendToken would send, without the coins reserved by the txs being sent
+func (w *Wallet) planSend(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...incognito.TxOption) (*rpcservice.TxPlan, error) {
+	receivers := map[string]uint64{receiverAddress: amount}
+	if tokenId == w.ConstantID {
+		param := []interface{}{privateKey, receivers, constant.EstimateFee, 1}
+		plan, err := incognito.PlanTxWithContext(ctx, w.IncChainIntegration.RpcClient, param, w.txOptions(opts)...)
+		return plan, errors.Wrap(err, "incognito.PlanTx")
+	}
+
+	param := privacyCustomTokenParams(privateKey, entity.WalletSend{TokenID: tokenId, Type: 1, PaymentAddresses: receivers})
+	plan, err := incognito.PlanPrivacyCustomTokenTransactionWithContext(ctx, w.IncChainIntegration.RpcClient, param, w.txOptions(opts)...)
+	return plan, errors.Wrap(err, "incognito.PlanPrivacyCustomTokenTransaction")
+}
+
+// oversizedTokens returns the tokens, tokenId first, whose coins put the tx of plan over the limits of a tx.
+// A tx too large without too many coins of either is put on the token it spends the most coins of.
+func oversizedTokens(plan *rpcservice.TxPlan, tokenId string) []string {
+	prv := common.PRVCoinID.String()
+	numPRVCoins := len(plan.InputCoins)
+	numTokenCoins := 0
+	if plan.Token != nil {
+		numTokenCoins = len(plan.Token.InputCoins)
+	}
+
+	var oversized []string
+	if tokenId != prv && numTokenCoins > transaction.MaxInputCoins {
+		oversized = append(oversized, tokenId)
+	}
+	if numPRVCoins > transaction.MaxInputCoins {
+		oversized = append(oversized, prv)
+	}
+	if len(oversized) == 0 && plan.ExceedsMaxTxSize {
+		if tokenId != prv && numTokenCoins >= numPRVCoins {
+			oversized = append(oversized, tokenId)
+		} else {
+			oversized = append(oversized, prv)
+		}
+	}
+	return oversized
+}
+
+// numInputCoins counts the coins the tx of plan spends
+func numInputCoins(plan *rpcservice.TxPlan) int {
+	n := len(plan.InputCoins)
+	if plan.Token != nil {
+		n += len(plan.Token.InputCoins)
+	}
+	return n
+}
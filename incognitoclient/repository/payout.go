@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
+)
+
+// maxPayoutsPerTx is the most payouts of a tx, its change takes the last output
+const maxPayoutsPerTx = transaction.MaxPrivacyOutputs - 1
+
+func (w *Wallet) SendBatch(privateKey string, tokenId string, payouts []entity.Payout, opts ...incognito.TxOption) (*entity.BatchPayoutResult, error) {
+	return w.SendBatchWithContext(context.Background(), privateKey, tokenId, payouts, opts...)
+}
+
+// SendBatchWithContext pays payouts of tokenId in as few txs as the limits of a tx allow. The payouts are packed
+// maxPayoutsPerTx by tx, a batch too large for a tx is split in two. When the account runs out of spendable coins,
+// the txs already sent are waited for, with the TxTracker of w or one of its own, to spend their change.
+// The payouts that could not be paid have their Err set, their Status tells whether their tx was rejected or may have
+// been sent, the error then counts them. opts customize how the txs are built.
+func (w *Wallet) SendBatchWithContext(ctx context.Context, privateKey string, tokenId string, payouts []entity.Payout, opts ...incognito.TxOption) (*entity.BatchPayoutResult, error) {
+	result := &entity.BatchPayoutResult{Results: make([]entity.PayoutResult, len(payouts))}
+
+	var valid []int
+	for i, payout := range payouts {
+		result.Results[i].Payout = payout
+		if err := validatePayout(payout); err != nil {
+			result.Results[i].Status = entity.PayoutFailed
+			result.Results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	var batches [][]int
+	for len(valid) > 0 {
+		n := maxPayoutsPerTx
+		if n > len(valid) {
+			n = len(valid)
+		}
+		batches = append(batches, valid[:n])
+		valid = valid[n:]
+	}
+
+	var tracker *rpcclient.TxTracker
+	var unconfirmed []string
+	for len(batches) > 0 {
+		batch := batches[0]
+		batches = batches[1:]
+
+		batchPayouts := make([]entity.Payout, len(batch))
+		for i, index := range batch {
+			batchPayouts[i] = payouts[index]
+		}
+		txID, err := w.sendPayouts(ctx, privateKey, tokenId, batchPayouts, opts...)
+
+		if errors.Is(err, rpcclient.ErrTxTooLarge) && len(batch) > 1 {
+			half := len(batch) / 2
+			batches = append([][]int{batch[:half], batch[half:]}, batches...)
+			continue
+		}
+		if errors.Is(err, rpcclient.ErrNotEnoughCoin) && len(unconfirmed) > 0 {
+			if closeTracker := w.waitForChange(ctx, &tracker, unconfirmed); closeTracker != nil {
+				defer closeTracker()
+			}
+			unconfirmed = nil
+			batches = append([][]int{batch}, batches...)
+			continue
+		}
+
+		status := entity.PayoutPaid
+		switch {
+		case err != nil && txID != "":
+			// the tx may be in the mempool, paying the batch again could pay it twice
+			status = entity.PayoutUnknown
+		case err != nil:
+			status = entity.PayoutFailed
+		}
+		for _, index := range batch {
+			result.Results[index].Status = status
+			result.Results[index].TxID = txID
+			result.Results[index].Err = err
+		}
+		if err != nil {
+			continue
+		}
+		result.TxIDs = append(result.TxIDs, txID)
+		unconfirmed = append(unconfirmed, txID)
+	}
+
+	for _, payoutResult := range result.Results {
+		switch payoutResult.Status {
+		case entity.PayoutFailed:
+			result.Failed++
+		case entity.PayoutUnknown:
+			result.Unknown++
+		}
+	}
+	if result.Failed > 0 || result.Unknown > 0 {
+		return result, errors.Errorf("%d of %d payouts failed, %d may be paid", result.Failed, len(payouts), result.Unknown)
+	}
+	return result, nil
+}
+
+// sendPayouts pays payouts of tokenId in one tx. When the send fails without the node rejecting the tx, the id of the
+// tx, which may have been sent, is returned with the error.
+func (w *Wallet) sendPayouts(ctx context.Context, privateKey string, tokenId string, payouts []entity.Payout, opts ...incognito.TxOption) (string, error) {
+	if tokenId == w.ConstantID {
+		return w.createAndSendConstantPrivacyTransaction(ctx, privateKey, entity.WalletSend{Type: 0, Payouts: payouts}, opts...)
+	}
+
+	tx, err := w.sendPrivacyCustomTokenTransaction(ctx, privateKey, entity.WalletSend{TokenID: tokenId, Type: 1, Payouts: payouts}, opts...)
+	if err != nil {
+		return failedTxID(tx), errors.Wrap(err, "p.SendPrivacyCustomTokenTransaction")
+	}
+	return tx.TxID, nil
+}
+
+// validatePayout rejects a payout no tx can pay
+func validatePayout(payout entity.Payout) error {
+	keyWallet, err := wallet.Base58CheckDeserialize(payout.PaymentAddress)
+	if err != nil || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 {
+		return errors.Errorf("invalid payment address %q", payout.PaymentAddress)
+	}
+	if payout.Amount == 0 {
+		return errors.New("amount is zero")
+	}
+	maxSizeMemo := privacy.MaxSizeInfoCoin
+	if payout.EncryptMemo {
+		maxSizeMemo = privacy.MaxSizeEncryptedMemo
+	}
+	if len(payout.Memo) > maxSizeMemo {
+		return errors.Errorf("memo is %d bytes, maximum = %d", len(payout.Memo), maxSizeMemo)
+	}
+	return nil
+}
+
+// paymentReceivers returns the receivers param of the tx paying req: its Payouts as a list of bean.Receiver
+// when set, its PaymentAddresses otherwise
+func paymentReceivers(req entity.WalletSend) interface{} {
+	if len(req.Payouts) == 0 {
+		return req.PaymentAddresses
+	}
+	receivers := make([]bean.Receiver, len(req.Payouts))
+	for i, payout := range req.Payouts {
+		receivers[i] = bean.Receiver{
+			PaymentAddress: payout.PaymentAddress,
+			Amount:         payout.Amount,
+			Message:        []byte(payout.Memo),
+			EncryptMessage: payout.EncryptMemo,
+		}
+	}
+	return receivers
+}
+
+// waitForChange waits for the txs of unconfirmed to be confirmed, the coins left may be their change, spendable once
+// they are. *tracker is set at the first call, to w.TxTracker or, when w has none, to a tracker of its own whose Close
+// is returned; nil is returned otherwise.
+func (w *Wallet) waitForChange(ctx context.Context, tracker **rpcclient.TxTracker, unconfirmed []string) (closeTracker func()) {
+	if *tracker == nil {
+		if w.TxTracker != nil {
+			*tracker = w.TxTracker
+		} else {
+			*tracker = NewTxTracker(w.IncChainIntegration.RpcClient, rpcclient.TxTrackerConfig{})
+			closeTracker = (*tracker).Close
+		}
+	}
+	for _, sent := range unconfirmed {
+		if _, err := (*tracker).WaitForConfirmation(ctx, sent); err != nil {
+			common.Log.Debugf("tx %s is not confirmed: %v", sent, err)
+		}
+	}
+	return closeTracker
+}
@@ -1,89 +1,89 @@
 package repository
 
 import (
+	"context"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/pkg/errors"
 	"strconv"
 )
 
 type Pdex struct {
-	Inc        *service.IncogClient
-	ConstantId string
-	Block      *Block
+	Inc                 *service.IncogClient
+	ConstantId          string
+	Block               *Block
+	IncChainIntegration *IncChainIntegration
+	// FeeEstimators estimate the fees of the txs planned, by shard, the node is asked when nil
+	FeeEstimators map[byte]*mempool.FeeEstimator
+	// TxTracker tracks the txs sent, nil if they are not tracked
+	TxTracker *rpcclient.TxTracker
 }
 
-func NewPdex(inc *service.IncogClient, constantId string, block *Block) *Pdex {
-	return &Pdex{Inc: inc, ConstantId: constantId, Block: block}
+func NewPdex(inc *service.IncogClient, constantId string, block *Block, incChainIntegration *IncChainIntegration) *Pdex {
+	return &Pdex{Inc: inc, ConstantId: constantId, Block: block, IncChainIntegration: incChainIntegration}
 }
 
 func (p *Pdex) GetPDexState(beacon int32) (map[string]interface{}, error) {
-	beaconParams := map[string]interface{}{"BeaconHeight": beacon}
-	param := []interface{}{beaconParams}
-	resp, _, err := p.Inc.PostAndReceiveInterface(constant.GetPdeState, param)
-	if err != nil {
-		return nil, errors.Wrap(err, "b.GetPdeState")
-	}
+	return p.GetPDexStateWithContext(context.Background(), beacon)
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get Result:  resp: %+v", data)
+func (p *Pdex) GetPDexStateWithContext(ctx context.Context, beacon int32) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := p.Inc.CallWithContext(ctx, entity.GetPdeStateReq{BeaconHeight: beacon}, &result); err != nil {
+		return nil, errors.Wrap(err, "b.GetPdeState")
 	}
 	return result, nil
 }
 
 func (p *Pdex) TradePDex(privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
+	return p.TradePDexWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+func (p *Pdex) TradePDexWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
 	if sellTokenId == p.ConstantId {
-		return p.SellPRVCrosspool(privateKey, buyTokenId, tradingFee, sellTokenAmount, minimumAmount, traderAddress)
+		return p.SellPRVCrosspoolWithContext(ctx, privateKey, buyTokenId, tradingFee, sellTokenAmount, minimumAmount, traderAddress)
 	}
 
-	return p.SellPTokenCrosspool(privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+	return p.SellPTokenCrosspoolWithContext(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
 }
 
 func (p *Pdex) GetPDexTradeStatus(txId string) (constant.PDexTradeStatus, error) {
-	param := map[string]interface{}{
-		"TxRequestIDStr": txId,
-	}
-
-	paramArray := []interface{}{
-		param,
-	}
+	return p.GetPDexTradeStatusWithContext(context.Background(), txId)
+}
 
-	resp, _, err := p.Inc.PostAndReceiveInterface(constant.GetPDETradeStatus, paramArray)
-	if err != nil {
-		return 0, errors.Wrapf(err, "p.blockchainAPI: param: %+v", paramArray)
-	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return 0, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(float64)
-	if !ok {
-		return 0, errors.Errorf("couldn't get txID:  resp: %+v", data)
+func (p *Pdex) GetPDexTradeStatusWithContext(ctx context.Context, txId string) (constant.PDexTradeStatus, error) {
+	var result constant.PDexTradeStatus
+	if err := p.Inc.CallWithContext(ctx, entity.GetPDETradeStatusReq{TxRequestIDStr: txId}, &result); err != nil {
+		return 0, errors.Wrapf(err, "p.blockchainAPI: txId: %s", txId)
 	}
-	return constant.PDexTradeStatus(result), nil
+	return result, nil
 }
 
 func (p *Pdex) SellPTokenCrosspool(privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
+	return p.SellPTokenCrosspoolWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+func (p *Pdex) SellPTokenCrosspoolWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
 	var burningAddress string
 	var err error
-	burningAddress, err = p.Block.GetBurningAddress()
+	burningAddress, err = p.Block.GetBurningAddressWithContext(ctx)
 	var FeePerKb int
 	var TokenFee uint64
 	if err != nil {
 		return "", errors.Wrap(err, "w.GetBurningAddress")
 	}
 
+	// a network fee paid in the token is converted from the PRV fee when it is not given
+	if networkFeeTokenID != p.ConstantId && networkFee == 0 {
+		networkFee, err = p.tradeTokenFee(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress)
+		if err != nil {
+			return "", errors.Wrap(err, "p.tradeTokenFee")
+		}
+	}
+
 	if networkFeeTokenID == p.ConstantId {
 		FeePerKb = 5
 		TokenFee = 0
@@ -123,33 +123,27 @@ func (p *Pdex) SellPTokenCrosspool(privateKey string, buyTokenId string, trading
 		0,
 	}
 
-	resp, _, err := p.Inc.PostAndReceiveInterface(constant.CreateAndSendTxWithPTokenCrosspolTradeReq, paramArray)
-	if err != nil {
+	var result entity.TxIDResult
+	req := entity.TradeReq{TradeMethod: constant.CreateAndSendTxWithPTokenCrosspolTradeReq, Args: paramArray}
+	if err := p.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return "", errors.Wrapf(err, "w.blockchainAPI: param: %+v", paramArray)
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", metadata, data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	if result["TxID"] == nil {
+	if result.TxID == "" {
 		return "", constant.ErrTxHashNotExists
 	}
+	trackSentTx(p.TxTracker, result.TxID, nil)
 
-	return result["TxID"].(string), nil
+	return result.TxID, nil
 }
 
 func (p *Pdex) SellPRVCrosspool(privateKey string, buyTokenId string, tradingFee uint64, sellTokenAmount uint64, minimumAmount uint64, traderAddress string) (string, error) {
+	return p.SellPRVCrosspoolWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenAmount, minimumAmount, traderAddress)
+}
+
+func (p *Pdex) SellPRVCrosspoolWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenAmount uint64, minimumAmount uint64, traderAddress string) (string, error) {
 	var burningAddress string
 	var err error
-	burningAddress, err = p.Block.GetBurningAddress()
+	burningAddress, err = p.Block.GetBurningAddressWithContext(ctx)
 
 	if err != nil {
 		return "", errors.Wrap(err, "w.GetBurningAddress")
@@ -174,24 +168,14 @@ func (p *Pdex) SellPRVCrosspool(privateKey string, buyTokenId string, tradingFee
 		metadata,
 	}
 
-	resp, _, err := p.Inc.PostAndReceiveInterface(constant.CreateAndSendTxWithPRVCrosspollTradeReq, paramArray)
-	if err != nil {
+	var result entity.TxIDResult
+	req := entity.TradeReq{TradeMethod: constant.CreateAndSendTxWithPRVCrosspollTradeReq, Args: paramArray}
+	if err := p.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return "", errors.Wrapf(err, "w.blockchainAPI: param: %+v", paramArray)
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", metadata, data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	if result["TxID"] == nil {
+	if result.TxID == "" {
 		return "", constant.ErrTxHashNotExists
 	}
-	return result["TxID"].(string), nil
+	trackSentTx(p.TxTracker, result.TxID, nil)
+	return result.TxID, nil
 }
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/pkg/errors"
+)
+
+func (w *Wallet) PlanSendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...incognito.TxOption) (*rpcservice.TxPlan, error) {
+	return w.PlanSendTokenWithContext(context.Background(), privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+// PlanSendTokenWithContext previews the tx SendToken would send paying the fee in PRV, without proving nor sending it
+func (w *Wallet) PlanSendTokenWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...incognito.TxOption) (*rpcservice.TxPlan, error) {
+	return w.planSend(ctx, privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+func (w *Wallet) PlanContractingRequestForPrivacyToken(privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (*rpcservice.TxPlan, error) {
+	return w.PlanContractingRequestForPrivacyTokenWithContext(context.Background(), privateKey, autoChargePRVFee, metadata)
+}
+
+// PlanContractingRequestForPrivacyTokenWithContext previews the tx CreateAndSendContractingRequestForPrivacyToken
+// would send
+func (w *Wallet) PlanContractingRequestForPrivacyTokenWithContext(ctx context.Context, privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (*rpcservice.TxPlan, error) {
+	param := contractingRequestParams(privateKey, autoChargePRVFee, metadata)
+	plan, err := incognito.PlanContractingRequestWithContext(ctx, w.IncChainIntegration.RpcClient, param, w.txOptions(nil)...)
+	return plan, errors.Wrap(err, "incognito.PlanContractingRequest")
+}
+
+func (w *Wallet) PlanBurningForDepositToSCRequest(privateKey string, amount *big.Int, remoteAddrStr string, incTokenId string) (*rpcservice.TxPlan, error) {
+	return w.PlanBurningForDepositToSCRequestWithContext(context.Background(), privateKey, amount, remoteAddrStr, incTokenId)
+}
+
+// PlanBurningForDepositToSCRequestWithContext previews the tx CreateAndSendBurningForDepositToSCRequest would send
+func (w *Wallet) PlanBurningForDepositToSCRequestWithContext(ctx context.Context, privateKey string, amount *big.Int, remoteAddrStr string, incTokenId string) (*rpcservice.TxPlan, error) {
+	burningAddress, err := w.getBurningAddressFromChain(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "w.blockchainAPI: method %+v, Get burn address", constant.CreateAndSendBurningForDepositToSCRequest)
+	}
+
+	param := burningForDepositToSCParams(privateKey, burningAddress, amount, remoteAddrStr, incTokenId)
+	plan, err := incognito.PlanBurningForDepositToSCRequestWithContext(ctx, w.IncChainIntegration.RpcClient, param, w.txOptions(nil)...)
+	return plan, errors.Wrap(err, "incognito.PlanBurningForDepositToSCRequest")
+}
+
+func (p *Pdex) PlanTradePDex(privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (*rpcservice.TxPlan, error) {
+	return p.PlanTradePDexWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+// PlanTradePDexWithContext previews the tx TradePDex would have the node send. A network fee paid in the sold token
+// is converted from the PRV fee when it is 0, as TradePDex does.
+func (p *Pdex) PlanTradePDexWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (*rpcservice.TxPlan, error) {
+	burningAddress, err := p.Block.GetBurningAddressWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "w.GetBurningAddress")
+	}
+	opts := feeEstimatorOptions(p.FeeEstimators, nil)
+
+	if sellTokenId == p.ConstantId {
+		metadata := map[string]interface{}{
+			"TokenIDToBuyStr":     buyTokenId,
+			"TokenIDToSellStr":    p.ConstantId,
+			"SellAmount":          sellTokenAmount,
+			"MinAcceptableAmount": minimumAmount,
+			"TradingFee":          tradingFee,
+			"TraderAddressStr":    traderAddress,
+		}
+		paramArray := []interface{}{privateKey, map[string]uint64{burningAddress: sellTokenAmount + tradingFee}, 1, -1, metadata}
+		plan, err := incognito.PlanTxWithPRVTradeReqWithContext(ctx, p.IncChainIntegration.RpcClient, paramArray, opts...)
+		return plan, errors.Wrap(err, "incognito.PlanTxWithPRVTradeReq")
+	}
+
+	if networkFeeTokenID != p.ConstantId && networkFee == 0 {
+		networkFee, err = p.tradeTokenFee(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "p.tradeTokenFee")
+		}
+	}
+
+	feePerKb, tokenFee := 5, uint64(0)
+	if networkFeeTokenID != p.ConstantId {
+		feePerKb, tokenFee = 0, networkFee/constant.PDEX_TRADE_STEPS
+	}
+
+	metadata := map[string]interface{}{
+		"Privacy":     true,
+		"TokenID":     sellTokenId,
+		"TokenTxType": 1,
+		"TokenName":   "",
+		"TokenSymbol": "",
+		"TokenAmount": sellTokenAmount,
+		"TokenReceivers": map[string]uint64{
+			burningAddress: sellTokenAmount,
+		},
+		"TokenFee":            tokenFee,
+		"TokenIDToBuyStr":     buyTokenId,
+		"TokenIDToSellStr":    sellTokenId,
+		"SellAmount":          sellTokenAmount,
+		"MinAcceptableAmount": minimumAmount,
+		"TradingFee":          tradingFee,
+		"TraderAddressStr":    traderAddress,
+	}
+	paramArray := []interface{}{privateKey, map[string]uint64{burningAddress: tradingFee}, feePerKb, -1, metadata, "", 0}
+	plan, err := incognito.PlanTxWithPTokenTradeReqWithContext(ctx, p.IncChainIntegration.RpcClient, paramArray, opts...)
+	return plan, errors.Wrap(err, "incognito.PlanTxWithPTokenTradeReq")
+}
+
+func (b *Stake) PlanStaking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (*rpcservice.TxPlan, error) {
+	return b.PlanStakingWithContext(context.Background(), receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
+// PlanStakingWithContext previews the tx Staking would send
+func (b *Stake) PlanStakingWithContext(ctx context.Context, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (*rpcservice.TxPlan, error) {
+	param := stakingParams(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+	plan, err := incognito.PlanStakingTxWithContext(ctx, b.IncChainIntegration.RpcClient, param, feeEstimatorOptions(b.FeeEstimators, nil)...)
+	return plan, errors.Wrap(err, "incognito.PlanStakingTx")
+}
@@ -1,18 +1,23 @@
 package repository
 
 import (
-	"encoding/json"
-	"fmt"
-	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"context"
 
+	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/pkg/errors"
 )
 
 type Stake struct {
 	Inc                 *service.IncogClient
 	IncChainIntegration *IncChainIntegration
+	// FeeEstimators estimate the fees of the txs built, by shard, the node is asked when nil
+	FeeEstimators map[byte]*mempool.FeeEstimator
+	// TxTracker tracks the txs sent, nil if they are not tracked
+	TxTracker *rpcclient.TxTracker
 }
 
 func NewStake(inc *service.IncogClient, incChainIntegration *IncChainIntegration) *Stake {
@@ -20,75 +25,68 @@ func NewStake(inc *service.IncogClient, incChainIntegration *IncChainIntegration
 }
 
 func (s *Stake) ListUnstake() ([]entity.Unstake, error) {
-	param := []interface{}{}
-	resp, _, err := s.Inc.PostAndReceiveInterface(constant.GetBeaconBestStateDetail, param)
-	if err != nil {
-		return nil, errors.Wrap(err, "b.ListUnstake")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
+	return s.ListUnstakeWithContext(context.Background())
+}
 
-	if !ok {
-		return nil, errors.Errorf("couldn't get Result:  resp: %+v", data)
+func (s *Stake) ListUnstakeWithContext(ctx context.Context) ([]entity.Unstake, error) {
+	var result entity.BeaconBestStateDetail
+	if err := s.Inc.CallWithContext(ctx, entity.GetBeaconBestStateDetailReq{}, &result); err != nil {
+		return nil, errors.Wrap(err, "b.ListUnstake")
 	}
-	// autoStake, ok := result["AutoStaking"].(map[string]interface{})
-	// if !ok {
-	// 	return nil, errors.Errorf("couldn't get AutoStaking:  resp: %+v",  data)
-	// }
 
-	// fmt.Println(autoStake)
 	var AutoStake []entity.Unstake
-	r, err := json.Marshal(result["AutoStaking"])
-	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
-	}
-	if err := json.Unmarshal(r, &AutoStake); err != nil {
-		return nil, errors.Wrap(err, "json.Unmarshal")
+	for _, autoStaking := range result.AutoStaking {
+		AutoStake = append(AutoStake, entity.Unstake{
+			IncPubKey:   autoStaking.IncPubKey,
+			IsAutoStake: autoStaking.IsAutoStake,
+		})
 	}
 	return AutoStake, nil
-
-	// return nil, nil
 }
 func (s *Stake) GetTotalStaker() (float64, error) {
-	param := []interface{}{}
-	resp, _, err := s.Inc.PostAndReceiveInterface(constant.GetTotalStaker, param)
-	if err != nil {
+	return s.GetTotalStakerWithContext(context.Background())
+}
+
+func (s *Stake) GetTotalStakerWithContext(ctx context.Context) (float64, error) {
+	var result entity.TotalStaker
+	if err := s.Inc.CallWithContext(ctx, entity.GetTotalStakerReq{}, &result); err != nil {
 		return 0, errors.Wrap(err, "b.GetTotalStaker")
 	}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return 0, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	fmt.Println(result)
-	if !ok {
-		return 0, errors.Errorf("couldn't get Result:  resp: %+v", data)
+	return float64(result.TotalStaker), nil
+}
+
+func (b *Stake) Staking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
+	return b.StakingWithContext(context.Background(), receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
+func (b *Stake) StakingWithContext(ctx context.Context, receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
+	param := stakingParams(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+
+	opts, reservation := withCoinReservation(feeEstimatorOptions(b.FeeEstimators, nil))
+	//rpc: CreateAndSendStakingTransaction
+	rawData, err := b.IncChainIntegration.CreateAndSendStakingTxWithContext(ctx, param, opts...)
+	if err != nil {
+		return "", errors.Wrap(err, "w.CreateAndSendStakingTx")
 	}
 
-	autoStake, ok := result["TotalStaker"].(float64)
-	if !ok {
-		return 0, errors.Errorf("couldn't get TotalStaker: result: %+v", result)
+	common.Log.Debugf("method CreateAndSendStakingTx created the raw tx")
+
+	var result entity.TxIDResult
+	if err := b.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
+		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
-	fmt.Println(autoStake)
-	return autoStake, nil
+	trackSentTx(b.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
-func (b *Stake) Staking(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
-
+// stakingParams returns the params of the tx staking the validator userValidatorKey, the stake is burnt
+// to burnTokenAddress
+func stakingParams(receiveRewardAddress, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) []interface{} {
 	amountToStake := uint64(1750000000000)
 
-	param := []interface{}{
+	return []interface{}{
 		privateKey,
 		map[string]uint64{burnTokenAddress: amountToStake},
 		5,
@@ -101,39 +99,13 @@ func (b *Stake) Staking(receiveRewardAddress, privateKey, userPaymentAddress, us
 			"AutoReStaking":                true,
 		},
 	}
-
-	//rpc: CreateAndSendStakingTransaction
-	rawData, err := b.IncChainIntegration.CreateAndSendStakingTx(param)
-	if err != nil {
-		return "", errors.Wrap(err, "w.CreateAndSendStakingTx")
-	}
-
-	fmt.Printf("raw data method CreateAndSendStakingTx: %v \n", rawData)
-
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-	if err != nil {
-		return "", errors.Wrap(err, "b.blockchainAPI")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
 }
 
 func (b *Stake) Unstaking(privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
+	return b.UnstakingWithContext(context.Background(), privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress)
+}
+
+func (b *Stake) UnstakingWithContext(ctx context.Context, privateKey, userPaymentAddress, userValidatorKey, burnTokenAddress string) (string, error) {
 	param := []interface{}{
 		privateKey,
 		map[string]uint64{burnTokenAddress: 0},
@@ -146,38 +118,29 @@ func (b *Stake) Unstaking(privateKey, userPaymentAddress, userValidatorKey, burn
 		},
 	}
 
+	opts, reservation := withCoinReservation(feeEstimatorOptions(b.FeeEstimators, nil))
 	//rpc: CreateAndSendUnStakingTransaction
-	rawData, err := b.IncChainIntegration.CreateAndSendStopAutoStakingTransaction(param)
+	rawData, err := b.IncChainIntegration.CreateAndSendStopAutoStakingTransactionWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.CreateAndSendStopAutoStakingTransaction")
 	}
 
-	fmt.Printf("raw data method CreateAndSendStopAutoStakingTransaction: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendStopAutoStakingTransaction created the raw tx")
 
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-	if err != nil {
+	var result entity.TxIDResult
+	if err := b.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+	trackSentTx(b.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
 func (b *Stake) WithDrawReward(privateKey, paymentAddress, tokenID string) (string, error) {
+	return b.WithDrawRewardWithContext(context.Background(), privateKey, paymentAddress, tokenID)
+}
+
+func (b *Stake) WithDrawRewardWithContext(ctx context.Context, privateKey, paymentAddress, tokenID string) (string, error) {
 	param := []interface{}{
 		privateKey,
 		nil,
@@ -189,130 +152,73 @@ func (b *Stake) WithDrawReward(privateKey, paymentAddress, tokenID string) (stri
 		},
 	}
 
+	opts, reservation := withCoinReservation(feeEstimatorOptions(b.FeeEstimators, nil))
 	//rpc: WithDrawReward
-	rawData, err := b.IncChainIntegration.CreateAndSendWithDrawTransaction(param)
+	rawData, err := b.IncChainIntegration.CreateAndSendWithDrawTransactionWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.CreateAndSendWithDrawTransaction")
 	}
 
-	fmt.Printf("raw data method CreateAndSendWithDrawTransaction: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendWithDrawTransaction created the raw tx")
 
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-	if err != nil {
+	var result entity.TxIDResult
+	if err := b.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+	trackSentTx(b.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
 func (b *Stake) GetRewardAmount(paymentAddress string) ([]entity.RewardItems, error) {
-	param := []interface{}{
-		paymentAddress,
-	}
+	return b.GetRewardAmountWithContext(context.Background(), paymentAddress)
+}
 
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.RewardAmount, param)
-	if err != nil {
+func (b *Stake) GetRewardAmountWithContext(ctx context.Context, paymentAddress string) ([]entity.RewardItems, error) {
+	var result entity.RewardAmountResult
+	if err := b.Inc.CallWithContext(ctx, entity.GetRewardAmountReq{PaymentAddress: paymentAddress}, &result); err != nil {
 		return nil, errors.Wrapf(err, "b.blockchainAPI")
 	}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-
 	var rewards []entity.RewardItems
-	for s, k := range result {
-		amount := k.(float64)
-		rewards = append(rewards, entity.RewardItems{s, amount})
+	for s, amount := range result {
+		rewards = append(rewards, entity.RewardItems{TokenId: s, Reward: amount})
 	}
 
 	return rewards, nil
 }
 
 func (b *Stake) GetNodeAvailable(validatorKey string) (float64, error) {
-	param := []interface{}{
-		validatorKey,
-	}
+	return b.GetNodeAvailableWithContext(context.Background(), validatorKey)
+}
 
-	resp, _, err := b.Inc.PostAndReceiveInterface(constant.RoleByValidatorKey, param)
-	if err != nil {
+func (b *Stake) GetNodeAvailableWithContext(ctx context.Context, validatorKey string) (float64, error) {
+	var result entity.RoleByValidatorKeyResult
+	if err := b.Inc.CallWithContext(ctx, entity.GetRoleByValidatorKeyReq{ValidatorKey: validatorKey}, &result); err != nil {
 		return 0, errors.Wrapf(err, "b.blockchainAPI")
 	}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-
-	if data["Result"] == nil {
-		return 0, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return 0, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-
-	role, ok := result["Role"].(float64)
-	if !ok {
-		return 0, errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-
-	return role, nil
+	return float64(result.Role), nil
 }
 
 // Dung.Dang: for PRV only :(
 func (w *Stake) ListRewardAmounts() ([]entity.RewardAmount, error) {
-	param := []interface{}{}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.ListRewardAmount, param)
-	if err != nil {
-		return nil, errors.Wrap(err, "w.ListRewardAmounts")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
+	return w.ListRewardAmountsWithContext(context.Background())
+}
 
-	if !ok {
-		return nil, errors.Errorf("couldn't get Result: resp: %+v", data)
+func (w *Stake) ListRewardAmountsWithContext(ctx context.Context) ([]entity.RewardAmount, error) {
+	var result entity.ListRewardAmountResult
+	if err := w.Inc.CallWithContext(ctx, entity.ListRewardAmountReq{}, &result); err != nil {
+		return nil, errors.Wrap(err, "w.ListRewardAmounts")
 	}
 	var rewards []entity.RewardAmount
 
-	for s, k := range result {
-		reward := k.(map[string]interface{})
-		if reward["0000000000000000000000000000000000000000000000000000000000000004"] == nil {
+	for s, reward := range result {
+		amount, ok := reward["0000000000000000000000000000000000000000000000000000000000000004"]
+		if !ok {
 			continue
 		}
-		amount := reward["0000000000000000000000000000000000000000000000000000000000000004"].(float64)
-		rewards = append(rewards, entity.RewardAmount{s, amount})
+		rewards = append(rewards, entity.RewardAmount{PublicKey: s, Reward: float64(amount)})
 	}
 
 	return rewards, nil
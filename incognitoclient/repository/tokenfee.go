@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/pkg/errors"
+)
+
+// TokenFeeMarginPercent is added to a PRV fee converted into a token, so that the fee still covers the PRV fee
+// when the pool rate moves before the tx is confirmed
+const TokenFeeMarginPercent = 10
+
+func (p *Pdex) GetPDEPoolPairs() ([]*mempool.PDEPoolPair, error) {
+	return p.GetPDEPoolPairsWithContext(context.Background())
+}
+
+// GetPDEPoolPairsWithContext returns the pool pairs of the pDEX at the best beacon height
+func (p *Pdex) GetPDEPoolPairsWithContext(ctx context.Context) ([]*mempool.PDEPoolPair, error) {
+	beaconHeight, err := p.Block.GetBeaconHeightWithContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.Block.GetBeaconHeight")
+	}
+
+	var result struct {
+		PDEPoolPairs map[string]*mempool.PDEPoolPair
+	}
+	if err := p.Inc.CallWithContext(ctx, entity.GetPdeStateReq{BeaconHeight: beaconHeight}, &result); err != nil {
+		return nil, errors.Wrapf(err, "p.GetPdeState: beaconHeight: %d", beaconHeight)
+	}
+
+	poolPairs := make([]*mempool.PDEPoolPair, 0, len(result.PDEPoolPairs))
+	for _, poolPair := range result.PDEPoolPairs {
+		poolPairs = append(poolPairs, poolPair)
+	}
+	return poolPairs, nil
+}
+
+func (p *Pdex) ConvertPRVToToken(prvAmount uint64, tokenId string) (uint64, error) {
+	return p.ConvertPRVToTokenWithContext(context.Background(), prvAmount, tokenId)
+}
+
+// ConvertPRVToTokenWithContext returns what prvAmount PRV is worth in tokenId at the rate of the PRV pool of the token,
+// plus TokenFeeMarginPercent
+func (p *Pdex) ConvertPRVToTokenWithContext(ctx context.Context, prvAmount uint64, tokenId string) (uint64, error) {
+	tokenID, err := common.Hash{}.NewHashFromStr(tokenId)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid token id: %s", tokenId)
+	}
+
+	poolPairs, err := p.GetPDEPoolPairsWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := mempool.ConvertNativeTokenToPrivacyToken(prvAmount, tokenID, poolPairs)
+	if err != nil {
+		return 0, errors.Wrapf(err, "tokenId: %s", tokenId)
+	}
+	return amount + (amount*TokenFeeMarginPercent+99)/100, nil
+}
+
+// tradeTokenFee returns the network fee, in the sold token, of a trade selling sellTokenAmount of sellTokenId
+func (p *Pdex) tradeTokenFee(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string) (uint64, error) {
+	burningAddress, err := p.Block.GetBurningAddressWithContext(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "w.GetBurningAddress")
+	}
+
+	metadata := map[string]interface{}{
+		"Privacy":     true,
+		"TokenID":     sellTokenId,
+		"TokenTxType": 1,
+		"TokenName":   "",
+		"TokenSymbol": "",
+		"TokenAmount": sellTokenAmount,
+		"TokenReceivers": map[string]uint64{
+			burningAddress: sellTokenAmount + tradingFee,
+		},
+		"TokenFee":            uint64(0),
+		"TokenIDToBuyStr":     buyTokenId,
+		"TokenIDToSellStr":    sellTokenId,
+		"SellAmount":          sellTokenAmount,
+		"MinAcceptableAmount": minimumAmount,
+		"TradingFee":          tradingFee,
+		"TraderAddressStr":    traderAddress,
+	}
+	paramArray := []interface{}{privateKey, map[string]uint64{}, -1, -1, metadata, "", 0}
+
+	plan, err := incognito.PlanTxWithPTokenTradeReqWithContext(ctx, p.IncChainIntegration.RpcClient, paramArray, feeEstimatorOptions(p.FeeEstimators, nil)...)
+	if err != nil {
+		return 0, errors.Wrap(err, "incognito.PlanTxWithPTokenTradeReq")
+	}
+
+	fee, err := p.ConvertPRVToTokenWithContext(ctx, plan.Fee, sellTokenId)
+	if err != nil {
+		return 0, err
+	}
+	// the node pays each step of the trade networkFee / PDEX_TRADE_STEPS
+	return fee * constant.PDEX_TRADE_STEPS, nil
+}
+
+func (w *Wallet) EstimateTokenFee(privateKey string, tokenId string, receivers map[string]uint64, opts ...incognito.TxOption) (uint64, error) {
+	return w.EstimateTokenFeeWithContext(context.Background(), privateKey, tokenId, receivers, opts...)
+}
+
+// EstimateTokenFeeWithContext returns the fee, in tokenId, of a tx sending receivers their amount of tokenId:
+// the PRV fee of the tx converted at the rate of the PRV pool of the token, see Pdex.ConvertPRVToToken
+func (w *Wallet) EstimateTokenFeeWithContext(ctx context.Context, privateKey string, tokenId string, receivers map[string]uint64, opts ...incognito.TxOption) (uint64, error) {
+	if tokenId == w.ConstantID {
+		return 0, errors.New("the fee of a PRV tx is paid in PRV")
+	}
+
+	param := privacyCustomTokenParams(privateKey, entity.WalletSend{
+		TokenID:          tokenId,
+		Type:             1,
+		PaymentAddresses: receivers,
+	})
+	plan, err := incognito.PlanPrivacyCustomTokenTransactionWithContext(ctx, w.IncChainIntegration.RpcClient, param, w.txOptions(opts)...)
+	if err != nil {
+		return 0, errors.Wrap(err, "incognito.PlanPrivacyCustomTokenTransaction")
+	}
+
+	return w.pdex().ConvertPRVToTokenWithContext(ctx, plan.Fee, tokenId)
+}
+
+func (w *Wallet) SendTokenWithTokenFee(privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...incognito.TxOption) (string, error) {
+	return w.SendTokenWithTokenFeeWithContext(context.Background(), privateKey, receiverAddress, tokenId, amount, opts...)
+}
+
+// SendTokenWithTokenFeeWithContext sends amount of tokenId paying the fee in tokenId, see EstimateTokenFee,
+// so that a sender holding no PRV can send the token
+func (w *Wallet) SendTokenWithTokenFeeWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, opts ...incognito.TxOption) (string, error) {
+	fee, err := w.EstimateTokenFeeWithContext(ctx, privateKey, tokenId, map[string]uint64{receiverAddress: amount}, opts...)
+	if err != nil {
+		return "", err
+	}
+	return w.SendTokenWithContext(ctx, privateKey, receiverAddress, tokenId, amount, fee, tokenId, opts...)
+}
@@ -1,15 +1,19 @@
 package repository
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"math/big"
 	"strings"
 
+	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
 	"github.com/incognitochain/go-incognito-sdk/incognitoclient/service"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
 	"github.com/pkg/errors"
 )
@@ -19,12 +23,40 @@ type Wallet struct {
 	ConstantID          string
 	Block               *Block
 	IncChainIntegration *IncChainIntegration
+	// FeeEstimators estimate the fees of the txs built, by shard, the node is asked when nil
+	FeeEstimators map[byte]*mempool.FeeEstimator
+	// TxTracker tracks the txs sent, nil if they are not tracked
+	TxTracker *rpcclient.TxTracker
 }
 
 func NewWallet(inc *service.IncogClient, constantID string, block *Block, incChainIntegration *IncChainIntegration) *Wallet {
 	return &Wallet{Inc: inc, ConstantID: constantID, Block: block, IncChainIntegration: incChainIntegration}
 }
 
+// txOptions returns the options building a tx of w, opts come last to override the ones of w
+func (w *Wallet) txOptions(opts []incognito.TxOption) []incognito.TxOption {
+	return feeEstimatorOptions(w.FeeEstimators, opts)
+}
+
+// pdex returns the Pdex trading with the keys, the fee estimators and the tx tracker of w
+func (w *Wallet) pdex() *Pdex {
+	pdex := NewPdex(w.Inc, w.ConstantID, w.Block, w.IncChainIntegration)
+	pdex.FeeEstimators = w.FeeEstimators
+	pdex.TxTracker = w.TxTracker
+	return pdex
+}
+
+// withTxTracker returns w when it has a TxTracker. Otherwise it returns a copy of w tracking the txs it sends
+// with a tracker of its own, the returned func closes it.
+func (w *Wallet) withTxTracker() (*Wallet, func()) {
+	if w.TxTracker != nil {
+		return w, func() {}
+	}
+	tracked := *w
+	tracked.TxTracker = NewTxTracker(w.IncChainIntegration.RpcClient, rpcclient.TxTrackerConfig{})
+	return &tracked, tracked.TxTracker.Close
+}
+
 func (w *Wallet) CreateWalletAddress() (paymentAddress, pubkey, readonlyKey, privateKey string, err error) {
 	wallet, err := w.IncChainIntegration.CreateWalletAddress()
 
@@ -79,24 +111,13 @@ func (w *Wallet) CreateWalletAddressByShardId(byShardId int) (paymentAddress, pu
 }
 
 func (w *Wallet) ListRewardAmountAll() ([]entity.RewardData, error) {
-	param := []interface{}{}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.ListRewardAmount, param)
-	if err != nil {
-		return nil, errors.Wrap(err, "w.ListRewardAmounts")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-
-	result, ok := data["Result"].(map[string]interface{})
+	return w.ListRewardAmountAllWithContext(context.Background())
+}
 
-	if !ok {
-		return nil, errors.Errorf("couldn't get Result: resp: %+v", data)
+func (w *Wallet) ListRewardAmountAllWithContext(ctx context.Context) ([]entity.RewardData, error) {
+	var result entity.ListRewardAmountResult
+	if err := w.Inc.CallWithContext(ctx, entity.ListRewardAmountReq{}, &result); err != nil {
+		return nil, errors.Wrap(err, "w.ListRewardAmounts")
 	}
 
 	// var rewards []entity.RewardItems
@@ -108,14 +129,12 @@ func (w *Wallet) ListRewardAmountAll() ([]entity.RewardData, error) {
 			PublicKey: k,
 		}
 
-		rewardItemMap := v.(map[string]interface{})
-
 		var rewardDataItems []entity.RewardDataItem
-		for k2, v2 := range rewardItemMap {
+		for k2, v2 := range v {
 
 			rewardDataItems = append(rewardDataItems, entity.RewardDataItem{
 				TokenId: k2,
-				Reward:  uint64(v2.(float64)),
+				Reward:  v2,
 			})
 			rewardData.RewardItems = rewardDataItems
 
@@ -127,8 +146,12 @@ func (w *Wallet) ListRewardAmountAll() ([]entity.RewardData, error) {
 }
 
 func (w *Wallet) GetBalanceByPrivateKey(privateKey string) (uint64, error) {
+	return w.GetBalanceByPrivateKeyWithContext(context.Background(), privateKey)
+}
+
+func (w *Wallet) GetBalanceByPrivateKeyWithContext(ctx context.Context, privateKey string) (uint64, error) {
 	//rpc: GetBalanceByPrivateKeyMethod
-	amount, err := w.IncChainIntegration.GetBalance(privateKey, w.ConstantID)
+	amount, err := w.IncChainIntegration.GetBalanceWithContext(ctx, privateKey, w.ConstantID)
 	if err != nil {
 		return 0, err
 	}
@@ -137,51 +160,37 @@ func (w *Wallet) GetBalanceByPrivateKey(privateKey string) (uint64, error) {
 }
 
 func (w *Wallet) GetBalanceByPaymentAddress(paymentAddress string) (uint64, error) {
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetBalanceByPaymentAddress, []interface{}{paymentAddress})
-	if err != nil {
+	return w.GetBalanceByPaymentAddressWithContext(context.Background(), paymentAddress)
+}
+
+func (w *Wallet) GetBalanceByPaymentAddressWithContext(ctx context.Context, paymentAddress string) (uint64, error) {
+	var balance uint64
+	err := w.Inc.CallWithContext(ctx, entity.GetBalanceByPaymentAddressReq{PaymentAddress: paymentAddress}, &balance)
+	if err != nil && errors.Cause(err) != service.ErrNoResult {
 		return 0, err
 	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return 0, nil
-	}
-	if v, ok := data["Result"].(float64); ok {
-		return uint64(v), nil
-	}
-	return 0, nil
+	return balance, nil
 }
 
 func (w *Wallet) GetListCustomTokenBalance(paymentAddress string) (*entity.ListCustomTokenBalance, error) {
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetListCustomTokenBalance, []interface{}{paymentAddress})
-	if err != nil {
-		return nil, err
-	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", paymentAddress, data)
-	}
-	resultResp := data["Result"].(map[string]interface{})
-	resultRespStr, err := json.Marshal(resultResp)
-	if err != nil {
-		return nil, err
-	}
+	return w.GetListCustomTokenBalanceWithContext(context.Background(), paymentAddress)
+}
+
+func (w *Wallet) GetListCustomTokenBalanceWithContext(ctx context.Context, paymentAddress string) (*entity.ListCustomTokenBalance, error) {
 	var result entity.ListCustomTokenBalance
-	err = json.Unmarshal(resultRespStr, &result)
-	if err != nil {
+	if err := w.Inc.CallWithContext(ctx, entity.GetListCustomTokenBalanceReq{PaymentAddress: paymentAddress}, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (w *Wallet) GetListPrivacyCustomTokenBalanceByID(privateKey, tokenID string) (*big.Int, error) {
+	return w.GetListPrivacyCustomTokenBalanceByIDWithContext(context.Background(), privateKey, tokenID)
+}
+
+func (w *Wallet) GetListPrivacyCustomTokenBalanceByIDWithContext(ctx context.Context, privateKey, tokenID string) (*big.Int, error) {
 	//rpc: GetBalanceByPrivateKeyMethod
-	amount, err := w.IncChainIntegration.GetBalance(privateKey, tokenID)
+	amount, err := w.IncChainIntegration.GetBalanceWithContext(ctx, privateKey, tokenID)
 	if err != nil {
 		return nil, err
 	}
@@ -193,103 +202,66 @@ func (w *Wallet) GetListPrivacyCustomTokenBalanceByID(privateKey, tokenID string
 
 // dont use
 func (w *Wallet) GetAmountVoteToken(paymentAddress string) (*entity.ListCustomTokenBalance, error) {
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetAmountVoteToken, []interface{}{paymentAddress, 0})
-	if err != nil {
-		return nil, errors.Wrap(err, "w.blockchainAPI")
-	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", paymentAddress, data)
-	}
+	return w.GetAmountVoteTokenWithContext(context.Background(), paymentAddress)
+}
+
+func (w *Wallet) GetAmountVoteTokenWithContext(ctx context.Context, paymentAddress string) (*entity.ListCustomTokenBalance, error) {
 	var result entity.ListCustomTokenBalance
-	r, err := json.Marshal(data["Result"])
-	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
-	}
-	if err := json.Unmarshal(r, &result); err != nil {
-		return nil, errors.Wrap(err, "json.Unmarshal")
+	if err := w.Inc.CallWithContext(ctx, entity.GetAmountVoteTokenReq{PaymentAddress: paymentAddress}, &result); err != nil {
+		return nil, errors.Wrap(err, "w.blockchainAPI")
 	}
 	return &result, nil
 }
 
-func (w *Wallet) createAndSendConstantPrivacyTransaction(privateKey string, req entity.WalletSend) (string, error) {
-	param := []interface{}{privateKey, req.PaymentAddresses, constant.EstimateFee, 1}
+func (w *Wallet) createAndSendConstantPrivacyTransaction(ctx context.Context, privateKey string, req entity.WalletSend, opts ...incognito.TxOption) (string, error) {
+	param := []interface{}{privateKey, paymentReceivers(req), constant.EstimateFee, 1}
 
+	opts, reservation := withCoinReservation(w.txOptions(opts))
 	//rpc: CreateAndSendTransaction
-	rawData, err := w.IncChainIntegration.CreateAndSendConstantTransaction(param)
+	rawData, err := w.IncChainIntegration.CreateAndSendConstantTransactionWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method CreateAndSendConstantPrivacyTransaction: %v \n", rawData)
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
+	common.Log.Debugf("method CreateAndSendConstantPrivacyTransaction created the raw tx")
 
-	if err != nil {
-		return "", errors.Wrap(err, "b.blockchainAPI")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		// the id of a tx which may have been sent is returned with the error
+		return settleFailedSend(reservation, err), errors.Wrap(err, "b.blockchainAPI")
 	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+	trackSentTx(w.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
 func (w *Wallet) EstimatePRVFee(privateKey, toAddress string, amountToSend uint64) (int, int, error) {
-	param := []interface{}{privateKey, map[string]uint64{toAddress: amountToSend}, -1, 0}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetEstimateFee, param)
-	if err != nil {
-		return 0, 0, errors.Wrap(err, "w.blockchainAPI")
-	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return 0, 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return 0, 0, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return 0, 0, errors.Errorf("couldn't get result:  resp: %+v", data)
-	}
+	return w.EstimatePRVFeeWithContext(context.Background(), privateKey, toAddress, amountToSend)
+}
 
-	estimateFeeCoinPerKb, ok := result["EstimateFeeCoinPerKb"].(float64)
-	if !ok {
-		return 0, 0, errors.Errorf("couldn't get estimateFeeCoinPerKb: result: %+v", result)
-	}
-	estimateTxSizeInKb, ok := result["EstimateTxSizeInKb"].(float64)
-	if !ok {
-		return 0, 0, errors.Errorf("couldn't get estimateTxSizeInKb: result: %+v", result)
+func (w *Wallet) EstimatePRVFeeWithContext(ctx context.Context, privateKey, toAddress string, amountToSend uint64) (int, int, error) {
+	req := entity.EstimateFeeReq{PrivateKey: privateKey, PaymentAddresses: map[string]uint64{toAddress: amountToSend}}
+	var result entity.EstimateFeeResult
+	if err := w.Inc.CallWithContext(ctx, req, &result); err != nil {
+		return 0, 0, errors.Wrap(err, "w.blockchainAPI")
 	}
-	return int(estimateFeeCoinPerKb), int(estimateTxSizeInKb), nil
+	return int(result.EstimateFeeCoinPerKb), int(result.EstimateTxSizeInKb), nil
 }
 
 // send max prv:
 func (w *Wallet) CreateAndSendMaxPRVTransaction(privateKey, toAddress string) (string, error) {
+	return w.CreateAndSendMaxPRVTransactionWithContext(context.Background(), privateKey, toAddress)
+}
+
+func (w *Wallet) CreateAndSendMaxPRVTransactionWithContext(ctx context.Context, privateKey, toAddress string) (string, error) {
 
-	prvBalance, err := w.GetBalanceByPrivateKey(privateKey)
+	prvBalance, err := w.GetBalanceByPrivateKeyWithContext(ctx, privateKey)
 	if err != nil {
 		return "", errors.Wrap(err, "w.GetBalanceByPrivateKey")
 	}
-	fmt.Println("max amount:", prvBalance)
+	common.Log.Debugf("max amount: %v", prvBalance)
 
 	// est fee:
-	estimateFeeCoinPerKb, estimateTxSizeInKb, err := w.EstimatePRVFee(privateKey, toAddress, prvBalance)
+	estimateFeeCoinPerKb, estimateTxSizeInKb, err := w.EstimatePRVFeeWithContext(ctx, privateKey, toAddress, prvBalance)
 
 	if err != nil {
 		return "", errors.Wrap(err, "w.EstimateFee")
@@ -301,46 +273,63 @@ func (w *Wallet) CreateAndSendMaxPRVTransaction(privateKey, toAddress string) (s
 
 	param := []interface{}{privateKey, map[string]uint64{toAddress: maxAmount}, estimateFee, 1}
 
+	opts, reservation := withCoinReservation(w.txOptions(nil))
 	//rpc: CreateAndSendTransaction
-	rawData, err := w.IncChainIntegration.CreateAndSendConstantTransaction(param)
+	rawData, err := w.IncChainIntegration.CreateAndSendConstantTransactionWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method CreateAndSendMaxPRVTransaction: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendMaxPRVTransaction created the raw tx")
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-
-	if err != nil {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
+	trackSentTx(w.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
+func (w *Wallet) sendPrivacyCustomTokenTransaction(ctx context.Context, privateKey string, req entity.WalletSend, opts ...incognito.TxOption) (*entity.TxIDResult, error) {
+	param := privacyCustomTokenParams(privateKey, req)
+
+	opts, reservation := withCoinReservation(w.txOptions(opts))
+	//rpc: CreateAndSendPrivacyCustomTokenTransaction
+	rawData, err := w.IncChainIntegration.SendPrivacyCustomTokenTransactionWithContext(ctx, param, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "w.IncChainIntegration")
 	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
+
+	common.Log.Debugf("method SendPrivacyCustomTokenTransaction created the raw tx")
+
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData}, &result); err != nil {
+		// the id of a tx which may have been sent is returned with the error
+		return &entity.TxIDResult{TxID: settleFailedSend(reservation, err)}, errors.Wrap(err, "b.blockchainAPI")
 	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
+	trackSentTx(w.TxTracker, result.TxID, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData})
+	return &result, nil
+}
+
+// failedTxID returns the id of the tx of a failed send, which may have been sent, empty if none
+func failedTxID(tx *entity.TxIDResult) string {
+	if tx == nil {
+		return ""
 	}
-	return txID, nil
+	return tx.TxID
 }
 
-func (w *Wallet) sendPrivacyCustomTokenTransaction(privateKey string, req entity.WalletSend) (map[string]interface{}, error) {
+// privacyCustomTokenParams returns the params of the privacy token tx sending req, its fee is paid in PRV
+// unless req.TokenFee is set
+func privacyCustomTokenParams(privateKey string, req entity.WalletSend) []interface{} {
 	tokenData := map[string]interface{}{}
 	tokenData["Privacy"] = true
 	tokenData["TokenID"] = req.TokenID
 	tokenData["TokenTxType"] = req.Type
 	tokenData["TokenName"] = req.TokenName
 	tokenData["TokenSymbol"] = req.TokenSymbol
-	tokenData["TokenReceivers"] = req.PaymentAddresses
+	tokenData["TokenReceivers"] = paymentReceivers(req)
 	tokenData["TokenAmount"] = req.TokenAmount
 	tokenData["TokenFee"] = req.TokenFee
 	object := map[string]uint64{}
@@ -350,162 +339,60 @@ func (w *Wallet) sendPrivacyCustomTokenTransaction(privateKey string, req entity
 		nativeFee = 0
 	}
 
-	param := []interface{}{privateKey, object, nativeFee, 1, tokenData, "", 1}
-
-	//rpc: CreateAndSendPrivacyCustomTokenTransaction
-	rawData, err := w.IncChainIntegration.SendPrivacyCustomTokenTransaction(param)
-	if err != nil {
-		return nil, errors.Wrap(err, "w.IncChainIntegration")
-	}
-
-	fmt.Printf("raw data method SendPrivacyCustomTokenTransaction: %v \n", rawData)
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawPrivacyCustomTokenTransaction, rawData)
-
-	if err != nil {
-		return nil, errors.Wrap(err, "b.blockchainAPI")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	return result, nil
+	return []interface{}{privateKey, object, nativeFee, 1, tokenData, "", 1}
 }
 
 func (w *Wallet) ListPrivacyCustomToken() ([]entity.PCustomToken, error) {
-	param := []interface{}{}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.ListPrivacyCustomToken, param)
-	if err != nil {
-		return nil, errors.Wrap(err, "w.ListPrivacyCustomToken")
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	// jsonData, err := json.Marshal(result["ListCustomToken"])
-
-	jsonData, err := json.MarshalIndent(result["ListCustomToken"], "", "\t")
-
-	fmt.Println("err", err)
-
-	// fmt.Println("jsonData", jsonData)
-
-	var finalData *[]entity.PCustomToken
+	return w.ListPrivacyCustomTokenWithContext(context.Background())
+}
 
-	if err := json.Unmarshal(jsonData, &finalData); err != nil {
-		fmt.Println("err Unmarshal", err)
-		return nil, errors.Errorf("invalid data")
+func (w *Wallet) ListPrivacyCustomTokenWithContext(ctx context.Context) ([]entity.PCustomToken, error) {
+	var result entity.ListPrivacyCustomTokenResult
+	if err := w.Inc.CallWithContext(ctx, entity.ListPrivacyCustomTokenReq{}, &result); err != nil {
+		return nil, errors.Wrap(err, "w.ListPrivacyCustomToken")
 	}
-	return *finalData, nil
+	return result.ListCustomToken, nil
 }
 
 func (w *Wallet) GetTxByHash(txHash string) (*entity.TransactionDetail, error) {
-	param := []string{txHash}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetTransactionByHash, param)
-	if err != nil {
-		return nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
+	return w.GetTxByHashWithContext(context.Background(), txHash)
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get result: data: %+v", data["Result"])
+func (w *Wallet) GetTxByHashWithContext(ctx context.Context, txHash string) (*entity.TransactionDetail, error) {
+	var tx entity.TransactionDetail
+	if err := w.Inc.CallWithContext(ctx, entity.GetTransactionByHashReq{TxHash: txHash}, &tx); err != nil {
+		return nil, errors.Wrapf(err, "w.blockchainAPI: txHash: %s", txHash)
 	}
-	if result["Hash"] == nil {
+	if tx.Hash == "" {
 		return nil, constant.ErrTxHashNotExists
 	}
-
-	sb, err := json.Marshal(result)
-	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
-	}
-	var tx entity.TransactionDetail
-	if err := json.Unmarshal(sb, &tx); err != nil {
-		return nil, errors.Wrapf(err, "json.Unmarshal: sb: %+v", string(sb))
-	}
 	return &tx, nil
 }
 
 func (w *Wallet) GetDecryptOutputCoinByKeyOfTransaction(txHash, paymentAddress, readonlyKey string) (*entity.DecrypTransactionPRV, error) {
+	return w.GetDecryptOutputCoinByKeyOfTransactionWithContext(context.Background(), txHash, paymentAddress, readonlyKey)
+}
 
-	param := []interface{}{txHash, map[string]string{"PaymentAddress": paymentAddress, "ReadonlyKey": readonlyKey}}
+func (w *Wallet) GetDecryptOutputCoinByKeyOfTransactionWithContext(ctx context.Context, txHash, paymentAddress, readonlyKey string) (*entity.DecrypTransactionPRV, error) {
+	req := entity.DecryptOutputCoinByKeyOfTransactionReq{TxHash: txHash, PaymentAddress: paymentAddress, ReadonlyKey: readonlyKey}
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.DecryptOutputCoinByKeyOfTransaction, param)
-	if err != nil {
-		return nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-
-	sb, err := json.Marshal(result)
-	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
-	}
 	var decrypTransactionPRV entity.DecrypTransactionPRV
-	if err := json.Unmarshal(sb, &decrypTransactionPRV); err != nil {
-		return nil, errors.Wrapf(err, "json.Unmarshal: sb: %+v", string(sb))
+	if err := w.Inc.CallWithContext(ctx, req, &decrypTransactionPRV); err != nil {
+		return nil, errors.Wrapf(err, "w.blockchainAPI: txHash: %s", txHash)
 	}
-	fmt.Println(decrypTransactionPRV)
 	return &decrypTransactionPRV, nil
 }
 
 func (w *Wallet) GetDecryptOutputCoinByKeyOfTrans(txHash, paymentAddress, readonlyKey string) (map[string]interface{}, error) {
-	var results map[string]interface{}
-	param := []interface{}{txHash, map[string]string{"PaymentAddress": paymentAddress, "ReadonlyKey": readonlyKey}}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.DecryptOutputCoinByKeyOfTransaction, param)
-	if err != nil {
-		return results, errors.Wrapf(err, "b.blockchainAPI")
-	}
+	return w.GetDecryptOutputCoinByKeyOfTransWithContext(context.Background(), txHash, paymentAddress, readonlyKey)
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return results, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response: resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return results, errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	sb, err := json.Marshal(result)
-	if err != nil {
-		return results, errors.Wrap(err, "json.Marshal")
-	}
-	if err := json.Unmarshal(sb, &results); err != nil {
-		return results, errors.Wrapf(err, "json.Unmarshal: sb: %+v", string(sb))
+func (w *Wallet) GetDecryptOutputCoinByKeyOfTransWithContext(ctx context.Context, txHash, paymentAddress, readonlyKey string) (map[string]interface{}, error) {
+	req := entity.DecryptOutputCoinByKeyOfTransactionReq{TxHash: txHash, PaymentAddress: paymentAddress, ReadonlyKey: readonlyKey}
+
+	var results map[string]interface{}
+	if err := w.Inc.CallWithContext(ctx, req, &results); err != nil {
+		return nil, errors.Wrapf(err, "b.blockchainAPI")
 	}
 	return results, nil
 }
@@ -513,48 +400,29 @@ func (w *Wallet) GetDecryptOutputCoinByKeyOfTrans(txHash, paymentAddress, readon
 //ProofDetail
 // get amount by hash public:
 func (w *Wallet) GetAmountByHashFromReceiveAddressAndToAddress(txHash, fromAddress, toAddress string) (*big.Int, error) {
+	return w.GetAmountByHashFromReceiveAddressAndToAddressWithContext(context.Background(), txHash, fromAddress, toAddress)
+}
+
+func (w *Wallet) GetAmountByHashFromReceiveAddressAndToAddressWithContext(ctx context.Context, txHash, fromAddress, toAddress string) (*big.Int, error) {
 
 	// convert address to PublicKey
-	fromPublicKey, err := w.GetPublickeyFromPaymentAddress(fromAddress)
+	fromPublicKey, err := w.GetPublickeyFromPaymentAddressWithContext(ctx, fromAddress)
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", fromAddress)
 	}
-	toPublicKey, err := w.GetPublickeyFromPaymentAddress(toAddress)
-	fmt.Println("toPublicKey, err", toPublicKey, err)
+	toPublicKey, err := w.GetPublickeyFromPaymentAddressWithContext(ctx, toAddress)
 	if err != nil {
 		return nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", fromAddress)
 	}
 
-	param := []string{txHash}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetTransactionByHash, param)
-	if err != nil {
-		return nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return nil, errors.Errorf("couldn't get result: data: %+v", data["Result"])
+	var tx entity.TransactionDetail
+	if err := w.Inc.CallWithContext(ctx, entity.GetTransactionByHashReq{TxHash: txHash}, &tx); err != nil {
+		return nil, errors.Wrapf(err, "w.blockchainAPI: txHash: %s", txHash)
 	}
-	if result["Hash"] == nil {
+	if tx.Hash == "" {
 		return nil, constant.ErrTxHashNotExists
 	}
-
-	sb, err := json.Marshal(result)
-	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
-	}
-	var tx entity.TransactionDetail
-	if err := json.Unmarshal(sb, &tx); err != nil {
-		return nil, errors.Wrapf(err, "json.Unmarshal: sb: %+v", string(sb))
-	}
 	// get amount:
 	var sendAmount uint64 = 0
 	var receiveFromPublicKey = false
@@ -599,13 +467,17 @@ func (w *Wallet) GetAmountByHashFromReceiveAddressAndToAddress(txHash, fromAddre
 		return nil, constant.ErrTxHashInvalidToAddress
 	}
 
-	fmt.Println("sendAmount:", sendAmount)
+	common.Log.Debugf("sendAmount: %v", sendAmount)
 	v := new(big.Int)
 	v.SetUint64(sendAmount)
 	return v, nil
 }
 
 func (w *Wallet) CreateAndSendIssuingRequest(privateKey, receiveAddress string, depositedAmount *big.Int, tokenId string, tokenName string) (string, error) {
+	return w.CreateAndSendIssuingRequestWithContext(context.Background(), privateKey, receiveAddress, depositedAmount, tokenId, tokenName)
+}
+
+func (w *Wallet) CreateAndSendIssuingRequestWithContext(ctx context.Context, privateKey, receiveAddress string, depositedAmount *big.Int, tokenId string, tokenName string) (string, error) {
 	if depositedAmount == nil {
 		return "", errors.New("depositedAmount is nil")
 	}
@@ -618,130 +490,112 @@ func (w *Wallet) CreateAndSendIssuingRequest(privateKey, receiveAddress string,
 
 	param := []interface{}{privateKey, nil, constant.EstimateFee, -1, depositedReq}
 
+	opts, reservation := withCoinReservation(w.txOptions(nil))
 	//rpc: CreateAndSendIssuingRequest
-	rawData, err := w.IncChainIntegration.CreateAndSendIssuingRequest(param)
+	rawData, err := w.IncChainIntegration.CreateAndSendIssuingRequestWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method CreateAndSendIssuingRequest: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendIssuingRequest created the raw tx")
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-	//resp, _, err := w.Inc.PostAndReceiveInterface(CreateAndSendIssuingRequest, param)
-	if err != nil {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "w.blockchainAPI")
 	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+	trackSentTx(w.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
 func (w *Wallet) GetIssuingStatus(txHash string) (string, uint64, error) {
-	param := []string{txHash}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetIssuingStatus, param)
-	if err != nil {
-		return "", 0, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
+	return w.GetIssuingStatusWithContext(context.Background(), txHash)
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", 0, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", 0, errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", 0, errors.Errorf("couldn't get result: data: %+v", data["Result"])
+func (w *Wallet) GetIssuingStatusWithContext(ctx context.Context, txHash string) (string, uint64, error) {
+	var result entity.IssuingStatusResult
+	if err := w.Inc.CallWithContext(ctx, entity.GetIssuingStatusReq{TxHash: txHash}, &result); err != nil {
+		return "", 0, errors.Wrapf(err, "w.blockchainAPI: txHash: %s", txHash)
 	}
-	if result["Status"] == nil {
-		return "", 0, errors.Errorf("bad result: data: %+v", data["Result"])
+	if result.Status == "" {
+		return "", 0, errors.Errorf("bad result: data: %+v", result)
 	}
-	if result["Amount"] == nil {
-		return "", 0, errors.Errorf("bad result: data: %+v", data["Result"])
-	}
-	return result["Status"].(string), uint64(result["Amount"].(float64)), nil
+	return result.Status, result.Amount, nil
 }
 
 func (w *Wallet) GetContractingStatus(txHash string) (string, *big.Int, error) {
-	param := []string{txHash}
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetContractingStatus, param)
-	if err != nil {
-		return "", nil, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
+	return w.GetContractingStatusWithContext(context.Background(), txHash)
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", nil, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
+func (w *Wallet) GetContractingStatusWithContext(ctx context.Context, txHash string) (string, *big.Int, error) {
+	var result entity.ContractingStatusResult
+	if err := w.Inc.CallWithContext(ctx, entity.GetContractingStatusReq{TxHash: txHash}, &result); err != nil {
+		return "", nil, errors.Wrapf(err, "w.blockchainAPI: txHash: %s", txHash)
 	}
-	if data["Result"] == nil {
-		return "", nil, errors.Errorf("couldn't get result from response:  resp: %+v", data)
+	if result.Status == "" {
+		return "", nil, errors.Errorf("bad result: data: %+v", result)
 	}
-	result, ok := data["Result"].(map[string]interface{})
+	redeem, ok := new(big.Int).SetString(result.Redeem, 10)
 	if !ok {
-		return "", nil, errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	if result["Status"] == nil {
-		return "", nil, errors.Errorf("bad result: data: %+v", data["Result"])
-	}
-	if result["Redeem"] == nil {
-		return "", nil, errors.Errorf("bad result: data: %+v", data["Result"])
+		return "", nil, errors.Errorf("bad redeem amount: data: %+v", result)
 	}
-	redeem, ok := new(big.Int).SetString(result["Redeem"].(string), 10)
-	return result["Status"].(string), redeem, nil
+	return result.Status, redeem, nil
 }
 
 func (w *Wallet) CreateAndSendIssuingRequestForPrivacyToken(privateKey string, metadata map[string]interface{}) (string, error) {
+	return w.CreateAndSendIssuingRequestForPrivacyTokenWithContext(context.Background(), privateKey, metadata)
+}
+
+func (w *Wallet) CreateAndSendIssuingRequestForPrivacyTokenWithContext(ctx context.Context, privateKey string, metadata map[string]interface{}) (string, error) {
 	param := []interface{}{privateKey, nil, constant.EstimateFee, -1, metadata}
 
+	opts, reservation := withCoinReservation(w.txOptions(nil))
 	//rpc: CreateAndSendIssuingRequest
-	rawData, err := w.IncChainIntegration.CreateAndSendIssuingRequest(param)
+	rawData, err := w.IncChainIntegration.CreateAndSendIssuingRequestWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method CreateAndSendIssuingRequestForPrivacyToken: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendIssuingRequestForPrivacyToken created the raw tx")
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
-	//resp, _, err := w.Inc.PostAndReceiveInterface(CreateAndSendIssuingRequest, param)
-	if err != nil {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "w.blockchainAPI")
 	}
+	trackSentTx(w.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
+func (w *Wallet) CreateAndSendContractingRequestForPrivacyToken(privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (string, error) {
+	return w.CreateAndSendContractingRequestForPrivacyTokenWithContext(context.Background(), privateKey, autoChargePRVFee, metadata)
+}
+
+func (w *Wallet) CreateAndSendContractingRequestForPrivacyTokenWithContext(ctx context.Context, privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (string, error) {
+	param := contractingRequestParams(privateKey, autoChargePRVFee, metadata)
+
+	opts, reservation := withCoinReservation(w.txOptions(nil))
+	//rpc: CreateAndSendContractingRequest
+	rawData, err := w.IncChainIntegration.CreateAndSendContractingRequestWithContext(ctx, param, opts...)
+	if err != nil {
+		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
+
+	common.Log.Debugf("method CreateAndSendContractingRequest created the raw tx")
+
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
+		return "", errors.Wrap(err, "w.blockchainAPI")
 	}
-	return txID, nil
+	trackSentTx(w.TxTracker, result.TxID, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
-func (w *Wallet) CreateAndSendContractingRequestForPrivacyToken(privateKey string, autoChargePRVFee int, metadata map[string]interface{}) (string, error) {
+// contractingRequestParams returns the params of the tx burning the centralized token of metadata
+func contractingRequestParams(privateKey string, autoChargePRVFee int, metadata map[string]interface{}) []interface{} {
 	// autoChargePRVFee: -1: auto prv fee, 0: 0 prv fee -> get ptoken fee
-	param := []interface{}{
+	return []interface{}{
 		privateKey,
 		nil,
 		autoChargePRVFee,
@@ -750,237 +604,126 @@ func (w *Wallet) CreateAndSendContractingRequestForPrivacyToken(privateKey strin
 		"",
 		0,
 	}
-
-	//rpc: CreateAndSendContractingRequest
-	rawData, err := w.IncChainIntegration.CreateAndSendContractingRequest(param)
-	if err != nil {
-		return "", errors.Wrap(err, "w.IncChainIntegration")
-	}
-
-	fmt.Printf("raw data method CreateAndSendContractingRequest: %v \n", rawData)
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawPrivacyCustomTokenTransaction, rawData)
-
-	if err != nil {
-		return "", errors.Wrap(err, "w.blockchainAPI")
-	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
 }
 
 func (w *Wallet) CreateAndSendTxWithIssuingEth(privateKey, burnerAddress string, metadata map[string]interface{}) (string, []byte, error) {
+	return w.CreateAndSendTxWithIssuingEthWithContext(context.Background(), privateKey, burnerAddress, metadata)
+}
+
+func (w *Wallet) CreateAndSendTxWithIssuingEthWithContext(ctx context.Context, privateKey, burnerAddress string, metadata map[string]interface{}) (string, []byte, error) {
 	transParams := map[string]uint64{burnerAddress: 0}
 	param := []interface{}{privateKey, transParams, constant.EstimateFee, -1, metadata}
 
 	//rpc: CreateAndSendTxWithIssuingEthReq
-	rawData, err := w.IncChainIntegration.CreateAndSendTxWithIssuingEth(param)
+	rawData, err := w.IncChainIntegration.CreateAndSendTxWithIssuingEthWithContext(ctx, param, w.txOptions(nil)...)
 	if err != nil {
 		return "", nil, errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method CreateAndSendTxWithIssuingEth: %v \n", rawData)
+	common.Log.Debugf("method CreateAndSendTxWithIssuingEth created the raw tx")
 
-	resp, body, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
+	body, err := w.Inc.PostWithContext(ctx, constant.SendRawTransaction, rawData)
 
 	if err != nil {
 		return "", body, errors.Wrap(err, "w.blockchainAPI")
 	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", body, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", body, errors.Errorf("couldn't get result from response:  resp: %+v", data)
+	var result entity.TxIDResult
+	if err := service.DecodeResponse(constant.SendRawTransaction, body, &result); err != nil {
+		return "", body, err
 	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", body, errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", body, errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, body, nil
+	return result.TxID, body, nil
 }
 
 func (w *Wallet) GetBridgeReqWithStatus(TxReqID string) (int, error) {
+	return w.GetBridgeReqWithStatusWithContext(context.Background(), TxReqID)
+}
 
-	txParams := map[string]interface{}{"TxReqID": TxReqID}
-
-	param := []interface{}{txParams}
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetBridgeReqWithStatus, param)
-	if err != nil {
-		return -1, errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return -1, errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return -1, errors.Errorf("couldn't get result from response:  resp: %+v", data)
+func (w *Wallet) GetBridgeReqWithStatusWithContext(ctx context.Context, TxReqID string) (int, error) {
+	var status int
+	if err := w.Inc.CallWithContext(ctx, entity.GetBridgeReqWithStatusReq{TxReqID: TxReqID}, &status); err != nil {
+		return -1, errors.Wrapf(err, "w.blockchainAPI: TxReqID: %s", TxReqID)
 	}
 
-	return int(data["Result"].(float64)), nil
+	return status, nil
 }
 
 // WithDrawReward
 func (w *Wallet) CreateWithDrawReward(privateKey, tokenID string) (string, error) {
+	return w.CreateWithDrawRewardWithContext(context.Background(), privateKey, tokenID)
+}
 
-	param := []interface{}{
-		privateKey,
-		0,
-		0,
-		0,
-		map[string]interface{}{"TokenID": tokenID},
-	}
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.WithDrawReward, param)
-	if err != nil {
-		return "", errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
-	// todo: update code get response ...
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
+func (w *Wallet) CreateWithDrawRewardWithContext(ctx context.Context, privateKey, tokenID string) (string, error) {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.WithDrawRewardReq{PrivateKey: privateKey, TokenID: tokenID}, &result); err != nil {
+		return "", errors.Wrapf(err, "w.blockchainAPI: tokenID: %s", tokenID)
 	}
-	return txID, nil
+	trackSentTx(w.TxTracker, result.TxID, nil)
+	return result.TxID, nil
 }
 
 // gen tokenid
 func (w *Wallet) GenerateTokenID(symbol, pSymbol string) (string, error) {
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GenerateTokenID, []interface{}{symbol, pSymbol})
-	if err != nil {
+	return w.GenerateTokenIDWithContext(context.Background(), symbol, pSymbol)
+}
+
+func (w *Wallet) GenerateTokenIDWithContext(ctx context.Context, symbol, pSymbol string) (string, error) {
+	var tokenID string
+	err := w.Inc.CallWithContext(ctx, entity.GenerateTokenIDReq{Symbol: symbol, PSymbol: pSymbol}, &tokenID)
+	if err != nil && errors.Cause(err) != service.ErrNoResult {
 		return "", err
 	}
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", nil
-	}
-	tokenID, ok := data["Result"].(string)
-	if !ok {
-		return "", nil
-	}
 	return tokenID, nil
 }
 
 func (w *Wallet) GetPublickeyFromPaymentAddress(paymentAddress string) (string, error) {
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetPublickeyFromPaymentAddress, []interface{}{paymentAddress})
+	return w.GetPublickeyFromPaymentAddressWithContext(context.Background(), paymentAddress)
+}
 
-	if err != nil {
+func (w *Wallet) GetPublickeyFromPaymentAddressWithContext(ctx context.Context, paymentAddress string) (string, error) {
+	var result entity.PublicKeyResult
+	err := w.Inc.CallWithContext(ctx, entity.GetPublickeyFromPaymentAddressReq{PaymentAddress: paymentAddress}, &result)
+	if err != nil && errors.Cause(err) != service.ErrNoResult {
 		return "", err
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", nil
-	}
-	result, ok := data["Result"].(map[string]interface{})
-
-	if !ok {
-		return "", errors.Errorf("couldn't get public ID: resp: %+v", data)
-	}
-	publicKeyInBase58Check, ok := result["PublicKeyInBase58Check"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get publicKeyInBase58Check: result: %+v", result)
-	}
-	return publicKeyInBase58Check, nil
+	return result.PublicKeyInBase58Check, nil
 }
 
 func (w *Wallet) GetShardFromPaymentAddress(paymentAddress string) (int, error) {
+	return w.GetShardFromPaymentAddressWithContext(context.Background(), paymentAddress)
+}
 
-	body, err := w.Inc.Post(constant.GetPublickeyFromPaymentAddress, []interface{}{paymentAddress})
-
-	if err != nil {
-		return -1, errors.Wrapf(err, "w.post: param: %+v", []interface{}{paymentAddress})
+func (w *Wallet) GetShardFromPaymentAddressWithContext(ctx context.Context, paymentAddress string) (int, error) {
+	var result entity.PublicKeyResult
+	if err := w.Inc.CallWithContext(ctx, entity.GetPublickeyFromPaymentAddressReq{PaymentAddress: paymentAddress}, &result); err != nil {
+		return -1, errors.Wrapf(err, "w.post: paymentAddress: %s", paymentAddress)
 	}
-
-	Message := entity.GetShardFromPaymentAddressObject{}
-
-	if err := json.Unmarshal(body, &Message); err != nil {
-		return -1, errors.Wrapf(err, "w.respone: param: %+v", body)
+	if len(result.PublicKeyInBytes) != 32 {
+		return -1, errors.Errorf("public key of %s has %d bytes", paymentAddress, len(result.PublicKeyInBytes))
 	}
-	fmt.Println(Message.Result.PublicKeyInBytes[31])
-	return (Message.Result.PublicKeyInBytes[31] % 8), nil
+	return (result.PublicKeyInBytes[31] % 8), nil
 }
 
-func (w *Wallet) getBurningAddressFromChain() (string, error) {
-
-	param := []interface{}{}
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetBurningAddress, param)
-	if err != nil {
-		return "", errors.Wrapf(err, "w.blockchainAPI: param: %+v", param)
-	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: resp: %+v", data)
+func (w *Wallet) getBurningAddressFromChain(ctx context.Context) (string, error) {
+	var burningAddress string
+	if err := w.Inc.CallWithContext(ctx, entity.GetBurningAddressReq{}, &burningAddress); err != nil {
+		return "", errors.Wrap(err, "w.blockchainAPI")
 	}
 
-	return data["Result"].(string), nil
+	return burningAddress, nil
 }
 
 func (w *Wallet) GetTransactionByReceivers(PaymentAddress, ReadonlyKey string) (res *entity.ReceivedTransactions, err error) {
-	param := []interface{}{map[string]string{"PaymentAddress": PaymentAddress, "ReadonlyKey": ReadonlyKey}}
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.GetTransactionByReceiver, param)
-
-	if err != nil {
-		return nil, err
-	}
-	data := resp.(map[string]interface{})
-	resultResp := data["Result"]
+	return w.GetTransactionByReceiversWithContext(context.Background(), PaymentAddress, ReadonlyKey)
+}
 
-	if resultResp == nil {
-		return nil, errors.New("Fail")
-	}
+func (w *Wallet) GetTransactionByReceiversWithContext(ctx context.Context, PaymentAddress, ReadonlyKey string) (res *entity.ReceivedTransactions, err error) {
+	req := entity.GetTransactionByReceiverReq{PaymentAddress: PaymentAddress, ReadonlyKey: ReadonlyKey}
 
 	result := entity.ReceivedTransactions{}
-	resultInBytes, err := json.MarshalIndent(resultResp, "", "\t")
-	if err != nil {
-		return nil, err
-	}
-
-	err = json.Unmarshal(resultInBytes, &result)
-	if err != nil {
+	if err := w.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return nil, err
 	}
+	decryptReceivedMemos(&result, ReadonlyKey)
 
 	return &result, nil
 }
@@ -991,7 +734,7 @@ func (w *Wallet) GetAmountByMemo(listTrans []entity.ReceivedTransaction, memo, t
 		memoDecode, _, err := base58.Base58Check{}.Decode(receivedTransaction.Info)
 
 		if err != nil {
-			fmt.Println("err", err)
+			common.Log.Warnf("decode memo of tx %s: %v", receivedTransaction.Hash, err)
 			return "", 0
 		}
 
@@ -1015,12 +758,46 @@ func (w *Wallet) CreateAndSendBurningForDepositToSCRequest(
 	remoteAddrStr string,
 	incTokenId string,
 ) (*entity.BurningForDepositToSCRes, error) {
-	burningAddress, err := w.getBurningAddressFromChain()
+	return w.CreateAndSendBurningForDepositToSCRequestWithContext(context.Background(), privateKey, amount, remoteAddrStr, incTokenId)
+}
+
+func (w *Wallet) CreateAndSendBurningForDepositToSCRequestWithContext(
+	ctx context.Context,
+	privateKey string,
+	amount *big.Int,
+	remoteAddrStr string,
+	incTokenId string,
+) (*entity.BurningForDepositToSCRes, error) {
+	burningAddress, err := w.getBurningAddressFromChain(ctx)
 	if err != nil {
 		return nil, errors.Wrapf(err, "w.blockchainAPI: method %+v, Get burn address", constant.CreateAndSendBurningForDepositToSCRequest)
 	}
 
-	param := []interface{}{
+	param := burningForDepositToSCParams(privateKey, burningAddress, amount, remoteAddrStr, incTokenId)
+
+	opts, reservation := withCoinReservation(w.txOptions(nil))
+	//rpc: CreateAndSendBurningForDepositToSCRequest
+	rawData, err := w.IncChainIntegration.CreateAndSendBurningForDepositToSCRequestWithContext(ctx, param, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "w.IncChainIntegration")
+	}
+
+	common.Log.Debugf("method CreateAndSendBurningForDepositToSCRequest created the raw tx")
+
+	result := entity.BurningForDepositToSCRes{}
+	if err := w.Inc.CallWithContext(ctx, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
+		return nil, errors.Wrapf(err, "w.blockchainAPI: method %+v", constant.CreateAndSendBurningForDepositToSCRequest)
+	}
+	trackSentTx(w.TxTracker, result.TxID, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData})
+
+	return &result, nil
+}
+
+// burningForDepositToSCParams returns the params of the tx burning amount of incTokenId, to be withdrawn on Ethereum
+// to remoteAddrStr
+func burningForDepositToSCParams(privateKey string, burningAddress string, amount *big.Int, remoteAddrStr string, incTokenId string) []interface{} {
+	return []interface{}{
 		privateKey,
 		nil,
 		5,
@@ -1041,51 +818,21 @@ func (w *Wallet) CreateAndSendBurningForDepositToSCRequest(
 		"",
 		0,
 	}
-
-	//rpc: CreateAndSendBurningForDepositToSCRequest
-	rawData, err := w.IncChainIntegration.CreateAndSendBurningForDepositToSCRequest(param)
-	if err != nil {
-		return nil, errors.Wrap(err, "w.IncChainIntegration")
-	}
-
-	fmt.Printf("raw data method CreateAndSendBurningForDepositToSCRequest: %v \n", rawData)
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawPrivacyCustomTokenTransaction, rawData)
-
-	if err != nil {
-		return nil, errors.Wrapf(err, "w.blockchainAPI: method %+v", constant.CreateAndSendBurningForDepositToSCRequest)
-	}
-
-	data := resp.(map[string]interface{})
-	resultResp := data["Result"]
-
-	if resultResp == nil {
-		return nil, errors.Errorf("couldn't get txID: resp: %+v", data)
-	}
-
-	resultInBytes, err := json.MarshalIndent(resultResp, "", "\t")
-	if err != nil {
-		return nil, err
-	}
-
-	result := entity.BurningForDepositToSCRes{}
-	err = json.Unmarshal(resultInBytes, &result)
-	if err != nil {
-		return nil, err
-	}
-
-	return &result, nil
 }
 
 func (w *Wallet) GetBalance(privateKey string, tokenId string) (uint64, error) {
+	return w.GetBalanceWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (w *Wallet) GetBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (uint64, error) {
 	var balance uint64
 	var bigBalance *big.Int
 	var err error
 
 	if tokenId == w.ConstantID {
-		balance, err = w.GetBalanceByPrivateKey(privateKey)
+		balance, err = w.GetBalanceByPrivateKeyWithContext(ctx, privateKey)
 	} else {
-		bigBalance, err = w.GetListPrivacyCustomTokenBalanceByID(privateKey, tokenId)
+		bigBalance, err = w.GetListPrivacyCustomTokenBalanceByIDWithContext(ctx, privateKey, tokenId)
 		if err == nil {
 			balance = bigBalance.Uint64()
 		}
@@ -1095,9 +842,13 @@ func (w *Wallet) GetBalance(privateKey string, tokenId string) (uint64, error) {
 }
 
 func (w *Wallet) SellPRV(privateKey string, buyTokenId string, tradingFee uint64, sellTokenAmount uint64, minimumAmount uint64, traderAddress string) (string, error) {
+	return w.SellPRVWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenAmount, minimumAmount, traderAddress)
+}
+
+func (w *Wallet) SellPRVWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenAmount uint64, minimumAmount uint64, traderAddress string) (string, error) {
 	var burningAddress string
 	var err error
-	burningAddress, err = w.Block.GetBurningAddress()
+	burningAddress, err = w.Block.GetBurningAddressWithContext(ctx)
 
 	if err != nil {
 		return "", errors.Wrap(err, "w.GetBurningAddress")
@@ -1122,38 +873,39 @@ func (w *Wallet) SellPRV(privateKey string, buyTokenId string, tradingFee uint64
 		metadata,
 	}
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.CreateAndSendTxWithPRVTradeReq, paramArray)
-	if err != nil {
+	var result entity.TxIDResult
+	req := entity.TradeReq{TradeMethod: constant.CreateAndSendTxWithPRVTradeReq, Args: paramArray}
+	if err := w.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return "", errors.Wrapf(err, "w.blockchainAPI")
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", metadata, data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	if result["TxID"] == nil {
+	if result.TxID == "" {
 		return "", constant.ErrTxHashNotExists
 	}
-	return result["TxID"].(string), nil
+	return result.TxID, nil
 }
 
 func (w *Wallet) SellPToken(privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
+	return w.SellPTokenWithContext(context.Background(), privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress, networkFeeTokenID, networkFee)
+}
+
+func (w *Wallet) SellPTokenWithContext(ctx context.Context, privateKey string, buyTokenId string, tradingFee uint64, sellTokenId string, sellTokenAmount uint64, minimumAmount uint64, traderAddress string, networkFeeTokenID string, networkFee uint64) (string, error) {
 	var burningAddress string
 	var err error
-	burningAddress, err = w.Block.GetBurningAddress()
+	burningAddress, err = w.Block.GetBurningAddressWithContext(ctx)
 	var FeePerKb int
 	var TokenFee uint64
 	if err != nil {
 		return "", errors.Wrap(err, "w.GetBurningAddress")
 	}
 
+	// a network fee paid in the token is converted from the PRV fee when it is not given
+	if networkFeeTokenID != w.ConstantID && networkFee == 0 {
+		networkFee, err = w.pdex().tradeTokenFee(ctx, privateKey, buyTokenId, tradingFee, sellTokenId, sellTokenAmount, minimumAmount, traderAddress)
+		if err != nil {
+			return "", errors.Wrap(err, "pdex.tradeTokenFee")
+		}
+	}
+
 	if networkFeeTokenID == w.ConstantID {
 		FeePerKb = 5
 		TokenFee = 0
@@ -1191,31 +943,24 @@ func (w *Wallet) SellPToken(privateKey string, buyTokenId string, tradingFee uin
 		0,
 	}
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.CreateAndSendTxWithPTokenTradeReq, paramArray)
-	if err != nil {
+	var result entity.TxIDResult
+	req := entity.TradeReq{TradeMethod: constant.CreateAndSendTxWithPTokenTradeReq, Args: paramArray}
+	if err := w.Inc.CallWithContext(ctx, req, &result); err != nil {
 		return "", errors.Wrapf(err, "w.blockchainAPI")
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response: req: %+v, resp: %+v", metadata, data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get result: data: %+v", data["Result"])
-	}
-	if result["TxID"] == nil {
+	if result.TxID == "" {
 		return "", constant.ErrTxHashNotExists
 	}
 
-	return result["TxID"].(string), nil
+	return result.TxID, nil
 }
 
 func (w *Wallet) GetTransactionAmount(txId string, walletAddress string, readOnlyKey string) (uint64, error) {
-	receiveDetail, err := w.GetDecryptOutputCoinByKeyOfTransaction(txId, walletAddress, readOnlyKey)
+	return w.GetTransactionAmountWithContext(context.Background(), txId, walletAddress, readOnlyKey)
+}
+
+func (w *Wallet) GetTransactionAmountWithContext(ctx context.Context, txId string, walletAddress string, readOnlyKey string) (uint64, error) {
+	receiveDetail, err := w.GetDecryptOutputCoinByKeyOfTransactionWithContext(ctx, txId, walletAddress, readOnlyKey)
 	if err != nil {
 		return 0, errors.Wrap(err, "w.GetDecryptOutputCoinByKeyOfTransaction")
 	}
@@ -1223,14 +968,20 @@ func (w *Wallet) GetTransactionAmount(txId string, walletAddress string, readOnl
 	return receiveDetail.AmountPRV, nil
 }
 
-func (w *Wallet) SendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string) (string, error) {
+func (w *Wallet) SendToken(privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string, opts ...incognito.TxOption) (string, error) {
+	return w.SendTokenWithContext(context.Background(), privateKey, receiverAddress, tokenId, amount, fee, feeTokenId, opts...)
+}
+
+// SendTokenWithContext sends amount of tokenId to receiverAddress, opts customize how the tx is built. When the send
+// fails without the node rejecting the tx, the id of the tx, which may have been sent, is returned with the error.
+func (w *Wallet) SendTokenWithContext(ctx context.Context, privateKey string, receiverAddress string, tokenId string, amount uint64, fee uint64, feeTokenId string, opts ...incognito.TxOption) (string, error) {
 	if tokenId == w.ConstantID {
 		var listPaymentAddresses = make(map[string]uint64)
 		listPaymentAddresses[receiverAddress] = amount
-		return w.createAndSendConstantPrivacyTransaction(privateKey, entity.WalletSend{
+		return w.createAndSendConstantPrivacyTransaction(ctx, privateKey, entity.WalletSend{
 			Type:             0,
 			PaymentAddresses: listPaymentAddresses,
-		})
+		}, opts...)
 	}
 
 	param := entity.WalletSend{
@@ -1248,60 +999,61 @@ func (w *Wallet) SendToken(privateKey string, receiverAddress string, tokenId st
 		param.TokenFee = fee
 	}
 
-	tx, err := w.sendPrivacyCustomTokenTransaction(privateKey, param)
+	tx, err := w.sendPrivacyCustomTokenTransaction(ctx, privateKey, param, opts...)
 
 	if err != nil {
-		return "", errors.Wrap(err, "p.SendPrivacyCustomTokenTransaction")
+		return failedTxID(tx), errors.Wrap(err, "p.SendPrivacyCustomTokenTransaction")
 	}
 
-	txID, ok := tx["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", tx)
-	}
-	return txID, nil
+	return tx.TxID, nil
+}
+
+func (w *Wallet) DefragmentationPrv(privateKey string, maxValue int64, opts ...incognito.TxOption) (string, error) {
+	return w.DefragmentationPrvWithContext(context.Background(), privateKey, maxValue, opts...)
 }
 
-func (w *Wallet) DefragmentationPrv(privateKey string, maxValue int64) (string, error) {
+func (w *Wallet) DefragmentationPrvWithContext(ctx context.Context, privateKey string, maxValue int64, opts ...incognito.TxOption) (string, error) {
+	return w.defragmentationPrv(ctx, privateKey, maxValue, rpcservice.MaxDefragmentQuantity, opts...)
+}
+
+// defragmentationPrv merges quantity coins worth at most maxValue at most
+func (w *Wallet) defragmentationPrv(ctx context.Context, privateKey string, maxValue int64, quantity int, opts ...incognito.TxOption) (string, error) {
 	param := []interface{}{
 		privateKey,
 		maxValue,
 		constant.EstimateFee,
 		0,
+		int64(quantity),
 	}
 
+	opts, reservation := withCoinReservation(w.txOptions(opts))
 	//rpc: defragmentaccount
-	rawData, err := w.IncChainIntegration.DefragmentationPrv(param)
+	rawData, err := w.IncChainIntegration.DefragmentationPrvWithContext(ctx, param, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method DefragmentationPrv: %v \n", rawData)
-
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawTransaction, rawData)
+	common.Log.Debugf("method DefragmentationPrv created the raw tx")
 
-	if err != nil {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
+	trackSentTx(w.TxTracker, result.TxID, entity.SendTransactionReq{RawData: rawData})
+	return result.TxID, nil
+}
 
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+func (w *Wallet) DefragmentationPToken(privateKey string, tokenId string, opts ...incognito.TxOption) (string, error) {
+	return w.DefragmentationPTokenWithContext(context.Background(), privateKey, tokenId, opts...)
+}
+
+func (w *Wallet) DefragmentationPTokenWithContext(ctx context.Context, privateKey string, tokenId string, opts ...incognito.TxOption) (string, error) {
+	return w.defragmentationPToken(ctx, privateKey, tokenId, rpcservice.MaxDefragmentQuantity, opts...)
 }
 
-func (w *Wallet) DefragmentationPToken(privateKey string, tokenId string) (string, error) {
+// defragmentationPToken merges quantity coins of tokenId at most
+func (w *Wallet) defragmentationPToken(ctx context.Context, privateKey string, tokenId string, quantity int, opts ...incognito.TxOption) (string, error) {
 	tokenData := map[string]interface{}{}
 	tokenData["Privacy"] = true
 	tokenData["TokenID"] = tokenId
@@ -1311,6 +1063,7 @@ func (w *Wallet) DefragmentationPToken(privateKey string, tokenId string) (strin
 	tokenData["TokenReceivers"] = map[string]uint64{}
 	tokenData["TokenAmount"] = uint64(0)
 	tokenData["TokenFee"] = uint64(0)
+	tokenData["MaxDefragmentQuantity"] = quantity
 
 	object := map[string]uint64{}
 	nativeFee := -1
@@ -1325,42 +1078,32 @@ func (w *Wallet) DefragmentationPToken(privateKey string, tokenId string) (strin
 		1,
 	}
 
+	opts, reservation := withCoinReservation(w.txOptions(opts))
 	//rpc: defragmentaccounttoken
-	rawData, err := w.IncChainIntegration.DefragmentationPToken(params)
+	rawData, err := w.IncChainIntegration.DefragmentationPTokenWithContext(ctx, params, opts...)
 	if err != nil {
 		return "", errors.Wrap(err, "w.IncChainIntegration")
 	}
 
-	fmt.Printf("raw data method DefragmentationPToken: %v \n", rawData)
+	common.Log.Debugf("method DefragmentationPToken created the raw tx")
 
-	resp, _, err := w.Inc.PostAndReceiveInterface(constant.SendRawPrivacyCustomTokenTransaction, rawData)
-
-	if err != nil {
+	var result entity.TxIDResult
+	if err := w.Inc.CallWithContext(ctx, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData}, &result); err != nil {
+		settleFailedSend(reservation, err)
 		return "", errors.Wrap(err, "b.blockchainAPI")
 	}
-
-	data := resp.(map[string]interface{})
-	if data["Error"] != nil {
-		return "", errors.Errorf("couldn't get result from response data: %+v", data["Error"])
-	}
-	if data["Result"] == nil {
-		return "", errors.Errorf("couldn't get result from response:  resp: %+v", data)
-	}
-	result, ok := data["Result"].(map[string]interface{})
-	if !ok {
-		return "", errors.Errorf("couldn't get txID:  resp: %+v", data)
-	}
-	txID, ok := result["TxID"].(string)
-	if !ok {
-		return "", errors.Errorf("couldn't get txID: result: %+v", result)
-	}
-	return txID, nil
+	trackSentTx(w.TxTracker, result.TxID, entity.SendRawPrivacyCustomTokenTransactionReq{RawData: rawData})
+	return result.TxID, nil
 }
 
 func (w *Wallet) GetUTXO(privateKey string, tokenId string) ([]*entity.Utxo, error) {
+	return w.GetUTXOWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (w *Wallet) GetUTXOWithContext(ctx context.Context, privateKey string, tokenId string) ([]*entity.Utxo, error) {
 	var input []*entity.Utxo
 
-	inputCoin, err := w.IncChainIntegration.GetUTXO(privateKey, tokenId)
+	inputCoin, err := w.IncChainIntegration.GetUTXOWithContext(ctx, privateKey, tokenId)
 	if err != nil {
 		return nil, err
 	}
@@ -1375,3 +1118,15 @@ func (w *Wallet) GetUTXO(privateKey string, tokenId string) ([]*entity.Utxo, err
 
 	return input, nil
 }
+
+func (w *Wallet) GetAccountBalance(privateKey string, tokenId string) (*entity.AccountBalance, error) {
+	return w.GetAccountBalanceWithContext(context.Background(), privateKey, tokenId)
+}
+
+func (w *Wallet) GetAccountBalanceWithContext(ctx context.Context, privateKey string, tokenId string) (*entity.AccountBalance, error) {
+	balance, err := w.IncChainIntegration.GetAccountBalanceWithContext(ctx, privateKey, tokenId)
+	if err != nil {
+		return nil, errors.Wrap(err, "w.IncChainIntegration")
+	}
+	return &entity.AccountBalance{Available: balance.Available, Pending: balance.Pending}, nil
+}
@@ -1,13 +1,11 @@
 package service
 
 import (
-	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/pkg/errors"
-	"io/ioutil"
 	"net/http"
-	"strings"
 )
 
 type IncogClientInterface interface {
@@ -15,13 +13,27 @@ type IncogClientInterface interface {
 	buildParameter() error
 }
 
+// Request is a typed RPC call, see the Req structs of the entity package
+type Request interface {
+	Method() string
+	Params() interface{}
+}
+
+// ErrNoResult is returned by DecodeResponse when the node answered neither an error nor a result
+var ErrNoResult = errors.New("response has no result")
+
 type IncogClient struct {
 	Client        *http.Client
 	ChainEndpoint string
+	Transport     rpcclient.Transport
 }
 
 func (i *IncogClient) PostAndReceiveInterface(method string, params interface{}) (interface{}, []byte, error) {
-	body, err := i.Post(method, params)
+	return i.PostAndReceiveInterfaceWithContext(context.Background(), method, params)
+}
+
+func (i *IncogClient) PostAndReceiveInterfaceWithContext(ctx context.Context, method string, params interface{}) (interface{}, []byte, error) {
+	body, err := i.PostWithContext(ctx, method, params)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "post")
 	}
@@ -34,65 +46,58 @@ func (i *IncogClient) PostAndReceiveInterface(method string, params interface{})
 	return v, body, nil
 }
 
-func (i *IncogClient) Post(method string, params interface{}) ([]byte, error) {
-	args := i.buildParameter(method, params)
-
-	data, err := json.Marshal(args)
+// CallWithContext sends req and decodes the Result of the answer into result, see DecodeResponse
+func (i *IncogClient) CallWithContext(ctx context.Context, req Request, result interface{}) error {
+	body, err := i.PostWithContext(ctx, req.Method(), req.Params())
 	if err != nil {
-		return nil, errors.Wrap(err, "json.Marshal")
+		return err
 	}
 
-	//fmt.Println("chain" , b.config.Incognito.ChainEndpoint)
-	if strings.Contains(i.ChainEndpoint, "https") {
-		//http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		i.Client.Transport = tr
-	}
+	return DecodeResponse(req.Method(), body, result)
+}
 
-	req, err := http.NewRequest(http.MethodPost, i.ChainEndpoint, bytes.NewReader(data))
-	if err != nil {
-		return nil, errors.Wrap(err, "http.NewRequest")
+// DecodeResponse unmarshals the Result of the body answered to method into result.
+// The error of the node, a null Result (ErrNoResult) and a Result not matching result
+// are all returned as errors naming method, so a change of the node schema never goes unnoticed.
+func DecodeResponse(method string, body []byte, result interface{}) error {
+	var resp struct {
+		rpcclient.RPCBaseRes
+		Result json.RawMessage `json:"Result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return errors.Wrapf(err, "decode %s response", method)
+	}
+	if resp.RPCError != nil {
+		return errors.Wrap(resp.RPCError, method)
+	}
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return errors.Wrap(ErrNoResult, method)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return errors.Wrapf(err, "decode %s result", method)
 	}
-	req.Close = true
 
-	req.Header.Set("Content-Type", "application/json")
-	//req.Header.Set("Authorization", i.BearerToken)
+	return nil
+}
 
-	// if b.config.Env == "localhost" {
-	// 	db, _ := httputil.DumpRequest(req, true)
-	// 	b.logger.With(zap.String("request", string(db))).Debug("post")
-	// 	fmt.Printf("string(db) = %+v\n", string(db))
-	// 	fmt.Printf("Call %+v\n", args)
-	// }
+func (i *IncogClient) Post(method string, params interface{}) ([]byte, error) {
+	return i.PostWithContext(context.Background(), method, params)
+}
 
-	resp, err := i.Client.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "b.c.Do: %q", req.URL.String())
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	// if b.config.Env == "localhost" {
-	// 	fmt.Println("________________________________________________")
-	// 	fmt.Println("Response from BlockChain:", string(body))
-	// 	fmt.Println("________________________________________________")
-	// }
+func (i *IncogClient) PostWithContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	body, err := i.transport().Call(ctx, method, params)
 	if err != nil {
-		return nil, errors.Wrap(err, "ioutil.ReadAll")
+		return nil, errors.Wrapf(err, "transport.Call: %q", method)
 	}
 
 	return body, nil
 }
 
-func (i *IncogClient) buildParameter(method string, params interface{}) *Parameter {
-	payload := &Parameter{
-		JsonRpc: "1.0",
-		Method:  method,
-		Params:  params,
-		Id:      1,
+// transport returns the injected Transport, or posts straight to ChainEndpoint with Client
+func (i *IncogClient) transport() rpcclient.Transport {
+	if i.Transport != nil {
+		return i.Transport
 	}
 
-	return payload
+	return rpcclient.NewHTTPTransport(i.Client, i.ChainEndpoint)
 }
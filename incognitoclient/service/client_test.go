@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type bodyTransport string
+
+func (b bodyTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	return []byte(b), nil
+}
+
+func TestDecodeResponse(t *testing.T) {
+	var txID entity.TxIDResult
+	err := DecodeResponse("sendtransaction", []byte(`{"Id":1,"Result":{"TxID":"abc","ShardID":3},"Error":null}`), &txID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.TxIDResult{TxID: "abc", ShardID: 3}, txID)
+
+	err = DecodeResponse("sendtransaction", []byte(`{"Id":1,"Result":null,"Error":{"Code":-1001,"Message":"double spend"}}`), &txID)
+	assert.EqualError(t, err, "sendtransaction: rpc error -1001: double spend")
+	assert.True(t, errors.Is(err, rpcclient.ErrDoubleSpend))
+
+	err = DecodeResponse("getblockcount", []byte(`{"Id":1,"Result":null,"Error":null}`), new(uint64))
+	assert.Equal(t, ErrNoResult, errors.Cause(err))
+
+	err = DecodeResponse("getblockcount", []byte(`{"Id":1,"Result":"42","Error":null}`), new(uint64))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "decode getblockcount result")
+
+	err = DecodeResponse("getblockcount", []byte(`<html>bad gateway</html>`), new(uint64))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "decode getblockcount response")
+}
+
+func TestCallWithContext(t *testing.T) {
+	inc := &IncogClient{Transport: bodyTransport(`{"Id":1,"Result":{"EstimateFeeCoinPerKb":10,"EstimateTxSizeInKb":2},"Error":null}`)}
+
+	var fee entity.EstimateFeeResult
+	err := inc.CallWithContext(context.Background(), entity.EstimateFeeReq{PrivateKey: "key"}, &fee)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.EstimateFeeResult{EstimateFeeCoinPerKb: 10, EstimateTxSizeInKb: 2}, fee)
+
+	inc.Transport = bodyTransport(`{"Id":1,"Result":{"EstimateFeeCoinPerKb":"10","EstimateTxSizeInKb":2},"Error":null}`)
+	err = inc.CallWithContext(context.Background(), entity.EstimateFeeReq{PrivateKey: "key"}, &fee)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "decode estimatefee result")
+}
@@ -0,0 +1,33 @@
+package incognitoclient
+
+import "github.com/incognitochain/go-incognito-sdk/rpcclient"
+
+// Events delivered by a SubscriptionClient
+type (
+	ShardBlockEvent  = rpcclient.ShardBlockEvent
+	BeaconBlockEvent = rpcclient.BeaconBlockEvent
+	TxEvent          = rpcclient.TxEvent
+)
+
+/*
+NewSubscriptionClient streams new blocks and pending transactions from the websocket server of a fullnode
+instead of polling it. The connection is opened on the first subscription; when it drops it is redialed
+with backoff and every active subscription is sent again, so channels keep delivering across reconnects.
+
+Example:
+
+	client := incognitoclient.NewSubscriptionClient(rpcclient.SubscriptionConfig{Endpoint: "ws://127.0.0.1:19334"})
+	defer client.Close()
+
+	blocks, sub, err := client.SubscribeShardBlocks(0)
+	if err != nil {
+		return err
+	}
+	for block := range blocks {
+		fmt.Println(block.Header.Height)
+	}
+	fmt.Println(sub.Err())
+*/
+func NewSubscriptionClient(config rpcclient.SubscriptionConfig) *rpcclient.SubscriptionClient {
+	return rpcclient.NewSubscriptionClient(config)
+}
@@ -0,0 +1,54 @@
+package incognitoclient
+
+import (
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/repository"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+)
+
+// Confirmation tracking, see rpcclient.TxTracker
+type (
+	TxTracker       = rpcclient.TxTracker
+	TxTrackerConfig = rpcclient.TxTrackerConfig
+	TxUpdate        = rpcclient.TxUpdate
+	TxStatus        = rpcclient.TxStatus
+)
+
+// Statuses of a tracked transaction
+const (
+	TxPending   = rpcclient.TxPending
+	TxInBlock   = rpcclient.TxInBlock
+	TxConfirmed = rpcclient.TxConfirmed
+	TxDropped   = rpcclient.TxDropped
+	TxRejected  = rpcclient.TxRejected
+)
+
+/*
+NewTxTracker returns a tracker watching transactions on the fullnode of public until they get
+config.Confirmations confirmations. Once a transaction is confirmed, dropped or rejected, the coins
+reserved for it by the sends of Wallet are released.
+
+Example:
+
+	tracker := incognitoclient.NewTxTracker(publicIncognito, incognitoclient.TxTrackerConfig{Confirmations: 2})
+	defer tracker.Close()
+
+	wallet := incognitoclient.NewWallet(publicIncognito, blockInfo, incognitoclient.WithTxTracker(tracker))
+	txID, err := wallet.SendToken(privateKey, receiver, PRVToken, amount, 0, "")
+	if err != nil {
+		return err
+	}
+	update, err := tracker.WaitForConfirmation(context.Background(), txID)
+*/
+func NewTxTracker(public *PublicIncognito, config TxTrackerConfig) *TxTracker {
+	return repository.NewTxTracker(public.incIntegration.RpcClient, config)
+}
+
+/*
+WithTxTracker makes a Wallet, Stake or PDex track the transactions it sends with tracker; the ones built by
+the SDK are sent again when the mempool drops them.
+*/
+func WithTxTracker(tracker *TxTracker) WalletOption {
+	return func(o *walletOptions) {
+		o.txTracker = tracker
+	}
+}
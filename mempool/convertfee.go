@@ -0,0 +1,58 @@
+package mempool
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+)
+
+// ErrNoPRVPoolPair is returned when a token has no pool pair with PRV to convert a PRV amount with
+var ErrNoPRVPoolPair = errors.New("no PRV pool pair of the token")
+
+// PDEPoolPair is a pDEX pool pair as listed in the PDEPoolPairs of getpdestate.
+type PDEPoolPair struct {
+	Token1IDStr     string
+	Token1PoolValue uint64
+	Token2IDStr     string
+	Token2PoolValue uint64
+}
+
+// prvPoolReserves returns the PRV and token reserves of the pool pair of tokenID with PRV among poolPairs.
+func prvPoolReserves(tokenID *common.Hash, poolPairs []*PDEPoolPair) (prvReserve uint64, tokenReserve uint64, err error) {
+	prv := common.PRVCoinID.String()
+	token := tokenID.String()
+	for _, pair := range poolPairs {
+		switch {
+		case pair.Token1IDStr == prv && pair.Token2IDStr == token:
+			prvReserve, tokenReserve = pair.Token1PoolValue, pair.Token2PoolValue
+		case pair.Token1IDStr == token && pair.Token2IDStr == prv:
+			prvReserve, tokenReserve = pair.Token2PoolValue, pair.Token1PoolValue
+		default:
+			continue
+		}
+		if prvReserve == 0 || tokenReserve == 0 {
+			return 0, 0, ErrNoPRVPoolPair
+		}
+		return prvReserve, tokenReserve, nil
+	}
+	return 0, 0, ErrNoPRVPoolPair
+}
+
+// ConvertNativeTokenToPrivacyToken returns what nativeTokenAmount PRV is worth in tokenID at the rate of the
+// pool pair of tokenID with PRV among poolPairs, rounded up so that a fee converted is never short.
+func ConvertNativeTokenToPrivacyToken(nativeTokenAmount uint64, tokenID *common.Hash, poolPairs []*PDEPoolPair) (uint64, error) {
+	prvReserve, tokenReserve, err := prvPoolReserves(tokenID, poolPairs)
+	if err != nil {
+		return 0, err
+	}
+
+	amount := new(big.Int).Mul(new(big.Int).SetUint64(nativeTokenAmount), new(big.Int).SetUint64(tokenReserve))
+	divisor := new(big.Int).SetUint64(prvReserve)
+	amount.Add(amount, new(big.Int).Sub(divisor, big.NewInt(1)))
+	amount.Div(amount, divisor)
+	if !amount.IsUint64() {
+		return 0, errors.New("converted amount overflows uint64")
+	}
+	return amount.Uint64(), nil
+}
@@ -0,0 +1,29 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertNativeTokenToPrivacyToken(t *testing.T) {
+	tokenID := common.Hash{0xaa}
+	poolPairs := []*PDEPoolPair{
+		{Token1IDStr: common.Hash{0xbb}.String(), Token1PoolValue: 10, Token2IDStr: tokenID.String(), Token2PoolValue: 10},
+		{Token1IDStr: common.PRVCoinID.String(), Token1PoolValue: 3000, Token2IDStr: tokenID.String(), Token2PoolValue: 1000},
+		{Token1IDStr: common.Hash{0xcc}.String(), Token1PoolValue: 0, Token2IDStr: common.PRVCoinID.String(), Token2PoolValue: 1000},
+	}
+
+	amount, err := ConvertNativeTokenToPrivacyToken(300, &tokenID, poolPairs)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), amount)
+	amount, err = ConvertNativeTokenToPrivacyToken(301, &tokenID, poolPairs)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(101), amount, "a converted amount is rounded up")
+
+	_, err = ConvertNativeTokenToPrivacyToken(300, &common.Hash{0xbb}, poolPairs)
+	assert.Equal(t, ErrNoPRVPoolPair, err)
+	_, err = ConvertNativeTokenToPrivacyToken(300, &common.Hash{0xcc}, poolPairs)
+	assert.Equal(t, ErrNoPRVPoolPair, err)
+}
@@ -5,19 +5,76 @@
 package mempool
 
 import (
-	"github.com/incognitochain/go-incognito-sdk/common"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
 )
 
 const (
 	// estimateFeeDepth is the maximum number of blocks before a transaction
 	// is confirmed that we want to track.
 	estimateFeeDepth = 200
+
+	// estimateFeeBinSize is the number of txs stored in each bin.
+	estimateFeeBinSize = 100
+
+	// estimateFeeMaxReplacements is the max number of replacements that
+	// can be made by the txs found in a given block.
+	estimateFeeMaxReplacements = 10
+
+	// unminedHeight is the mined height of a transaction not mined yet.
+	unminedHeight = 0
+
+	// DefaultEstimateFeeMaxRollback is the default number of rollbacks
+	// allowed by the fee estimator for orphaned blocks.
+	DefaultEstimateFeeMaxRollback = 2
+
+	// DefaultEstimateFeeMinRegisteredBlocks is the default minimum
+	// number of blocks which must be observed by the fee estimator before
+	// it will provide fee estimations.
+	DefaultEstimateFeeMinRegisteredBlocks = 3
+)
+
+var (
+	// ErrNotEnoughData is returned by EstimateFee when too few blocks or
+	// transactions were observed to answer.
+	ErrNotEnoughData = errors.New("not enough blocks or transactions observed to estimate the fee")
 )
 
 // CoinPerKilobyte is number with units of coins per kilobyte.
 type CoinPerKilobyte uint64
 
+// NewCoinPerKilobyte returns the fee rate of a transaction paying fee
+// for sizeInKb kilobytes.
+func NewCoinPerKilobyte(fee uint64, sizeInKb uint64) CoinPerKilobyte {
+	if sizeInKb == 0 {
+		sizeInKb = 1
+	}
+	return CoinPerKilobyte(fee / sizeInKb)
+}
+
+// TxDesc is what the fee estimator needs to know of a transaction.
+type TxDesc struct {
+	// A transaction hash.
+	Hash common.Hash
+
+	// The PRV fee per kilobyte of the transaction in coins.
+	FeePerKb CoinPerKilobyte
+
+	// The token fee per kilobyte of the transaction in coins, by token.
+	TokenFeePerKb map[common.Hash]CoinPerKilobyte
+}
+
+// Block is a shard block and the transactions it mined.
+type Block struct {
+	Hash   common.Hash
+	Height uint64
+	Txs    []*TxDesc
+}
 
 // observedTransaction represents an observed transaction and some
 // additional data required for the fee estimation algorithm.
@@ -75,6 +132,9 @@ type FeeEstimator struct {
 	// The cached estimates.
 	cached []CoinPerKilobyte
 
+	// The cached estimates of the fees paid in tokens.
+	cachedForToken map[common.Hash][]CoinPerKilobyte
+
 	// Transactions that have been removed from the bins. This allows us to
 	// revert in case of an orphaned block.
 	dropped []*registeredBlock
@@ -83,9 +143,426 @@ type FeeEstimator struct {
 	limitFee uint64
 }
 
+// NewFeeEstimator creates a FeeEstimator for which at most maxRollback blocks
+// can be unregistered and which returns an error unless minRegisteredBlocks
+// have been registered with it. The PRV estimates are never below limitFee.
+func NewFeeEstimator(maxRollback, minRegisteredBlocks uint32, limitFee uint64) *FeeEstimator {
+	return &FeeEstimator{
+		maxRollback:         maxRollback,
+		minRegisteredBlocks: minRegisteredBlocks,
+		lastKnownHeight:     unminedHeight,
+		binSize:             estimateFeeBinSize,
+		maxReplacements:     estimateFeeMaxReplacements,
+		observed:            make(map[common.Hash]*observedTransaction),
+		dropped:             make([]*registeredBlock, 0, maxRollback),
+		limitFee:            limitFee,
+	}
+}
+
+// ObserveTransaction is called when a new transaction is observed in the mempool.
+func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// If we haven't seen a block yet we don't know when this one arrived,
+	// so we ignore it.
+	if ef.lastKnownHeight == unminedHeight {
+		return
+	}
+
+	ef.observe(t, ef.lastKnownHeight)
+}
+
+// observe records t as observed at height, ef.mtx must be held.
+func (ef *FeeEstimator) observe(t *TxDesc, height uint64) *observedTransaction {
+	if o, ok := ef.observed[t.Hash]; ok {
+		return o
+	}
+	o := &observedTransaction{
+		hash:            t.Hash,
+		feeRate:         t.FeePerKb,
+		feeRateForToken: make(map[common.Hash]CoinPerKilobyte, len(t.TokenFeePerKb)),
+		observed:        height,
+		mined:           unminedHeight,
+	}
+	for tokenID, feeRate := range t.TokenFeePerKb {
+		o.feeRateForToken[tokenID] = feeRate
+	}
+	ef.observed[t.Hash] = o
+	return o
+}
+
+// RegisterBlock informs the fee estimator of a new block to take into account.
+// The transactions of the block that were not observed in the mempool are
+// taken as observed in the previous block, so that a fee estimator fed with
+// blocks only answers too.
+func (ef *FeeEstimator) RegisterBlock(block *Block) error {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// The previous sorted list is invalid, so delete it.
+	ef.cached = nil
+	ef.cachedForToken = nil
+
+	height := block.Height
+	if height != ef.lastKnownHeight+1 && ef.lastKnownHeight != unminedHeight {
+		return fmt.Errorf("intermediate block not recorded; current height is %d; new height is %d",
+			ef.lastKnownHeight, height)
+	}
+
+	// Update the last known height.
+	ef.lastKnownHeight = height
+	ef.numBlocksRegistered++
+
+	// Randomly order txs in block.
+	transactions := make(map[*TxDesc]struct{})
+	for _, t := range block.Txs {
+		transactions[t] = struct{}{}
+	}
+
+	// Count the number of replacements we make per bin so that we don't
+	// replace too many.
+	var replacementCounts [estimateFeeDepth]int
+
+	// Keep track of which txs were dropped in case of an orphan block.
+	dropped := &registeredBlock{
+		hash:         block.Hash,
+		transactions: make([]*observedTransaction, 0, 100),
+	}
+
+	// Go through the txs in the block.
+	for t := range transactions {
+		o, ok := ef.observed[t.Hash]
+		if !ok {
+			o = ef.observe(t, height-1)
+		}
+
+		// This shouldn't happen if the fee estimator works correctly,
+		// but return an error if it does.
+		if o.mined != unminedHeight {
+			return fmt.Errorf("transaction %s has already been mined", t.Hash.String())
+		}
+
+		// Put the observed tx in the appropriate bin.
+		blocksToConfirm := height - o.observed - 1
+
+		// This shouldn't happen but check just in case to avoid
+		// an out-of-bounds array index later.
+		if blocksToConfirm >= estimateFeeDepth {
+			continue
+		}
+
+		// Make sure we do not replace too many transactions per min.
+		if replacementCounts[blocksToConfirm] == int(ef.maxReplacements) {
+			continue
+		}
+
+		o.mined = height
+
+		replacementCounts[blocksToConfirm]++
+
+		bin := ef.bin[blocksToConfirm]
+
+		// Remove a random element and replace it with this new tx.
+		if len(bin) == int(ef.binSize) {
+			// Don't drop transactions we have just added from this same block.
+			l := int(ef.binSize) - replacementCounts[blocksToConfirm]
+			drop := rand.Intn(l)
+			dropped.transactions = append(dropped.transactions, bin[drop])
+
+			bin[drop] = bin[l-1]
+			bin[l-1] = o
+		} else {
+			bin = append(bin, o)
+		}
+		ef.bin[blocksToConfirm] = bin
+	}
+
+	// Go through the mempool for txs that have been in too long.
+	for hash, o := range ef.observed {
+		if o.mined == unminedHeight && height-o.observed >= estimateFeeDepth {
+			delete(ef.observed, hash)
+		}
+	}
+
+	// Add dropped list to history.
+	if ef.maxRollback == 0 {
+		return nil
+	}
+
+	if uint32(len(ef.dropped)) == ef.maxRollback {
+		ef.dropped = append(ef.dropped[1:], dropped)
+	} else {
+		ef.dropped = append(ef.dropped, dropped)
+	}
+
+	return nil
+}
+
+// LastKnownHeight returns the height of the last block which was registered.
+func (ef *FeeEstimator) LastKnownHeight() uint64 {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	return ef.lastKnownHeight
+}
+
+// Rollback unregisters a recently registered block from the FeeEstimator.
+// This can be used to reverse the effect of an orphaned block on the fee
+// estimator. The maximum number of rollbacks allowed is given by
+// maxRollbacks.
+//
+// Note: not everything can be rolled back because some transactions are
+// deleted if they have been observed too long ago. That means the result
+// of Rollback won't always be exactly the same as if the last block had not
+// happened, but it should be close enough.
+func (ef *FeeEstimator) Rollback(hash *common.Hash) error {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// Find this block in the stack of recent registered blocks.
+	var n int
+	for n = 1; n <= len(ef.dropped); n++ {
+		if ef.dropped[len(ef.dropped)-n].hash.IsEqual(hash) {
+			break
+		}
+	}
+
+	if n > len(ef.dropped) {
+		return errors.New("no such block was recently registered")
+	}
+
+	for i := 0; i < n; i++ {
+		ef.rollback()
+	}
+
+	return nil
+}
+
+// rollback rolls back the effect of the last block in the stack
+// of registered blocks.
+func (ef *FeeEstimator) rollback() {
+	// The previous sorted list is invalid, so delete it.
+	ef.cached = nil
+	ef.cachedForToken = nil
+
+	// pop the last list of dropped txs from the stack.
+	last := len(ef.dropped) - 1
+	if last == -1 {
+		// Cannot really happen because the exported calling function
+		// only rolls back a block already known to be in the list
+		// of dropped transactions.
+		return
+	}
+
+	dropped := ef.dropped[last]
+
+	// where we are in each bin as we replace txs?
+	var replacementCounters [estimateFeeDepth]int
+
+	// Go through the txs in the dropped block.
+	for _, o := range dropped.transactions {
+		// Which bin was this tx in?
+		blocksToConfirm := o.mined - o.observed - 1
+
+		bin := ef.bin[blocksToConfirm]
+
+		var counter = replacementCounters[blocksToConfirm]
+
+		// Continue to go through that bin where we left off.
+		for {
+			if counter >= len(bin) {
+				// Panic, as we have entered an unrecoverable invalid state.
+				panic(errors.New("illegal state: cannot rollback dropped transaction"))
+			}
+
+			prev := bin[counter]
+
+			if prev.mined == ef.lastKnownHeight {
+				prev.mined = unminedHeight
+
+				bin[counter] = o
+
+				counter++
+				break
+			}
+
+			counter++
+		}
+
+		replacementCounters[blocksToConfirm] = counter
+	}
+
+	// Continue going through bins to find other txs to remove
+	// which did not replace any other when they were entered.
+	for i, j := range replacementCounters {
+		for {
+			l := len(ef.bin[i])
+			if j >= l {
+				break
+			}
+
+			prev := ef.bin[i][j]
+
+			if prev.mined == ef.lastKnownHeight {
+				prev.mined = unminedHeight
+
+				newBin := append(ef.bin[i][0:j], ef.bin[i][j+1:l]...)
+				ef.bin[i] = newBin
+
+				continue
+			}
+
+			j++
+		}
+	}
+
+	ef.dropped = ef.dropped[0:last]
+
+	// The number of blocks the fee estimator has seen is decrimented.
+	ef.numBlocksRegistered--
+	ef.lastKnownHeight--
+}
+
+// estimateFeeSet is a set of txs that can that is sorted
+// by the fee per kb rate.
+type estimateFeeSet struct {
+	feeRate []CoinPerKilobyte
+	bin     [estimateFeeDepth]uint32
+}
+
+func (b *estimateFeeSet) Len() int { return len(b.feeRate) }
+
+func (b *estimateFeeSet) Less(i, j int) bool {
+	return b.feeRate[i] > b.feeRate[j]
+}
+
+func (b *estimateFeeSet) Swap(i, j int) {
+	b.feeRate[i], b.feeRate[j] = b.feeRate[j], b.feeRate[i]
+}
+
+// estimateFee returns the estimated fee for a transaction
+// to confirm in confirmations blocks from now, given
+// the data set we have collected.
+func (b *estimateFeeSet) estimateFee(confirmations int) CoinPerKilobyte {
+	if confirmations <= 0 || confirmations > estimateFeeDepth || len(b.feeRate) == 0 {
+		return 0
+	}
+
+	var min, max int = 0, 0
+	for i := 0; i < confirmations-1; i++ {
+		min += int(b.bin[i])
+	}
+
+	max = min + int(b.bin[confirmations-1]) - 1
+	if max < min {
+		max = min
+	}
+	feeIndex := (min + max) / 2
+	if feeIndex >= len(b.feeRate) {
+		feeIndex = len(b.feeRate) - 1
+	}
+
+	return b.feeRate[feeIndex]
+}
+
+// newEstimateFeeSet creates a temporary data structure that
+// can be used to find all fee estimates, of PRV when tokenID is nil,
+// of tokenID otherwise.
+func (ef *FeeEstimator) newEstimateFeeSet(tokenID *common.Hash) *estimateFeeSet {
+	set := new(estimateFeeSet)
+
+	for i, b := range ef.bin {
+		for _, o := range b {
+			if tokenID == nil {
+				set.feeRate = append(set.feeRate, o.feeRate)
+			} else if feeRate, ok := o.feeRateForToken[*tokenID]; ok {
+				set.feeRate = append(set.feeRate, feeRate)
+			} else {
+				continue
+			}
+			set.bin[i]++
+		}
+	}
+
+	sort.Sort(set)
+
+	return set
+}
+
+// estimates returns the set of all fee estimates from 1 to estimateFeeDepth
+// confirmations from now, nil when no transaction of the bins pays a fee
+// of tokenID.
+func (ef *FeeEstimator) estimates(tokenID *common.Hash) []CoinPerKilobyte {
+	set := ef.newEstimateFeeSet(tokenID)
+	if len(set.feeRate) == 0 {
+		return nil
+	}
+
+	estimates := make([]CoinPerKilobyte, estimateFeeDepth)
+	for i := 0; i < estimateFeeDepth; i++ {
+		estimates[i] = set.estimateFee(i + 1)
+	}
+
+	return estimates
+}
+
+// EstimateFee estimates the fee per kilobyte to have a tx confirmed a given
+// number of blocks from now, in PRV when tokenID is nil or the PRV id, in
+// tokenID otherwise. It returns ErrNotEnoughData when too few blocks were
+// registered or no registered tx paid a fee in tokenID.
+func (ef *FeeEstimator) EstimateFee(numBlocks uint64, tokenID *common.Hash) (CoinPerKilobyte, error) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// If the number of registered blocks is below the minimum, return
+	// an error.
+	if ef.numBlocksRegistered < ef.minRegisteredBlocks {
+		return 0, ErrNotEnoughData
+	}
+
+	if numBlocks == 0 {
+		return 0, errors.New("cannot confirm transaction in zero blocks")
+	}
+
+	if numBlocks > estimateFeeDepth {
+		return 0, fmt.Errorf("can only estimate fees for up to %d blocks from now", estimateFeeDepth)
+	}
+
+	if tokenID != nil && tokenID.IsEqual(&common.PRVCoinID) {
+		tokenID = nil
+	}
+
+	// If there are no cached results, generate them.
+	var estimates []CoinPerKilobyte
+	if tokenID == nil {
+		if ef.cached == nil {
+			ef.cached = ef.estimates(nil)
+		}
+		estimates = ef.cached
+	} else {
+		if ef.cachedForToken == nil {
+			ef.cachedForToken = make(map[common.Hash][]CoinPerKilobyte)
+		}
+		if _, ok := ef.cachedForToken[*tokenID]; !ok {
+			ef.cachedForToken[*tokenID] = ef.estimates(tokenID)
+		}
+		estimates = ef.cachedForToken[*tokenID]
+	}
+	if estimates == nil {
+		return 0, ErrNotEnoughData
+	}
+
+	feeRate := estimates[int(numBlocks)-1]
+	if tokenID == nil && uint64(feeRate) < ef.limitFee {
+		feeRate = CoinPerKilobyte(ef.limitFee)
+	}
+	return feeRate, nil
+}
+
 // returns the limit fee of tokenID
 // if there is no exchange rate between native token and privacy token, return limit fee of native token
-func (ef FeeEstimator) GetLimitFeeForNativeToken() uint64 {
+func (ef *FeeEstimator) GetLimitFeeForNativeToken() uint64 {
 	limitFee := ef.limitFee
 	//isFeePToken := false
 
@@ -98,4 +575,4 @@ func (ef FeeEstimator) GetLimitFeeForNativeToken() uint64 {
 	//}
 
 	return limitFee
-}
\ No newline at end of file
+}
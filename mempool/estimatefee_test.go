@@ -0,0 +1,75 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTxDesc(n byte, feePerKb CoinPerKilobyte) *TxDesc {
+	return &TxDesc{Hash: common.Hash{n}, FeePerKb: feePerKb}
+}
+
+func TestFeeEstimator(t *testing.T) {
+	ef := NewFeeEstimator(DefaultEstimateFeeMaxRollback, DefaultEstimateFeeMinRegisteredBlocks, 5)
+	tokenID := common.Hash{0xaa}
+
+	// txs observed before the first block are ignored
+	ef.ObserveTransaction(newTxDesc(1, 1000))
+	assert.NoError(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xb1}, Height: 10}))
+	_, err := ef.EstimateFee(1, nil)
+	assert.Equal(t, ErrNotEnoughData, err)
+
+	// fast txs pay more than slow ones
+	ef.ObserveTransaction(newTxDesc(2, 100))
+	ef.ObserveTransaction(newTxDesc(3, 20))
+	ef.ObserveTransaction(&TxDesc{Hash: common.Hash{4}, FeePerKb: 2, TokenFeePerKb: map[common.Hash]CoinPerKilobyte{tokenID: 300}})
+	assert.NoError(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xb2}, Height: 11, Txs: []*TxDesc{newTxDesc(2, 100)}}))
+	assert.Error(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xb4}, Height: 13}))
+	assert.NoError(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xb3}, Height: 12, Txs: []*TxDesc{newTxDesc(3, 20), newTxDesc(4, 2)}}))
+
+	fee, err := ef.EstimateFee(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(100), fee)
+	fee, err = ef.EstimateFee(2, &common.PRVCoinID)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(20), fee)
+	fee, err = ef.EstimateFee(3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(5), fee, "an estimate is never below the limit fee")
+	fee, err = ef.EstimateFee(2, &tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(300), fee)
+	_, err = ef.EstimateFee(1, &common.Hash{0xbb})
+	assert.Equal(t, ErrNotEnoughData, err)
+	_, err = ef.EstimateFee(0, nil)
+	assert.Error(t, err)
+	_, err = ef.EstimateFee(estimateFeeDepth+1, nil)
+	assert.Error(t, err)
+
+	// the txs of a block that were not observed count as confirmed in one block
+	assert.NoError(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xb4}, Height: 13, Txs: []*TxDesc{newTxDesc(5, 400), newTxDesc(6, 300)}}))
+	fee, err = ef.EstimateFee(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(300), fee)
+
+	// rolling back the last two blocks leaves too few blocks registered to answer
+	assert.Error(t, ef.Rollback(&common.Hash{0xff}))
+	assert.NoError(t, ef.Rollback(&common.Hash{0xb3}))
+	assert.Equal(t, uint64(11), ef.LastKnownHeight())
+	fee, err = ef.EstimateFee(1, nil)
+	assert.Equal(t, ErrNotEnoughData, err)
+	assert.NoError(t, ef.RegisterBlock(&Block{Hash: common.Hash{0xc3}, Height: 12, Txs: []*TxDesc{newTxDesc(3, 20)}}))
+	fee, err = ef.EstimateFee(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(100), fee)
+	fee, err = ef.EstimateFee(2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CoinPerKilobyte(20), fee)
+}
+
+func TestNewCoinPerKilobyte(t *testing.T) {
+	assert.Equal(t, CoinPerKilobyte(50), NewCoinPerKilobyte(100, 2))
+	assert.Equal(t, CoinPerKilobyte(100), NewCoinPerKilobyte(100, 0))
+}
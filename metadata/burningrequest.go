@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"encoding/hex"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/pkg/errors"
 	"strconv"
 )
 
@@ -54,3 +56,23 @@ func (bReq BurningRequest) Hash() *common.Hash {
 func (bReq *BurningRequest) CalculateSize() uint64 {
 	return calculateSize(bReq)
 }
+
+// Validate checks the burner address, the burned amount, the token id and that the remote address is hex
+func (bReq *BurningRequest) Validate() error {
+	if err := checkType(bReq.Type, bReq); err != nil {
+		return err
+	}
+	if err := checkPaymentAddress(bReq.BurnerAddress); err != nil {
+		return errors.New("invalid burner address")
+	}
+	if bReq.BurningAmount == 0 {
+		return errors.New("burning amount must be positive")
+	}
+	if err := checkTokenID(bReq.TokenID, false); err != nil {
+		return err
+	}
+	if _, err := hex.DecodeString(bReq.RemoteAddress); err != nil || len(bReq.RemoteAddress) == 0 {
+		return errors.Errorf("invalid remote address %v", bReq.RemoteAddress)
+	}
+	return nil
+}
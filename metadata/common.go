@@ -1,6 +1,13 @@
 package metadata
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
+)
 
 func calculateSize(meta Metadata) uint64 {
 	metaBytes, err := json.Marshal(meta)
@@ -8,4 +15,32 @@ func calculateSize(meta Metadata) uint64 {
 		return 0
 	}
 	return uint64(len(metaBytes))
-}
\ No newline at end of file
+}
+
+// checkPaymentAddress returns an error when addr does not have the key sizes of a payment address
+func checkPaymentAddress(addr privacy.PaymentAddress) error {
+	if len(addr.Pk) != common.PublicKeySize || len(addr.Tk) != common.TransmissionKeySize {
+		return errors.New("invalid payment address")
+	}
+	return nil
+}
+
+// checkPaymentAddressStr returns an error when paymentAddress is not a base58 check encoded payment address
+func checkPaymentAddressStr(paymentAddress string) error {
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil {
+		return errors.Wrapf(err, "invalid payment address %v", paymentAddress)
+	}
+	return checkPaymentAddress(keyWallet.KeySet.PaymentAddress)
+}
+
+// checkTokenID returns an error when tokenID is the zero hash or, unless allowPRV, the PRV id
+func checkTokenID(tokenID common.Hash, allowPRV bool) error {
+	if tokenID.IsEqual(&common.Hash{}) {
+		return errors.New("missing token id")
+	}
+	if !allowPRV && tokenID.IsEqual(&common.PRVCoinID) {
+		return errors.New("token id must not be PRV")
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"errors"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
 )
@@ -50,3 +51,17 @@ func (cReq ContractingRequest) Hash() *common.Hash {
 func (cReq *ContractingRequest) CalculateSize() uint64 {
 	return calculateSize(cReq)
 }
+
+// Validate checks the burner address, the burned amount and the token id
+func (cReq *ContractingRequest) Validate() error {
+	if err := checkType(cReq.Type, cReq); err != nil {
+		return err
+	}
+	if err := checkPaymentAddress(cReq.BurnerAddress); err != nil {
+		return errors.New("invalid burner address")
+	}
+	if cReq.BurnedAmount == 0 {
+		return errors.New("burned amount must be positive")
+	}
+	return checkTokenID(cReq.TokenID, false)
+}
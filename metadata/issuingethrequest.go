@@ -78,3 +78,17 @@ func (iReq IssuingETHRequest) Hash() *common.Hash {
 func (iReq *IssuingETHRequest) CalculateSize() uint64 {
 	return calculateSize(iReq)
 }
+
+// Validate checks the block hash, the proof and the token id
+func (iReq *IssuingETHRequest) Validate() error {
+	if err := checkType(iReq.Type, iReq); err != nil {
+		return err
+	}
+	if iReq.BlockHash == (rCommon.Hash{}) {
+		return errors.New("missing block hash")
+	}
+	if len(iReq.ProofStrs) == 0 {
+		return errors.New("missing proof")
+	}
+	return checkTokenID(iReq.IncTokenID, false)
+}
@@ -101,3 +101,17 @@ func (iReq IssuingRequest) Hash() *common.Hash {
 func (iReq *IssuingRequest) CalculateSize() uint64 {
 	return calculateSize(iReq)
 }
+
+// Validate checks the receiver address, the deposited amount and the token id
+func (iReq *IssuingRequest) Validate() error {
+	if err := checkType(iReq.Type, iReq); err != nil {
+		return err
+	}
+	if err := checkPaymentAddress(iReq.ReceiverAddress); err != nil {
+		return errors.New("invalid receiver address")
+	}
+	if iReq.DepositedAmount == 0 {
+		return errors.New("deposited amount must be positive")
+	}
+	return checkTokenID(iReq.TokenID, false)
+}
@@ -10,4 +10,6 @@ type Metadata interface {
 	GetType() int
 	Hash() *common.Hash
 	CalculateSize() uint64
+	// Validate checks the metadata against the rules of the chain, before the tx carrying it is sent
+	Validate() error
 }
\ No newline at end of file
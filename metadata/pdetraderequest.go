@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/pkg/errors"
 	"strconv"
 )
 
@@ -80,3 +81,25 @@ func (pc PDETradeRequest) Hash() *common.Hash {
 func (pc *PDETradeRequest) CalculateSize() uint64 {
 	return calculateSize(pc)
 }
+
+// Validate checks the token ids, the amounts and the trader address
+func (pc *PDETradeRequest) Validate() error {
+	if err := checkType(pc.Type, pc); err != nil {
+		return err
+	}
+	tokenIDToBuy, err := common.Hash{}.NewHashFromStr(pc.TokenIDToBuyStr)
+	if err != nil {
+		return errors.Wrap(err, "invalid token id to buy")
+	}
+	tokenIDToSell, err := common.Hash{}.NewHashFromStr(pc.TokenIDToSellStr)
+	if err != nil {
+		return errors.Wrap(err, "invalid token id to sell")
+	}
+	if tokenIDToBuy.IsEqual(tokenIDToSell) {
+		return errors.New("token to buy and token to sell must differ")
+	}
+	if pc.SellAmount == 0 {
+		return errors.New("sell amount must be positive")
+	}
+	return checkPaymentAddressStr(pc.TraderAddressStr)
+}
@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownMetadataType is returned by ParseMetadata for a type with no registered constructor
+var ErrUnknownMetadataType = errors.New("unknown metadata type")
+
+// registry maps a metadata type to a constructor of the empty struct its JSON is decoded into
+var registry = map[int]func() Metadata{
+	IssuingRequestMeta:               func() Metadata { return &IssuingRequest{} },
+	ContractingRequestMeta:           func() Metadata { return &ContractingRequest{} },
+	IssuingETHRequestMeta:            func() Metadata { return &IssuingETHRequest{} },
+	WithDrawRewardRequestMeta:        func() Metadata { return &WithDrawRewardRequest{} },
+	ShardStakingMeta:                 func() Metadata { return &StakingMetadata{} },
+	BeaconStakingMeta:                func() Metadata { return &StakingMetadata{} },
+	StopAutoStakingMeta:              func() Metadata { return &StopAutoStakingMetadata{} },
+	PDETradeRequestMeta:              func() Metadata { return &PDETradeRequest{} },
+	BurningForDepositToSCRequestMeta: func() Metadata { return &BurningRequest{} },
+}
+
+/*
+RegisterMetadata registers newMetadata as the constructor of metaType, replacing the one registered before.
+The portal and relaying types have no struct in the SDK, register one to parse or send them;
+a tx carrying one otherwise decodes with a RawMetadata.
+Call it from an init function, the registry is not safe for concurrent use.
+
+Example:
+
+	metadata.RegisterMetadata(metadata.PortalCustodianDepositMeta, func() metadata.Metadata {
+		return &PortalCustodianDeposit{}
+	})
+*/
+func RegisterMetadata(metaType int, newMetadata func() Metadata) {
+	registry[metaType] = newMetadata
+}
+
+/*
+ParseMetadata decodes the JSON of a metadata, as found in the Metadata field of a tx, into the struct
+registered for its Type. It returns nil for an empty or null raw, and ErrUnknownMetadataType for a type
+with no registered struct.
+
+Example:
+
+	meta, err := metadata.ParseMetadata(raw)
+	if err != nil {
+		return err
+	}
+	if trade, ok := meta.(*metadata.PDETradeRequest); ok {
+		fmt.Println(trade.SellAmount)
+	}
+*/
+func ParseMetadata(raw json.RawMessage) (Metadata, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	var base MetadataBase
+	err := json.Unmarshal(raw, &base)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid metadata")
+	}
+	newMetadata, ok := registry[base.Type]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownMetadataType, "type %d", base.Type)
+	}
+	meta := newMetadata()
+	err = json.Unmarshal(raw, meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid metadata of type %d", base.Type)
+	}
+	return meta, nil
+}
+
+// RawMetadata is a metadata of a type with no registered struct, kept as the JSON found in the tx so that the tx
+// still decodes. Hash is the one of MetadataBase, not the one the node computes for the type, and Validate fails,
+// so a tx carrying it can be read but not built.
+type RawMetadata struct {
+	MetadataBase
+	Raw json.RawMessage
+}
+
+// NewRawMetadata keeps raw, the JSON of a metadata, as a RawMetadata
+func NewRawMetadata(raw json.RawMessage) (*RawMetadata, error) {
+	meta := &RawMetadata{Raw: raw}
+	err := json.Unmarshal(raw, &meta.MetadataBase)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid metadata")
+	}
+	return meta, nil
+}
+
+// MarshalJSON returns the JSON the metadata was read from
+func (meta RawMetadata) MarshalJSON() ([]byte, error) {
+	return meta.Raw, nil
+}
+
+// Validate fails, the SDK does not know the rules of the type of meta
+func (meta RawMetadata) Validate() error {
+	return errors.Wrapf(ErrUnknownMetadataType, "type %d", meta.Type)
+}
+
+// checkType returns an error when metaType is not registered to the struct of meta
+func checkType(metaType int, meta Metadata) error {
+	newMetadata, ok := registry[metaType]
+	if !ok {
+		return errors.Wrapf(ErrUnknownMetadataType, "type %d", metaType)
+	}
+	if reflect.TypeOf(newMetadata()) != reflect.TypeOf(meta) {
+		return errors.Errorf("type %d is not a %T", metaType, meta)
+	}
+	return nil
+}
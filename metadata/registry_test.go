@@ -0,0 +1,127 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/incognitokey"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAccount returns the key of a new account and its payment address
+func newAccount(t *testing.T) (*wallet.KeyWallet, string) {
+	account, err := wallet.CreateNewAccount()
+	assert.NoError(t, err)
+	return &account.Key, account.Key.Base58CheckSerialize(wallet.PaymentAddressType)
+}
+
+// newCommitteeKey returns the committee key, base58 encoded, of a validator of the account of keyWallet
+func newCommitteeKey(t *testing.T, keyWallet *wallet.KeyWallet) string {
+	committeeKey, err := incognitokey.NewCommitteeKeyFromSeed(common.HashB([]byte("seed")), keyWallet.KeySet.PaymentAddress.Pk)
+	assert.NoError(t, err)
+	committeeKeyBytes, err := committeeKey.Bytes()
+	assert.NoError(t, err)
+	return base58.Base58Check{}.Encode(committeeKeyBytes, common.ZeroByte)
+}
+
+func TestParseMetadata(t *testing.T) {
+	keyWallet, paymentAddress := newAccount(t)
+	committeeKey := newCommitteeKey(t, keyWallet)
+	tokenID := common.Hash{1, 2, 3}
+
+	trade, _ := NewPDETradeRequest(tokenID.String(), common.PRVIDStr, 100, 90, 1, paymentAddress, PDETradeRequestMeta)
+	staking, err := NewStakingMetadata(BeaconStakingMeta, paymentAddress, paymentAddress, 1750000000000, committeeKey, true)
+	assert.NoError(t, err)
+	stopStaking, err := NewStopAutoStakingMetadata(StopAutoStakingMeta, committeeKey)
+	assert.NoError(t, err)
+	burning, _ := NewBurningRequest(keyWallet.KeySet.PaymentAddress, 100, tokenID, "pETH", "d5808ba261c91d640a2d4149e8cdb3fd4512efe4", BurningForDepositToSCRequestMeta)
+	contracting, _ := NewContractingRequest(keyWallet.KeySet.PaymentAddress, 100, tokenID, ContractingRequestMeta)
+	issuing, _ := NewIssuingRequest(keyWallet.KeySet.PaymentAddress, 100, tokenID, "pETH", IssuingRequestMeta)
+	withdraw := &WithDrawRewardRequest{
+		PaymentAddress: keyWallet.KeySet.PaymentAddress,
+		MetadataBase:   MetadataBase{Type: WithDrawRewardRequestMeta},
+		TokenID:        common.PRVCoinID,
+		Version:        1,
+	}
+
+	for _, meta := range []Metadata{trade, staking, stopStaking, burning, contracting, issuing, withdraw} {
+		assert.NoError(t, meta.Validate(), "%T", meta)
+
+		raw, err := json.Marshal(meta)
+		assert.NoError(t, err)
+		parsed, err := ParseMetadata(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, meta, parsed)
+	}
+
+	meta, err := ParseMetadata(json.RawMessage("null"))
+	assert.NoError(t, err)
+	assert.Nil(t, meta)
+	_, err = ParseMetadata(json.RawMessage(`{"Type":100}`))
+	assert.Equal(t, ErrUnknownMetadataType, errors.Cause(err))
+	_, err = ParseMetadata(json.RawMessage(`{"Type":"trade"}`))
+	assert.Error(t, err)
+}
+
+func TestRegisterMetadata(t *testing.T) {
+	const customMeta = 100
+	defer delete(registry, customMeta)
+
+	RegisterMetadata(customMeta, func() Metadata { return &StopAutoStakingMetadata{} })
+	parsed, err := ParseMetadata(json.RawMessage(`{"Type":100,"CommitteePublicKey":"key"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &StopAutoStakingMetadata{MetadataBase: MetadataBase{Type: customMeta}, CommitteePublicKey: "key"}, parsed)
+}
+
+func TestRawMetadata(t *testing.T) {
+	raw := json.RawMessage(`{"Type":105,"Rates":{"BTC":1}}`)
+	meta, err := NewRawMetadata(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, PortalExchangeRatesMeta, meta.GetType())
+	marshalled, err := json.Marshal(meta)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(raw), string(marshalled))
+	assert.Equal(t, ErrUnknownMetadataType, errors.Cause(meta.Validate()))
+
+	_, err = NewRawMetadata(json.RawMessage(`{"Type":"rates"}`))
+	assert.Error(t, err)
+}
+
+func TestValidateMetadata(t *testing.T) {
+	keyWallet, paymentAddress := newAccount(t)
+	tokenID := common.Hash{1, 2, 3}
+
+	sameTokens, _ := NewPDETradeRequest(common.PRVIDStr, common.PRVIDStr, 100, 90, 1, paymentAddress, PDETradeRequestMeta)
+	wrongType, _ := NewPDETradeRequest(tokenID.String(), common.PRVIDStr, 100, 90, 1, paymentAddress, ShardStakingMeta)
+	burnPRV, _ := NewBurningRequest(keyWallet.KeySet.PaymentAddress, 100, common.PRVCoinID, "PRV", "d5808ba261c91d640a2d4149e8cdb3fd4512efe4", BurningForDepositToSCRequestMeta)
+	notHex, _ := NewBurningRequest(keyWallet.KeySet.PaymentAddress, 100, tokenID, "pETH", "not hex", BurningForDepositToSCRequestMeta)
+	invalidKey, err := NewStakingMetadata(ShardStakingMeta, paymentAddress, paymentAddress, 1750000000000, "invalid", true)
+	assert.NoError(t, err)
+	noAmount, _ := NewContractingRequest(keyWallet.KeySet.PaymentAddress, 0, tokenID, ContractingRequestMeta)
+	withdrawV2 := &WithDrawRewardRequest{
+		PaymentAddress: keyWallet.KeySet.PaymentAddress,
+		MetadataBase:   MetadataBase{Type: WithDrawRewardRequestMeta},
+		TokenID:        common.PRVCoinID,
+		Version:        2,
+	}
+
+	tests := []struct {
+		name string
+		meta Metadata
+	}{
+		{"trade of a token for itself", sameTokens},
+		{"type of another struct", wrongType},
+		{"burning PRV", burnPRV},
+		{"remote address not hex", notHex},
+		{"invalid committee key", invalidKey},
+		{"zero amount", noAmount},
+		{"unknown version", withdrawV2},
+	}
+	for _, test := range tests {
+		assert.Error(t, test.meta.Validate(), test.name)
+	}
+}
@@ -2,6 +2,8 @@ package metadata
 
 import (
 	"errors"
+
+	"github.com/incognitochain/go-incognito-sdk/incognitokey"
 )
 
 type StakingMetadata struct {
@@ -49,3 +51,29 @@ func (stakingMetadata StakingMetadata) GetBeaconStakeAmount() uint64 {
 func (stakingMetadata StakingMetadata) GetShardStateAmount() uint64 {
 	return stakingMetadata.StakingAmountShard
 }
+
+// Validate checks the payment addresses, the staking amount and the committee public key
+func (stakingMetadata *StakingMetadata) Validate() error {
+	if err := checkType(stakingMetadata.Type, stakingMetadata); err != nil {
+		return err
+	}
+	if err := checkPaymentAddressStr(stakingMetadata.FunderPaymentAddress); err != nil {
+		return err
+	}
+	if err := checkPaymentAddressStr(stakingMetadata.RewardReceiverPaymentAddress); err != nil {
+		return err
+	}
+	if stakingMetadata.StakingAmountShard == 0 {
+		return errors.New("staking amount must be positive")
+	}
+	return checkCommitteePublicKey(stakingMetadata.CommitteePublicKey)
+}
+
+// checkCommitteePublicKey returns an error when committeePublicKey is not a base58 check encoded committee key
+func checkCommitteePublicKey(committeePublicKey string) error {
+	committeePK := new(incognitokey.CommitteePublicKey)
+	if err := committeePK.FromString(committeePublicKey); err != nil || !committeePK.CheckSanityData() {
+		return errors.New("invalid committee public key")
+	}
+	return nil
+}
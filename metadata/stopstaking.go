@@ -25,3 +25,11 @@ func (stopAutoStakingMetadata StopAutoStakingMetadata) GetType() int {
 func (stopAutoStakingMetadata *StopAutoStakingMetadata) CalculateSize() uint64 {
 	return calculateSize(stopAutoStakingMetadata)
 }
+
+// Validate checks the committee public key
+func (stopAutoStakingMetadata *StopAutoStakingMetadata) Validate() error {
+	if err := checkType(stopAutoStakingMetadata.Type, stopAutoStakingMetadata); err != nil {
+		return err
+	}
+	return checkCommitteePublicKey(stopAutoStakingMetadata.CommitteePublicKey)
+}
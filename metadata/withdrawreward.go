@@ -71,3 +71,17 @@ func NewWithDrawRewardRequestFromRPC(data map[string]interface{}) (Metadata, err
 
 	return result, nil
 }
+
+// Validate checks the payment address and the version
+func (withDrawRewardRequest *WithDrawRewardRequest) Validate() error {
+	if err := checkType(withDrawRewardRequest.Type, withDrawRewardRequest); err != nil {
+		return err
+	}
+	if err := checkPaymentAddress(withDrawRewardRequest.PaymentAddress); err != nil {
+		return err
+	}
+	if ok, err := common.SliceExists(AcceptedWithdrawRewardRequestVersion, withDrawRewardRequest.Version); !ok || err != nil {
+		return errors.Errorf("Invalid version %d", withDrawRewardRequest.Version)
+	}
+	return checkTokenID(withDrawRewardRequest.TokenID, true)
+}
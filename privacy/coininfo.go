@@ -0,0 +1,69 @@
+package privacy
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"fmt"
+)
+
+// encryptedInfoPrefix starts the info of a coin holding a memo encrypted by EncryptCoinInfo
+const encryptedInfoPrefix byte = 0xE1
+
+// encryptedMemoMagic starts the plaintext of a memo encrypted by EncryptCoinInfo. A plain memo which looks like an
+// encrypted one, or a memo encrypted to another key, does not decrypt to it.
+const encryptedMemoMagic = "incmemo1"
+
+// MaxSizeEncryptedMemo is the largest memo EncryptCoinInfo fits in the info of a coin, the prefix,
+// the ElGamal encrypted key, the AES IV and the magic of the plaintext take the rest
+const MaxSizeEncryptedMemo = MaxSizeInfoCoin - 1 - elGamalCiphertextSize - aes.BlockSize - len(encryptedMemoMagic)
+
+// ErrNotEncryptedCoinInfo is returned by DecryptCoinInfo for the info of a coin which is not a memo encrypted to the key
+var ErrNotEncryptedCoinInfo = errors.New("info of coin is not a memo encrypted to the key")
+
+// EncryptCoinInfo encrypts memo to the transmission key of the receiver of a coin, the result is the info of the coin.
+// Only the receiving key of the receiver decrypts it, see DecryptCoinInfo.
+func EncryptCoinInfo(memo []byte, transmissionKey []byte) ([]byte, error) {
+	if len(memo) == 0 {
+		return []byte{}, nil
+	}
+	if len(memo) > MaxSizeEncryptedMemo {
+		return nil, errors.New("memo is too large to be encrypted in the info of a coin")
+	}
+	publicKey, err := new(Point).FromBytesS(transmissionKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := HybridEncrypt(append([]byte(encryptedMemoMagic), memo...), publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{encryptedInfoPrefix}, ciphertext.Bytes()...), nil
+}
+
+// IsEncryptedCoinInfo tells whether info, the info of a coin, is laid out as a memo encrypted by EncryptCoinInfo.
+// A plain memo may be too, only DecryptCoinInfo tells them apart.
+func IsEncryptedCoinInfo(info []byte) bool {
+	return len(info) > 1+elGamalCiphertextSize+aes.BlockSize+len(encryptedMemoMagic) && info[0] == encryptedInfoPrefix
+}
+
+// DecryptCoinInfo returns the memo EncryptCoinInfo encrypted in info with the receiving key of the receiver of the coin.
+// It returns ErrNotEncryptedCoinInfo when info is a plain memo or a memo encrypted to another key.
+func DecryptCoinInfo(info []byte, receivingKey []byte) ([]byte, error) {
+	if !IsEncryptedCoinInfo(info) {
+		return nil, ErrNotEncryptedCoinInfo
+	}
+	// a plain memo laid out as an encrypted one may not even hold a valid ciphertext
+	ciphertext := new(HybridCipherText)
+	if err := ciphertext.SetBytes(info[1:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotEncryptedCoinInfo, err)
+	}
+	plaintext, err := HybridDecrypt(ciphertext, new(Scalar).FromBytesS(receivingKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotEncryptedCoinInfo, err)
+	}
+	if !bytes.HasPrefix(plaintext, []byte(encryptedMemoMagic)) {
+		return nil, ErrNotEncryptedCoinInfo
+	}
+	return plaintext[len(encryptedMemoMagic):], nil
+}
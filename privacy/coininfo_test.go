@@ -0,0 +1,36 @@
+package privacy
+
+import (
+	"crypto/aes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinInfoEncryption(t *testing.T) {
+	receivingKey := RandomScalar()
+	transmissionKey := new(Point).ScalarMultBase(receivingKey)
+
+	info, err := EncryptCoinInfo([]byte("deposit 1042"), transmissionKey.ToBytesS())
+	assert.NoError(t, err)
+	assert.True(t, IsEncryptedCoinInfo(info))
+	memo, err := DecryptCoinInfo(info, receivingKey.ToBytesS())
+	assert.NoError(t, err)
+	assert.Equal(t, "deposit 1042", string(memo))
+
+	// another key does not decrypt the memo
+	_, err = DecryptCoinInfo(info, RandomScalar().ToBytesS())
+	assert.True(t, errors.Is(err, ErrNotEncryptedCoinInfo))
+
+	// a plain memo laid out as an encrypted one is told apart
+	plain := make([]byte, 1+elGamalCiphertextSize+aes.BlockSize+len(encryptedMemoMagic)+10)
+	plain[0] = encryptedInfoPrefix
+	copy(plain[1:], info[1:1+elGamalCiphertextSize])
+	assert.True(t, IsEncryptedCoinInfo(plain))
+	_, err = DecryptCoinInfo(plain, receivingKey.ToBytesS())
+	assert.True(t, errors.Is(err, ErrNotEncryptedCoinInfo))
+
+	_, err = EncryptCoinInfo(make([]byte, MaxSizeEncryptedMemo+1), transmissionKey.ToBytesS())
+	assert.Error(t, err)
+}
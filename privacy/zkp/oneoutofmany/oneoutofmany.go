@@ -368,8 +368,12 @@ func (proof OneOutOfManyProof) Verify() (bool, error) {
 	}
 	n := privacy.CommitmentRingSizeExp
 
-	//Calculate x
-	x := new(privacy.Scalar).FromUint64(0)
+	//Calculate x the same way Prove does, starting from the hash of the commitments
+	cmtsInBytes := make([][]byte, 0, N)
+	for _, cmts := range proof.Statement.Commitments {
+		cmtsInBytes = append(cmtsInBytes, cmts.ToBytesS())
+	}
+	x := utils.GenerateChallenge(cmtsInBytes)
 
 	for j := 0; j < n; j++ {
 		x = utils.GenerateChallenge([][]byte{x.ToBytesS(), proof.cl[j].ToBytesS(), proof.ca[j].ToBytesS(), proof.cb[j].ToBytesS(), proof.cd[j].ToBytesS()})
@@ -0,0 +1,203 @@
+package zkp
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/privacy/zkp/oneoutofmany"
+)
+
+// Verify checks the proof the way a node does before accepting the tx spending it:
+// pubKey is the SigPubKey of the tx, fee its fee and shardID the shard of the sender.
+// With privacy, commitments are the coin commitments at GetCommitmentIndices, in the same order,
+// one ring of privacy.CommitmentRingSize commitments per input coin.
+// Without privacy the input coins are revealed with their openings, so commitments is not used,
+// it is up to the caller to check their commitments exist on chain.
+func (proof PaymentProof) Verify(hasPrivacy bool, pubKey privacy.PublicKey, fee uint64, shardID byte, commitments []*privacy.Point) (bool, *privacy.PrivacyError) {
+	if !hasPrivacy {
+		return proof.verifyNoPrivacy(pubKey, fee)
+	}
+	return proof.verifyHasPrivacy(pubKey, fee, shardID, commitments)
+}
+
+func (proof PaymentProof) verifyNoPrivacy(pubKey privacy.PublicKey, fee uint64) (bool, *privacy.PrivacyError) {
+	if len(proof.serialNumberNoPrivacyProof) != len(proof.inputCoins) {
+		return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberNoPrivacyProofFailedErr, errors.New("number of serial number proofs is not equal to number of input coins"))
+	}
+
+	sumInputValue := uint64(0)
+	for i, inputCoin := range proof.inputCoins {
+		coin := inputCoin.CoinDetails
+		if coin.GetPublicKey() == nil || !bytes.Equal(coin.GetPublicKey().ToBytesS(), pubKey) {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberNoPrivacyProofFailedErr, errors.New("input coin is not owned by the signer of the tx"))
+		}
+
+		// the proof must be about the revealed coin
+		snProof := proof.serialNumberNoPrivacyProof[i]
+		if coin.GetSerialNumber() == nil || coin.GetSNDerivator() == nil ||
+			!privacy.IsPointEqual(snProof.GetOutput(), coin.GetSerialNumber()) ||
+			!privacy.IsPointEqual(snProof.GetVKey(), coin.GetPublicKey()) ||
+			!bytes.Equal(snProof.GetInput().ToBytesS(), coin.GetSNDerivator().ToBytesS()) {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberNoPrivacyProofFailedErr, fmt.Errorf("serial number proof %v does not match input coin", i))
+		}
+		valid, err := snProof.Verify(nil)
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberNoPrivacyProofFailedErr, err)
+		}
+
+		valid, err = isCoinCommitmentCorrect(coin)
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifyCoinCommitmentInputFailedErr, err)
+		}
+		sumInputValue += coin.GetValue()
+	}
+
+	sumOutputValue := uint64(0)
+	for _, outputCoin := range proof.outputCoins {
+		valid, err := isCoinCommitmentCorrect(outputCoin.CoinDetails)
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifyCoinCommitmentOutputFailedErr, err)
+		}
+		sumOutputValue += outputCoin.CoinDetails.GetValue()
+	}
+
+	if len(proof.inputCoins) > 0 && sumInputValue != sumOutputValue+fee {
+		return false, privacy.NewPrivacyErr(privacy.VerifyAmountNoPrivacyFailedErr, fmt.Errorf("input %v != output %v + fee %v", sumInputValue, sumOutputValue, fee))
+	}
+
+	return true, nil
+}
+
+// isCoinCommitmentCorrect recommits the revealed details of coin and compares them with its commitment
+func isCoinCommitmentCorrect(coin *privacy.Coin) (bool, error) {
+	if coin == nil || coin.GetPublicKey() == nil || coin.GetSNDerivator() == nil || coin.GetRandomness() == nil || coin.GetCoinCommitment() == nil {
+		return false, errors.New("coin details are missing")
+	}
+	tmp := new(privacy.Coin).Init()
+	tmp.SetPublicKey(coin.GetPublicKey())
+	tmp.SetValue(coin.GetValue())
+	tmp.SetSNDerivator(coin.GetSNDerivator())
+	tmp.SetRandomness(coin.GetRandomness())
+	err := tmp.CommitAll()
+	if err != nil {
+		return false, err
+	}
+	if !privacy.IsPointEqual(tmp.GetCoinCommitment(), coin.GetCoinCommitment()) {
+		return false, errors.New("coin commitment does not open to the coin details")
+	}
+	return true, nil
+}
+
+func (proof PaymentProof) verifyHasPrivacy(pubKey privacy.PublicKey, fee uint64, shardID byte, commitments []*privacy.Point) (bool, *privacy.PrivacyError) {
+	numInputCoins := len(proof.inputCoins)
+	if len(proof.oneOfManyProof) != numInputCoins || len(proof.serialNumberProof) != numInputCoins ||
+		len(proof.commitmentInputValue) != numInputCoins || len(proof.commitmentInputSND) != numInputCoins {
+		return false, privacy.NewPrivacyErr(privacy.VerifyOneOutOfManyProofFailedErr, errors.New("number of proofs is not equal to number of input coins"))
+	}
+	if len(commitments) != numInputCoins*privacy.CommitmentRingSize {
+		return false, privacy.NewPrivacyErr(privacy.VerifyOneOutOfManyProofFailedErr, fmt.Errorf("need %v commitments, got %v", numInputCoins*privacy.CommitmentRingSize, len(commitments)))
+	}
+
+	if numInputCoins > 0 {
+		// the tx is signed with the opening of the commitment to the private key
+		if proof.commitmentInputSecretKey == nil || !bytes.Equal(proof.commitmentInputSecretKey.ToBytesS(), pubKey) {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberPrivacyProofFailedErr, errors.New("commitment to the private key is not the signing key of the tx"))
+		}
+		cmShardID := privacy.PedCom.CommitAtIndex(new(privacy.Scalar).FromUint64(uint64(shardID)), privacy.FixedRandomnessShardID, privacy.PedersenShardIDIndex)
+		if proof.commitmentInputShardID == nil || !privacy.IsPointEqual(proof.commitmentInputShardID, cmShardID) {
+			return false, privacy.NewPrivacyErr(privacy.VerifyOneOutOfManyProofFailedErr, errors.New("commitment to the shard id is not the shard of the sender"))
+		}
+	}
+
+	for i := 0; i < numInputCoins; i++ {
+		// the ring shifted by the sum of the input commitments holds one commitment to zero
+		cmInputSum := new(privacy.Point).Add(proof.commitmentInputSecretKey, proof.commitmentInputValue[i])
+		cmInputSum.Add(cmInputSum, proof.commitmentInputSND[i])
+		cmInputSum.Add(cmInputSum, proof.commitmentInputShardID)
+
+		ring := make([]*privacy.Point, privacy.CommitmentRingSize)
+		for j := 0; j < privacy.CommitmentRingSize; j++ {
+			ring[j] = new(privacy.Point).Sub(commitments[i*privacy.CommitmentRingSize+j], cmInputSum)
+		}
+		if proof.oneOfManyProof[i].Statement == nil {
+			proof.oneOfManyProof[i].Statement = new(oneoutofmany.OneOutOfManyStatement)
+		}
+		proof.oneOfManyProof[i].Statement.Set(ring)
+		valid, err := proof.oneOfManyProof[i].Verify()
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifyOneOutOfManyProofFailedErr, err)
+		}
+
+		// the serial number proof must be about the committed values above
+		snProof := proof.serialNumberProof[i]
+		if proof.inputCoins[i].CoinDetails.GetSerialNumber() == nil ||
+			!privacy.IsPointEqual(snProof.GetSN(), proof.inputCoins[i].CoinDetails.GetSerialNumber()) ||
+			!privacy.IsPointEqual(snProof.GetComSK(), proof.commitmentInputSecretKey) ||
+			!privacy.IsPointEqual(snProof.GetComInput(), proof.commitmentInputSND[i]) {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberPrivacyProofFailedErr, fmt.Errorf("serial number proof %v does not match input coin", i))
+		}
+		valid, err = snProof.Verify(nil)
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifySerialNumberPrivacyProofFailedErr, err)
+		}
+	}
+
+	numOutputCoins := len(proof.outputCoins)
+	if len(proof.commitmentOutputValue) != numOutputCoins || len(proof.commitmentOutputSND) != numOutputCoins ||
+		len(proof.commitmentOutputShardID) != numOutputCoins {
+		return false, privacy.NewPrivacyErr(privacy.VerifyCoinCommitmentOutputFailedErr, errors.New("number of commitments is not equal to number of output coins"))
+	}
+	for i, outputCoin := range proof.outputCoins {
+		if outputCoin.CoinDetails.GetPublicKey() == nil || outputCoin.CoinDetails.GetCoinCommitment() == nil {
+			return false, privacy.NewPrivacyErr(privacy.VerifyCoinCommitmentOutputFailedErr, fmt.Errorf("output coin %v is missing its public key or commitment", i))
+		}
+		cmOutputSum := new(privacy.Point).Add(outputCoin.CoinDetails.GetPublicKey(), proof.commitmentOutputValue[i])
+		cmOutputSum.Add(cmOutputSum, proof.commitmentOutputSND[i])
+		cmOutputSum.Add(cmOutputSum, proof.commitmentOutputShardID[i])
+		if !privacy.IsPointEqual(cmOutputSum, outputCoin.CoinDetails.GetCoinCommitment()) {
+			return false, privacy.NewPrivacyErr(privacy.VerifyCoinCommitmentOutputFailedErr, fmt.Errorf("commitment of output coin %v is not the sum of its commitments", i))
+		}
+	}
+
+	// the range proof must be about the output value commitments
+	if proof.aggregatedRangeProof == nil {
+		return false, privacy.NewPrivacyErr(privacy.VerifyAggregatedProofFailedErr, errors.New("missing range proof"))
+	}
+	cmsValue := proof.aggregatedRangeProof.GetCmValues()
+	if len(cmsValue) != numOutputCoins {
+		return false, privacy.NewPrivacyErr(privacy.VerifyAggregatedProofFailedErr, errors.New("range proof is not about the output coins"))
+	}
+	for i := range cmsValue {
+		if !privacy.IsPointEqual(cmsValue[i], proof.commitmentOutputValue[i]) {
+			return false, privacy.NewPrivacyErr(privacy.VerifyAggregatedProofFailedErr, errors.New("range proof is not about the output coins"))
+		}
+	}
+	if numOutputCoins > 0 {
+		valid, err := proof.aggregatedRangeProof.Verify()
+		if !valid {
+			return false, privacy.NewPrivacyErr(privacy.VerifyAggregatedProofFailedErr, err)
+		}
+	}
+
+	// sum of the input values = sum of the output values + fee, the randomness of the commitments cancel out
+	if numInputCoins > 0 {
+		comInputValueSum := new(privacy.Point).Identity()
+		for _, cm := range proof.commitmentInputValue {
+			comInputValueSum.Add(comInputValueSum, cm)
+		}
+		comOutputValueSum := new(privacy.Point).Identity()
+		for _, cm := range proof.commitmentOutputValue {
+			comOutputValueSum.Add(comOutputValueSum, cm)
+		}
+		if fee > 0 {
+			comOutputValueSum.Add(comOutputValueSum, new(privacy.Point).ScalarMult(privacy.PedCom.G[privacy.PedersenValueIndex], new(privacy.Scalar).FromUint64(fee)))
+		}
+		if !privacy.IsPointEqual(comInputValueSum, comOutputValueSum) {
+			return false, privacy.NewPrivacyErr(privacy.VerifyAmountPrivacyFailedErr, nil)
+		}
+	}
+
+	return true, nil
+}
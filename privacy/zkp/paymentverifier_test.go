@@ -0,0 +1,105 @@
+package zkp
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPaymentWitnessParam returns the params spending a coin of value of a new key, paying amount to another key,
+// the rest minus fee back to the sender. With privacy the coin spent is hidden in a ring of random commitments.
+func newPaymentWitnessParam(t *testing.T, hasPrivacy bool, value uint64, amount uint64, fee uint64) PaymentWitnessParam {
+	privateKey := privacy.GeneratePrivateKey(privacy.RandBytes(32))
+	publicKey := privacy.GeneratePublicKey(privateKey)
+	skScalar := new(privacy.Scalar).FromBytesS(privateKey)
+	pkPoint, err := new(privacy.Point).FromBytesS(publicKey)
+	assert.NoError(t, err)
+
+	inputCoin := new(privacy.InputCoin).Init()
+	inputCoin.CoinDetails.SetPublicKey(pkPoint)
+	inputCoin.CoinDetails.SetValue(value)
+	inputCoin.CoinDetails.SetSNDerivator(privacy.RandomScalar())
+	inputCoin.CoinDetails.SetRandomness(privacy.RandomScalar())
+	assert.NoError(t, inputCoin.CoinDetails.CommitAll())
+	inputCoin.CoinDetails.SetSerialNumber(new(privacy.Point).Derive(privacy.PedCom.G[privacy.PedersenPrivateKeyIndex], skScalar, inputCoin.CoinDetails.GetSNDerivator()))
+
+	receiver := privacy.GeneratePublicKey(privacy.GeneratePrivateKey(privacy.RandBytes(32)))
+	outputCoins := make([]*privacy.OutputCoin, 2)
+	for i, output := range []struct {
+		publicKey privacy.PublicKey
+		amount    uint64
+	}{{receiver, amount}, {publicKey, value - amount - fee}} {
+		outputPK, err := new(privacy.Point).FromBytesS(output.publicKey)
+		assert.NoError(t, err)
+		outputCoins[i] = new(privacy.OutputCoin).Init()
+		outputCoins[i].CoinDetails.SetPublicKey(outputPK)
+		outputCoins[i].CoinDetails.SetValue(output.amount)
+		outputCoins[i].CoinDetails.SetSNDerivator(privacy.RandomScalar())
+	}
+
+	param := PaymentWitnessParam{
+		HasPrivacy:              hasPrivacy,
+		PrivateKey:              skScalar,
+		InputCoins:              []*privacy.InputCoin{inputCoin},
+		OutputCoins:             outputCoins,
+		PublicKeyLastByteSender: publicKey[len(publicKey)-1],
+		Fee:                     fee,
+	}
+	if hasPrivacy {
+		const myIndex = 3
+		param.Commitments = make([]*privacy.Point, privacy.CommitmentRingSize)
+		param.CommitmentIndices = make([]uint64, privacy.CommitmentRingSize)
+		for i := range param.Commitments {
+			param.Commitments[i] = privacy.RandomPoint()
+			param.CommitmentIndices[i] = uint64(i * 10)
+		}
+		param.Commitments[myIndex] = inputCoin.CoinDetails.GetCoinCommitment()
+		param.MyCommitmentIndices = []uint64{myIndex}
+	}
+	return param
+}
+
+func TestPaymentProofVerify(t *testing.T) {
+	for _, hasPrivacy := range []bool{false, true} {
+		param := newPaymentWitnessParam(t, hasPrivacy, 5000, 1000, 100)
+		witness := new(PaymentWitness)
+		assert.Nil(t, witness.Init(param))
+		proof, err := witness.Prove(hasPrivacy)
+		assert.Nil(t, err)
+
+		// the tx of a proof with privacy is signed with the commitment to the private key
+		pubKey := param.InputCoins[0].CoinDetails.GetPublicKey().ToBytesS()
+		if hasPrivacy {
+			pubKey = proof.commitmentInputSecretKey.ToBytesS()
+		}
+		shardID := common.GetShardIDFromLastByte(param.PublicKeyLastByteSender)
+
+		valid, err := proof.Verify(hasPrivacy, pubKey, param.Fee, shardID, param.Commitments)
+		assert.True(t, valid, "hasPrivacy %v", hasPrivacy)
+		assert.Nil(t, err)
+
+		// the proof does not balance with another fee
+		valid, err = proof.Verify(hasPrivacy, pubKey, param.Fee+1, shardID, param.Commitments)
+		assert.False(t, valid)
+		assert.NotNil(t, err)
+
+		// nor is it valid for another signer
+		other := privacy.GeneratePublicKey(privacy.GeneratePrivateKey(privacy.RandBytes(32)))
+		valid, err = proof.Verify(hasPrivacy, other, param.Fee, shardID, param.Commitments)
+		assert.False(t, valid)
+		assert.NotNil(t, err)
+
+		if hasPrivacy {
+			// the coin spent is not in a ring made of other commitments
+			ring := make([]*privacy.Point, len(param.Commitments))
+			for i := range ring {
+				ring[i] = privacy.RandomPoint()
+			}
+			valid, err = proof.Verify(hasPrivacy, pubKey, param.Fee, shardID, ring)
+			assert.False(t, valid)
+			assert.NotNil(t, err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/privacy/zkp/oneoutofmany"
 	"github.com/incognitochain/go-incognito-sdk/privacy/zkp/serialnumbernoprivacy"
 	"github.com/incognitochain/go-incognito-sdk/privacy/zkp/serialnumberprivacy"
+	"time"
 )
 
 // PaymentWitness contains all of witness for proving when spending coins
@@ -282,6 +283,23 @@ func (wit *PaymentWitness) Init(PaymentWitnessParam PaymentWitnessParam) *privac
 
 // Prove creates big proof
 func (wit *PaymentWitness) Prove(hasPrivacy bool) (*PaymentProof, *privacy.PrivacyError) {
+	start := time.Now()
+	proof, err := wit.prove(hasPrivacy)
+	// a nil *PrivacyError must not be reported as a non nil error
+	var observed error
+	if err != nil {
+		observed = err
+	}
+	observeProof("payment", start, observed)
+	return proof, err
+}
+
+// observeProof reports a proof built since start to the observer of the SDK
+func observeProof(name string, start time.Time, err error) {
+	common.ObserveStep(common.StepProof, name, start, err, 0)
+}
+
+func (wit *PaymentWitness) prove(hasPrivacy bool) (*PaymentProof, *privacy.PrivacyError) {
 	proof := new(PaymentProof)
 	proof.Init()
 
@@ -303,7 +321,9 @@ func (wit *PaymentWitness) Prove(hasPrivacy bool) (*PaymentProof, *privacy.Priva
 	if !hasPrivacy {
 		// Proving that serial number is derived from the committed derivator
 		for i := 0; i < len(wit.inputCoins); i++ {
+			start := time.Now()
 			snNoPrivacyProof, err := wit.serialNumberNoPrivacyWitness[i].Prove(nil)
+			observeProof("serialnumbernoprivacy", start, err)
 			if err != nil {
 				return nil, privacy.NewPrivacyErr(privacy.ProveSerialNumberNoPrivacyErr, err)
 			}
@@ -317,14 +337,18 @@ func (wit *PaymentWitness) Prove(hasPrivacy bool) (*PaymentProof, *privacy.Priva
 
 	for i := 0; i < numInputCoins; i++ {
 		// Proving one-out-of-N commitments is a commitment to the coins being spent
+		start := time.Now()
 		oneOfManyProof, err := wit.oneOfManyWitness[i].Prove()
+		observeProof("oneoutofmany", start, err)
 		if err != nil {
 			return nil, privacy.NewPrivacyErr(privacy.ProveOneOutOfManyErr, err)
 		}
 		proof.oneOfManyProof = append(proof.oneOfManyProof, oneOfManyProof)
 
 		// Proving that serial number is derived from the committed derivator
+		start = time.Now()
 		serialNumberProof, err := wit.serialNumberWitness[i].Prove(nil)
+		observeProof("serialnumberprivacy", start, err)
 		if err != nil {
 			return nil, privacy.NewPrivacyErr(privacy.ProveSerialNumberPrivacyErr, err)
 		}
@@ -333,7 +357,9 @@ func (wit *PaymentWitness) Prove(hasPrivacy bool) (*PaymentProof, *privacy.Priva
 	var err error
 
 	// Proving that each output values and sum of them does not exceed v_max
+	start := time.Now()
 	proof.aggregatedRangeProof, err = wit.aggregatedRangeWitness.Prove()
+	observeProof("aggregatedrange", start, err)
 	if err != nil {
 		return nil, privacy.NewPrivacyErr(privacy.ProveAggregatedRangeErr, err)
 	}
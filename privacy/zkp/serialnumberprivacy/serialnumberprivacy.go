@@ -311,6 +311,8 @@ func (proof SNPrivacyProof) Verify(mess []byte) (bool, error) {
 	x := new(privacy.Scalar)
 	if mess == nil {
 		x = utils.GenerateChallenge([][]byte{
+			proof.stmt.sn.ToBytesS(),
+			proof.stmt.comSK.ToBytesS(),
 			proof.tSK.ToBytesS(),
 			proof.tInput.ToBytesS(),
 			proof.tSN.ToBytesS()})
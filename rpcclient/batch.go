@@ -0,0 +1,144 @@
+package rpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// BatchRequest is one call of a JSON-RPC batch
+type BatchRequest struct {
+	Method string
+	Params interface{}
+}
+
+// BatchTransport is a Transport able to send several calls in a single round trip.
+// The returned bodies are in the order of reqs.
+type BatchTransport interface {
+	Transport
+	CallBatch(ctx context.Context, reqs []BatchRequest) ([][]byte, error)
+}
+
+// CallBatch sends reqs in one round trip when transport supports it, one by one otherwise
+func CallBatch(ctx context.Context, transport Transport, reqs []BatchRequest) ([][]byte, error) {
+	if batchTransport, ok := transport.(BatchTransport); ok {
+		return batchTransport.CallBatch(ctx, reqs)
+	}
+
+	bodies := make([][]byte, len(reqs))
+	for i, req := range reqs {
+		body, err := transport.Call(ctx, req.Method, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		bodies[i] = body
+	}
+	return bodies, nil
+}
+
+func (t *HTTPTransport) CallBatch(ctx context.Context, reqs []BatchRequest) (bodies [][]byte, err error) {
+	start := time.Now()
+	defer func() {
+		for i, req := range reqs {
+			var body []byte
+			if bodies != nil {
+				body = bodies[i]
+			}
+			observeCall(req.Method, start, body, err)
+		}
+	}()
+
+	payload := make([]*RPCRequest, len(reqs))
+	for i, req := range reqs {
+		payload[i] = NewRPCRequest(req.Method, req.Params)
+		payload[i].Id = uint(i)
+	}
+	payloadInBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Endpoint, bytes.NewReader(payloadInBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, unreachable(ctx, t.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, unreachable(ctx, t.Endpoint, err)
+	}
+
+	return splitBatchResponse(body, len(reqs))
+}
+
+// splitBatchResponse matches the responses of a batch to its requests by id, nodes may answer out of order
+func splitBatchResponse(body []byte, n int) ([][]byte, error) {
+	var responses []json.RawMessage
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("batch response is not an array: %w", err)
+	}
+
+	bodies := make([][]byte, n)
+	for _, response := range responses {
+		var header struct {
+			Id *uint `json:"Id"`
+		}
+		if err := json.Unmarshal(response, &header); err != nil {
+			return nil, err
+		}
+		if header.Id == nil || *header.Id >= uint(n) || bodies[*header.Id] != nil {
+			return nil, fmt.Errorf("batch response has an unexpected id in %s", response)
+		}
+		bodies[*header.Id] = response
+	}
+
+	for i, b := range bodies {
+		if b == nil {
+			return nil, fmt.Errorf("batch response misses the answer of call #%d", i)
+		}
+	}
+	return bodies, nil
+}
+
+// BatchCall is one call of RPCBatchCallWithContext, its response is unmarshalled into RPCResponse
+type BatchCall struct {
+	Method      string
+	Params      interface{}
+	RPCResponse interface{}
+}
+
+// RPCBatchCallWithContext sends calls in a single JSON-RPC batch when the transport supports it
+func (client *HttpClient) RPCBatchCallWithContext(ctx context.Context, calls []*BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	reqs := make([]BatchRequest, len(calls))
+	for i, call := range calls {
+		reqs[i] = BatchRequest{Method: call.Method, Params: call.Params}
+	}
+
+	bodies, err := CallBatch(ctx, client.transport, reqs)
+	if err != nil {
+		return err
+	}
+
+	for i, call := range calls {
+		if err := json.Unmarshal(bodies[i], call.RPCResponse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
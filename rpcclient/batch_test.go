@@ -0,0 +1,128 @@
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBatchNode answers every call of a batch with handle, in reverse order
+func newBatchNode(roundTrips *int, handle func(method string, params []interface{}) interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*roundTrips++
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []struct {
+			Method string
+			Params []interface{}
+			Id     uint
+		}
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		responses := make([]map[string]interface{}, 0, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			responses = append(responses, map[string]interface{}{
+				"Id":     reqs[i].Id,
+				"Result": handle(reqs[i].Method, reqs[i].Params),
+				"Error":  nil,
+			})
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func TestHTTPTransportCallBatch(t *testing.T) {
+	roundTrips := 0
+	server := newBatchNode(&roundTrips, func(method string, params []interface{}) interface{} {
+		return method
+	})
+	defer server.Close()
+
+	bodies, err := NewHTTPTransport(nil, server.URL).CallBatch(context.Background(), []BatchRequest{
+		{Method: "listoutputcoins"},
+		{Method: "hasserialnumbers"},
+		{Method: "randomcommitments"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, roundTrips)
+	for i, method := range []string{"listoutputcoins", "hasserialnumbers", "randomcommitments"} {
+		var res IncognitoRPCRes
+		assert.NoError(t, json.Unmarshal(bodies[i], &res))
+		assert.Equal(t, method, res.Result)
+	}
+}
+
+func TestSplitBatchResponse(t *testing.T) {
+	_, err := splitBatchResponse([]byte(`{"Id":0}`), 1)
+	assert.Error(t, err)
+
+	_, err = splitBatchResponse([]byte(`[{"Id":0}]`), 2)
+	assert.Error(t, err)
+
+	_, err = splitBatchResponse([]byte(`[{"Id":0},{"Id":0}]`), 2)
+	assert.Error(t, err)
+
+	bodies, err := splitBatchResponse([]byte(`[{"Id":1,"Result":"b"},{"Id":0,"Result":"a"}]`), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Id":0,"Result":"a"}`, string(bodies[0]))
+}
+
+func TestCheckSNDerivatorsExistenceWithContext(t *testing.T) {
+	existing := privacy.RandomScalar()
+	roundTrips := 0
+	hassnderivators := 0
+	server := newBatchNode(&roundTrips, func(method string, params []interface{}) interface{} {
+		hassnderivators++
+		snds := params[1].([]interface{})
+		result := make([]bool, len(snds))
+		for i, snd := range snds {
+			result[i] = snd == base58.Base58Check{}.Encode(existing.ToBytesS(), common.Base58Version)
+		}
+		return result
+	})
+	defer server.Close()
+
+	client := NewHttpClient(server.URL, "", "", 0)
+	snds := []*privacy.Scalar{privacy.RandomScalar(), existing, privacy.RandomScalar()}
+	existed, err := CheckSNDerivatorsExistenceWithContext(context.Background(), client, []string{"addr1", "addr2", "addr1"}, snds)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false}, existed)
+	assert.Equal(t, 1, roundTrips)
+	assert.Equal(t, 2, hassnderivators)
+}
+
+func TestHTTPTransportObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id":1,"Result":null,"Error":{"Code":-1001,"Message":"reject"}}`))
+	}))
+	defer server.Close()
+
+	var steps []common.Step
+	common.SetObserver(func(step common.Step) {
+		steps = append(steps, step)
+	})
+	defer common.SetObserver(nil)
+
+	_, err := NewHTTPTransport(nil, server.URL).Call(context.Background(), "sendtransaction", []interface{}{"tx"})
+	assert.NoError(t, err)
+	_, err = NewHTTPTransport(nil, "http://127.0.0.1:1").Call(context.Background(), "getblockcount", nil)
+	assert.Error(t, err)
+
+	assert.Len(t, steps, 2)
+	assert.Equal(t, common.StepRPC, steps[0].Kind)
+	assert.Equal(t, "sendtransaction", steps[0].Name)
+	assert.Equal(t, -1001, steps[0].Code)
+	assert.Equal(t, "getblockcount", steps[1].Name)
+	assert.True(t, errors.Is(steps[1].Err, ErrNodeUnreachable))
+}
@@ -0,0 +1,282 @@
+package rpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// CassetteMode tells whether a Cassette records calls or replays them
+type CassetteMode int
+
+const (
+	// CassetteRecord sends calls through the wrapped transport and keeps every answer for Save
+	CassetteRecord CassetteMode = iota
+	// CassetteReplay answers calls from the fixture file, nothing is sent
+	CassetteReplay
+)
+
+// RedactedKey replaces the private and readonly keys in the params of a recorded or replayed call
+const RedactedKey = "REDACTED_KEY"
+
+// ErrCassetteMiss is returned in replay mode for a call the fixture has no answer for
+var ErrCassetteMiss = errors.New("no recorded interaction matches the call")
+
+// Interaction is a recorded call and the body answered to it
+type Interaction struct {
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params"`
+	Response json.RawMessage `json:"response"`
+}
+
+// cassetteFile is the content of a fixture file
+type cassetteFile struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// CassetteConfig tunes a Cassette, Path is required
+type CassetteConfig struct {
+	// Path is the fixture file, written by Save in record mode and read by NewCassette in replay mode
+	Path string
+	Mode CassetteMode
+	// Lenient matching answers a call whose params match no interaction with the first one of the same
+	// method, and replays the last answer of a call again once all of its answers were used.
+	// Strict matching needs the same method and params, and replays every answer once in recorded order.
+	Lenient bool
+	// Normalize, when set, rewrites the params of every call before they are matched or recorded,
+	// e.g. to blank random SNDs out of hassnderivators. It runs before the keys are redacted, which
+	// happens anyway unless RecordKeys is set.
+	Normalize func(method string, params interface{}) interface{}
+	// RecordKeys keeps the private and readonly keys found in the params of the calls, which are
+	// otherwise replaced by RedactedKey so that a fixture file can be committed
+	RecordKeys bool
+}
+
+// Cassette is a Transport recording the calls of a real node session to a fixture file, and replaying them
+// in tests. Calls are keyed by method and params, the params are normalized to canonical JSON so the order
+// of map keys does not matter, and the private and readonly keys among them are redacted. Give it to incognitoclient.WithTransport or NewHttpClientWithTransport to
+// record or replay the calls of the facade and of transaction building.
+type Cassette struct {
+	transport Transport
+	config    CassetteConfig
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	// used counts the answers replayed for every key
+	used map[string]int
+}
+
+// NewCassette returns a cassette wrapping transport. In replay mode the fixture at config.Path is loaded
+// and transport may be nil.
+func NewCassette(transport Transport, config CassetteConfig) (*Cassette, error) {
+	c := &Cassette{
+		transport: transport,
+		config:    config,
+		used:      make(map[string]int),
+	}
+
+	switch config.Mode {
+	case CassetteRecord:
+		if transport == nil {
+			return nil, errors.New("a recording cassette needs a transport")
+		}
+	case CassetteReplay:
+		data, err := ioutil.ReadFile(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		var file cassetteFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("decode cassette %s: %w", config.Path, err)
+		}
+		// Save indents the file, compact params and responses back to the bytes that were recorded
+		for _, interaction := range file.Interactions {
+			if interaction.Params, err = compactJSON(interaction.Params); err != nil {
+				return nil, fmt.Errorf("decode cassette %s: %w", config.Path, err)
+			}
+			if interaction.Response, err = compactJSON(interaction.Response); err != nil {
+				return nil, fmt.Errorf("decode cassette %s: %w", config.Path, err)
+			}
+		}
+		c.interactions = file.Interactions
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %d", config.Mode)
+	}
+	return c, nil
+}
+
+// Interactions returns the recorded or loaded interactions
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Save writes the recorded interactions to config.Path
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(&cassetteFile{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.config.Path, data, 0644)
+}
+
+func (c *Cassette) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	normalized, err := c.normalize(method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.Mode == CassetteReplay {
+		return c.replay(method, normalized)
+	}
+
+	body, err := c.transport.Call(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	c.record(method, normalized, body)
+	return body, nil
+}
+
+// CallBatch records every call of the batch as its own interaction, so a batch replays calls recorded one by one
+// and the other way round
+func (c *Cassette) CallBatch(ctx context.Context, reqs []BatchRequest) ([][]byte, error) {
+	normalized := make([]json.RawMessage, len(reqs))
+	for i, req := range reqs {
+		var err error
+		if normalized[i], err = c.normalize(req.Method, req.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.config.Mode == CassetteReplay {
+		bodies := make([][]byte, len(reqs))
+		for i, req := range reqs {
+			body, err := c.replay(req.Method, normalized[i])
+			if err != nil {
+				return nil, err
+			}
+			// batch answers are matched to their call by id
+			if bodies[i], err = withId(body, i); err != nil {
+				return nil, err
+			}
+		}
+		return bodies, nil
+	}
+
+	bodies, err := CallBatch(ctx, c.transport, reqs)
+	if err != nil {
+		return nil, err
+	}
+	for i, req := range reqs {
+		c.record(req.Method, normalized[i], bodies[i])
+	}
+	return bodies, nil
+}
+
+// normalize returns params as canonical JSON, map keys sorted, with the keys redacted unless config.RecordKeys is set
+func (c *Cassette) normalize(method string, params interface{}) (json.RawMessage, error) {
+	if c.config.Normalize != nil {
+		params = c.config.Normalize(method, params)
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	if !c.config.RecordKeys {
+		generic = redactKeys(generic)
+	}
+	return json.Marshal(generic)
+}
+
+// redactKeys replaces the serialized private and readonly keys of a JSON value by RedactedKey, payment
+// addresses are left as they are
+func redactKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		key, err := wallet.Base58CheckDeserialize(v)
+		if err == nil && (len(key.KeySet.PrivateKey) > 0 || len(key.KeySet.ReadonlyKey.Rk) > 0) {
+			return RedactedKey
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactKeys(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = redactKeys(v[k])
+		}
+	}
+	return value
+}
+
+func compactJSON(data json.RawMessage) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Cassette) record(method string, params json.RawMessage, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &Interaction{
+		Method:   method,
+		Params:   params,
+		Response: append(json.RawMessage(nil), body...),
+	})
+}
+
+func (c *Cassette) replay(method string, params json.RawMessage) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := method + " " + string(params)
+	matches := make([]*Interaction, 0)
+	for _, interaction := range c.interactions {
+		if interaction.Method == method && string(interaction.Params) == string(params) {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 && c.config.Lenient {
+		for _, interaction := range c.interactions {
+			if interaction.Method == method {
+				return interaction.Response, nil
+			}
+		}
+	}
+
+	n := c.used[key]
+	if n >= len(matches) {
+		if !c.config.Lenient || len(matches) == 0 {
+			return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, method, params)
+		}
+		n = len(matches) - 1
+	}
+	c.used[key] = n + 1
+	return matches[n].Response, nil
+}
+
+// withId sets the Id of a JSON-RPC response body
+func withId(body []byte, id int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	fields["Id"] = json.RawMessage(fmt.Sprint(id))
+	return json.Marshal(fields)
+}
@@ -0,0 +1,147 @@
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	roundTrips := 0
+	heights := map[string]int{}
+	server := newBatchNode(&roundTrips, func(method string, params []interface{}) interface{} {
+		heights[method]++
+		return heights[method]
+	})
+	defer server.Close()
+
+	recorder, err := NewCassette(NewHTTPTransport(nil, server.URL), CassetteConfig{Path: path, Mode: CassetteRecord})
+	assert.NoError(t, err)
+	ctx := context.Background()
+	_, err = recorder.CallBatch(ctx, []BatchRequest{
+		{Method: "getblockcount", Params: []interface{}{0}},
+		{Method: "getpdestate", Params: []interface{}{map[string]interface{}{"BeaconHeight": 10, "A": 1}}},
+	})
+	assert.NoError(t, err)
+	_, err = recorder.CallBatch(ctx, []BatchRequest{{Method: "getblockcount", Params: []interface{}{0}}})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Save())
+
+	strict, err := NewCassette(nil, CassetteConfig{Path: path, Mode: CassetteReplay})
+	assert.NoError(t, err)
+	assert.Len(t, strict.Interactions(), 3)
+
+	// answers of the same call are replayed in order, whatever the order of map keys
+	for _, height := range []int{1, 2} {
+		var res IncognitoRPCRes
+		body, err := strict.Call(ctx, "getblockcount", []interface{}{0})
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, float64(height), res.Result)
+	}
+	_, err = strict.Call(ctx, "getblockcount", []interface{}{0})
+	assert.True(t, errors.Is(err, ErrCassetteMiss))
+	bodies, err := strict.CallBatch(ctx, []BatchRequest{
+		{Method: "getpdestate", Params: []interface{}{map[string]int{"A": 1, "BeaconHeight": 10}}},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Id":0,"Result":1,"Error":null}`, string(bodies[0]))
+	_, err = strict.Call(ctx, "getpdestate", []interface{}{map[string]int{"BeaconHeight": 11}})
+	assert.True(t, errors.Is(err, ErrCassetteMiss))
+
+	lenient, err := NewCassette(nil, CassetteConfig{Path: path, Mode: CassetteReplay, Lenient: true})
+	assert.NoError(t, err)
+	body, err := lenient.Call(ctx, "getpdestate", []interface{}{map[string]int{"BeaconHeight": 11}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Id":1,"Result":1,"Error":null}`, string(body))
+	for i := 0; i < 3; i++ {
+		_, err = lenient.Call(ctx, "getblockcount", []interface{}{0})
+		assert.NoError(t, err)
+	}
+	_, err = lenient.Call(ctx, "getbeaconbeststatedetail", []interface{}{})
+	assert.True(t, errors.Is(err, ErrCassetteMiss))
+	assert.Equal(t, 2, roundTrips)
+}
+
+func TestCassetteNormalize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	// SNDs are random, blank them out so a replayed transaction matches the recorded one
+	config := CassetteConfig{Path: path, Mode: CassetteRecord, Normalize: func(method string, params interface{}) interface{} {
+		if method == "hassnderivators" {
+			return nil
+		}
+		return params
+	}}
+	recorder, err := NewCassette(&stubTransport{body: `{"Result":[false]}`}, config)
+	assert.NoError(t, err)
+	_, err = recorder.Call(context.Background(), "hassnderivators", []interface{}{"address", []string{"snd1"}})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Save())
+
+	config.Mode = CassetteReplay
+	player, err := NewCassette(nil, config)
+	assert.NoError(t, err)
+	body, err := player.Call(context.Background(), "hassnderivators", []interface{}{"address", []string{"snd2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Result":[false]}`, string(body))
+}
+
+func TestCassetteRedactsKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	key, err := wallet.NewMasterKey([]byte("cassette redaction seed of 32 bytes"))
+	assert.NoError(t, err)
+	privateKey := key.Base58CheckSerialize(wallet.PriKeyType)
+	readonlyKey := key.Base58CheckSerialize(wallet.ReadonlyKeyType)
+	paymentAddress := key.Base58CheckSerialize(wallet.PaymentAddressType)
+
+	config := CassetteConfig{Path: path, Mode: CassetteRecord}
+	recorder, err := NewCassette(&stubTransport{body: `{"Result":5}`}, config)
+	assert.NoError(t, err)
+	_, err = recorder.Call(context.Background(), "estimatefeewithestimator", []interface{}{-1, paymentAddress, 8, map[string]interface{}{"PrivateKey": privateKey}})
+	assert.NoError(t, err)
+	_, err = recorder.Call(context.Background(), "listoutputcoins", []interface{}{[]interface{}{map[string]string{"PaymentAddress": paymentAddress, "ReadonlyKey": readonlyKey}}})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Save())
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), privateKey)
+	assert.NotContains(t, string(data), readonlyKey)
+	assert.Contains(t, string(data), paymentAddress)
+	assert.Contains(t, string(data), RedactedKey)
+
+	// the calls of a replay are redacted the same way, so they match
+	config.Mode = CassetteReplay
+	player, err := NewCassette(nil, config)
+	assert.NoError(t, err)
+	body, err := player.Call(context.Background(), "estimatefeewithestimator", []interface{}{-1, paymentAddress, 8, map[string]interface{}{"PrivateKey": privateKey}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Result":5}`, string(body))
+
+	config = CassetteConfig{Path: path, Mode: CassetteRecord, RecordKeys: true}
+	recorder, err = NewCassette(&stubTransport{body: `{"Result":5}`}, config)
+	assert.NoError(t, err)
+	_, err = recorder.Call(context.Background(), "createandsendtransaction", []interface{}{privateKey})
+	assert.NoError(t, err)
+	assert.Contains(t, string(recorder.Interactions()[0].Params), privateKey)
+}
@@ -1,7 +1,9 @@
 package rpcclient
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/common/base58"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
@@ -10,6 +12,10 @@ import (
 )
 
 func GetEstimateFeeWithEstimator(rpcClient *HttpClient, defaultFee int64, paymentAddrSerialize string, tokenIDStr *common.Hash) (uint64, error) {
+	return GetEstimateFeeWithEstimatorWithContext(context.Background(), rpcClient, defaultFee, paymentAddrSerialize, tokenIDStr)
+}
+
+func GetEstimateFeeWithEstimatorWithContext(ctx context.Context, rpcClient *HttpClient, defaultFee int64, paymentAddrSerialize string, tokenIDStr *common.Hash) (uint64, error) {
 	var estimateFees EstimateFeeRes
 
 	params := []interface{}{
@@ -19,13 +25,13 @@ func GetEstimateFeeWithEstimator(rpcClient *HttpClient, defaultFee int64, paymen
 		tokenIDStr,
 	}
 
-	err := rpcClient.RPCCall("estimatefeewithestimator", params, &estimateFees)
+	err := rpcClient.RPCCallWithContext(ctx, "estimatefeewithestimator", params, &estimateFees)
 	if err != nil {
 		return 0, err
 	}
 
 	if estimateFees.RPCError != nil {
-		return 0, errors.New(estimateFees.RPCError.StackTrace)
+		return 0, fmt.Errorf("estimatefeewithestimator: %w", estimateFees.RPCError)
 	}
 
 	return estimateFees.Result.EstimateFeeCoinPerKb, nil
@@ -33,38 +39,102 @@ func GetEstimateFeeWithEstimator(rpcClient *HttpClient, defaultFee int64, paymen
 
 // GetUnspentOutputCoins return utxos of an account
 func GetUnspentOutputCoins(rpcClient *HttpClient, keyWallet *wallet.KeyWallet, tokenId *common.Hash) ([]*privacy.OutputCoin, error) {
-	privateKey := &keyWallet.KeySet.PrivateKey
-	paymentAddressStr := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
-	viewingKeyStr := keyWallet.Base58CheckSerialize(wallet.ReadonlyKeyType)
+	return GetUnspentOutputCoinsWithContext(context.Background(), rpcClient, keyWallet, tokenId)
+}
 
-	outputCoins, err := getListOutputCoins(rpcClient, paymentAddressStr, viewingKeyStr, tokenId)
+// GetUnspentOutputCoinsWithContext is GetUnspentOutputCoins bound to ctx
+func GetUnspentOutputCoinsWithContext(ctx context.Context, rpcClient *HttpClient, keyWallet *wallet.KeyWallet, tokenId *common.Hash) ([]*privacy.OutputCoin, error) {
+	utxos, err := GetUnspentOutputCoinsOfTokensWithContext(ctx, rpcClient, keyWallet, []*common.Hash{tokenId})
 	if err != nil {
 		return nil, err
 	}
+	return utxos[0], nil
+}
 
-	serialNumbers, err := deriveSerialNumbers(privateKey, outputCoins)
-	if err != nil {
+// GetUnspentOutputCoinsOfTokensWithContext return utxos of an account for every token of tokenIds, in the same order.
+// It takes two round trips whatever the number of tokens: one batch of listoutputcoins, one batch of hasserialnumbers.
+func GetUnspentOutputCoinsOfTokensWithContext(ctx context.Context, rpcClient *HttpClient, keyWallet *wallet.KeyWallet, tokenIds []*common.Hash) ([][]*privacy.OutputCoin, error) {
+	privateKey := &keyWallet.KeySet.PrivateKey
+	paymentAddressStr := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	viewingKeyStr := keyWallet.Base58CheckSerialize(wallet.ReadonlyKeyType)
+
+	outputCoinsRes := make([]ListOutputCoinsRes, len(tokenIds))
+	calls := make([]*BatchCall, len(tokenIds))
+	for i, tokenId := range tokenIds {
+		calls[i] = &BatchCall{
+			Method:      "listoutputcoins",
+			Params:      listOutputCoinsParams(paymentAddressStr, viewingKeyStr, tokenId),
+			RPCResponse: &outputCoinsRes[i],
+		}
+	}
+	if err := rpcClient.RPCBatchCallWithContext(ctx, calls); err != nil {
 		return nil, err
 	}
 
-	isExisted, err := checkExistenceSerialNumber(rpcClient, paymentAddressStr, serialNumbers, tokenId)
-	if err != nil {
+	outputCoins := make([][]*privacy.OutputCoin, len(tokenIds))
+	hasSerialNumberRes := make([]HasSerialNumberRes, len(tokenIds))
+	for i, tokenId := range tokenIds {
+		if outputCoinsRes[i].RPCError != nil {
+			return nil, fmt.Errorf("listoutputcoins: %w", outputCoinsRes[i].RPCError)
+		}
+
+		var err error
+		outputCoins[i], err = NewOutputCoinsFromResponse(outputCoinsRes[i].Result.Outputs[viewingKeyStr])
+		if err != nil {
+			return nil, err
+		}
+
+		serialNumbers, err := deriveSerialNumbers(privateKey, outputCoins[i])
+		if err != nil {
+			return nil, err
+		}
+
+		calls[i] = &BatchCall{
+			Method:      "hasserialnumbers",
+			Params:      hasSerialNumbersParams(paymentAddressStr, serialNumbers, tokenId),
+			RPCResponse: &hasSerialNumberRes[i],
+		}
+	}
+	if err := rpcClient.RPCBatchCallWithContext(ctx, calls); err != nil {
 		return nil, err
 	}
 
-	utxos := make([]*privacy.OutputCoin, 0)
-	for i, out := range outputCoins {
-		if !isExisted[i] {
-			utxos = append(utxos, out)
+	utxos := make([][]*privacy.OutputCoin, len(tokenIds))
+	for i := range tokenIds {
+		if hasSerialNumberRes[i].RPCError != nil {
+			return nil, fmt.Errorf("hasserialnumbers: %w", hasSerialNumberRes[i].RPCError)
+		}
+		isExisted := hasSerialNumberRes[i].Result
+		if len(isExisted) != len(outputCoins[i]) {
+			return nil, errors.New("hasserialnumbers returned a result of unexpected length")
+		}
+
+		utxos[i] = make([]*privacy.OutputCoin, 0)
+		for j, out := range outputCoins[i] {
+			if !isExisted[j] {
+				utxos[i] = append(utxos[i], out)
+			}
 		}
 	}
 
 	return utxos, nil
 }
 
-// GetListOutputCoins calls Incognito RPC to get all output coins of the account
-func getListOutputCoins(rpcClient *HttpClient, paymentAddress string, viewingKey string, tokenId *common.Hash) ([]*privacy.OutputCoin, error) {
+// ListOutputCoinsWithContext returns every output coin of the account given by its payment address and readonly key,
+// spent or not: telling spent coins apart needs the private key. It is the listing of watch-only accounts.
+func ListOutputCoinsWithContext(ctx context.Context, rpcClient *HttpClient, paymentAddress string, readonlyKey string, tokenId *common.Hash) ([]*privacy.OutputCoin, error) {
 	var outputCoinsRes ListOutputCoinsRes
+	if err := rpcClient.RPCCallWithContext(ctx, "listoutputcoins", listOutputCoinsParams(paymentAddress, readonlyKey, tokenId), &outputCoinsRes); err != nil {
+		return nil, err
+	}
+	if outputCoinsRes.RPCError != nil {
+		return nil, fmt.Errorf("listoutputcoins: %w", outputCoinsRes.RPCError)
+	}
+	return NewOutputCoinsFromResponse(outputCoinsRes.Result.Outputs[readonlyKey])
+}
+
+// listOutputCoinsParams builds the params of listoutputcoins for all output coins of the account
+func listOutputCoinsParams(paymentAddress string, viewingKey string, tokenId *common.Hash) []interface{} {
 	params := []interface{}{
 		0,
 		999999,
@@ -79,21 +149,7 @@ func getListOutputCoins(rpcClient *HttpClient, paymentAddress string, viewingKey
 	if len(tokenId.String()) > 0 {
 		params = append(params, tokenId.String())
 	}
-
-	err := rpcClient.RPCCall("listoutputcoins", params, &outputCoinsRes)
-	if err != nil {
-		return nil, err
-	}
-
-	if outputCoinsRes.RPCError != nil {
-		return nil, errors.New(outputCoinsRes.RPCError.StackTrace)
-	}
-
-	outputCoins, err := newOutputCoinsFromResponse(outputCoinsRes.Result.Outputs[viewingKey])
-	if err != nil {
-		return nil, err
-	}
-	return outputCoins, nil
+	return params
 }
 
 func deriveSerialNumbers(privateKey *privacy.PrivateKey, outputCoins []*privacy.OutputCoin) ([]*privacy.Point, error) {
@@ -110,35 +166,23 @@ func deriveSerialNumbers(privateKey *privacy.PrivateKey, outputCoins []*privacy.
 	return serialNumbers, nil
 }
 
-// CheckExistenceSerialNumber calls Incognito RPC to check existence serial number on network
-// to check output coins is spent or unspent
-func checkExistenceSerialNumber(rpcClient *HttpClient, paymentAddressStr string, sns []*privacy.Point, tokenId *common.Hash) ([]bool, error) {
-	var hasSerialNumberRes HasSerialNumberRes
-	result := make([]bool, 0)
+// hasSerialNumbersParams builds the params of hasserialnumbers,
+// checking existence of serial numbers on network tells whether output coins are spent
+func hasSerialNumbersParams(paymentAddressStr string, sns []*privacy.Point, tokenId *common.Hash) []interface{} {
 	snStrs := make([]interface{}, len(sns))
 	for i, sn := range sns {
 		snStrs[i] = base58.Base58Check{}.Encode(sn.ToBytesS(), common.Base58Version)
 	}
 
-	params := []interface{}{
+	return []interface{}{
 		paymentAddressStr,
 		snStrs,
 		tokenId.String(),
 	}
-	err := rpcClient.RPCCall("hasserialnumbers", params, &hasSerialNumberRes)
-	if err != nil {
-		return nil, err
-	}
-
-	if hasSerialNumberRes.RPCError != nil {
-		return nil, errors.New(hasSerialNumberRes.RPCError.StackTrace)
-	}
-
-	result = hasSerialNumberRes.Result
-	return result, nil
 }
 
-func newOutputCoinsFromResponse(outCoins []OutCoin) ([]*privacy.OutputCoin, error) {
+// NewOutputCoinsFromResponse decodes the coins answered by listoutputcoins, it is the inverse of NewOutCoin
+func NewOutputCoinsFromResponse(outCoins []OutCoin) ([]*privacy.OutputCoin, error) {
 	outputCoins := make([]*privacy.OutputCoin, len(outCoins))
 	for i, outCoin := range outCoins {
 		outputCoins[i] = new(privacy.OutputCoin).Init()
@@ -160,13 +204,18 @@ func newOutputCoinsFromResponse(outCoins []OutCoin) ([]*privacy.OutputCoin, erro
 
 		value, _ := strconv.Atoi(outCoin.Value)
 		outputCoins[i].CoinDetails.SetValue(uint64(value))
+
+		if outCoin.Info != "" {
+			info, _, _ := base58.Base58Check{}.Decode(outCoin.Info)
+			outputCoins[i].CoinDetails.SetInfo(info)
+		}
 	}
 
 	return outputCoins, nil
 }
 
-
-func newOutCoin(outCoin *privacy.OutputCoin) OutCoin {
+// NewOutCoin encodes outCoin the way the node answers and expects coins
+func NewOutCoin(outCoin *privacy.OutputCoin) OutCoin {
 	serialNumber := ""
 
 	if outCoin.CoinDetails.GetSerialNumber() != nil && !outCoin.CoinDetails.GetSerialNumber().IsIdentity() {
@@ -194,11 +243,15 @@ func newOutCoin(outCoin *privacy.OutputCoin) OutCoin {
 }
 
 func RandomCommitmentsProcess(rpcClient *HttpClient, outputCoins []*privacy.OutputCoin, paymentAddrStr string, tokenID *common.Hash) ([]uint64, []uint64, []string, error) {
+	return RandomCommitmentsProcessWithContext(context.Background(), rpcClient, outputCoins, paymentAddrStr, tokenID)
+}
+
+func RandomCommitmentsProcessWithContext(ctx context.Context, rpcClient *HttpClient, outputCoins []*privacy.OutputCoin, paymentAddrStr string, tokenID *common.Hash) ([]uint64, []uint64, []string, error) {
 	var randomCommitmentRes RandomCommitmentRes
 
 	item := make([]OutCoin, 0)
 	for _, outCoin := range outputCoins {
-		item = append(item, newOutCoin(outCoin))
+		item = append(item, NewOutCoin(outCoin))
 	}
 
 	params := []interface{}{
@@ -207,19 +260,45 @@ func RandomCommitmentsProcess(rpcClient *HttpClient, outputCoins []*privacy.Outp
 		tokenID.String(),
 	}
 
-	err := rpcClient.RPCCall("randomcommitments", params, &randomCommitmentRes)
+	err := rpcClient.RPCCallWithContext(ctx, "randomcommitments", params, &randomCommitmentRes)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	if randomCommitmentRes.RPCError != nil {
-		return nil, nil, nil, errors.New(randomCommitmentRes.RPCError.StackTrace)
+		return nil, nil, nil, fmt.Errorf("randomcommitments: %w", randomCommitmentRes.RPCError)
 	}
 
 	return randomCommitmentRes.Result.CommitmentIndices, randomCommitmentRes.Result.MyCommitmentIndexs, randomCommitmentRes.Result.Commitments, nil
 }
 
+func GetTransactionByHash(rpcClient *HttpClient, txHash string) (*TransactionDetail, error) {
+	return GetTransactionByHashWithContext(context.Background(), rpcClient, txHash)
+}
+
+// GetTransactionByHashWithContext fetches the tx txHash from the mempool or the chain, decode it with transaction.DecodeTxDetail
+func GetTransactionByHashWithContext(ctx context.Context, rpcClient *HttpClient, txHash string) (*TransactionDetail, error) {
+	var res TransactionDetailRes
+	err := rpcClient.RPCCallWithContext(ctx, "gettransactionbyhash", []interface{}{txHash}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.RPCError != nil {
+		return nil, fmt.Errorf("gettransactionbyhash: %w", res.RPCError)
+	}
+	if res.Result == nil {
+		return nil, fmt.Errorf("gettransactionbyhash: tx %v not found", txHash)
+	}
+
+	return res.Result, nil
+}
+
 func CheckSNDerivatorExistence(rpcClient *HttpClient, paymentAddressStr string, sndOut []*privacy.Scalar) ([]bool, error) {
+	return CheckSNDerivatorExistenceWithContext(context.Background(), rpcClient, paymentAddressStr, sndOut)
+}
+
+func CheckSNDerivatorExistenceWithContext(ctx context.Context, rpcClient *HttpClient, paymentAddressStr string, sndOut []*privacy.Scalar) ([]bool, error) {
 	var hasSNDerivatorRes HasSNDerivatorRes
 	sndStrs := make([]interface{}, len(sndOut))
 	for i, sn := range sndOut {
@@ -229,14 +308,63 @@ func CheckSNDerivatorExistence(rpcClient *HttpClient, paymentAddressStr string,
 		paymentAddressStr,
 		sndStrs,
 	}
-	err := rpcClient.RPCCall("hassnderivators", params, &hasSNDerivatorRes)
+	err := rpcClient.RPCCallWithContext(ctx, "hassnderivators", params, &hasSNDerivatorRes)
 	if err != nil {
 		return nil, err
 	}
 
 	if hasSNDerivatorRes.RPCError != nil {
-		return nil, errors.New(hasSNDerivatorRes.RPCError.StackTrace)
+		return nil, fmt.Errorf("hassnderivators: %w", hasSNDerivatorRes.RPCError)
 	}
 
 	return hasSNDerivatorRes.Result, nil
-}
\ No newline at end of file
+}
+
+// CheckSNDerivatorsExistenceWithContext checks sndOut[i] on the shard of paymentAddressStrs[i] for every i.
+// The checks are grouped by payment address and sent as one JSON-RPC batch.
+func CheckSNDerivatorsExistenceWithContext(ctx context.Context, rpcClient *HttpClient, paymentAddressStrs []string, sndOut []*privacy.Scalar) ([]bool, error) {
+	if len(paymentAddressStrs) != len(sndOut) {
+		return nil, errors.New("every SND needs a payment address")
+	}
+
+	indexes := make(map[string][]int)
+	addresses := make([]string, 0)
+	for i, paymentAddressStr := range paymentAddressStrs {
+		if _, ok := indexes[paymentAddressStr]; !ok {
+			addresses = append(addresses, paymentAddressStr)
+		}
+		indexes[paymentAddressStr] = append(indexes[paymentAddressStr], i)
+	}
+
+	hasSNDerivatorRes := make([]HasSNDerivatorRes, len(addresses))
+	calls := make([]*BatchCall, len(addresses))
+	for i, paymentAddressStr := range addresses {
+		sndStrs := make([]interface{}, len(indexes[paymentAddressStr]))
+		for j, index := range indexes[paymentAddressStr] {
+			sndStrs[j] = base58.Base58Check{}.Encode(sndOut[index].ToBytesS(), common.Base58Version)
+		}
+		calls[i] = &BatchCall{
+			Method:      "hassnderivators",
+			Params:      []interface{}{paymentAddressStr, sndStrs},
+			RPCResponse: &hasSNDerivatorRes[i],
+		}
+	}
+	if err := rpcClient.RPCBatchCallWithContext(ctx, calls); err != nil {
+		return nil, err
+	}
+
+	result := make([]bool, len(sndOut))
+	for i, paymentAddressStr := range addresses {
+		if hasSNDerivatorRes[i].RPCError != nil {
+			return nil, fmt.Errorf("hassnderivators: %w", hasSNDerivatorRes[i].RPCError)
+		}
+		if len(hasSNDerivatorRes[i].Result) != len(indexes[paymentAddressStr]) {
+			return nil, errors.New("hassnderivators returned a result of unexpected length")
+		}
+		for j, index := range indexes[paymentAddressStr] {
+			result[index] = hasSNDerivatorRes[i].Result[j]
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors of the common reasons a call or a transaction fails, match them with errors.Is.
+// They are returned, wrapped, both when the node rejects a call (see RPCError.Reason) and when the
+// SDK refuses to build a transaction for the same reason.
+var (
+	ErrNotEnoughCoin   = errors.New("not enough coin")
+	ErrDoubleSpend     = errors.New("double spend, serial number already exists")
+	ErrTxTooLarge      = errors.New("transaction too large")
+	ErrNodeUnreachable = errors.New("node unreachable")
+)
+
+// rejections maps parts of the message and stack trace answered by the fullnode to the reason they report.
+// The node wraps mempool and transaction errors under a generic code, so the text is what tells them apart.
+var rejections = []struct {
+	reason error
+	texts  []string
+}{
+	{ErrDoubleSpend, []string{"double spend", "doublespend", "serial number exist", "serialnumber exist"}},
+	{ErrTxTooLarge, []string{"invalid size", "size overload", "too large", "very larger", "exceed max size of tx"}},
+	{ErrNotEnoughCoin, []string{"not enough", "insufficient", "less than output value"}},
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Reason returns the sentinel error of the rejection the node reported, nil when it is none of them
+func (e *RPCError) Reason() error {
+	text := strings.ToLower(e.Message + " " + e.StackTrace)
+	for _, rejection := range rejections {
+		for _, t := range rejection.texts {
+			if strings.Contains(text, t) {
+				return rejection.reason
+			}
+		}
+	}
+	return nil
+}
+
+// Is makes errors.Is(err, ErrDoubleSpend) and the like hold for a node error reporting that reason
+func (e *RPCError) Is(target error) bool {
+	reason := e.Reason()
+	return reason != nil && reason == target
+}
+
+// UnreachableError is returned when a request could not reach Endpoint or got no answer from it
+type UnreachableError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *UnreachableError) Error() string {
+	return fmt.Sprintf("node %s unreachable: %v", e.Endpoint, e.Err)
+}
+
+func (e *UnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrNodeUnreachable) hold
+func (e *UnreachableError) Is(target error) bool {
+	return target == ErrNodeUnreachable
+}
+
+// unreachable wraps err of a request to endpoint, unless it failed because ctx is done
+func unreachable(ctx context.Context, endpoint string, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return &UnreachableError{Endpoint: endpoint, Err: err}
+}
@@ -0,0 +1,54 @@
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCErrorReason(t *testing.T) {
+	cases := []struct {
+		err    *RPCError
+		reason error
+	}{
+		{&RPCError{Code: -1015, Message: "Can not send tx", StackTrace: "Reject tx double spend with blockchain"}, ErrDoubleSpend},
+		{&RPCError{Code: -1015, Message: "Can not send tx", StackTrace: "serial number existed in db"}, ErrDoubleSpend},
+		{&RPCError{Code: -1015, Message: "Reject invalid size"}, ErrTxTooLarge},
+		{&RPCError{Code: -1014, Message: "Can not create tx", StackTrace: "Not enough coin"}, ErrNotEnoughCoin},
+		{&RPCError{Code: -1002, Message: "Method not found"}, nil},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.reason, c.err.Reason(), c.err.Error())
+
+		wrapped := fmt.Errorf("sendtransaction: %w", c.err)
+		for _, sentinel := range []error{ErrDoubleSpend, ErrTxTooLarge, ErrNotEnoughCoin} {
+			assert.Equal(t, sentinel == c.reason, errors.Is(wrapped, sentinel))
+		}
+
+		var rpcErr *RPCError
+		assert.True(t, errors.As(wrapped, &rpcErr))
+		assert.Equal(t, c.err.Code, rpcErr.Code)
+	}
+}
+
+func TestHTTPTransportUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := server.URL
+	server.Close()
+
+	_, err := NewHTTPTransport(nil, endpoint).Call(context.Background(), "getblockcount", []interface{}{-1})
+	assert.True(t, errors.Is(err, ErrNodeUnreachable))
+	assert.True(t, isDialError(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewHTTPTransport(nil, endpoint).Call(ctx, "getblockcount", []interface{}{-1})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, ErrNodeUnreachable))
+}
@@ -0,0 +1,322 @@
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthCheckMethod is the cheap call used to probe an endpoint, it returns the beacon height
+const HealthCheckMethod = "getblockcount"
+
+// FailoverConfig tunes retries of a FailoverTransport, zero values take the defaults
+type FailoverConfig struct {
+	// MaxAttempts is how many rounds over the endpoint list a read call makes, default 3
+	MaxAttempts int
+	// BaseBackoff is the wait before the second round, doubled for every next round, default 200ms
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between rounds, default 5s
+	MaxBackoff time.Duration
+	// Cooldown is how long a failed endpoint is tried only after the healthy ones, default 30s
+	Cooldown time.Duration
+	// Idempotent reports whether method is safe to send twice, default IsIdempotentMethod
+	Idempotent func(method string) bool
+}
+
+func (config FailoverConfig) withDefaults() FailoverConfig {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = 200 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Second
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.Idempotent == nil {
+		config.Idempotent = IsIdempotentMethod
+	}
+	return config
+}
+
+// idempotentMethodPrefixes are the prefixes of the read methods the SDK calls
+var idempotentMethodPrefixes = []string{"get", "list", "has", "estimate", "retrieve"}
+
+// idempotentMethods are the read methods the SDK calls whose name has none of idempotentMethodPrefixes
+var idempotentMethods = map[string]bool{
+	"randomcommitments":                   true,
+	"decryptoutputcoinbykeyoftransaction": true,
+}
+
+// IsIdempotentMethod reports whether method is one of the read methods the SDK calls.
+// Any other method, such as one creating or sending a transaction, must not reach two nodes.
+func IsIdempotentMethod(method string) bool {
+	method = strings.ToLower(method)
+	if idempotentMethods[method] {
+		return true
+	}
+	for _, prefix := range idempotentMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointStatus is the health of one endpoint of a FailoverTransport
+type EndpointStatus struct {
+	Name      string
+	Healthy   bool
+	LastError error
+}
+
+type failoverEndpoint struct {
+	name      string
+	transport Transport
+	downUntil time.Time
+	lastError error
+}
+
+// FailoverTransport sends calls to an ordered list of endpoints.
+// Read calls go to the first healthy endpoint, fail over to the next ones and retry
+// every round with jittered exponential backoff. Non idempotent calls only fail over
+// when the request could not be sent at all, they are never sent twice.
+type FailoverTransport struct {
+	config    FailoverConfig
+	endpoints []*failoverEndpoint
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewFailoverTransport to get a transport failing over between transports, in order of preference
+func NewFailoverTransport(config FailoverConfig, transports ...Transport) *FailoverTransport {
+	endpoints := make([]*failoverEndpoint, len(transports))
+	for i, transport := range transports {
+		name := fmt.Sprintf("endpoint #%d", i)
+		if httpTransport, ok := transport.(*HTTPTransport); ok {
+			name = httpTransport.Endpoint
+		}
+		endpoints[i] = &failoverEndpoint{name: name, transport: transport}
+	}
+
+	return &FailoverTransport{
+		config:    config.withDefaults(),
+		endpoints: endpoints,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// NewHTTPFailoverTransport to get a FailoverTransport posting to endpoints with client
+func NewHTTPFailoverTransport(client *http.Client, endpoints []string, config FailoverConfig) *FailoverTransport {
+	transports := make([]Transport, len(endpoints))
+	for i, endpoint := range endpoints {
+		transports[i] = NewHTTPTransport(client, endpoint)
+	}
+	return NewFailoverTransport(config, transports...)
+}
+
+func (t *FailoverTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	bodies, err := t.do(ctx, t.config.Idempotent(method), method, func(transport Transport) ([][]byte, error) {
+		body, err := transport.Call(ctx, method, params)
+		return [][]byte{body}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bodies[0], nil
+}
+
+// CallBatch sends the batch like Call, it is only retried when every call of it is idempotent
+func (t *FailoverTransport) CallBatch(ctx context.Context, reqs []BatchRequest) ([][]byte, error) {
+	idempotent := true
+	for _, req := range reqs {
+		idempotent = idempotent && t.config.Idempotent(req.Method)
+	}
+
+	return t.do(ctx, idempotent, fmt.Sprintf("batch of %d calls", len(reqs)), func(transport Transport) ([][]byte, error) {
+		return CallBatch(ctx, transport, reqs)
+	})
+}
+
+func (t *FailoverTransport) do(ctx context.Context, idempotent bool, name string, send func(Transport) ([][]byte, error)) ([][]byte, error) {
+	if len(t.endpoints) == 0 {
+		return nil, fmt.Errorf("failover transport has no endpoint: %w", ErrNodeUnreachable)
+	}
+
+	if !idempotent {
+		return t.sendOnce(ctx, name, send)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < t.config.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := t.sleep(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, endpoint := range t.ordered() {
+			bodies, err := t.send(ctx, endpoint, send)
+			if err == nil {
+				return bodies, nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+		}
+	}
+
+	return nil, fmt.Errorf("%s failed on all %d endpoints after %d attempts: %w", name, len(t.endpoints), t.config.MaxAttempts, lastErr)
+}
+
+// sendOnce moves to the next endpoint only while the request has not left the process
+func (t *FailoverTransport) sendOnce(ctx context.Context, name string, send func(Transport) ([][]byte, error)) ([][]byte, error) {
+	var lastErr error
+	for _, endpoint := range t.ordered() {
+		bodies, err := t.send(ctx, endpoint, send)
+		if err == nil {
+			return bodies, nil
+		}
+		if ctx.Err() != nil || !isDialError(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("%s could not reach any of %d endpoints: %w", name, len(t.endpoints), lastErr)
+}
+
+func (t *FailoverTransport) send(ctx context.Context, endpoint *failoverEndpoint, send func(Transport) ([][]byte, error)) ([][]byte, error) {
+	bodies, err := send(endpoint.transport)
+	for i := 0; err == nil && i < len(bodies); i++ {
+		if !json.Valid(bodies[i]) {
+			err = fmt.Errorf("%s returned a non JSON response", endpoint.name)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			endpoint.downUntil = time.Now().Add(t.config.Cooldown)
+			endpoint.lastError = err
+		}
+		return nil, err
+	}
+	endpoint.downUntil = time.Time{}
+	endpoint.lastError = nil
+	return bodies, nil
+}
+
+// ordered returns the healthy endpoints first, then the ones cooling down, both in configured order
+func (t *FailoverTransport) ordered() []*failoverEndpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*failoverEndpoint, 0, len(t.endpoints))
+	down := make([]*failoverEndpoint, 0)
+	for _, endpoint := range t.endpoints {
+		if now.Before(endpoint.downUntil) {
+			down = append(down, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// backoff returns base * 2^(attempt-1) capped at MaxBackoff, with the upper half jittered
+func (t *FailoverTransport) backoff(attempt int) time.Duration {
+	d := t.config.BaseBackoff
+	for i := 1; i < attempt && d < t.config.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > t.config.MaxBackoff {
+		d = t.config.MaxBackoff
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return d/2 + time.Duration(t.rand.Int63n(int64(d/2)+1))
+}
+
+func (t *FailoverTransport) sleep(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(t.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// CheckHealth probes every endpoint once with HealthCheckMethod
+func (t *FailoverTransport) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range t.endpoints {
+		wg.Add(1)
+		go func(endpoint *failoverEndpoint) {
+			defer wg.Done()
+			t.send(ctx, endpoint, func(transport Transport) ([][]byte, error) {
+				body, err := transport.Call(ctx, HealthCheckMethod, []interface{}{-1})
+				return [][]byte{body}, err
+			})
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// StartHealthCheck probes the endpoints every interval until ctx is done
+func (t *FailoverTransport) StartHealthCheck(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			t.CheckHealth(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Status returns the health of every endpoint, in configured order
+func (t *FailoverTransport) Status() []EndpointStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	status := make([]EndpointStatus, len(t.endpoints))
+	for i, endpoint := range t.endpoints {
+		status[i] = EndpointStatus{
+			Name:      endpoint.name,
+			Healthy:   !now.Before(endpoint.downUntil),
+			LastError: endpoint.lastError,
+		}
+	}
+	return status
+}
+
+// isDialError reports whether err happened before the request was written to the connection
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,104 @@
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubTransport struct {
+	calls int
+	body  string
+	err   error
+}
+
+func (s *stubTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []byte(s.body), nil
+}
+
+func TestFailoverTransportReadFailsOver(t *testing.T) {
+	down := &stubTransport{err: errors.New("connection reset")}
+	up := &stubTransport{body: `{"Result":1}`}
+	transport := NewFailoverTransport(FailoverConfig{}, down, up)
+
+	body, err := transport.Call(context.Background(), "listoutputcoins", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Result":1}`, string(body))
+	assert.Equal(t, 1, down.calls)
+
+	// the failed endpoint is cooling down, the healthy one is tried first
+	_, err = transport.Call(context.Background(), "hasserialnumbers", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, down.calls)
+	assert.Equal(t, 2, up.calls)
+
+	status := transport.Status()
+	assert.False(t, status[0].Healthy)
+	assert.True(t, status[1].Healthy)
+}
+
+func TestFailoverTransportReadRetries(t *testing.T) {
+	down := &stubTransport{body: "<html>502 Bad Gateway</html>"}
+	transport := NewFailoverTransport(FailoverConfig{MaxAttempts: 3, BaseBackoff: time.Millisecond}, down)
+
+	_, err := transport.Call(context.Background(), "randomcommitments", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 3, down.calls)
+}
+
+func TestFailoverTransportSendIsNotRepeated(t *testing.T) {
+	first := &stubTransport{err: errors.New("unexpected EOF")}
+	second := &stubTransport{body: `{"Result":{}}`}
+	transport := NewFailoverTransport(FailoverConfig{BaseBackoff: time.Millisecond}, first, second)
+
+	_, err := transport.Call(context.Background(), "sendtransaction", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 0, second.calls)
+}
+
+func TestFailoverTransportSendFailsOverUnreachable(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Result":{"TxID":"abc"}}`))
+	}))
+	defer up.Close()
+
+	transport := NewHTTPFailoverTransport(nil, []string{closed.URL, up.URL}, FailoverConfig{})
+	body, err := transport.Call(context.Background(), "sendtransaction", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Result":{"TxID":"abc"}}`, string(body))
+}
+
+func TestFailoverTransportCheckHealth(t *testing.T) {
+	flaky := &stubTransport{err: errors.New("timeout")}
+	transport := NewFailoverTransport(FailoverConfig{}, flaky)
+
+	transport.CheckHealth(context.Background())
+	assert.False(t, transport.Status()[0].Healthy)
+
+	flaky.err = nil
+	flaky.body = `{"Result":10}`
+	transport.CheckHealth(context.Background())
+	assert.True(t, transport.Status()[0].Healthy)
+}
+
+func TestIsIdempotentMethod(t *testing.T) {
+	for _, method := range []string{"getbalancebyprivatekey", "listoutputcoins", "hasserialnumbers", "estimatefeewithestimator", "randomcommitments", "retrieveblockbyheight"} {
+		assert.True(t, IsIdempotentMethod(method), method)
+	}
+	// a method the SDK does not know is not retried
+	for _, method := range []string{"sendtransaction", "createandsendstakingtransaction", "withdrawreward", "newstatechangingmethod"} {
+		assert.False(t, IsIdempotentMethod(method), method)
+	}
+}
@@ -1,20 +1,20 @@
 package rpcclient
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"time"
 )
 
 type HttpClient struct {
 	*http.Client
-	url      string
-	protocol string
-	host     string
-	port     uint
+	url       string
+	protocol  string
+	host      string
+	port      uint
+	transport Transport
 }
 
 // NewHttpClient to get http client instance
@@ -23,14 +23,28 @@ func NewHttpClient(url string, protocol string, host string, port uint) *HttpCli
 		Timeout: time.Second * 60,
 	}
 	return &HttpClient{
-		Client:   httpClient,
-		url:      url,
-		protocol: protocol,
-		host:     host,
-		port:     port,
+		Client:    httpClient,
+		url:       url,
+		protocol:  protocol,
+		host:      host,
+		port:      port,
+		transport: NewHTTPTransport(httpClient, buildHttpServerAddress(url, protocol, host, port)),
 	}
 }
 
+// NewHttpClientWithTransport to get http client instance sending every call through transport
+func NewHttpClientWithTransport(transport Transport) *HttpClient {
+	return &HttpClient{
+		Client:    &http.Client{},
+		transport: transport,
+	}
+}
+
+// RPCTransport returns the transport calls are sent through
+func (client *HttpClient) RPCTransport() Transport {
+	return client.transport
+}
+
 func buildHttpServerAddress(url string, protocol string, host string, port uint) string {
 	if url != "" {
 		return url
@@ -43,30 +57,17 @@ func (client *HttpClient) RPCCall(
 	params interface{},
 	rpcResponse interface{},
 ) (err error) {
-	rpcEndpoint := buildHttpServerAddress(
-		client.url, client.protocol, client.host, client.port,
-	)
-
-	payload := map[string]interface{}{
-		"method": method,
-		"params": params,
-		"id":     0,
-	}
-	payloadInBytes, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	resp, err := client.Post(rpcEndpoint, "application/json", bytes.NewBuffer(payloadInBytes))
-
-	if err != nil {
-		return err
-	}
-
-	respBody := resp.Body
-	defer respBody.Close()
+	return client.RPCCallWithContext(context.Background(), method, params, rpcResponse)
+}
 
-	body, err := ioutil.ReadAll(respBody)
+// RPCCallWithContext is RPCCall bound to ctx, the request is aborted when ctx is done
+func (client *HttpClient) RPCCallWithContext(
+	ctx context.Context,
+	method string,
+	params interface{},
+	rpcResponse interface{},
+) (err error) {
+	body, err := client.transport.Call(ctx, method, params)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,47 @@
+package rpcclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCCallWithContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewHttpClient(server.URL, "", "", 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var res RPCBaseRes
+	start := time.Now()
+	err := client.RPCCallWithContext(ctx, "getblockchaininfo", []interface{}{}, &res)
+	assert.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestRPCCallWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id":1,"Result":null,"Error":null}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(server.URL, "", "", 0)
+	var res RPCBaseRes
+	err := client.RPCCallWithContext(context.Background(), "getblockchaininfo", []interface{}{}, &res)
+	assert.NoError(t, err)
+	assert.Nil(t, res.RPCError)
+}
@@ -44,4 +44,9 @@ type EstimateFeeRes struct {
 type RandomCommitmentRes struct {
 	RPCBaseRes
 	Result *RandomCommitmentResult
+}
+
+type TransactionDetailRes struct {
+	RPCBaseRes
+	Result *TransactionDetail
 }
\ No newline at end of file
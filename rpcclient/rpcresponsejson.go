@@ -1,5 +1,7 @@
 package rpcclient
 
+import "github.com/incognitochain/go-incognito-sdk/privacy/zkp"
+
 type ListOutputCoins struct {
 	Outputs map[string][]OutCoin `json:"Outputs"`
 }
@@ -40,4 +42,26 @@ type RandomCommitmentResult struct {
 	CommitmentIndices  []uint64 `json:"CommitmentIndices"`
 	MyCommitmentIndexs []uint64 `json:"MyCommitmentIndexs"`
 	Commitments        []string `json:"Commitments"`
-}
\ No newline at end of file
+}
+// TransactionDetail is the answer of gettransactionbyhash.
+// Metadata and PrivacyCustomTokenData hold JSON, SigPubKey and Sig are base58 check encoded.
+type TransactionDetail struct {
+	BlockHash              string            `json:"BlockHash"`
+	BlockHeight            uint64            `json:"BlockHeight"`
+	Index                  uint64            `json:"Index"`
+	ShardID                byte              `json:"ShardID"`
+	Hash                   string            `json:"Hash"`
+	Version                int8              `json:"Version"`
+	Type                   string            `json:"Type"`
+	LockTime               string            `json:"LockTime"`
+	Fee                    uint64            `json:"Fee"`
+	IsPrivacy              bool              `json:"IsPrivacy"`
+	Proof                  *zkp.PaymentProof `json:"Proof"`
+	SigPubKey              string            `json:"SigPubKey"`
+	Sig                    string            `json:"Sig"`
+	Metadata               string            `json:"Metadata"`
+	PrivacyCustomTokenData string            `json:"PrivacyCustomTokenData"`
+	IsInMempool            bool              `json:"IsInMempool"`
+	IsInBlock              bool              `json:"IsInBlock"`
+	Info                   string            `json:"Info"`
+}
@@ -0,0 +1,500 @@
+package rpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Subscription methods of the fullnode websocket server, the spelling is the node's
+const (
+	SubscribeNewShardBlock      = "subcribenewshardblock"
+	SubscribeNewBeaconBlock     = "subcribenewbeaconblock"
+	SubscribePendingTransaction = "subcribependingtransaction"
+)
+
+const (
+	subscriptionTypeSubscribe   byte = 0
+	subscriptionTypeUnsubscribe byte = 1
+)
+
+// ErrSubscriptionClientClosed is returned when subscribing on a closed SubscriptionClient
+var ErrSubscriptionClientClosed = errors.New("subscription client closed")
+
+// subscriptionRequest is the message the node websocket server expects, Subcription is the id of the stream
+type subscriptionRequest struct {
+	Request      *RPCRequest `json:"Request"`
+	Subscription string      `json:"Subcription"`
+	Type         byte        `json:"Type"`
+}
+
+type subscriptionResponse struct {
+	RPCBaseRes
+	Result struct {
+		Subscription string          `json:"Subscription"`
+		Result       json.RawMessage `json:"Result"`
+	} `json:"Result"`
+}
+
+// ShardBlockHeader is the header of a shard block as the node serializes it
+type ShardBlockHeader struct {
+	Producer          string            `json:"Producer"`
+	Version           int               `json:"Version"`
+	Height            uint64            `json:"Height"`
+	Epoch             uint64            `json:"Epoch"`
+	Round             int               `json:"Round"`
+	Timestamp         int64             `json:"Timestamp"`
+	PreviousBlockHash string            `json:"PreviousBlockHash"`
+	ShardID           byte              `json:"ShardID"`
+	BeaconHeight      uint64            `json:"BeaconHeight"`
+	BeaconHash        string            `json:"BeaconHash"`
+	TotalTxsFee       map[string]uint64 `json:"TotalTxsFee"`
+}
+
+// ShardBlockEvent is delivered for every new block of the subscribed shard
+type ShardBlockEvent struct {
+	Header ShardBlockHeader `json:"Header"`
+	// Raw is the whole block as answered by the node
+	Raw json.RawMessage `json:"-"`
+}
+
+// BeaconBlockHeader is the header of a beacon block as the node serializes it
+type BeaconBlockHeader struct {
+	Producer          string `json:"Producer"`
+	Version           int    `json:"Version"`
+	Height            uint64 `json:"Height"`
+	Epoch             uint64 `json:"Epoch"`
+	Round             int    `json:"Round"`
+	Timestamp         int64  `json:"Timestamp"`
+	PreviousBlockHash string `json:"PreviousBlockHash"`
+}
+
+// BeaconBlockEvent is delivered for every new beacon block
+type BeaconBlockEvent struct {
+	Header BeaconBlockHeader `json:"Header"`
+	// Raw is the whole block as answered by the node
+	Raw json.RawMessage `json:"-"`
+}
+
+// TxEvent is delivered when a subscribed pending transaction lands in a block
+type TxEvent struct {
+	Hash        string `json:"Hash"`
+	BlockHash   string `json:"BlockHash"`
+	BlockHeight uint64 `json:"BlockHeight"`
+	Index       uint64 `json:"Index"`
+	ShardID     byte   `json:"ShardID"`
+	Fee         uint64 `json:"Fee"`
+	IsInBlock   bool   `json:"IsInBlock"`
+	// Raw is the whole transaction detail as answered by the node
+	Raw json.RawMessage `json:"-"`
+}
+
+// SubscriptionConfig tunes a SubscriptionClient, only Endpoint is required
+type SubscriptionConfig struct {
+	// Endpoint is the websocket url of the fullnode, ws://host:port or wss://host/path
+	Endpoint string
+	// Header is added to the handshake request, e.g. for an auth token
+	Header http.Header
+	// TLSConfig is used for wss endpoints, see TLSConfig.TLSClientConfig
+	TLSConfig *tls.Config
+	// BaseBackoff is the wait before the second reconnect attempt, doubled for every next one, default 500ms
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between reconnect attempts, default 30s
+	MaxBackoff time.Duration
+	// PingInterval is how often the connection is pinged, it is dropped and redialed after
+	// two intervals without any frame from the node, default 30s
+	PingInterval time.Duration
+	// BufferSize is the capacity of every event channel, default 16
+	BufferSize int
+	// OnError, when set, is called with every connection error before reconnecting
+	OnError func(err error)
+}
+
+func (config SubscriptionConfig) withDefaults() SubscriptionConfig {
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = 30 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 16
+	}
+	return config
+}
+
+// SubscriptionClient streams new blocks and transactions from the websocket server of a fullnode.
+// It connects on the first subscription, and when the connection drops it redials with jittered
+// exponential backoff and subscribes every active Subscription again. Events of a subscription are
+// delivered in order on its channel; a consumer not keeping up with a full channel holds back the
+// events of every subscription of the client.
+type SubscriptionClient struct {
+	config SubscriptionConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	subs    map[string]*Subscription
+	nextID  uint64
+	conn    *wsConn
+	started bool
+}
+
+// NewSubscriptionClient returns a client for config.Endpoint, nothing is dialed before the first subscription
+func NewSubscriptionClient(config SubscriptionConfig) *SubscriptionClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SubscriptionClient{
+		config: config.withDefaults(),
+		ctx:    ctx,
+		cancel: cancel,
+		subs:   make(map[string]*Subscription),
+	}
+}
+
+// Subscription is an active stream of events, its channel is closed once it ends
+type Subscription struct {
+	client *SubscriptionClient
+	id     string
+	method string
+	params []interface{}
+
+	// deliver decodes a result and sends it on the typed channel, it is only called by the read loop
+	deliver func(result json.RawMessage) error
+	// closeEvents closes the typed channel
+	closeEvents func()
+
+	mu    sync.Mutex
+	done  chan struct{}
+	ended bool
+	err   error
+	// sendMu keeps the typed channel from being closed while an event is sent on it
+	sendMu sync.Mutex
+}
+
+// Unsubscribe stops the subscription and closes its channel
+func (s *Subscription) Unsubscribe() {
+	s.client.unsubscribe(s, nil)
+}
+
+// Done is closed once the subscription ended, its channel is closed by then too
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscription ended: nil while it is active or after Unsubscribe,
+// the error answered by the node, or ErrSubscriptionClientClosed
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) end(err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.err = err
+	close(s.done)
+	s.mu.Unlock()
+
+	// a send in progress returns as soon as done is closed
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.closeEvents()
+}
+
+// send delivers an event unless the subscription ended meanwhile
+func (s *Subscription) send(push func(done <-chan struct{})) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	push(s.done)
+}
+
+// SubscribeShardBlocks streams the new blocks of shardID
+func (c *SubscriptionClient) SubscribeShardBlocks(shardID byte) (<-chan ShardBlockEvent, *Subscription, error) {
+	events := make(chan ShardBlockEvent, c.config.BufferSize)
+	sub := c.newSubscription(SubscribeNewShardBlock, []interface{}{shardID})
+	sub.deliver = func(result json.RawMessage) error {
+		var event ShardBlockEvent
+		if err := json.Unmarshal(result, &event); err != nil {
+			return err
+		}
+		event.Raw = result
+		sub.send(func(done <-chan struct{}) {
+			select {
+			case events <- event:
+			case <-done:
+			}
+		})
+		return nil
+	}
+	sub.closeEvents = func() { close(events) }
+
+	if err := c.subscribe(sub); err != nil {
+		return nil, nil, err
+	}
+	return events, sub, nil
+}
+
+// SubscribeBeaconBlocks streams the new beacon blocks
+func (c *SubscriptionClient) SubscribeBeaconBlocks() (<-chan BeaconBlockEvent, *Subscription, error) {
+	events := make(chan BeaconBlockEvent, c.config.BufferSize)
+	sub := c.newSubscription(SubscribeNewBeaconBlock, []interface{}{})
+	sub.deliver = func(result json.RawMessage) error {
+		var event BeaconBlockEvent
+		if err := json.Unmarshal(result, &event); err != nil {
+			return err
+		}
+		event.Raw = result
+		sub.send(func(done <-chan struct{}) {
+			select {
+			case events <- event:
+			case <-done:
+			}
+		})
+		return nil
+	}
+	sub.closeEvents = func() { close(events) }
+
+	if err := c.subscribe(sub); err != nil {
+		return nil, nil, err
+	}
+	return events, sub, nil
+}
+
+// SubscribePendingTransaction streams txHash once it is included in a block
+func (c *SubscriptionClient) SubscribePendingTransaction(txHash string) (<-chan TxEvent, *Subscription, error) {
+	events := make(chan TxEvent, c.config.BufferSize)
+	sub := c.newSubscription(SubscribePendingTransaction, []interface{}{txHash})
+	sub.deliver = func(result json.RawMessage) error {
+		var event TxEvent
+		if err := json.Unmarshal(result, &event); err != nil {
+			return err
+		}
+		event.Raw = result
+		sub.send(func(done <-chan struct{}) {
+			select {
+			case events <- event:
+			case <-done:
+			}
+		})
+		return nil
+	}
+	sub.closeEvents = func() { close(events) }
+
+	if err := c.subscribe(sub); err != nil {
+		return nil, nil, err
+	}
+	return events, sub, nil
+}
+
+// Close ends every subscription with ErrSubscriptionClientClosed and drops the connection
+func (c *SubscriptionClient) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = make(map[string]*Subscription)
+	conn := c.conn
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.end(ErrSubscriptionClientClosed)
+	}
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+func (c *SubscriptionClient) newSubscription(method string, params []interface{}) *Subscription {
+	c.mu.Lock()
+	c.nextID++
+	id := strconv.FormatUint(c.nextID, 10)
+	c.mu.Unlock()
+
+	return &Subscription{
+		client: c,
+		id:     id,
+		method: method,
+		params: params,
+		done:   make(chan struct{}),
+	}
+}
+
+func (c *SubscriptionClient) subscribe(sub *Subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx.Err() != nil {
+		return ErrSubscriptionClientClosed
+	}
+
+	c.subs[sub.id] = sub
+	if !c.started {
+		c.started = true
+		go c.run()
+	} else if c.conn != nil {
+		// a failed write drops the connection, the read loop then reconnects and subscribes again
+		c.conn.WriteMessage(subscriptionMessage(sub, subscriptionTypeSubscribe))
+	}
+	return nil
+}
+
+// unsubscribe tells the node to stop the stream and ends sub with err
+func (c *SubscriptionClient) unsubscribe(sub *Subscription, err error) {
+	c.mu.Lock()
+	_, active := c.subs[sub.id]
+	delete(c.subs, sub.id)
+	if active && c.conn != nil {
+		c.conn.WriteMessage(subscriptionMessage(sub, subscriptionTypeUnsubscribe))
+	}
+	c.mu.Unlock()
+
+	sub.end(err)
+}
+
+func subscriptionMessage(sub *Subscription, subscriptionType byte) []byte {
+	message, _ := json.Marshal(&subscriptionRequest{
+		Request:      NewRPCRequest(sub.method, sub.params),
+		Subscription: sub.id,
+		Type:         subscriptionType,
+	})
+	return message
+}
+
+// run keeps a connection up until the client is closed
+func (c *SubscriptionClient) run() {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for attempt := 0; ; attempt++ {
+		connected, err := c.connect()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if connected {
+			attempt = 0
+		}
+		if c.config.OnError != nil {
+			c.config.OnError(err)
+		}
+
+		timer := time.NewTimer(c.backoff(attempt, rnd))
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns BaseBackoff * 2^attempt capped at MaxBackoff, with the upper half jittered
+func (c *SubscriptionClient) backoff(attempt int, rnd *rand.Rand) time.Duration {
+	d := c.config.BaseBackoff
+	for i := 0; i < attempt && d < c.config.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.config.MaxBackoff {
+		d = c.config.MaxBackoff
+	}
+	return d/2 + time.Duration(rnd.Int63n(int64(d/2)+1))
+}
+
+// connect dials, subscribes the active subscriptions and reads until the connection drops
+func (c *SubscriptionClient) connect() (connected bool, err error) {
+	conn, err := dialWebsocket(c.ctx, c.config.Endpoint, c.config.Header, c.config.TLSConfig)
+	if err != nil {
+		return false, err
+	}
+	conn.readTimeout = 2 * c.config.PingInterval
+
+	c.mu.Lock()
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		conn.Close()
+		return true, c.ctx.Err()
+	}
+	c.conn = conn
+	for _, sub := range c.subs {
+		conn.WriteMessage(subscriptionMessage(sub, subscriptionTypeSubscribe))
+	}
+	c.mu.Unlock()
+
+	stopPing := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.config.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopPing:
+				return
+			case <-ticker.C:
+				conn.Ping()
+			}
+		}
+	}()
+
+	err = c.readLoop(conn)
+
+	close(stopPing)
+	c.mu.Lock()
+	c.conn = nil
+	c.mu.Unlock()
+	conn.Close()
+
+	return true, err
+}
+
+func (c *SubscriptionClient) readLoop(conn *wsConn) error {
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var resp subscriptionResponse
+		if err := json.Unmarshal(message, &resp); err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		sub := c.subs[resp.Result.Subscription]
+		if sub != nil && resp.RPCError != nil {
+			delete(c.subs, sub.id)
+		}
+		c.mu.Unlock()
+		if sub == nil {
+			continue
+		}
+
+		if resp.RPCError != nil {
+			sub.end(fmt.Errorf("%s: %w", sub.method, resp.RPCError))
+			continue
+		}
+		result := resp.Result.Result
+		if len(result) == 0 || string(result) == "null" {
+			continue
+		}
+		if err := sub.deliver(result); err != nil {
+			c.unsubscribe(sub, fmt.Errorf("decode %s event: %w", sub.method, err))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// acceptWebsocket upgrades r to a server side websocket
+func acceptWebsocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	rw.Flush()
+	return &wsConn{conn: conn, reader: rw.Reader}, nil
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// fakeNode answers every subscription with the events of handle and records the requests
+type fakeNode struct {
+	mu       sync.Mutex
+	requests []subscriptionRequest
+	conns    int
+	handle   func(conn *wsConn, conns int, req subscriptionRequest)
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := acceptWebsocket(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer conn.conn.Close()
+
+	n.mu.Lock()
+	n.conns++
+	conns := n.conns
+	n.mu.Unlock()
+
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req subscriptionRequest
+		json.Unmarshal(message, &req)
+
+		n.mu.Lock()
+		n.requests = append(n.requests, req)
+		n.mu.Unlock()
+
+		n.handle(conn, conns, req)
+	}
+}
+
+func (n *fakeNode) Requests() []subscriptionRequest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]subscriptionRequest(nil), n.requests...)
+}
+
+func writeEvent(conn *wsConn, subscription string, result interface{}, rpcErr *RPCError) {
+	var resp struct {
+		Id     int       `json:"Id"`
+		Error  *RPCError `json:"Error"`
+		Result struct {
+			Subscription string      `json:"Subscription"`
+			Result       interface{} `json:"Result"`
+		} `json:"Result"`
+	}
+	resp.Error = rpcErr
+	resp.Result.Subscription = subscription
+	resp.Result.Result = result
+	message, _ := json.Marshal(resp)
+	conn.WriteMessage(message)
+}
+
+func TestSubscriptionClientResubscribes(t *testing.T) {
+	node := &fakeNode{}
+	node.handle = func(conn *wsConn, conns int, req subscriptionRequest) {
+		if req.Type != subscriptionTypeSubscribe {
+			return
+		}
+		writeEvent(conn, req.Subscription, map[string]interface{}{
+			"Header": map[string]interface{}{"Height": 10 + conns, "ShardID": 2},
+		}, nil)
+		if conns == 1 {
+			// drop the first connection right after its event
+			conn.conn.Close()
+		}
+	}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client := NewSubscriptionClient(SubscriptionConfig{Endpoint: wsURL(server), BaseBackoff: 10 * time.Millisecond})
+	defer client.Close()
+
+	events, sub, err := client.SubscribeShardBlocks(2)
+	assert.NoError(t, err)
+
+	for _, height := range []uint64{11, 12} {
+		select {
+		case event := <-events:
+			assert.Equal(t, height, event.Header.Height)
+			assert.Equal(t, byte(2), event.Header.ShardID)
+			assert.NotEmpty(t, event.Raw)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+		}
+	}
+
+	sub.Unsubscribe()
+	_, open := <-events
+	assert.False(t, open)
+	assert.NoError(t, sub.Err())
+
+	assert.Eventually(t, func() bool { return len(node.Requests()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	requests := node.Requests()
+	for _, req := range requests[:2] {
+		assert.Equal(t, SubscribeNewShardBlock, req.Request.Method)
+		assert.Equal(t, sub.id, req.Subscription)
+		assert.Equal(t, subscriptionTypeSubscribe, req.Type)
+	}
+	assert.Equal(t, subscriptionTypeUnsubscribe, requests[2].Type)
+}
+
+func TestSubscriptionClientNodeError(t *testing.T) {
+	node := &fakeNode{}
+	node.handle = func(conn *wsConn, conns int, req subscriptionRequest) {
+		writeEvent(conn, req.Subscription, nil, &RPCError{Code: -1, Message: "invalid tx hash"})
+	}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	client := NewSubscriptionClient(SubscriptionConfig{Endpoint: wsURL(server)})
+	events, sub, err := client.SubscribePendingTransaction("abc")
+	assert.NoError(t, err)
+
+	select {
+	case <-sub.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended")
+	}
+	_, open := <-events
+	assert.False(t, open)
+	assert.EqualError(t, sub.Err(), "subcribependingtransaction: rpc error -1: invalid tx hash")
+
+	client.Close()
+	_, _, err = client.SubscribeBeaconBlocks()
+	assert.Equal(t, ErrSubscriptionClientClosed, err)
+}
@@ -0,0 +1,79 @@
+package rpcclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TLSConfig describes how to trust a fullnode served over https
+type TLSConfig struct {
+	// RootCAs replaces the system roots when set
+	RootCAs *x509.CertPool
+	// PinnedPublicKeys are hex encoded SHA-256 hashes of the SubjectPublicKeyInfo the node's
+	// certificate chain must contain, checked on top of the usual verification
+	PinnedPublicKeys []string
+	// Certificates are presented to nodes asking for a client certificate
+	Certificates []tls.Certificate
+	// InsecureSkipVerify disables every check, only meant for local testing
+	InsecureSkipVerify bool
+}
+
+// TLSClientConfig builds the crypto/tls config
+func (config TLSConfig) TLSClientConfig() *tls.Config {
+	tlsConfig := &tls.Config{
+		RootCAs:            config.RootCAs,
+		Certificates:       config.Certificates,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if len(config.PinnedPublicKeys) > 0 && !config.InsecureSkipVerify {
+		pins := make(map[string]bool, len(config.PinnedPublicKeys))
+		for _, pin := range config.PinnedPublicKeys {
+			pins[strings.ToLower(pin)] = true
+		}
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			for _, chain := range verifiedChains {
+				for _, cert := range chain {
+					sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+					if pins[hex.EncodeToString(sum[:])] {
+						return nil
+					}
+				}
+			}
+			return errors.New("no pinned public key in the node certificate chain")
+		}
+	}
+
+	return tlsConfig
+}
+
+// HTTPClient returns a copy of base using this TLS config, base itself is left untouched.
+// A nil base or one without Transport gets a clone of http.DefaultTransport, an *http.Transport is cloned.
+// Any other RoundTripper can not be given the TLS config, an error is returned rather than a client
+// ignoring the root CAs, pins and certificates.
+func (config TLSConfig) HTTPClient(base *http.Client) (*http.Client, error) {
+	client := &http.Client{}
+	if base != nil {
+		*client = *base
+	}
+
+	var transport *http.Transport
+	switch rt := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		return nil, fmt.Errorf("the TLS config can not be applied to a transport of type %T, only to an *http.Transport", rt)
+	}
+
+	transport.TLSClientConfig = config.TLSClientConfig()
+	client.Transport = transport
+	return client, nil
+}
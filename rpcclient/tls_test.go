@@ -0,0 +1,79 @@
+package rpcclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTLSNode() (*httptest.Server, *x509.CertPool) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Result":1}`))
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return server, pool
+}
+
+// httpClient returns a client of config with a clone of http.DefaultTransport
+func httpClient(t *testing.T, config TLSConfig) *http.Client {
+	client, err := config.HTTPClient(nil)
+	assert.NoError(t, err)
+	return client
+}
+
+func TestTLSConfigRootCAs(t *testing.T) {
+	server, pool := newTLSNode()
+	defer server.Close()
+
+	_, err := NewHTTPTransport(httpClient(t, TLSConfig{}), server.URL).Call(context.Background(), "getblockcount", nil)
+	assert.Error(t, err)
+
+	_, err = NewHTTPTransport(httpClient(t, TLSConfig{RootCAs: pool}), server.URL).Call(context.Background(), "getblockcount", nil)
+	assert.NoError(t, err)
+
+	_, err = NewHTTPTransport(httpClient(t, TLSConfig{InsecureSkipVerify: true}), server.URL).Call(context.Background(), "getblockcount", nil)
+	assert.NoError(t, err)
+}
+
+func TestTLSConfigPinnedPublicKeys(t *testing.T) {
+	server, pool := newTLSNode()
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pinned := TLSConfig{RootCAs: pool, PinnedPublicKeys: []string{hex.EncodeToString(sum[:])}}
+	_, err := NewHTTPTransport(httpClient(t, pinned), server.URL).Call(context.Background(), "getblockcount", nil)
+	assert.NoError(t, err)
+
+	other := TLSConfig{RootCAs: pool, PinnedPublicKeys: []string{hex.EncodeToString(make([]byte, 32))}}
+	_, err = NewHTTPTransport(httpClient(t, other), server.URL).Call(context.Background(), "getblockcount", nil)
+	assert.Error(t, err)
+}
+
+func TestTLSConfigKeepsCallerTransport(t *testing.T) {
+	base := &http.Transport{}
+	caller := &http.Client{Transport: base}
+
+	client, err := TLSConfig{InsecureSkipVerify: true}.HTTPClient(caller)
+	assert.NoError(t, err)
+	assert.True(t, caller.Transport == base)
+	assert.True(t, base.TLSClientConfig == nil || !base.TLSClientConfig.InsecureSkipVerify)
+	assert.True(t, client.Transport != http.RoundTripper(base))
+
+	roundTripper := http.RoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) { return nil, nil }))
+	// the TLS config can not be applied to it, it is not silently dropped
+	_, err = TLSConfig{}.HTTPClient(&http.Client{Transport: roundTripper})
+	assert.Error(t, err)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,95 @@
+package rpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+)
+
+// Transport carries a JSON-RPC call to a node and returns the raw response body.
+// Both HttpClient and the incognitoclient facade send every call through a Transport,
+// so an in-memory, recording or multi-endpoint implementation can replace HTTP.
+type Transport interface {
+	Call(ctx context.Context, method string, params interface{}) ([]byte, error)
+}
+
+// RPCRequest is the JSON-RPC payload posted to a node
+type RPCRequest struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+	Id      uint        `json:"id"`
+}
+
+// NewRPCRequest builds the payload for method, it is the shape every Transport should send
+func NewRPCRequest(method string, params interface{}) *RPCRequest {
+	return &RPCRequest{
+		JsonRpc: "1.0",
+		Method:  method,
+		Params:  params,
+		Id:      1,
+	}
+}
+
+// HTTPTransport posts calls to a single node endpoint
+type HTTPTransport struct {
+	Client   *http.Client
+	Endpoint string
+}
+
+// NewHTTPTransport to get a transport posting to endpoint with client
+func NewHTTPTransport(client *http.Client, endpoint string) *HTTPTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPTransport{
+		Client:   client,
+		Endpoint: endpoint,
+	}
+}
+
+func (t *HTTPTransport) Call(ctx context.Context, method string, params interface{}) (body []byte, err error) {
+	start := time.Now()
+	defer func() {
+		observeCall(method, start, body, err)
+	}()
+
+	payloadInBytes, err := json.Marshal(NewRPCRequest(method, params))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Endpoint, bytes.NewReader(payloadInBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, unreachable(ctx, t.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, unreachable(ctx, t.Endpoint, err)
+	}
+	return body, nil
+}
+
+// observeCall reports a call to the observer of the SDK with the error code answered in body
+func observeCall(method string, start time.Time, body []byte, err error) {
+	code := 0
+	var res RPCBaseRes
+	if err == nil && json.Unmarshal(body, &res) == nil && res.RPCError != nil {
+		code = res.RPCError.Code
+	}
+	common.ObserveStep(common.StepRPC, method, start, err, code)
+}
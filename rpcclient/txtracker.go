@@ -0,0 +1,462 @@
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TxStatus is the state of a transaction watched by a TxTracker
+type TxStatus int
+
+const (
+	// TxPending is a transaction in the mempool
+	TxPending TxStatus = iota
+	// TxInBlock is a transaction in a block with fewer confirmations than required
+	TxInBlock
+	// TxConfirmed is a transaction with the required confirmations
+	TxConfirmed
+	// TxDropped is a transaction gone from the mempool that could not be sent again
+	TxDropped
+	// TxRejected is a dropped transaction the node rejected when it was sent again
+	TxRejected
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxPending:
+		return "pending"
+	case TxInBlock:
+		return "in block"
+	case TxConfirmed:
+		return "confirmed"
+	case TxDropped:
+		return "dropped"
+	case TxRejected:
+		return "rejected"
+	}
+	return fmt.Sprintf("TxStatus(%d)", int(s))
+}
+
+// Final reports whether a transaction with status s is no longer watched
+func (s TxStatus) Final() bool {
+	return s == TxConfirmed || s == TxDropped || s == TxRejected
+}
+
+var (
+	// ErrTxDropped is the error of a TxDropped update
+	ErrTxDropped = errors.New("transaction dropped from mempool")
+	// ErrTxTrackerClosed is returned when watching a transaction with a closed TxTracker
+	ErrTxTrackerClosed = errors.New("tx tracker closed")
+)
+
+// RawTx is a signed transaction as it was sent, kept to send it again once the mempool drops it
+type RawTx struct {
+	// Method is the send method, sendtransaction or sendrawprivacycustomtokentransaction
+	Method string
+	// Params are the params of Method, the base58 check encoded transaction
+	Params interface{}
+}
+
+// TxUpdate reports a change of the status of a watched transaction
+type TxUpdate struct {
+	TxID          string
+	Status        TxStatus
+	ShardID       byte
+	BlockHeight   uint64
+	Confirmations uint64
+	// Rebroadcasts counts how many times the transaction was sent again
+	Rebroadcasts int
+	// Err tells why the transaction is dropped or rejected
+	Err error
+}
+
+// TxTrackerConfig tunes a TxTracker, the zero value is usable
+type TxTrackerConfig struct {
+	// PollInterval is how often the node is asked for the watched transactions, default 10s
+	PollInterval time.Duration
+	// Confirmations is the number of blocks, the one holding the transaction included, after which
+	// it is confirmed, default 1
+	Confirmations uint64
+	// MissedPolls is the number of polls in a row a transaction must be missing from both the mempool and
+	// the chain before it is considered dropped, default 3
+	MissedPolls int
+	// MaxRebroadcasts caps how many times a dropped transaction is sent again, default 3, negative to never send it again
+	MaxRebroadcasts int
+	// BufferSize is the capacity of every channel returned by Watch, default 16
+	BufferSize int
+}
+
+func (config TxTrackerConfig) withDefaults() TxTrackerConfig {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 10 * time.Second
+	}
+	if config.Confirmations == 0 {
+		config.Confirmations = 1
+	}
+	if config.MissedPolls <= 0 {
+		config.MissedPolls = 3
+	}
+	if config.MaxRebroadcasts == 0 {
+		config.MaxRebroadcasts = 3
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 16
+	}
+	return config
+}
+
+/*
+TxTracker watches sent transactions until they are confirmed, polling the node with gettransactionbyhash and
+getblockcount. A transaction missing from both the mempool and the chain is sent again from its RawTx, when
+it was given one, and reported dropped otherwise. Updates are delivered to the callbacks registered with
+OnUpdate and to the channels returned by Watch, in order; a consumer not keeping up with a full channel holds
+back the polling.
+
+Example:
+
+	tracker := rpcclient.NewTxTracker(rpcClient, rpcclient.TxTrackerConfig{Confirmations: 3})
+	defer tracker.Close()
+
+	tracker.Track(txID, &rpcclient.RawTx{Method: "sendtransaction", Params: raw})
+	update, err := tracker.WaitForConfirmation(ctx, txID)
+	if err != nil {
+		return err
+	}
+	fmt.Println(update.BlockHeight)
+*/
+type TxTracker struct {
+	client *HttpClient
+	config TxTrackerConfig
+
+	mu        sync.Mutex
+	txs       map[string]*trackedTx
+	callbacks []func(TxUpdate)
+	started   bool
+	closed    bool
+	wake      chan struct{}
+	done      chan struct{}
+	cancel    context.CancelFunc
+	ctx       context.Context
+}
+
+type trackedTx struct {
+	update   TxUpdate
+	raw      *RawTx
+	missed   int
+	watchers []chan TxUpdate
+}
+
+// NewTxTracker returns a TxTracker polling the node of client, it starts polling on the first tracked transaction
+func NewTxTracker(client *HttpClient, config TxTrackerConfig) *TxTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TxTracker{
+		client: client,
+		config: config.withDefaults(),
+		txs:    make(map[string]*trackedTx),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Track watches the transaction txID. raw, when not nil, is sent again if the mempool drops the transaction;
+// tracking a watched transaction again only sets its raw.
+func (t *TxTracker) Track(txID string, raw *RawTx) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tx, err := t.track(txID)
+	if err != nil {
+		return err
+	}
+	if raw != nil {
+		tx.raw = raw
+	}
+	return nil
+}
+
+// track returns the watched transaction txID, watching it if it was not, t.mu must be held
+func (t *TxTracker) track(txID string) (*trackedTx, error) {
+	if t.closed {
+		return nil, ErrTxTrackerClosed
+	}
+	tx, ok := t.txs[txID]
+	if !ok {
+		tx = &trackedTx{update: TxUpdate{TxID: txID, Status: TxPending}}
+		t.txs[txID] = tx
+	}
+	if !t.started {
+		t.started = true
+		go t.run()
+	}
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+	return tx, nil
+}
+
+// Watch returns a channel delivering every update of the transaction txID, closed after the final one or
+// once the tracker is closed. The transaction is tracked if it was not.
+func (t *TxTracker) Watch(txID string) (<-chan TxUpdate, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tx, err := t.track(txID)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan TxUpdate, t.config.BufferSize)
+	tx.watchers = append(tx.watchers, ch)
+	return ch, nil
+}
+
+// OnUpdate registers callback to be called with every update of every watched transaction, from the polling goroutine
+func (t *TxTracker) OnUpdate(callback func(TxUpdate)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// Status returns the last update of the transaction txID, false when it is not watched
+func (t *TxTracker) Status(txID string) (TxUpdate, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tx, ok := t.txs[txID]
+	if !ok {
+		return TxUpdate{}, false
+	}
+	return tx.update, true
+}
+
+// WaitForConfirmation tracks the transaction txID and waits until it is confirmed. It fails with the Err of
+// the update when the transaction is dropped or rejected, and with ctx.Err() once ctx is done.
+func (t *TxTracker) WaitForConfirmation(ctx context.Context, txID string) (*TxUpdate, error) {
+	updates, err := t.Watch(txID)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return nil, ErrTxTrackerClosed
+			}
+			if !update.Status.Final() {
+				continue
+			}
+			return &update, update.Err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close stops polling and closes the channels returned by Watch
+func (t *TxTracker) Close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	started := t.started
+	t.mu.Unlock()
+
+	t.cancel()
+	if started {
+		<-t.done
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for txID, tx := range t.txs {
+		for _, ch := range tx.watchers {
+			close(ch)
+		}
+		delete(t.txs, txID)
+	}
+}
+
+func (t *TxTracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.wake:
+		case <-t.ctx.Done():
+			return
+		}
+		t.poll(t.ctx)
+	}
+}
+
+// poll checks every watched transaction once and delivers the updates
+func (t *TxTracker) poll(ctx context.Context) {
+	t.mu.Lock()
+	txIDs := make([]string, 0, len(t.txs))
+	for txID := range t.txs {
+		txIDs = append(txIDs, txID)
+	}
+	t.mu.Unlock()
+
+	heights := make(map[byte]uint64)
+	for _, txID := range txIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		t.check(ctx, txID, heights)
+	}
+}
+
+// check asks the node for the transaction txID and delivers its update when it changed.
+// heights caches the best block heights of the shards for the current poll.
+func (t *TxTracker) check(ctx context.Context, txID string, heights map[byte]uint64) {
+	t.mu.Lock()
+	tx, ok := t.txs[txID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	update := tx.update
+	raw := tx.raw
+	t.mu.Unlock()
+
+	missing := false
+	detail, err := GetTransactionByHashWithContext(ctx, t.client, txID)
+	var rpcErr *RPCError
+	switch {
+	case err != nil && !errors.As(err, &rpcErr):
+		// the node could not be asked, try again on the next poll
+		return
+	case err != nil || (!detail.IsInBlock && !detail.IsInMempool):
+		missing = true
+	case detail.IsInBlock:
+		height, ok := heights[detail.ShardID]
+		if !ok {
+			height, err = bestBlockHeight(ctx, t.client, detail.ShardID)
+			if err != nil {
+				return
+			}
+			heights[detail.ShardID] = height
+		}
+		update.ShardID = detail.ShardID
+		update.BlockHeight = detail.BlockHeight
+		update.Confirmations = 1
+		if height > detail.BlockHeight {
+			update.Confirmations = height - detail.BlockHeight + 1
+		}
+		update.Status = TxInBlock
+		if update.Confirmations >= t.config.Confirmations {
+			update.Status = TxConfirmed
+		}
+	default:
+		update.ShardID = detail.ShardID
+		update.Status = TxPending
+		update.BlockHeight = 0
+		update.Confirmations = 0
+	}
+
+	t.mu.Lock()
+	if !missing {
+		tx.missed = 0
+	} else {
+		tx.missed++
+		if tx.missed < t.config.MissedPolls {
+			t.mu.Unlock()
+			return
+		}
+		tx.missed = 0
+	}
+	t.mu.Unlock()
+
+	if missing {
+		update.Status = TxPending
+		update.BlockHeight = 0
+		update.Confirmations = 0
+		if raw == nil || update.Rebroadcasts >= t.config.MaxRebroadcasts {
+			update.Status = TxDropped
+			update.Err = ErrTxDropped
+		} else {
+			var res SendRawTxRes
+			err := t.client.RPCCallWithContext(ctx, raw.Method, raw.Params, &res)
+			switch {
+			case err != nil:
+				return
+			case res.RPCError != nil && t.known(ctx, txID):
+				// the tx reached a block or the mempool since it was asked for, the next poll reports it
+				return
+			case res.RPCError != nil && errors.Is(res.RPCError, ErrDoubleSpend):
+				// its serial numbers may be spent by the tx itself in a block the node does not report yet,
+				// it is checked again on the next polls and dropped once it is sent MaxRebroadcasts times
+				update.Rebroadcasts++
+			case res.RPCError != nil:
+				update.Status = TxRejected
+				update.Err = fmt.Errorf("%s: %w", raw.Method, res.RPCError)
+			default:
+				update.Rebroadcasts++
+			}
+		}
+	}
+	t.deliver(tx, update)
+}
+
+// known asks the node again whether it has the transaction txID in a block or its mempool
+func (t *TxTracker) known(ctx context.Context, txID string) bool {
+	detail, err := GetTransactionByHashWithContext(ctx, t.client, txID)
+	return err == nil && (detail.IsInBlock || detail.IsInMempool)
+}
+
+// deliver records update as the last one of tx and hands it to the callbacks and watchers when it changed
+func (t *TxTracker) deliver(tx *trackedTx, update TxUpdate) {
+	t.mu.Lock()
+	if update == tx.update {
+		t.mu.Unlock()
+		return
+	}
+	tx.update = update
+	callbacks := t.callbacks
+	watchers := tx.watchers
+	if update.Status.Final() {
+		tx.watchers = nil
+		delete(t.txs, update.TxID)
+	}
+	t.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(update)
+	}
+	for _, ch := range watchers {
+		select {
+		case ch <- update:
+		case <-t.ctx.Done():
+		}
+		if update.Status.Final() {
+			close(ch)
+		}
+	}
+}
+
+// bestBlockHeight asks the node for the height of the best block of shardID
+func bestBlockHeight(ctx context.Context, rpcClient *HttpClient, shardID byte) (uint64, error) {
+	var res struct {
+		RPCBaseRes
+		Result uint64
+	}
+	err := rpcClient.RPCCallWithContext(ctx, "getblockcount", []interface{}{shardID}, &res)
+	if err != nil {
+		return 0, err
+	}
+	if res.RPCError != nil {
+		return 0, fmt.Errorf("getblockcount: %w", res.RPCError)
+	}
+	return res.Result, nil
+}
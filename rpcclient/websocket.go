@@ -0,0 +1,291 @@
+package rpcclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// wsMaxMessageSize bounds the messages read from a node, a shard block with many transactions is a few MB
+const wsMaxMessageSize = 64 << 20
+
+var errWebsocketClosed = errors.New("websocket closed by peer")
+
+// wsConn is a minimal RFC 6455 connection, enough for the JSON text messages of the fullnode.
+// Control frames are answered while reading, writes are safe for concurrent use.
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// client frames are masked, server ones are not
+	client bool
+	// readTimeout, when set, closes a connection that stays silent, pings included
+	readTimeout time.Duration
+
+	writeMu sync.Mutex
+}
+
+// dialWebsocket opens a websocket to endpoint, a ws:// or wss:// url
+func dialWebsocket(ctx context.Context, endpoint string, header http.Header, tlsConfig *tls.Config) (*wsConn, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	port := u.Port()
+	switch u.Scheme {
+	case "ws":
+		if port == "" {
+			port = "80"
+		}
+	case "wss":
+		if port == "" {
+			port = "443"
+		}
+	default:
+		return nil, fmt.Errorf("websocket endpoint %q must be a ws:// or wss:// url", endpoint)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, unreachable(ctx, endpoint, err)
+	}
+
+	// the handshake below is not context aware, unblock it once ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	if u.Scheme == "wss" {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, unreachable(ctx, endpoint, err)
+		}
+		conn = tlsConn
+	}
+
+	ws, err := clientHandshake(conn, u, header)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, unreachable(ctx, endpoint, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return ws, nil
+}
+
+func clientHandshake(conn net.Conn, u *url.URL, header http.Header) (*wsConn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	writer := bufio.NewWriter(conn)
+	fmt.Fprintf(writer, "GET %s HTTP/1.1\r\nHost: %s\r\n", u.RequestURI(), u.Host)
+	fmt.Fprintf(writer, "Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: %s\r\n", key)
+	if err := header.Write(writer); err != nil {
+		return nil, err
+	}
+	writer.WriteString("\r\n")
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodGet, URL: u})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("websocket handshake answered %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		return nil, errors.New("websocket handshake answered a wrong Sec-WebSocket-Accept")
+	}
+
+	return &wsConn{conn: conn, reader: reader, client: true}, nil
+}
+
+// websocketAccept is the Sec-WebSocket-Accept matching key
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WriteMessage sends payload as a single text frame
+func (c *wsConn) WriteMessage(payload []byte) error {
+	return c.writeFrame(wsOpText, payload)
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 2, 14+len(payload))
+	frame[0] = 0x80 | opcode
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame[1] = maskBit | byte(n)
+	case n <= 0xFFFF:
+		frame[1] = maskBit | 126
+		frame = append(frame, byte(n>>8), byte(n))
+	default:
+		frame[1] = maskBit | 127
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(n))
+		frame = append(frame, size[:]...)
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	size := uint64(header[1] & 0x7F)
+	switch size {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.reader, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.reader, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	}
+	if size > wsMaxMessageSize {
+		return false, 0, nil, fmt.Errorf("websocket frame of %d bytes is too large", size)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// ReadMessage returns the next data message, answering pings and reassembling fragments on the way
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			c.writeFrame(wsOpClose, payload)
+			return nil, errWebsocketClosed
+		}
+
+		message = append(message, payload...)
+		if len(message) > wsMaxMessageSize {
+			return nil, fmt.Errorf("websocket message of more than %d bytes", wsMaxMessageSize)
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// Ping sends a ping frame, the answer is consumed by ReadMessage
+func (c *wsConn) Ping() error {
+	return c.writeFrame(wsOpPing, nil)
+}
+
+// Close sends a normal closure frame and closes the connection
+func (c *wsConn) Close() error {
+	c.writeFrame(wsOpClose, []byte{0x03, 0xE8})
+	return c.conn.Close()
+}
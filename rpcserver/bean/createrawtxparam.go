@@ -99,30 +99,19 @@ func NewCreateRawTxParam(params interface{}) (*CreateRawTxParam, error) {
 		return nil, err
 	}
 
-	// param #2: list receivers
-	receivers := make(map[string]uint64)
-	if arrayParams[1] != nil {
-		receivers, ok = arrayParams[1].(map[string]uint64)
-		if !ok {
-			return nil, errors.New("receivers param is invalid")
-		}
-	}
-
+	// param #2: list receivers, amounts by payment address or a list of Receiver
 	paymentInfos := make([]*privacy.PaymentInfo, 0)
-	for paymentAddressStr, amount := range receivers {
-		keyWalletReceiver, err := wallet.Base58CheckDeserialize(paymentAddressStr)
-		if err != nil {
-			return nil, err
-		}
-		if len(keyWalletReceiver.KeySet.PaymentAddress.Pk) == 0 {
-			return nil, fmt.Errorf("payment info %+v is invalid", paymentAddressStr)
-		}
-
-		paymentInfo := &privacy.PaymentInfo{
-			Amount:         amount,
-			PaymentAddress: keyWalletReceiver.KeySet.PaymentAddress,
-		}
-		paymentInfos = append(paymentInfos, paymentInfo)
+	switch receivers := arrayParams[1].(type) {
+	case nil:
+	case map[string]uint64:
+		paymentInfos, err = NewPaymentInfos(receivers)
+	case []Receiver:
+		paymentInfos, err = NewPaymentInfosFromReceivers(receivers)
+	default:
+		return nil, errors.New("receivers param is invalid")
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// param #3: estimation fee nano P per kb
@@ -167,3 +156,67 @@ func NewCreateRawTxParam(params interface{}) (*CreateRawTxParam, error) {
 		Info:                 info,
 	}, nil
 }
+
+// Receiver is a payment of Amount to PaymentAddress. Unlike the amounts by payment address, a list of
+// receivers can pay one address several times, and attach a Message to each output coin.
+type Receiver struct {
+	PaymentAddress string
+	Amount         uint64
+	// Message is stored in the info of the output coin, privacy.MaxSizeInfoCoin bytes at most
+	Message []byte
+	// EncryptMessage encrypts Message to the transmission key of PaymentAddress, it is then
+	// privacy.MaxSizeEncryptedMemo bytes at most, see privacy.DecryptCoinInfo
+	EncryptMessage bool
+}
+
+// NewPaymentInfosFromReceivers converts receivers to payment infos, in order
+func NewPaymentInfosFromReceivers(receivers []Receiver) ([]*privacy.PaymentInfo, error) {
+	paymentInfos := make([]*privacy.PaymentInfo, 0, len(receivers))
+	for _, receiver := range receivers {
+		keyWalletReceiver, err := wallet.Base58CheckDeserialize(receiver.PaymentAddress)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyWalletReceiver.KeySet.PaymentAddress.Pk) == 0 {
+			return nil, fmt.Errorf("payment info %+v is invalid", receiver.PaymentAddress)
+		}
+		message := receiver.Message
+		if receiver.EncryptMessage {
+			message, err = privacy.EncryptCoinInfo(receiver.Message, keyWalletReceiver.KeySet.PaymentAddress.Tk)
+			if err != nil {
+				return nil, fmt.Errorf("encrypt message to %v: %w", receiver.PaymentAddress, err)
+			}
+		}
+		if len(message) > privacy.MaxSizeInfoCoin {
+			return nil, fmt.Errorf("message to %v is %d bytes, maximum = %d", receiver.PaymentAddress, len(message), privacy.MaxSizeInfoCoin)
+		}
+
+		paymentInfos = append(paymentInfos, &privacy.PaymentInfo{
+			Amount:         receiver.Amount,
+			PaymentAddress: keyWalletReceiver.KeySet.PaymentAddress,
+			Message:        message,
+		})
+	}
+	return paymentInfos, nil
+}
+
+// NewPaymentInfos converts receivers, amounts by payment address, to payment infos
+func NewPaymentInfos(receivers map[string]uint64) ([]*privacy.PaymentInfo, error) {
+	paymentInfos := make([]*privacy.PaymentInfo, 0)
+	for paymentAddressStr, amount := range receivers {
+		keyWalletReceiver, err := wallet.Base58CheckDeserialize(paymentAddressStr)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyWalletReceiver.KeySet.PaymentAddress.Pk) == 0 {
+			return nil, fmt.Errorf("payment info %+v is invalid", paymentAddressStr)
+		}
+
+		paymentInfo := &privacy.PaymentInfo{
+			Amount:         amount,
+			PaymentAddress: keyWalletReceiver.KeySet.PaymentAddress,
+		}
+		paymentInfos = append(paymentInfos, paymentInfo)
+	}
+	return paymentInfos, nil
+}
@@ -0,0 +1,215 @@
+package rpcservice
+
+import (
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+)
+
+// DefaultCoinLockTimeout is how long a coin stays reserved for a tx that is neither confirmed nor rejected
+const DefaultCoinLockTimeout = 10 * time.Minute
+
+// maxReserveAttempts bounds how many times the coins of a tx are chosen again when a concurrent send reserved one first
+const maxReserveAttempts = 3
+
+// ErrCoinReserved is returned when a coin to reserve is already reserved for another tx
+var ErrCoinReserved = errors.New("coin is reserved by a pending tx")
+
+// DefaultCoinLocker is the CoinLocker of the sends of the incognitoclient package
+var DefaultCoinLocker = NewCoinLocker(DefaultCoinLockTimeout)
+
+/*
+CoinLocker reserves the coins spent by the pending txs of the accounts of a process, so that concurrent sends from
+one account choose different coins. A reservation ends when it is released, once the tx is rejected, when the node
+no longer lists its coins as unspent, once the tx is confirmed, or after the timeout.
+
+Example:
+
+	reservation := rpcservice.DefaultCoinLocker.NewReservation()
+	raw, err := incognito.CreateAndSendTxWithContext(ctx, rpcClient, params, incognito.WithCoinReservation(reservation))
+	if err != nil {
+		return err
+	}
+	if err := rpcClient.RPCCall("sendtransaction", raw, &result); err != nil {
+		reservation.Release()
+		return err
+	}
+*/
+type CoinLocker struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	locks   map[string]*coinLock
+}
+
+type coinLock struct {
+	account string
+	tokenID string
+	txID    string
+	expiry  time.Time
+}
+
+// NewCoinLocker returns a CoinLocker whose reservations time out after timeout
+func NewCoinLocker(timeout time.Duration) *CoinLocker {
+	return &CoinLocker{
+		timeout: timeout,
+		locks:   make(map[string]*coinLock),
+	}
+}
+
+// coinKey identifies a coin by its commitment
+func coinKey(coin *privacy.OutputCoin) string {
+	return hex.EncodeToString(coin.CoinDetails.GetCoinCommitment().ToBytesS())
+}
+
+// Available splits unspent, the unspent coins of tokenID of account as listed by the node, into the coins free
+// to spend and the coins reserved for pending txs. The reservations of coins missing from unspent, spent by
+// a confirmed tx, end.
+func (locker *CoinLocker) Available(account string, tokenID string, unspent []*privacy.OutputCoin) (available []*privacy.OutputCoin, pending []*privacy.OutputCoin) {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	locker.expire()
+	listed := make(map[string]bool, len(unspent))
+	for _, coin := range unspent {
+		key := coinKey(coin)
+		listed[key] = true
+		if _, ok := locker.locks[key]; ok {
+			pending = append(pending, coin)
+		} else {
+			available = append(available, coin)
+		}
+	}
+	for key, lock := range locker.locks {
+		if lock.account == account && lock.tokenID == tokenID && !listed[key] {
+			delete(locker.locks, key)
+		}
+	}
+	return available, pending
+}
+
+// Reserve reserves coins of tokenID for a tx of account, it fails with ErrCoinReserved and reserves nothing
+// when one of them is already reserved
+func (locker *CoinLocker) Reserve(account string, tokenID string, coins []*privacy.OutputCoin) (*CoinReservation, error) {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	locker.expire()
+	keys := make([]string, len(coins))
+	for i, coin := range coins {
+		keys[i] = coinKey(coin)
+		if _, ok := locker.locks[keys[i]]; ok {
+			return nil, ErrCoinReserved
+		}
+	}
+	reservation := &CoinReservation{locker: locker, locks: make(map[string]*coinLock, len(keys))}
+	expiry := time.Now().Add(locker.timeout)
+	for _, key := range keys {
+		lock := &coinLock{
+			account: account,
+			tokenID: tokenID,
+			expiry:  expiry,
+		}
+		locker.locks[key] = lock
+		reservation.locks[key] = lock
+	}
+	return reservation, nil
+}
+
+// ReleaseTx ends the reservations of the coins spent by the tx txID, once it is confirmed or rejected
+func (locker *CoinLocker) ReleaseTx(txID string) {
+	locker.mu.Lock()
+	defer locker.mu.Unlock()
+
+	for key, lock := range locker.locks {
+		if lock.txID == txID {
+			delete(locker.locks, key)
+		}
+	}
+}
+
+// expire ends the reservations past their timeout, locker.mu must be held
+func (locker *CoinLocker) expire() {
+	now := time.Now()
+	for key, lock := range locker.locks {
+		if now.After(lock.expiry) {
+			delete(locker.locks, key)
+		}
+	}
+}
+
+// CoinReservation is the set of coins reserved in a CoinLocker for the txs built with it, see TxService.CoinReservation
+type CoinReservation struct {
+	mu     sync.Mutex
+	locker *CoinLocker
+	locks  map[string]*coinLock
+	txIDs  []string
+}
+
+// NewReservation returns an empty reservation of locker, the txs built with it reserve the coins they spend in locker
+// and skip the coins reserved by other txs
+func (locker *CoinLocker) NewReservation() *CoinReservation {
+	return &CoinReservation{locker: locker, locks: make(map[string]*coinLock)}
+}
+
+// Release ends the reservation of its coins, when the tx spending them is rejected or not sent
+func (reservation *CoinReservation) Release() {
+	if reservation == nil {
+		return
+	}
+	reservation.mu.Lock()
+	locks := reservation.locks
+	reservation.locks = make(map[string]*coinLock)
+	reservation.mu.Unlock()
+
+	reservation.locker.mu.Lock()
+	defer reservation.locker.mu.Unlock()
+	for key, lock := range locks {
+		// the coin may be reserved again once its lock timed out
+		if reservation.locker.locks[key] == lock {
+			delete(reservation.locker.locks, key)
+		}
+	}
+}
+
+// TxIDs returns the ids of the txs built under the reservation, in the order they were built. A tx whose send
+// failed without the node rejecting it may be in the mempool, these are the ids to look it up with.
+func (reservation *CoinReservation) TxIDs() []string {
+	if reservation == nil {
+		return nil
+	}
+	reservation.mu.Lock()
+	defer reservation.mu.Unlock()
+	return append([]string(nil), reservation.txIDs...)
+}
+
+// bind ties the coins of reservation to the tx txID spending them, see CoinLocker.ReleaseTx
+func (reservation *CoinReservation) bind(txID string) {
+	reservation.locker.mu.Lock()
+	for _, lock := range reservation.locks {
+		lock.txID = txID
+	}
+	reservation.locker.mu.Unlock()
+
+	if txID != "" {
+		reservation.mu.Lock()
+		reservation.txIDs = []string{txID}
+		reservation.mu.Unlock()
+	}
+}
+
+// add moves the coins of other, and the txs they are bound to, into reservation
+func (reservation *CoinReservation) add(other *CoinReservation) {
+	reservation.mu.Lock()
+	defer reservation.mu.Unlock()
+	for key, lock := range other.locks {
+		reservation.locks[key] = lock
+	}
+	for _, txID := range other.txIDs {
+		if len(reservation.txIDs) == 0 || reservation.txIDs[len(reservation.txIDs)-1] != txID {
+			reservation.txIDs = append(reservation.txIDs, txID)
+		}
+	}
+}
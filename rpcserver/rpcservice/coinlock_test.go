@@ -0,0 +1,74 @@
+package rpcservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCommittedCoins(values ...uint64) []*privacy.OutputCoin {
+	coins := newCoins(values...)
+	for _, coin := range coins {
+		coin.CoinDetails.SetCoinCommitment(privacy.RandomPoint())
+	}
+	return coins
+}
+
+func TestCoinLocker(t *testing.T) {
+	locker := NewCoinLocker(time.Minute)
+	coins := newCommittedCoins(1, 2, 3)
+
+	first, err := locker.Reserve("account", "prv", coins[:2])
+	assert.NoError(t, err)
+	_, err = locker.Reserve("account", "prv", coins[1:])
+	assert.Equal(t, ErrCoinReserved, err)
+
+	available, pending := locker.Available("account", "prv", coins)
+	assert.Equal(t, []uint64{3}, coinValues(available))
+	assert.Equal(t, []uint64{1, 2}, coinValues(pending))
+
+	first.bind("tx")
+	locker.ReleaseTx("tx")
+	available, pending = locker.Available("account", "prv", coins)
+	assert.Len(t, available, 3)
+	assert.Empty(t, pending)
+
+	// a confirmed tx spends its coins, the node no longer lists them
+	_, err = locker.Reserve("account", "prv", coins[:1])
+	assert.NoError(t, err)
+	locker.Available("account", "prv", coins[1:])
+	available, _ = locker.Available("account", "prv", coins)
+	assert.Len(t, available, 3)
+}
+
+func TestCoinReservation(t *testing.T) {
+	locker := NewCoinLocker(time.Minute)
+	coins := newCommittedCoins(1, 2)
+
+	reservation := locker.NewReservation()
+
+	chosen, err := locker.Reserve("account", "prv", coins)
+	assert.NoError(t, err)
+	chosen.bind("tx")
+	reservation.add(chosen)
+	assert.Equal(t, []string{"tx"}, reservation.TxIDs())
+	reservation.Release()
+	_, pending := locker.Available("account", "prv", coins)
+	assert.Empty(t, pending)
+
+	var none *CoinReservation
+	none.Release()
+}
+
+func TestCoinLockerTimeout(t *testing.T) {
+	locker := NewCoinLocker(time.Millisecond)
+	coins := newCommittedCoins(1)
+
+	_, err := locker.Reserve("account", "prv", coins)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = locker.Reserve("account", "prv", coins)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,334 @@
+package rpcservice
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+)
+
+/*
+CoinSelector chooses the coins a tx spends. SelectCoins returns at most maxInputs of coins worth at least amount,
+the coins left and the value of the chosen ones. An amount of 0 asks for coins to consolidate, as defragmenting does:
+up to maxInputs coins, in the order the strategy prefers to spend them.
+It fails with rpcclient.ErrNotEnoughCoin when coins are not worth amount, and with rpcclient.ErrTxTooLarge
+when amount needs more than maxInputs coins.
+
+Example:
+
+	txService := &rpcservice.TxService{RpcClient: rpcClient, KeyWallet: keyWallet, CoinSelector: rpcservice.MinInputsSelector{}}
+	tx, err := txService.BuildRawTransaction(ctx, params, nil)
+*/
+type CoinSelector interface {
+	SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) (selected []*privacy.OutputCoin, remaining []*privacy.OutputCoin, total uint64, err error)
+}
+
+// DefaultCoinSelector spends either the smallest coins, or the largest coin when it is worth more than twice amount
+// or the smallest coins are not enough
+type DefaultCoinSelector struct{}
+
+func (DefaultCoinSelector) SelectCoins(outCoins []*privacy.OutputCoin, amount uint64, maxInputs int) (resultOutputCoins []*privacy.OutputCoin, remainOutputCoins []*privacy.OutputCoin, totalResultOutputCoinAmount uint64, err error) {
+	if amount == 0 {
+		sorted := sortCoins(outCoins, true)
+		return takeCoins(sorted, len(sorted), maxInputs)
+	}
+
+	resultOutputCoins = make([]*privacy.OutputCoin, 0)
+	remainOutputCoins = make([]*privacy.OutputCoin, 0)
+	totalResultOutputCoinAmount = uint64(0)
+
+	// either take the smallest coins, or a single largest one
+	var outCoinOverLimit *privacy.OutputCoin
+	outCoinsUnderLimit := make([]*privacy.OutputCoin, 0)
+	for _, outCoin := range outCoins {
+		if outCoin.CoinDetails.GetValue() < amount {
+			outCoinsUnderLimit = append(outCoinsUnderLimit, outCoin)
+		} else if outCoinOverLimit == nil {
+			outCoinOverLimit = outCoin
+		} else if outCoinOverLimit.CoinDetails.GetValue() > outCoin.CoinDetails.GetValue() {
+			remainOutputCoins = append(remainOutputCoins, outCoin)
+		} else {
+			remainOutputCoins = append(remainOutputCoins, outCoinOverLimit)
+			outCoinOverLimit = outCoin
+		}
+	}
+	sort.Slice(outCoinsUnderLimit, func(i, j int) bool {
+		return outCoinsUnderLimit[i].CoinDetails.GetValue() < outCoinsUnderLimit[j].CoinDetails.GetValue()
+	})
+	for _, outCoin := range outCoinsUnderLimit {
+		if totalResultOutputCoinAmount < amount {
+			totalResultOutputCoinAmount += outCoin.CoinDetails.GetValue()
+			resultOutputCoins = append(resultOutputCoins, outCoin)
+		} else {
+			remainOutputCoins = append(remainOutputCoins, outCoin)
+		}
+	}
+	if outCoinOverLimit != nil && (outCoinOverLimit.CoinDetails.GetValue() > 2*amount || totalResultOutputCoinAmount < amount || len(resultOutputCoins) > maxInputs) {
+		remainOutputCoins = append(remainOutputCoins, resultOutputCoins...)
+		resultOutputCoins = []*privacy.OutputCoin{outCoinOverLimit}
+		totalResultOutputCoinAmount = outCoinOverLimit.CoinDetails.GetValue()
+	} else if outCoinOverLimit != nil {
+		remainOutputCoins = append(remainOutputCoins, outCoinOverLimit)
+	}
+
+	if totalResultOutputCoinAmount < amount {
+		return resultOutputCoins, remainOutputCoins, totalResultOutputCoinAmount, fmt.Errorf("%w: need %d, have %d", rpcclient.ErrNotEnoughCoin, amount, totalResultOutputCoinAmount)
+	}
+	if len(resultOutputCoins) > maxInputs {
+		// the smallest coins are too many, spend the largest ones instead
+		return MinInputsSelector{}.SelectCoins(outCoins, amount, maxInputs)
+	}
+	return resultOutputCoins, remainOutputCoins, totalResultOutputCoinAmount, nil
+}
+
+// BranchAndBoundSelector searches coins worth exactly amount, so the tx has no change output.
+// It gives up after MaxTries steps, 100000 when 0, and then chooses with Fallback, DefaultCoinSelector when nil.
+type BranchAndBoundSelector struct {
+	MaxTries int
+	Fallback CoinSelector
+}
+
+func (selector BranchAndBoundSelector) SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	fallback := selector.Fallback
+	if fallback == nil {
+		fallback = DefaultCoinSelector{}
+	}
+	if amount == 0 {
+		return fallback.SelectCoins(coins, amount, maxInputs)
+	}
+	maxTries := selector.MaxTries
+	if maxTries <= 0 {
+		maxTries = 100000
+	}
+
+	sorted := sortCoins(coins, false)
+	// left[i] is the value of the coins from i on, the most the search can still add
+	left := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		left[i] = left[i+1] + sorted[i].CoinDetails.GetValue()
+	}
+
+	tries := 0
+	chosen := make([]bool, len(sorted))
+	var search func(i int, sum uint64, count int) bool
+	search = func(i int, sum uint64, count int) bool {
+		tries++
+		if sum == amount {
+			return true
+		}
+		if i == len(sorted) || count == maxInputs || sum+left[i] < amount || tries > maxTries {
+			return false
+		}
+		value := sorted[i].CoinDetails.GetValue()
+		if sum+value <= amount {
+			chosen[i] = true
+			if search(i+1, sum+value, count+1) {
+				return true
+			}
+			chosen[i] = false
+		}
+		// coins worth the same lead to the same sums, skip them
+		j := i + 1
+		for j < len(sorted) && sorted[j].CoinDetails.GetValue() == value {
+			j++
+		}
+		return search(j, sum, count)
+	}
+	if !search(0, 0, 0) {
+		return fallback.SelectCoins(coins, amount, maxInputs)
+	}
+
+	selected := make([]*privacy.OutputCoin, 0)
+	remaining := make([]*privacy.OutputCoin, 0)
+	for i, coin := range sorted {
+		if chosen[i] {
+			selected = append(selected, coin)
+		} else {
+			remaining = append(remaining, coin)
+		}
+	}
+	return selected, remaining, amount, nil
+}
+
+// MinInputsSelector spends the fewest coins, so the tx is as small and its fee as low as possible.
+// Among the coins able to complete the largest ones it takes the smallest, to keep the change low.
+type MinInputsSelector struct{}
+
+func (MinInputsSelector) SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	sorted := sortCoins(coins, false)
+	if amount == 0 {
+		return takeCoins(sorted, len(sorted), maxInputs)
+	}
+
+	count, sum := 0, uint64(0)
+	for count < len(sorted) && sum < amount {
+		sum += sorted[count].CoinDetails.GetValue()
+		count++
+	}
+	if err := checkCoinCount(sum, amount, count, maxInputs); err != nil {
+		return nil, coins, 0, err
+	}
+
+	// swap the last coin taken for the smallest one still completing the amount
+	last := count - 1
+	need := amount - (sum - sorted[last].CoinDetails.GetValue())
+	for i := len(sorted) - 1; i > last; i-- {
+		if sorted[i].CoinDetails.GetValue() >= need {
+			sorted[last], sorted[i] = sorted[i], sorted[last]
+			break
+		}
+	}
+	return takeCoins(sorted, count, maxInputs)
+}
+
+// ConsolidationSelector spends the smallest coins first, and keeps spending coins worth at most DustThreshold
+// once amount is reached, up to maxInputs, to merge dust into the change. A DustThreshold of 0 spends every coin maxInputs allows.
+type ConsolidationSelector struct {
+	DustThreshold uint64
+}
+
+func (selector ConsolidationSelector) SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	sorted := sortCoins(coins, true)
+	isDust := func(coin *privacy.OutputCoin) bool {
+		return selector.DustThreshold == 0 || coin.CoinDetails.GetValue() <= selector.DustThreshold
+	}
+
+	count, sum := 0, uint64(0)
+	for count < len(sorted) && count < maxInputs && (sum < amount || isDust(sorted[count])) {
+		sum += sorted[count].CoinDetails.GetValue()
+		count++
+	}
+	if sum >= amount {
+		return takeCoins(sorted, count, maxInputs)
+	}
+
+	// the smallest coins are not enough, spend the largest ones in place of the largest of them
+	for j := 1; j <= count; j++ {
+		selected := append(append([]*privacy.OutputCoin{}, sorted[:count-j]...), sorted[len(sorted)-j:]...)
+		if total := sumCoins(selected); total >= amount {
+			return selected, append([]*privacy.OutputCoin{}, sorted[count-j:len(sorted)-j]...), total, nil
+		}
+	}
+	total := sumCoins(sorted)
+	if total < amount {
+		return nil, coins, 0, fmt.Errorf("%w: need %d, have %d", rpcclient.ErrNotEnoughCoin, amount, total)
+	}
+	return nil, coins, 0, fmt.Errorf("%w: %d coins are not enough to pay %d", rpcclient.ErrTxTooLarge, maxInputs, amount)
+}
+
+/*
+PrivacyAwareSelector spends as few coins as it can, since the coins spent by a tx are linked to one another,
+and picks them at random among the coins able to pay rather than by value, so the choice does not tell
+which coins a wallet holds.
+*/
+type PrivacyAwareSelector struct{}
+
+func (PrivacyAwareSelector) SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	shuffled := shuffleCoins(coins)
+	if amount == 0 {
+		return takeCoins(shuffled, len(shuffled), maxInputs)
+	}
+
+	// the fewest coins able to pay
+	sorted := sortCoins(coins, false)
+	count, sum := 0, uint64(0)
+	for count < len(sorted) && sum < amount {
+		sum += sorted[count].CoinDetails.GetValue()
+		count++
+	}
+	if err := checkCoinCount(sum, amount, count, maxInputs); err != nil {
+		return nil, coins, 0, err
+	}
+
+	if count == 1 {
+		eligible := make([]*privacy.OutputCoin, 0)
+		for _, coin := range shuffled {
+			if coin.CoinDetails.GetValue() >= amount {
+				eligible = append(eligible, coin)
+			}
+		}
+		return takeCoins(moveToFront(shuffled, eligible[0]), 1, maxInputs)
+	}
+
+	// as many random picks of count coins as there are coins, then the largest ones
+	for attempt := 0; attempt < len(coins); attempt++ {
+		if sumCoins(shuffled[:count]) >= amount {
+			return takeCoins(shuffled, count, maxInputs)
+		}
+		shuffled = shuffleCoins(coins)
+	}
+	return takeCoins(sorted, count, maxInputs)
+}
+
+// sortCoins returns a copy of coins sorted by value, ascending or descending
+func sortCoins(coins []*privacy.OutputCoin, ascending bool) []*privacy.OutputCoin {
+	sorted := make([]*privacy.OutputCoin, len(coins))
+	copy(sorted, coins)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ascending {
+			return sorted[i].CoinDetails.GetValue() < sorted[j].CoinDetails.GetValue()
+		}
+		return sorted[i].CoinDetails.GetValue() > sorted[j].CoinDetails.GetValue()
+	})
+	return sorted
+}
+
+// shuffleCoins returns a copy of coins in a random order
+func shuffleCoins(coins []*privacy.OutputCoin) []*privacy.OutputCoin {
+	shuffled := make([]*privacy.OutputCoin, len(coins))
+	copy(shuffled, coins)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j, err := common.RandBigIntMaxRange(big.NewInt(int64(i + 1)))
+		if err != nil {
+			continue
+		}
+		shuffled[i], shuffled[j.Int64()] = shuffled[j.Int64()], shuffled[i]
+	}
+	return shuffled
+}
+
+// moveToFront returns coins with coin first
+func moveToFront(coins []*privacy.OutputCoin, coin *privacy.OutputCoin) []*privacy.OutputCoin {
+	moved := []*privacy.OutputCoin{coin}
+	for _, c := range coins {
+		if c != coin {
+			moved = append(moved, c)
+		}
+	}
+	return moved
+}
+
+func sumCoins(coins []*privacy.OutputCoin) uint64 {
+	sum := uint64(0)
+	for _, coin := range coins {
+		sum += coin.CoinDetails.GetValue()
+	}
+	return sum
+}
+
+// takeCoins selects the first count of coins, maxInputs at most
+func takeCoins(coins []*privacy.OutputCoin, count int, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	if count > maxInputs {
+		count = maxInputs
+	}
+	if count < 0 {
+		count = 0
+	}
+	selected := coins[:count:count]
+	remaining := append([]*privacy.OutputCoin{}, coins[count:]...)
+	return selected, remaining, sumCoins(selected), nil
+}
+
+// checkCoinCount returns the error of a selection needing count coins worth sum to pay amount
+func checkCoinCount(sum uint64, amount uint64, count int, maxInputs int) error {
+	if sum < amount {
+		return fmt.Errorf("%w: need %d, have %d", rpcclient.ErrNotEnoughCoin, amount, sum)
+	}
+	if count > maxInputs {
+		return fmt.Errorf("%w: need %d input coins, maximum = %d", rpcclient.ErrTxTooLarge, count, maxInputs)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package rpcservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCoins(values ...uint64) []*privacy.OutputCoin {
+	coins := make([]*privacy.OutputCoin, len(values))
+	for i, value := range values {
+		coins[i] = new(privacy.OutputCoin).Init()
+		coins[i].CoinDetails.SetValue(value)
+	}
+	return coins
+}
+
+func coinValues(coins []*privacy.OutputCoin) []uint64 {
+	values := make([]uint64, len(coins))
+	for i, coin := range coins {
+		values[i] = coin.CoinDetails.GetValue()
+	}
+	return values
+}
+
+func TestCoinSelectors(t *testing.T) {
+	coins := newCoins(1, 2, 3, 50, 60)
+
+	tests := []struct {
+		name      string
+		selector  CoinSelector
+		amount    uint64
+		maxInputs int
+		want      []uint64
+	}{
+		{"default smallest", DefaultCoinSelector{}, 52, 255, []uint64{1, 2, 3, 50}},
+		{"default single", DefaultCoinSelector{}, 20, 255, []uint64{60}},
+		{"default consolidate", DefaultCoinSelector{}, 0, 2, []uint64{1, 2}},
+		{"branch and bound exact", BranchAndBoundSelector{}, 63, 255, []uint64{60, 3}},
+		{"branch and bound fallback", BranchAndBoundSelector{}, 49, 255, []uint64{60}},
+		{"min inputs single", MinInputsSelector{}, 55, 255, []uint64{60}},
+		{"min inputs low change", MinInputsSelector{}, 100, 255, []uint64{60, 50}},
+		{"min inputs smallest last", MinInputsSelector{}, 112, 255, []uint64{60, 50, 2}},
+		{"consolidation dust", ConsolidationSelector{DustThreshold: 3}, 4, 255, []uint64{1, 2, 3}},
+		{"consolidation all", ConsolidationSelector{}, 4, 255, []uint64{1, 2, 3, 50, 60}},
+		{"consolidation largest", ConsolidationSelector{DustThreshold: 3}, 55, 2, []uint64{1, 60}},
+		{"privacy aware single", PrivacyAwareSelector{}, 55, 255, []uint64{60}},
+		{"privacy aware fewest", PrivacyAwareSelector{}, 110, 255, []uint64{60, 50}},
+	}
+	for _, test := range tests {
+		selected, remaining, total, err := test.selector.SelectCoins(coins, test.amount, test.maxInputs)
+		assert.NoError(t, err, test.name)
+		assert.ElementsMatch(t, test.want, coinValues(selected), test.name)
+		assert.Len(t, remaining, len(coins)-len(selected), test.name)
+		assert.Equal(t, sumCoins(selected), total, test.name)
+		assert.True(t, total >= test.amount, test.name)
+	}
+
+	for _, selector := range []CoinSelector{DefaultCoinSelector{}, BranchAndBoundSelector{}, MinInputsSelector{}, ConsolidationSelector{}, PrivacyAwareSelector{}} {
+		_, _, _, err := selector.SelectCoins(coins, 200, 255)
+		assert.True(t, errors.Is(err, rpcclient.ErrNotEnoughCoin), "%T", selector)
+
+		_, _, _, err = selector.SelectCoins(coins, 110, 1)
+		assert.True(t, errors.Is(err, rpcclient.ErrTxTooLarge), "%T", selector)
+	}
+}
+
+type failingSelector struct{}
+
+func (failingSelector) SelectCoins(coins []*privacy.OutputCoin, amount uint64, maxInputs int) ([]*privacy.OutputCoin, []*privacy.OutputCoin, uint64, error) {
+	return nil, coins, 0, rpcclient.ErrTxTooLarge
+}
+
+func TestDefragmentSelectorError(t *testing.T) {
+	txService := TxService{CoinSelector: failingSelector{}}
+	_, _, err := txService.calculateOutputCoinsByMinValue(newCoins(1, 2, 3), 10, MaxDefragmentQuantity)
+	assert.True(t, errors.Is(err, rpcclient.ErrTxTooLarge))
+}
@@ -1,6 +1,7 @@
 package rpcservice
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -12,7 +13,11 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/transaction"
 )
 
+// MaxDefragmentQuantity is the most coins a defragment tx merges, far under the input and size limits of a tx
+const MaxDefragmentQuantity = 32
+
 func (txService TxService) BuildDeFragmentRawTransaction(
+	ctx context.Context,
 	params interface{},
 	metadataParam metadata.Metadata,
 ) (*transaction.Tx, error) {
@@ -42,14 +47,14 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	hasPrivacyCoinParam := arrayParams[3].(int)
 	hasPrivacyCoin := hasPrivacyCoinParam > 0
 
-	maxDefragmentQuantity := 32
+	maxDefragmentQuantity := MaxDefragmentQuantity
 	if len(arrayParams) >= 5 {
 		maxDefragmentQuantityTemp, ok := arrayParams[4].(int64)
 		if !ok {
-			maxDefragmentQuantityTemp = 32
+			maxDefragmentQuantityTemp = MaxDefragmentQuantity
 		}
-		if maxDefragmentQuantityTemp > 32 || maxDefragmentQuantityTemp <= 0 {
-			maxDefragmentQuantityTemp = 32
+		if maxDefragmentQuantityTemp > MaxDefragmentQuantity || maxDefragmentQuantityTemp <= 0 {
+			maxDefragmentQuantityTemp = MaxDefragmentQuantity
 		}
 		maxDefragmentQuantity = int(maxDefragmentQuantityTemp)
 	}
@@ -62,18 +67,29 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 		return nil, err
 	}
 
-	outCoins, err := rpcclient.GetUnspentOutputCoins(txService.RpcClient, txService.KeyWallet, prvCoinID)
+	senderKeySet, shardIDSender, err := bean.GetKeySetFromPrivateKeyParams(senderKeyParam)
 	if err != nil {
 		return nil, err
 	}
 
-	outCoins, amount := txService.calculateOutputCoinsByMinValue(outCoins, maxVal, maxDefragmentQuantity)
+	var outCoins []*privacy.OutputCoin
+	var amount uint64
+	reservation, err := txService.reserveChosenCoins(prvCoinID.String(), func() ([]*privacy.OutputCoin, error) {
+		unspentCoins, err := txService.spendableOutputCoins(ctx, prvCoinID)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(outCoins) == 0 {
-		return nil, errors.New("outCoins is empty")
-	}
+		outCoins, amount, err = txService.calculateOutputCoinsByMinValue(unspentCoins, maxVal, maxDefragmentQuantity)
+		if err != nil {
+			return nil, err
+		}
 
-	senderKeySet, shardIDSender, err := bean.GetKeySetFromPrivateKeyParams(senderKeyParam)
+		if len(outCoins) == 0 {
+			return nil, errors.New("outCoins is empty")
+		}
+		return outCoins, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +104,7 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	paymentInfos := []*privacy.PaymentInfo{paymentInfo}
 
 	realFee, _, _, err := txService.estimateFee(
+		ctx,
 		estimateFeeCoinPerKb,
 		false,
 		outCoins,
@@ -101,6 +118,7 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	)
 
 	if err != nil {
+		reservation.Release()
 		return nil, err
 	}
 
@@ -109,7 +127,8 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	}
 
 	if amount < realFee {
-		return nil, errors.New("amount must large ethan fee")
+		reservation.Release()
+		return nil, fmt.Errorf("%w: amount %d must be larger than fee %d", rpcclient.ErrNotEnoughCoin, amount, realFee)
 	}
 	paymentInfo.Amount = amount - realFee
 
@@ -119,7 +138,8 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	// init tx
 	tx := transaction.Tx{}
 
-	err = tx.Init(
+	err = tx.InitWithContext(
+		ctx,
 		transaction.NewTxPrivacyInitParams(
 			&senderKeySet.PrivateKey,
 			paymentInfos,
@@ -136,27 +156,31 @@ func (txService TxService) BuildDeFragmentRawTransaction(
 	)
 
 	if err != nil {
+		reservation.Release()
 		return nil, err
 	}
+	txService.holdReservation(reservation, tx.Hash().String())
 	return &tx, nil
 }
 
-func (txService TxService) calculateOutputCoinsByMinValue(outCoins []*privacy.OutputCoin, maxVal uint64, maxDefragmentQuantityTemp int) ([]*privacy.OutputCoin, uint64) {
+// calculateOutputCoinsByMinValue returns the coins worth at most maxVal the coin selector consolidates first,
+// maxDefragmentQuantityTemp at most, and their value; the error of the selector is returned as is
+func (txService TxService) calculateOutputCoinsByMinValue(outCoins []*privacy.OutputCoin, maxVal uint64, maxDefragmentQuantityTemp int) ([]*privacy.OutputCoin, uint64, error) {
 	outCoinsTmp := make([]*privacy.OutputCoin, 0)
-	amount := uint64(0)
 	for _, outCoin := range outCoins {
 		if outCoin.CoinDetails.GetValue() <= maxVal {
 			outCoinsTmp = append(outCoinsTmp, outCoin)
-			amount += outCoin.CoinDetails.GetValue()
-			if len(outCoinsTmp) >= maxDefragmentQuantityTemp {
-				break
-			}
 		}
 	}
-	return outCoinsTmp, amount
+	outCoinsTmp, _, amount, err := txService.coinSelector().SelectCoins(outCoinsTmp, 0, maxDefragmentQuantityTemp)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select coins to defragment: %w", err)
+	}
+	return outCoinsTmp, amount, nil
 }
 
 func (txService TxService) buildDefragmentPrivacyCustomTokenParam(
+	ctx context.Context,
 	tokenParamsRaw map[string]interface{},
 	senderKeySet *incognitokey.KeySet,
 	shardIDSender byte,
@@ -202,7 +226,11 @@ func (txService TxService) buildDefragmentPrivacyCustomTokenParam(
 		Fee:            tokenFee,
 	}
 
-	maxDefragmentQuantity := 32
+	// MaxDefragmentQuantity is optional, it caps the coins merged as param #5 of BuildDeFragmentRawTransaction does
+	maxDefragmentQuantity, ok := tokenParamsRaw["MaxDefragmentQuantity"].(int)
+	if !ok || maxDefragmentQuantity > MaxDefragmentQuantity || maxDefragmentQuantity <= 0 {
+		maxDefragmentQuantity = MaxDefragmentQuantity
+	}
 
 	// get list custom token
 	switch tokenParams.TokenTxType {
@@ -212,12 +240,15 @@ func (txService TxService) buildDefragmentPrivacyCustomTokenParam(
 			return nil, nil, err
 		}
 
-		outputTokens, err := rpcclient.GetUnspentOutputCoins(txService.RpcClient, txService.KeyWallet, tokenID)
+		outputTokens, err := txService.spendableOutputCoins(ctx, tokenID)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		candidateOutputTokens, amount := txService.calculateOutputCoinsByMinValue(outputTokens, 10000*1e9, maxDefragmentQuantity)
+		candidateOutputTokens, amount, err := txService.calculateOutputCoinsByMinValue(outputTokens, 10000*1e9, maxDefragmentQuantity)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		if len(candidateOutputTokens) == 0 {
 			return nil, nil, errors.New("lis output coin is empty")
@@ -235,7 +266,7 @@ func (txService TxService) buildDefragmentPrivacyCustomTokenParam(
 	return tokenParams, nil, nil
 }
 
-func (txService TxService) buildDefragmentTokenParam(tokenParamsRaw map[string]interface{}, senderKeySet *incognitokey.KeySet, shardIDSender byte) (*transaction.CustomTokenPrivacyParamTx, error) {
+func (txService TxService) buildDefragmentTokenParam(ctx context.Context, tokenParamsRaw map[string]interface{}, senderKeySet *incognitokey.KeySet, shardIDSender byte) (*transaction.CustomTokenPrivacyParamTx, error) {
 	var privacyTokenParam *transaction.CustomTokenPrivacyParamTx
 	var err error
 
@@ -247,7 +278,7 @@ func (txService TxService) buildDefragmentTokenParam(tokenParamsRaw map[string]i
 		// Check normal custom token param
 	} else {
 		// Check privacy custom token param
-		privacyTokenParam, _, err = txService.buildDefragmentPrivacyCustomTokenParam(tokenParamsRaw, senderKeySet, shardIDSender)
+		privacyTokenParam, _, err = txService.buildDefragmentPrivacyCustomTokenParam(ctx, tokenParamsRaw, senderKeySet, shardIDSender)
 		if err != nil {
 			return nil, err
 		}
@@ -257,6 +288,7 @@ func (txService TxService) buildDefragmentTokenParam(tokenParamsRaw map[string]i
 }
 
 func (txService TxService) BuildDeFragmentPTokenRawTransaction(
+	ctx context.Context,
 	params interface{},
 	metaData metadata.Metadata,
 ) (*transaction.TxCustomTokenPrivacy, error) {
@@ -266,13 +298,23 @@ func (txService TxService) BuildDeFragmentPTokenRawTransaction(
 	}
 	tokenParamsRaw := txParam.TokenParamsRaw
 	var err error
-	tokenParams, err := txService.buildDefragmentTokenParam(tokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender)
+	var tokenParams *transaction.CustomTokenPrivacyParamTx
+	tokenID, _ := tokenParamsRaw["TokenID"].(string)
+	tokenReservation, err := txService.reserveChosenCoins(tokenID, func() ([]*privacy.OutputCoin, error) {
+		var err error
+		tokenParams, err = txService.buildDefragmentTokenParam(ctx, tokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender)
+		if err != nil || tokenParams == nil {
+			return nil, err
+		}
+		return tokenParams.TokenOutput, nil
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
 	if tokenParams == nil {
+		tokenReservation.Release()
 		return nil, errors.New("can not build token params for request")
 	}
 
@@ -281,20 +323,26 @@ func (txService TxService) BuildDeFragmentPTokenRawTransaction(
 	var outputPrvCoins []*privacy.OutputCoin
 	realFeePRV := uint64(0)
 
-	inputCoins, outputPrvCoins, realFeePRV, err = txService.chooseOutsCoinByKeyset(
-		txParam.PaymentInfos,
-		txParam.EstimateFeeCoinPerKb,
-		0,
-		txParam.SenderKeySet,
-		txParam.ShardIDSender,
-		txParam.HasPrivacyCoin,
-		nil,
-		tokenParams,
-		txParam.IsGetPTokenFee,
-		txParam.UnitPTokenFee,
-	)
+	reservation, err := txService.reserveChosenCoins(common.PRVCoinID.String(), func() ([]*privacy.OutputCoin, error) {
+		var err error
+		inputCoins, outputPrvCoins, realFeePRV, err = txService.chooseOutsCoinByKeyset(
+			ctx,
+			txParam.PaymentInfos,
+			txParam.EstimateFeeCoinPerKb,
+			0,
+			txParam.SenderKeySet,
+			txParam.ShardIDSender,
+			txParam.HasPrivacyCoin,
+			nil,
+			tokenParams,
+			txParam.IsGetPTokenFee,
+			txParam.UnitPTokenFee,
+		)
+		return outputPrvCoins, err
+	})
 
 	if err != nil {
+		tokenReservation.Release()
 		return nil, err
 	}
 
@@ -304,7 +352,8 @@ func (txService TxService) BuildDeFragmentPTokenRawTransaction(
 
 	/******* END GET output coins native coins(PRV), which is used to create tx *****/
 	tx := &transaction.TxCustomTokenPrivacy{}
-	err = tx.Init(
+	err = tx.InitWithContext(
+		ctx,
 		transaction.NewTxPrivacyTokenInitParams(
 			&txParam.SenderKeySet.PrivateKey,
 			txParam.PaymentInfos,
@@ -323,8 +372,12 @@ func (txService TxService) BuildDeFragmentPTokenRawTransaction(
 	)
 
 	if err != nil {
+		tokenReservation.Release()
+		reservation.Release()
 		return nil, err
 	}
 
+	txService.holdReservation(tokenReservation, tx.Hash().String())
+	txService.holdReservation(reservation, tx.Hash().String())
 	return tx, nil
 }
@@ -0,0 +1,36 @@
+package rpcservice
+
+import (
+	"context"
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+)
+
+// defaultEstimateFeeBlocks is the number of blocks a tx is expected to be confirmed in when the caller asks for none,
+// it is the one estimatefeewithestimator is called with
+const defaultEstimateFeeBlocks = 8
+
+// feeEstimator returns the fee estimator of shardID, nil if there is none
+func (txService TxService) feeEstimator(shardID byte) *mempool.FeeEstimator {
+	return txService.FeeEstimator[shardID]
+}
+
+// estimateFeeCoinPerKb returns the fee per kb of a tx of shardID to be confirmed in numBlock blocks, paid in tokenId,
+// nil for PRV. The local fee estimator of the shard answers, estimatefeewithestimator when it has too little data.
+func (txService TxService) estimateFeeCoinPerKb(ctx context.Context, defaultFee int64, numBlock uint64, shardID byte, paymentAddrStr string, tokenId *common.Hash) (uint64, error) {
+	if feeEstimator := txService.feeEstimator(shardID); feeEstimator != nil {
+		if numBlock == 0 {
+			numBlock = defaultEstimateFeeBlocks
+		}
+		feeRate, err := feeEstimator.EstimateFee(numBlock, tokenId)
+		if err == nil {
+			if defaultFee > 0 && uint64(defaultFee) > uint64(feeRate) {
+				return uint64(defaultFee), nil
+			}
+			return uint64(feeRate), nil
+		}
+		common.Log.Debugf("local fee estimator of shard %d: %v, asking the node", shardID, err)
+	}
+	return rpcclient.GetEstimateFeeWithEstimatorWithContext(ctx, txService.RpcClient, defaultFee, paymentAddrStr, tokenId)
+}
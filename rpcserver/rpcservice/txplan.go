@@ -0,0 +1,170 @@
+package rpcservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognitokey"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+)
+
+// noInputLimit lets a plan choose as many coins as the amount needs, to tell how far over the limits the tx goes
+const noInputLimit = math.MaxInt32
+
+// TxPlan previews a tx without proving it: the coins it spends, its change, size and fee
+type TxPlan struct {
+	// InputCoins are the PRV coins the tx spends
+	InputCoins    []*privacy.OutputCoin
+	InputAmount   uint64
+	PaymentAmount uint64
+	Change        uint64
+	// Fee is the PRV fee, FeePerKb times SizeInKb
+	Fee      uint64
+	FeePerKb uint64
+	SizeInKb uint64
+	// ExceedsMaxTxSize is set when SizeInKb is over common.MaxTxSize, the tx would then be rejected
+	ExceedsMaxTxSize bool
+	// ExceedsMaxInputs is set when the tx, or its token part, spends more than transaction.MaxInputCoins coins
+	ExceedsMaxInputs bool
+	// Insufficient is set when the PRV coins of the account are not worth the payments and the fee, building
+	// the tx then fails with rpcclient.ErrNotEnoughCoin
+	Insufficient bool
+	// Token is the token part of a privacy token tx, nil for a PRV tx
+	Token *TokenPlan
+}
+
+// TokenPlan previews the token part of a privacy token tx
+type TokenPlan struct {
+	TokenID       string
+	InputCoins    []*privacy.OutputCoin
+	InputAmount   uint64
+	PaymentAmount uint64
+	Change        uint64
+	// Fee is the fee paid in the token
+	Fee uint64
+}
+
+// PlanRawTransaction previews the tx BuildRawTransaction would build from params, without proving it.
+// Instead of failing when the tx needs too many coins or more PRV than the account has, it reports it in the plan.
+func (txService TxService) PlanRawTransaction(ctx context.Context, params *bean.CreateRawTxParam, meta metadata.Metadata) (*TxPlan, error) {
+	err := validateMetadata(meta)
+	if err != nil {
+		return nil, err
+	}
+	return txService.planTx(
+		ctx,
+		params.PaymentInfos,
+		params.EstimateFeeCoinPerKb,
+		params.SenderKeySet,
+		params.ShardIDSender,
+		params.HasPrivacyCoin,
+		meta,
+		nil,
+	)
+}
+
+// PlanRawPrivacyCustomTokenTransaction previews the tx BuildRawPrivacyCustomTokenTransaction would build from params,
+// without proving it
+func (txService TxService) PlanRawPrivacyCustomTokenTransaction(ctx context.Context, params interface{}, metaData metadata.Metadata) (*TxPlan, error) {
+	err := validateMetadata(metaData)
+	if err != nil {
+		return nil, err
+	}
+	txParam, err := bean.NewCreateRawPrivacyTokenTxParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenParams, err := txService.buildTokenParam(ctx, txParam.TokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender, true)
+	if errors.Is(err, rpcclient.ErrTxTooLarge) {
+		tokenParams, err = txService.buildTokenParam(ctx, txParam.TokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tokenParams == nil {
+		return nil, errors.New("can not build token params for request")
+	}
+
+	plan, err := txService.planTx(
+		ctx,
+		txParam.PaymentInfos,
+		txParam.EstimateFeeCoinPerKb,
+		txParam.SenderKeySet,
+		txParam.ShardIDSender,
+		txParam.HasPrivacyCoin,
+		metaData,
+		tokenParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Token = &TokenPlan{
+		TokenID:     tokenParams.PropertyID,
+		InputCoins:  tokenParams.TokenOutput,
+		InputAmount: sumCoins(tokenParams.TokenOutput),
+		Fee:         tokenParams.Fee,
+	}
+	for _, receiver := range tokenParams.Receiver {
+		plan.Token.PaymentAmount += receiver.Amount
+	}
+	if plan.Token.InputAmount > plan.Token.PaymentAmount+plan.Token.Fee {
+		plan.Token.Change = plan.Token.InputAmount - plan.Token.PaymentAmount - plan.Token.Fee
+	}
+	if len(tokenParams.TokenInput) > transaction.MaxInputCoins {
+		plan.ExceedsMaxInputs = true
+	}
+	return plan, nil
+}
+
+// planTx chooses the PRV coins of a tx as chooseOutsCoinByKeyset does. When they do not fit in a tx it
+// chooses them again with no limit, and completes the plan with the change, size and limits.
+func (txService TxService) planTx(
+	ctx context.Context,
+	paymentInfos []*privacy.PaymentInfo,
+	unitFeeNativeToken int64,
+	keySet *incognitokey.KeySet,
+	shardIDSender byte,
+	hasPrivacy bool,
+	meta metadata.Metadata,
+	privacyCustomTokenParams *transaction.CustomTokenPrivacyParamTx,
+) (*TxPlan, error) {
+	maxInputs := maxInputCoins(len(paymentInfos)+1, hasPrivacy, meta, privacyCustomTokenParams)
+	plan, err := txService.planOutsCoinByKeyset(ctx, paymentInfos, unitFeeNativeToken, 0, keySet, shardIDSender, hasPrivacy, meta, privacyCustomTokenParams, maxInputs)
+	if errors.Is(err, rpcclient.ErrTxTooLarge) {
+		plan, err = txService.planOutsCoinByKeyset(ctx, paymentInfos, unitFeeNativeToken, 0, keySet, shardIDSender, hasPrivacy, meta, privacyCustomTokenParams, noInputLimit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	numPayments := len(paymentInfos)
+	if plan.InputAmount > plan.PaymentAmount+plan.Fee {
+		plan.Change = plan.InputAmount - plan.PaymentAmount - plan.Fee
+		numPayments++
+	}
+	plan.SizeInKb = txService.estimateTxSizeInKb(len(plan.InputCoins), numPayments, shardIDSender, hasPrivacy, meta, privacyCustomTokenParams)
+	plan.ExceedsMaxTxSize = plan.SizeInKb > common.MaxTxSize
+	plan.ExceedsMaxInputs = len(plan.InputCoins) > transaction.MaxInputCoins
+	return plan, nil
+}
+
+// validateMetadata runs the checks the tx would run on meta when it is built
+func validateMetadata(meta metadata.Metadata) error {
+	if meta == nil {
+		return nil
+	}
+	err := meta.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid metadata: %w", err)
+	}
+	return nil
+}
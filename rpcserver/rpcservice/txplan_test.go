@@ -0,0 +1,133 @@
+package rpcservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/incognitochain/go-incognito-sdk/simulator"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFundedAccount returns the private key and the payment address of a new account, given a coin of tokenID on sim
+// for each of amounts
+func newFundedAccount(t *testing.T, sim *simulator.Simulator, tokenID string, amounts ...uint64) (string, string) {
+	account, err := wallet.CreateNewAccount()
+	assert.NoError(t, err)
+	paymentAddress := account.Key.Base58CheckSerialize(wallet.PaymentAddressType)
+	for _, amount := range amounts {
+		assert.NoError(t, sim.Fund(paymentAddress, tokenID, amount))
+	}
+	return account.Key.Base58CheckSerialize(wallet.PriKeyType), paymentAddress
+}
+
+// newTestTxService returns the TxService building the txs of the private key first in params
+func newTestTxService(t *testing.T, sim *simulator.Simulator, params []interface{}) rpcservice.TxService {
+	keyWallet, err := bean.GetPrivateKey(params)
+	assert.NoError(t, err)
+	return rpcservice.TxService{
+		RpcClient: rpcclient.NewHttpClient(sim.URL(), "", "", 0),
+		KeyWallet: keyWallet,
+	}
+}
+
+func TestPlanRawTransaction(t *testing.T) {
+	sim := simulator.New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender, senderAddress := newFundedAccount(t, sim, prv, 100000, 200000)
+	_, receiver := newFundedAccount(t, sim, prv)
+
+	// the plan matches the tx built from the same params
+	params := []interface{}{sender, map[string]uint64{receiver: 250000}, 5, 1}
+	txService := newTestTxService(t, sim, params)
+	createRawTxParam, err := bean.NewCreateRawTxParam(params)
+	assert.NoError(t, err)
+	plan, err := txService.PlanRawTransaction(context.Background(), createRawTxParam, nil)
+	assert.NoError(t, err)
+	assert.Len(t, plan.InputCoins, 2)
+	assert.Equal(t, uint64(300000), plan.InputAmount)
+	assert.Equal(t, uint64(250000), plan.PaymentAmount)
+	assert.True(t, plan.Fee > 0)
+	assert.Equal(t, plan.FeePerKb*plan.SizeInKb, plan.Fee)
+	assert.Equal(t, 300000-250000-plan.Fee, plan.Change)
+	assert.False(t, plan.ExceedsMaxInputs)
+	assert.False(t, plan.ExceedsMaxTxSize)
+	assert.False(t, plan.Insufficient)
+
+	tx, err := txService.BuildRawTransaction(context.Background(), createRawTxParam, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plan.Fee, tx.Fee)
+	assert.Len(t, tx.Proof.GetInputCoins(), 2)
+
+	// the metadata is checked as building the tx would
+	sameTokens, _ := metadata.NewPDETradeRequest(prv, prv, 100, 90, 1, senderAddress, metadata.PDETradeRequestMeta)
+	_, err = txService.PlanRawTransaction(context.Background(), createRawTxParam, sameTokens)
+	assert.Error(t, err)
+
+	// coins worth the amount but not the fee are not enough
+	_, receiverOfAll := newFundedAccount(t, sim, prv)
+	params = []interface{}{sender, map[string]uint64{receiverOfAll: 300000}, 5, 1}
+	createRawTxParam, err = bean.NewCreateRawTxParam(params)
+	assert.NoError(t, err)
+	plan, err = txService.PlanRawTransaction(context.Background(), createRawTxParam, nil)
+	assert.NoError(t, err)
+	assert.True(t, plan.Insufficient)
+	_, err = txService.BuildRawTransaction(context.Background(), createRawTxParam, nil)
+	assert.True(t, errors.Is(err, rpcclient.ErrNotEnoughCoin), "%v", err)
+
+	// too many coins are reported rather than failing
+	amounts := make([]uint64, transaction.MaxInputCoins+10)
+	for i := range amounts {
+		amounts[i] = 1000
+	}
+	dusty, _ := newFundedAccount(t, sim, prv, amounts...)
+	params = []interface{}{dusty, map[string]uint64{receiver: 260000}, 5, 1}
+	createRawTxParam, err = bean.NewCreateRawTxParam(params)
+	assert.NoError(t, err)
+	plan, err = newTestTxService(t, sim, params).PlanRawTransaction(context.Background(), createRawTxParam, nil)
+	assert.NoError(t, err)
+	assert.True(t, len(plan.InputCoins) > transaction.MaxInputCoins)
+	assert.True(t, plan.ExceedsMaxInputs)
+	assert.True(t, plan.ExceedsMaxTxSize)
+}
+
+func TestPlanRawPrivacyCustomTokenTransaction(t *testing.T) {
+	sim := simulator.New()
+	defer sim.Close()
+
+	tokenID := "ffd8d42dc40a8d166ea4848baf8b5f6e9fe0e9c30d60062eb7d44a8df9e00854"
+	assert.NoError(t, sim.RegisterToken(tokenID, "Ether", "pETH"))
+	sender, senderAddress := newFundedAccount(t, sim, tokenID, 5000, 3000)
+	assert.NoError(t, sim.Fund(senderAddress, common.PRVCoinID.String(), 100000))
+	_, receiver := newFundedAccount(t, sim, tokenID)
+
+	tokenData := map[string]interface{}{
+		"Privacy":        true,
+		"TokenID":        tokenID,
+		"TokenTxType":    transaction.CustomTokenTransfer,
+		"TokenName":      "",
+		"TokenSymbol":    "",
+		"TokenReceivers": map[string]uint64{receiver: 6000},
+		"TokenAmount":    uint64(0),
+		"TokenFee":       uint64(0),
+	}
+	params := []interface{}{sender, map[string]uint64{}, 5, 1, tokenData, "", 0}
+	plan, err := newTestTxService(t, sim, params).PlanRawPrivacyCustomTokenTransaction(context.Background(), params, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, tokenID, plan.Token.TokenID)
+	assert.Len(t, plan.Token.InputCoins, 2)
+	assert.Equal(t, uint64(8000), plan.Token.InputAmount)
+	assert.Equal(t, uint64(6000), plan.Token.PaymentAmount)
+	assert.Equal(t, uint64(2000), plan.Token.Change)
+	assert.True(t, plan.Fee > 0)
+	assert.NotEmpty(t, plan.InputCoins)
+}
@@ -1,7 +1,9 @@
 package rpcservice
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/incognitokey"
 	"github.com/incognitochain/go-incognito-sdk/metadata"
@@ -11,20 +13,30 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/transaction"
 )
 
-func (txService TxService) BuildRawPrivacyCustomTokenTransaction(params interface{}, metaData metadata.Metadata) (*transaction.TxCustomTokenPrivacy, error) {
+func (txService TxService) BuildRawPrivacyCustomTokenTransaction(ctx context.Context, params interface{}, metaData metadata.Metadata) (*transaction.TxCustomTokenPrivacy, error) {
 	txParam, errParam := bean.NewCreateRawPrivacyTokenTxParam(params)
 	if errParam != nil {
 		return nil, errParam
 	}
 	tokenParamsRaw := txParam.TokenParamsRaw
 	var err error
-	tokenParams, err := txService.buildTokenParam(tokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender)
+	var tokenParams *transaction.CustomTokenPrivacyParamTx
+	tokenID, _ := tokenParamsRaw["TokenID"].(string)
+	tokenReservation, err := txService.reserveChosenCoins(tokenID, func() ([]*privacy.OutputCoin, error) {
+		var err error
+		tokenParams, err = txService.buildTokenParam(ctx, tokenParamsRaw, txParam.SenderKeySet, txParam.ShardIDSender, true)
+		if err != nil || tokenParams == nil {
+			return nil, err
+		}
+		return tokenParams.TokenOutput, nil
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
 	if tokenParams == nil {
+		tokenReservation.Release()
 		return nil, errors.New("can not build token params for request")
 	}
 
@@ -33,20 +45,26 @@ func (txService TxService) BuildRawPrivacyCustomTokenTransaction(params interfac
 	var outputPrvCoins []*privacy.OutputCoin
 	realFeePRV := uint64(0)
 
-	inputCoins, outputPrvCoins, realFeePRV, err = txService.chooseOutsCoinByKeyset(
-		txParam.PaymentInfos,
-		txParam.EstimateFeeCoinPerKb,
-		0,
-		txParam.SenderKeySet,
-		txParam.ShardIDSender,
-		txParam.HasPrivacyCoin,
-		nil,
-		tokenParams,
-		txParam.IsGetPTokenFee,
-		txParam.UnitPTokenFee,
-	)
+	reservation, err := txService.reserveChosenCoins(common.PRVCoinID.String(), func() ([]*privacy.OutputCoin, error) {
+		var err error
+		inputCoins, outputPrvCoins, realFeePRV, err = txService.chooseOutsCoinByKeyset(
+			ctx,
+			txParam.PaymentInfos,
+			txParam.EstimateFeeCoinPerKb,
+			0,
+			txParam.SenderKeySet,
+			txParam.ShardIDSender,
+			txParam.HasPrivacyCoin,
+			nil,
+			tokenParams,
+			txParam.IsGetPTokenFee,
+			txParam.UnitPTokenFee,
+		)
+		return outputPrvCoins, err
+	})
 
 	if err != nil {
+		tokenReservation.Release()
 		return nil, err
 	}
 
@@ -55,7 +73,8 @@ func (txService TxService) BuildRawPrivacyCustomTokenTransaction(params interfac
 	}
 	/******* END GET output coins native coins(PRV), which is used to create tx *****/
 	tx := &transaction.TxCustomTokenPrivacy{}
-	err = tx.Init(
+	err = tx.InitWithContext(
+		ctx,
 		transaction.NewTxPrivacyTokenInitParams(
 			&txParam.SenderKeySet.PrivateKey,
 			txParam.PaymentInfos,
@@ -74,13 +93,18 @@ func (txService TxService) BuildRawPrivacyCustomTokenTransaction(params interfac
 	)
 
 	if err != nil {
+		tokenReservation.Release()
+		reservation.Release()
 		return nil, err
 	}
 
+	txService.holdReservation(tokenReservation, tx.Hash().String())
+	txService.holdReservation(reservation, tx.Hash().String())
 	return tx, nil
 }
 
-func (txService TxService) buildTokenParam(tokenParamsRaw map[string]interface{}, senderKeySet *incognitokey.KeySet, shardIDSender byte) (*transaction.CustomTokenPrivacyParamTx, error) {
+// buildTokenParam builds the token part of a tx, limitInputs caps the token coins it spends to what fits in a tx
+func (txService TxService) buildTokenParam(ctx context.Context, tokenParamsRaw map[string]interface{}, senderKeySet *incognitokey.KeySet, shardIDSender byte, limitInputs bool) (*transaction.CustomTokenPrivacyParamTx, error) {
 	var privacyTokenParam *transaction.CustomTokenPrivacyParamTx
 	var err error
 
@@ -92,7 +116,7 @@ func (txService TxService) buildTokenParam(tokenParamsRaw map[string]interface{}
 		// Check normal custom token param
 	} else {
 		// Check privacy custom token param
-		privacyTokenParam, _, err = txService.buildPrivacyCustomTokenParam(tokenParamsRaw, senderKeySet, shardIDSender)
+		privacyTokenParam, _, err = txService.buildPrivacyCustomTokenParam(ctx, tokenParamsRaw, senderKeySet, shardIDSender, limitInputs)
 		if err != nil {
 			return nil, err
 		}
@@ -102,9 +126,11 @@ func (txService TxService) buildTokenParam(tokenParamsRaw map[string]interface{}
 }
 
 func (txService TxService) buildPrivacyCustomTokenParam(
+	ctx context.Context,
 	tokenParamsRaw map[string]interface{},
 	senderKeySet *incognitokey.KeySet,
 	shardIDSender byte,
+	limitInputs bool,
 ) (*transaction.CustomTokenPrivacyParamTx, map[common.Hash]transaction.TxCustomTokenPrivacy, error) {
 	property, ok := tokenParamsRaw["TokenID"].(string)
 	if !ok {
@@ -149,7 +175,15 @@ func (txService TxService) buildPrivacyCustomTokenParam(
 	voutsAmount := int64(0)
 	var err1 error
 
-	tokenParams.Receiver, voutsAmount, err1 = transaction.CreateCustomTokenPrivacyReceiverArray(tokenParamsRaw["TokenReceivers"])
+	// TokenReceivers are amounts by payment address or a list of bean.Receiver
+	if receivers, ok := tokenParamsRaw["TokenReceivers"].([]bean.Receiver); ok {
+		tokenParams.Receiver, err1 = bean.NewPaymentInfosFromReceivers(receivers)
+		for _, receiver := range receivers {
+			voutsAmount += int64(receiver.Amount)
+		}
+	} else {
+		tokenParams.Receiver, voutsAmount, err1 = transaction.CreateCustomTokenPrivacyReceiverArray(tokenParamsRaw["TokenReceivers"])
+	}
 	if err1 != nil {
 		return nil, nil, err1
 	}
@@ -164,16 +198,20 @@ func (txService TxService) buildPrivacyCustomTokenParam(
 				return nil, nil, err
 			}
 
-			outputTokens, err := rpcclient.GetUnspentOutputCoins(txService.RpcClient, txService.KeyWallet, tokenID)
+			outputTokens, err := txService.spendableOutputCoins(ctx, tokenID)
 			if err != nil {
 				return nil, nil, err
 			}
 
 			if len(outputTokens) == 0 && voutsAmount > 0 {
-				return nil, nil, errors.New("not enough output coin")
+				return nil, nil, fmt.Errorf("%w: no output token to spend", rpcclient.ErrNotEnoughCoin)
 			}
 
-			candidateOutputTokens, _, _, err := txService.chooseBestOutCoinsToSpent(outputTokens, uint64(voutsAmount))
+			maxInputs := noInputLimit
+			if limitInputs {
+				maxInputs = maxInputCoins(len(tokenParams.Receiver)+1, true, nil, nil)
+			}
+			candidateOutputTokens, _, _, err := txService.coinSelector().SelectCoins(outputTokens, uint64(voutsAmount), maxInputs)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1,6 +1,7 @@
 package rpcservice
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk/common"
@@ -12,7 +13,6 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcserver/bean"
 	"github.com/incognitochain/go-incognito-sdk/transaction"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
-	"sort"
 )
 
 type TxService struct {
@@ -20,22 +20,52 @@ type TxService struct {
 	Wallet       *wallet.Wallet
 	KeyWallet    *wallet.KeyWallet
 	FeeEstimator map[byte]*mempool.FeeEstimator
+	// CoinSelector chooses the coins to spend, nil means DefaultCoinSelector
+	CoinSelector CoinSelector
+	// CoinReservation reserves the coins the txs spend in its CoinLocker, nil reserves none
+	CoinReservation *CoinReservation
 }
 
-func (txService TxService) BuildRawTransaction(params *bean.CreateRawTxParam, meta metadata.Metadata) (*transaction.Tx, error) {
+func (txService TxService) coinSelector() CoinSelector {
+	if txService.CoinSelector != nil {
+		return txService.CoinSelector
+	}
+	return DefaultCoinSelector{}
+}
+
+// maxInputCoins returns how many coins a tx paying numPayments outputs can spend without exceeding common.MaxTxSize
+func maxInputCoins(numPayments int, hasPrivacy bool, meta metadata.Metadata, privacyCustomTokenParams *transaction.CustomTokenPrivacyParamTx) int {
+	for numInputs := transaction.MaxInputCoins; numInputs > 1; numInputs-- {
+		estimateTxSizeParam := transaction.NewEstimateTxSizeParam(numInputs, numPayments, hasPrivacy, meta, privacyCustomTokenParams, 0)
+		if transaction.EstimateTxSize(estimateTxSizeParam) <= common.MaxTxSize {
+			return numInputs
+		}
+	}
+	return 1
+}
+
+func (txService TxService) BuildRawTransaction(ctx context.Context, params *bean.CreateRawTxParam, meta metadata.Metadata) (*transaction.Tx, error) {
 	// get output coins to spend and real fee
-	inputCoins, outputCoin, realFee, err := txService.chooseOutsCoinByKeyset(
-		params.PaymentInfos,
-		params.EstimateFeeCoinPerKb,
-		0,
-		params.SenderKeySet,
-		params.ShardIDSender,
-		params.HasPrivacyCoin,
-		meta,
-		nil,
-		false,
-		int64(0),
-	)
+	var inputCoins []*privacy.InputCoin
+	var outputCoin []*privacy.OutputCoin
+	var realFee uint64
+	reservation, err := txService.reserveChosenCoins(common.PRVCoinID.String(), func() ([]*privacy.OutputCoin, error) {
+		var err error
+		inputCoins, outputCoin, realFee, err = txService.chooseOutsCoinByKeyset(
+			ctx,
+			params.PaymentInfos,
+			params.EstimateFeeCoinPerKb,
+			0,
+			params.SenderKeySet,
+			params.ShardIDSender,
+			params.HasPrivacyCoin,
+			meta,
+			nil,
+			false,
+			int64(0),
+		)
+		return outputCoin, err
+	})
 
 	if err != nil {
 		return nil, err
@@ -44,7 +74,8 @@ func (txService TxService) BuildRawTransaction(params *bean.CreateRawTxParam, me
 	// init tx
 	tx := transaction.Tx{}
 
-	err = tx.Init(
+	err = tx.InitWithContext(
+		ctx,
 		transaction.NewTxPrivacyInitParams(
 			&params.SenderKeySet.PrivateKey,
 			params.PaymentInfos,
@@ -61,12 +92,124 @@ func (txService TxService) BuildRawTransaction(params *bean.CreateRawTxParam, me
 	)
 
 	if err != nil {
+		reservation.Release()
 		return nil, err
 	}
+	txService.holdReservation(reservation, tx.Hash().String())
 	return &tx, nil
 }
 
+// PrepareRawTransaction is the online half of BuildRawTransaction: it chooses the coins to spend and fetches
+// what the proof needs, the returned tx is signed offline with transaction.UnsignedTx.Sign.
+// KeyWallet may be watch-only, holding the payment address and readonly key only.
+func (txService TxService) PrepareRawTransaction(ctx context.Context, params *bean.CreateRawTxParam) (*transaction.UnsignedTx, error) {
+	// get output coins to spend and real fee
+	var outputCoins []*privacy.OutputCoin
+	var realFee uint64
+	reservation, err := txService.reserveChosenCoins(common.PRVCoinID.String(), func() ([]*privacy.OutputCoin, error) {
+		var err error
+		_, outputCoins, realFee, err = txService.chooseOutsCoinByKeyset(
+			ctx,
+			params.PaymentInfos,
+			params.EstimateFeeCoinPerKb,
+			0,
+			params.SenderKeySet,
+			params.ShardIDSender,
+			params.HasPrivacyCoin,
+			nil,
+			nil,
+			false,
+			int64(0),
+		)
+		return outputCoins, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the id of the tx is only known once signed, the coins stay reserved until they are spent or time out
+	unsignedTx, err := transaction.PrepareUnsignedTxWithContext(
+		ctx,
+		txService.RpcClient,
+		&transaction.UnsignedTxParams{
+			Sender:       txService.KeyWallet.Base58CheckSerialize(wallet.PaymentAddressType),
+			PaymentInfos: params.PaymentInfos,
+			InputCoins:   outputCoins,
+			Fee:          realFee,
+			HasPrivacy:   params.HasPrivacyCoin,
+			Info:         params.Info,
+		},
+	)
+	if err != nil {
+		reservation.Release()
+		return nil, err
+	}
+	txService.holdReservation(reservation, "")
+	return unsignedTx, nil
+}
+
+// spendableOutputCoins returns the unspent coins of the account. A watch-only account cannot tell its spent coins
+// apart, which needs the private key, so all of its coins are returned.
+// With a CoinReservation, the coins reserved for pending txs are left out.
+func (txService TxService) spendableOutputCoins(ctx context.Context, tokenID *common.Hash) ([]*privacy.OutputCoin, error) {
+	var outCoins []*privacy.OutputCoin
+	var err error
+	if len(txService.KeyWallet.KeySet.PrivateKey) == 0 {
+		outCoins, err = rpcclient.ListOutputCoinsWithContext(
+			ctx,
+			txService.RpcClient,
+			txService.KeyWallet.Base58CheckSerialize(wallet.PaymentAddressType),
+			txService.KeyWallet.Base58CheckSerialize(wallet.ReadonlyKeyType),
+			tokenID,
+		)
+	} else {
+		outCoins, err = rpcclient.GetUnspentOutputCoinsWithContext(ctx, txService.RpcClient, txService.KeyWallet, tokenID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if txService.CoinReservation != nil {
+		outCoins, _ = txService.CoinReservation.locker.Available(txService.account(), tokenID.String(), outCoins)
+	}
+	return outCoins, nil
+}
+
+func (txService TxService) account() string {
+	return txService.KeyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+}
+
+// reserveChosenCoins reserves the coins of tokenID returned by choose, when the TxService has a CoinReservation.
+// A concurrent send may reserve one of them between their listing and their reservation, they are then chosen again.
+func (txService TxService) reserveChosenCoins(tokenID string, choose func() ([]*privacy.OutputCoin, error)) (*CoinReservation, error) {
+	for attempt := 1; ; attempt++ {
+		outCoins, err := choose()
+		if err != nil {
+			return nil, err
+		}
+		if txService.CoinReservation == nil {
+			return nil, nil
+		}
+		chosen, err := txService.CoinReservation.locker.Reserve(txService.account(), tokenID, outCoins)
+		if errors.Is(err, ErrCoinReserved) && attempt < maxReserveAttempts {
+			continue
+		}
+		return chosen, err
+	}
+}
+
+// holdReservation keeps the coins of chosen, spent by the tx txID, reserved under the CoinReservation of the TxService
+// until the tx is confirmed or rejected
+func (txService TxService) holdReservation(chosen *CoinReservation, txID string) {
+	if chosen == nil {
+		return
+	}
+	chosen.bind(txID)
+	txService.CoinReservation.add(chosen)
+}
+
 func (txService TxService) chooseOutsCoinByKeyset(
+	ctx context.Context,
 	paymentInfos []*privacy.PaymentInfo,
 	unitFeeNativeToken int64,
 	numBlock uint64,
@@ -78,6 +221,46 @@ func (txService TxService) chooseOutsCoinByKeyset(
 	isGetFeePToken bool,
 	unitFeePToken int64,
 ) ([]*privacy.InputCoin, []*privacy.OutputCoin, uint64, error) {
+	// the change output is counted in the payments
+	maxInputs := maxInputCoins(len(paymentInfos)+1, hasPrivacy, metadataParam, privacyCustomTokenParams)
+	plan, err := txService.planOutsCoinByKeyset(
+		ctx,
+		paymentInfos,
+		unitFeeNativeToken,
+		numBlock,
+		keySet,
+		shardIDSender,
+		hasPrivacy,
+		metadataParam,
+		privacyCustomTokenParams,
+		maxInputs,
+	)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if plan.Insufficient {
+		return nil, nil, 0, fmt.Errorf("%w: the coins are worth %d, the tx pays %d and a fee of %d", rpcclient.ErrNotEnoughCoin, plan.InputAmount, plan.PaymentAmount, plan.Fee)
+	}
+
+	// convert to inputcoins
+	inputCoins := transaction.ConvertOutputCoinToInputCoin(plan.InputCoins)
+	return inputCoins, plan.InputCoins, plan.Fee, nil
+}
+
+// planOutsCoinByKeyset chooses the coins paying paymentInfos and the fee, maxInputs coins at most, and fills
+// the coins, amounts and fee of the returned plan
+func (txService TxService) planOutsCoinByKeyset(
+	ctx context.Context,
+	paymentInfos []*privacy.PaymentInfo,
+	unitFeeNativeToken int64,
+	numBlock uint64,
+	keySet *incognitokey.KeySet,
+	shardIDSender byte,
+	hasPrivacy bool,
+	metadataParam metadata.Metadata,
+	privacyCustomTokenParams *transaction.CustomTokenPrivacyParamTx,
+	maxInputs int,
+) (*TxPlan, error) {
 	// calculate total amount to send
 	totalAmmount := uint64(0)
 	for _, receiver := range paymentInfos {
@@ -88,22 +271,23 @@ func (txService TxService) chooseOutsCoinByKeyset(
 	prvCoinID := &common.Hash{}
 	err := prvCoinID.SetBytes(common.PRVCoinID[:])
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
 
-	outCoins, err := rpcclient.GetUnspentOutputCoins(txService.RpcClient, txService.KeyWallet, prvCoinID)
+	outCoins, err := txService.spendableOutputCoins(ctx, prvCoinID)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
 
 	if len(outCoins) == 0 && totalAmmount > 0 {
-		return nil, nil, 0, errors.New("not enough output coin")
+		return nil, fmt.Errorf("%w: no output coin to spend", rpcclient.ErrNotEnoughCoin)
 	}
 
-	// Use Knapsack to get candiate output coin
-	candidateOutputCoins, outCoins, candidateOutputCoinAmount, err := txService.chooseBestOutCoinsToSpent(outCoins, totalAmmount)
+	selector := txService.coinSelector()
+	allOutCoins := outCoins
+	candidateOutputCoins, outCoins, candidateOutputCoinAmount, err := selector.SelectCoins(outCoins, totalAmmount, maxInputs)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
 
 	//todo
@@ -117,7 +301,8 @@ func (txService TxService) chooseOutsCoinByKeyset(
 		})
 	}
 
-	realFee, _, _, err := txService.estimateFee(
+	realFee, estimateFeeCoinPerKb, _, err := txService.estimateFee(
+		ctx,
 		unitFeeNativeToken,
 		false,
 		candidateOutputCoins,
@@ -131,7 +316,7 @@ func (txService TxService) chooseOutsCoinByKeyset(
 	)
 
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
 	}
 
 	if totalAmmount == 0 && realFee == 0 {
@@ -140,82 +325,55 @@ func (txService TxService) chooseOutsCoinByKeyset(
 			switch metadataType {
 			case metadata.WithDrawRewardRequestMeta:
 				{
-					return nil, nil, realFee, nil
+					return &TxPlan{Fee: realFee, FeePerKb: estimateFeeCoinPerKb}, nil
 				}
 			}
-			return nil, nil, realFee, fmt.Errorf("totalAmmount: %+v, realFee: %+v", totalAmmount, realFee)
+			return nil, fmt.Errorf("totalAmmount: %+v, realFee: %+v", totalAmmount, realFee)
 		}
 
 		if privacyCustomTokenParams != nil {
 			// for privacy token
-			return nil, nil, 0, nil
+			return &TxPlan{FeePerKb: estimateFeeCoinPerKb}, nil
 		}
 	}
 
 	needToPayFee := int64((totalAmmount + realFee) - candidateOutputCoinAmount)
 	// if not enough to pay fee
 	if needToPayFee > 0 {
-		if len(outCoins) > 0 {
-			candidateOutputCoinsForFee, _, _, err1 := txService.chooseBestOutCoinsToSpent(outCoins, uint64(needToPayFee))
+		// choose again for the amount and the fee together, an exact match then leaves no change
+		reselected, _, reselectedAmount, errSelect := selector.SelectCoins(allOutCoins, totalAmmount+realFee, maxInputs)
+		numPayments := len(paymentInfos)
+		if overBalanceAmount > 0 {
+			numPayments--
+		}
+		if reselectedAmount > totalAmmount+realFee {
+			numPayments++
+		}
+		reselectedFee := estimateFeeCoinPerKb * txService.estimateTxSizeInKb(len(reselected), numPayments, shardIDSender, hasPrivacy, metadataParam, privacyCustomTokenParams)
+		if errSelect == nil && reselectedFee <= realFee {
+			candidateOutputCoins = reselected
+		} else if len(outCoins) > 0 {
+			candidateOutputCoinsForFee, _, _, err1 := selector.SelectCoins(outCoins, uint64(needToPayFee), maxInputs-len(candidateOutputCoins))
 			if err1 != nil {
-				return nil, nil, 0, err1
+				return nil, err1
 			}
 			candidateOutputCoins = append(candidateOutputCoins, candidateOutputCoinsForFee...)
 		}
 	}
 
-	// convert to inputcoins
-	inputCoins := transaction.ConvertOutputCoinToInputCoin(candidateOutputCoins)
-	return inputCoins, candidateOutputCoins, realFee, nil
-}
-
-func (txService TxService) chooseBestOutCoinsToSpent(outCoins []*privacy.OutputCoin, amount uint64) (resultOutputCoins []*privacy.OutputCoin, remainOutputCoins []*privacy.OutputCoin, totalResultOutputCoinAmount uint64, err error) {
-	resultOutputCoins = make([]*privacy.OutputCoin, 0)
-	remainOutputCoins = make([]*privacy.OutputCoin, 0)
-	totalResultOutputCoinAmount = uint64(0)
-
-	// either take the smallest coins, or a single largest one
-	var outCoinOverLimit *privacy.OutputCoin
-	outCoinsUnderLimit := make([]*privacy.OutputCoin, 0)
-	for _, outCoin := range outCoins {
-		if outCoin.CoinDetails.GetValue() < amount {
-			outCoinsUnderLimit = append(outCoinsUnderLimit, outCoin)
-		} else if outCoinOverLimit == nil {
-			outCoinOverLimit = outCoin
-		} else if outCoinOverLimit.CoinDetails.GetValue() > outCoin.CoinDetails.GetValue() {
-			remainOutputCoins = append(remainOutputCoins, outCoin)
-		} else {
-			remainOutputCoins = append(remainOutputCoins, outCoinOverLimit)
-			outCoinOverLimit = outCoin
-		}
-	}
-	sort.Slice(outCoinsUnderLimit, func(i, j int) bool {
-		return outCoinsUnderLimit[i].CoinDetails.GetValue() < outCoinsUnderLimit[j].CoinDetails.GetValue()
-	})
-	for _, outCoin := range outCoinsUnderLimit {
-		if totalResultOutputCoinAmount < amount {
-			totalResultOutputCoinAmount += outCoin.CoinDetails.GetValue()
-			resultOutputCoins = append(resultOutputCoins, outCoin)
-		} else {
-			remainOutputCoins = append(remainOutputCoins, outCoin)
-		}
-	}
-	if outCoinOverLimit != nil && (outCoinOverLimit.CoinDetails.GetValue() > 2*amount || totalResultOutputCoinAmount < amount) {
-		remainOutputCoins = append(remainOutputCoins, resultOutputCoins...)
-		resultOutputCoins = []*privacy.OutputCoin{outCoinOverLimit}
-		totalResultOutputCoinAmount = outCoinOverLimit.CoinDetails.GetValue()
-	} else if outCoinOverLimit != nil {
-		remainOutputCoins = append(remainOutputCoins, outCoinOverLimit)
-	}
-
-	if totalResultOutputCoinAmount < amount {
-		return resultOutputCoins, remainOutputCoins, totalResultOutputCoinAmount, errors.New("Not enough coin")
-	} else {
-		return resultOutputCoins, remainOutputCoins, totalResultOutputCoinAmount, nil
-	}
+	inputAmount := sumCoins(candidateOutputCoins)
+	return &TxPlan{
+		InputCoins:    candidateOutputCoins,
+		InputAmount:   inputAmount,
+		PaymentAmount: totalAmmount,
+		Fee:           realFee,
+		FeePerKb:      estimateFeeCoinPerKb,
+		Insufficient:  inputAmount < totalAmmount+realFee,
+	}, nil
 }
 
 func (txService TxService) estimateFee(
+	ctx context.Context,
 	defaultFee int64,
 	isGetPTokenFee bool,
 	candidateOutputCoins []*privacy.OutputCoin,
@@ -241,10 +399,16 @@ func (txService TxService) estimateFee(
 		tokenId = nil
 	}
 
+	// a privacy token tx with no unit fee pays its fee in the token, see CustomTokenPrivacyParamTx.Fee
+	if defaultFee == 0 && privacyCustomTokenParams != nil && privacyCustomTokenParams.Fee > 0 {
+		estimateTxSizeInKb = txService.estimateTxSizeInKb(len(candidateOutputCoins), len(paymentInfos), shardID, hasPrivacy, metadata, privacyCustomTokenParams)
+		return 0, 0, estimateTxSizeInKb, nil
+	}
+
 	paymentAddrStr := txService.KeyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
 
 	//payment address from private key
-	estimateFeeCoinPerKb, err := rpcclient.GetEstimateFeeWithEstimator(txService.RpcClient, defaultFee, paymentAddrStr, tokenId)
+	estimateFeeCoinPerKb, err := txService.estimateFeeCoinPerKb(ctx, defaultFee, numBlock, shardID, paymentAddrStr, tokenId)
 	if err != nil {
 		return 0, 0, 0, err
 	}
@@ -253,15 +417,25 @@ func (txService TxService) estimateFee(
 		estimateFeeCoinPerKb += uint64(txService.Wallet.GetConfig().IncrementalFee)
 	}
 
-	limitFee := uint64(0)
-	if feeEstimator, ok := txService.FeeEstimator[shardID]; ok {
-		limitFee = feeEstimator.GetLimitFeeForNativeToken()
-	}
-
-	estimateTxSizeInKb = transaction.EstimateTxSize(transaction.NewEstimateTxSizeParam(len(candidateOutputCoins), len(paymentInfos), hasPrivacy, metadata, privacyCustomTokenParams, limitFee))
+	estimateTxSizeInKb = txService.estimateTxSizeInKb(len(candidateOutputCoins), len(paymentInfos), shardID, hasPrivacy, metadata, privacyCustomTokenParams)
 	realFee = uint64(estimateFeeCoinPerKb) * uint64(estimateTxSizeInKb)
 
-	fmt.Println(fmt.Sprintf("default fee: %v, estimate fee: %v, estimate tx size (kb) %v, real fee %v", defaultFee, estimateFeeCoinPerKb, estimateTxSizeInKb, realFee))
+	common.Log.Debugf("default fee: %v, estimate fee: %v, estimate tx size (kb) %v, real fee %v", defaultFee, estimateFeeCoinPerKb, estimateTxSizeInKb, realFee)
 
 	return realFee, estimateFeeCoinPerKb, estimateTxSizeInKb, nil
 }
+
+func (txService TxService) estimateTxSizeInKb(
+	numInputCoins int,
+	numPayments int,
+	shardID byte,
+	hasPrivacy bool,
+	metadata metadata.Metadata,
+	privacyCustomTokenParams *transaction.CustomTokenPrivacyParamTx,
+) uint64 {
+	limitFee := uint64(0)
+	if feeEstimator := txService.feeEstimator(shardID); feeEstimator != nil {
+		limitFee = feeEstimator.GetLimitFeeForNativeToken()
+	}
+	return transaction.EstimateTxSize(transaction.NewEstimateTxSizeParam(numInputCoins, numPayments, hasPrivacy, metadata, privacyCustomTokenParams, limitFee))
+}
@@ -0,0 +1,594 @@
+// Package simulator is an in-memory Incognito chain for tests. It keeps the coin commitments,
+// serial numbers, SNDs and tokens a fullnode would, and answers the RPC calls the SDK makes to
+// build and send transactions, so send, defragment, staking and trade flows run offline.
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/privacy/zkp"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// DefaultFeePerKb is the fee per kb answered by estimatefeewithestimator unless SetFeePerKb is called
+const DefaultFeePerKb = 10
+
+// Token is a token known to the chain
+type Token struct {
+	ID     string
+	Name   string
+	Symbol string
+	// Amount is the total amount minted
+	Amount uint64
+}
+
+// ledger holds the coins of one token
+type ledger struct {
+	// commitments of every shard in the order they were added, the index is the one of randomcommitments
+	commitments map[byte][][]byte
+	// commitmentIndex maps a commitment of a shard to its index in commitments
+	commitmentIndex map[byte]map[string]uint64
+	serialNumbers   map[string]bool
+	// coins by public key of their owner, as they were stored in a transaction
+	coins map[string][]*privacy.OutputCoin
+}
+
+func newLedger() *ledger {
+	return &ledger{
+		commitments:     make(map[byte][][]byte),
+		commitmentIndex: make(map[byte]map[string]uint64),
+		serialNumbers:   make(map[string]bool),
+		coins:           make(map[string][]*privacy.OutputCoin),
+	}
+}
+
+// Chain is the state of the simulated network, it is safe for concurrent use
+type Chain struct {
+	mu       sync.Mutex
+	rand     *rand.Rand
+	feePerKb uint64
+	tokens   map[string]*Token
+	ledgers  map[string]*ledger
+	snds     map[string]bool
+	txs      map[string]*Tx
+	// dropped are the transactions evicted from the mempool, sending one again puts it back
+	dropped map[string]*Tx
+	// height is the best block height of every shard
+	height uint64
+	// hold keeps the sent transactions in the mempool until MineBlock
+	hold bool
+	// poolPairs are the pDEX pool pairs by the ids of their tokens, see SetPDEPoolPair
+	poolPairs map[string]*mempool.PDEPoolPair
+	// tradeStatuses are the statuses of the settled trades by the id of their request
+	tradeStatuses map[string]int
+}
+
+// Tx is a transaction accepted by the chain
+type Tx struct {
+	ID      string
+	ShardID byte
+	Fee     uint64
+	// TokenID is set for privacy token transactions
+	TokenID string
+	// TokenFee is the fee paid in the token by a privacy token transaction
+	TokenFee uint64
+	// Metadata is the raw metadata of the transaction, nil when it has none
+	Metadata json.RawMessage
+	// BlockHeight is the height of the block holding the transaction, zero while it is in the mempool
+	BlockHeight uint64
+
+	raw rawTx
+}
+
+// NewChain returns an empty chain knowing only PRV
+func NewChain() *Chain {
+	prv := common.PRVCoinID.String()
+	return &Chain{
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		feePerKb: DefaultFeePerKb,
+		tokens:   map[string]*Token{prv: {ID: prv, Name: "PRV", Symbol: "PRV"}},
+		ledgers:  map[string]*ledger{prv: newLedger()},
+		snds:     make(map[string]bool),
+		txs:      make(map[string]*Tx),
+		dropped:  make(map[string]*Tx),
+		height:   1,
+
+		poolPairs:     make(map[string]*mempool.PDEPoolPair),
+		tradeStatuses: make(map[string]int),
+	}
+}
+
+// SetFeePerKb sets the fee per kb answered by estimatefeewithestimator
+func (c *Chain) SetFeePerKb(feePerKb uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.feePerKb = feePerKb
+}
+
+// SetPDEPoolPair sets the reserves of the pDEX pool of token1 and token2 answered by getpdestate
+func (c *Chain) SetPDEPoolPair(token1, token2 string, value1, value2 uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// a node keeps the token ids of a pool sorted
+	if token2 < token1 {
+		token1, token2 = token2, token1
+		value1, value2 = value2, value1
+	}
+	c.poolPairs[token1+"-"+token2] = &mempool.PDEPoolPair{
+		Token1IDStr:     token1,
+		Token1PoolValue: value1,
+		Token2IDStr:     token2,
+		Token2PoolValue: value2,
+	}
+}
+
+// PDEPoolPairs returns the pDEX pool pairs keyed as in getpdestate at beaconHeight
+func (c *Chain) PDEPoolPairs(beaconHeight uint64) map[string]mempool.PDEPoolPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	poolPairs := make(map[string]mempool.PDEPoolPair, len(c.poolPairs))
+	for tokens, poolPair := range c.poolPairs {
+		poolPairs[fmt.Sprintf("pdepool-%d-%s", beaconHeight, tokens)] = *poolPair
+	}
+	return poolPairs
+}
+
+// HoldInMempool keeps the transactions sent from now on in the mempool until MineBlock is called,
+// instead of adding each of them to a block of its own
+func (c *Chain) HoldInMempool(hold bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hold = hold
+}
+
+// MineBlock adds a block to every shard holding the transactions of the mempool and returns its height
+func (c *Chain) MineBlock() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.height++
+	for _, tx := range c.txs {
+		if tx.BlockHeight == 0 {
+			tx.BlockHeight = c.height
+			c.settleTrade(tx)
+		}
+	}
+	return c.height
+}
+
+// BestBlockHeight returns the height of the best block of every shard
+func (c *Chain) BestBlockHeight() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+// DropTransaction evicts the transaction txID from the mempool as a node does. Its coins stay spent,
+// so only sending the same transaction again is accepted, it then goes back to the mempool.
+func (c *Chain) DropTransaction(txID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.txs[txID]
+	if !ok {
+		return fmt.Errorf("transaction %s not found", txID)
+	}
+	if tx.BlockHeight != 0 {
+		return fmt.Errorf("transaction %s is in block %d", txID, tx.BlockHeight)
+	}
+	delete(c.txs, txID)
+	c.dropped[txID] = tx
+	return nil
+}
+
+// RegisterToken adds a token to the registry, tokenID is the hex string of its hash
+func (c *Chain) RegisterToken(tokenID, name, symbol string) error {
+	if _, err := (common.Hash{}).NewHashFromStr(tokenID); err != nil {
+		return fmt.Errorf("invalid token id %q: %v", tokenID, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.tokens[tokenID]; ok {
+		return fmt.Errorf("token %s is already registered", tokenID)
+	}
+	c.registerToken(tokenID, name, symbol)
+	return nil
+}
+
+func (c *Chain) registerToken(tokenID, name, symbol string) *Token {
+	token := &Token{ID: tokenID, Name: name, Symbol: symbol}
+	c.tokens[tokenID] = token
+	c.ledgers[tokenID] = newLedger()
+	return token
+}
+
+// Tokens returns the registered tokens, PRV included
+func (c *Chain) Tokens() []Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tokens := make([]Token, 0, len(c.tokens))
+	for _, token := range c.tokens {
+		tokens = append(tokens, *token)
+	}
+	return tokens
+}
+
+// Transaction returns the accepted transaction txID
+func (c *Chain) Transaction(txID string) (Tx, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.txs[txID]
+	if !ok {
+		return Tx{}, false
+	}
+	return *tx, true
+}
+
+// Fund mints a coin of amount of tokenID to paymentAddress, as a faucet would
+func (c *Chain) Fund(paymentAddress string, tokenID string, amount uint64) error {
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil {
+		return fmt.Errorf("invalid payment address: %v", err)
+	}
+	publicKey, err := new(privacy.Point).FromBytesS(keyWallet.KeySet.PaymentAddress.Pk)
+	if err != nil {
+		return fmt.Errorf("invalid payment address: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mint(publicKey, tokenID, amount)
+}
+
+// mint adds a coin of amount of tokenID owned by publicKey, c.mu must be held
+func (c *Chain) mint(publicKey *privacy.Point, tokenID string, amount uint64) error {
+	token, ok := c.tokens[tokenID]
+	if !ok {
+		return fmt.Errorf("token %s is not registered", tokenID)
+	}
+
+	coin := new(privacy.OutputCoin).Init()
+	coin.CoinDetailsEncrypted = nil
+	coin.CoinDetails.SetPublicKey(publicKey)
+	coin.CoinDetails.SetValue(amount)
+	coin.CoinDetails.SetRandomness(privacy.RandomScalar())
+	snd := privacy.RandomScalar()
+	for c.snds[string(snd.ToBytesS())] {
+		snd = privacy.RandomScalar()
+	}
+	coin.CoinDetails.SetSNDerivator(snd)
+	if err := coin.CoinDetails.CommitAll(); err != nil {
+		return err
+	}
+
+	c.addOutputCoin(c.ledgers[tokenID], coin)
+	token.Amount += amount
+	return nil
+}
+
+func (c *Chain) addOutputCoin(l *ledger, coin *privacy.OutputCoin) {
+	publicKey := coin.CoinDetails.GetPublicKey().ToBytesS()
+	shardID := common.GetShardIDFromLastByte(publicKey[len(publicKey)-1])
+	c.addCommitment(l, shardID, coin.CoinDetails.GetCoinCommitment().ToBytesS())
+
+	c.snds[string(coin.CoinDetails.GetSNDerivator().ToBytesS())] = true
+	l.coins[string(publicKey)] = append(l.coins[string(publicKey)], coin)
+}
+
+func (c *Chain) addCommitment(l *ledger, shardID byte, commitment []byte) {
+	if l.commitmentIndex[shardID] == nil {
+		l.commitmentIndex[shardID] = make(map[string]uint64)
+	}
+	l.commitmentIndex[shardID][string(commitment)] = uint64(len(l.commitments[shardID]))
+	l.commitments[shardID] = append(l.commitments[shardID], commitment)
+}
+
+// ledger returns the ledger of tokenID, an empty tokenID is PRV
+func (c *Chain) ledger(tokenID string) (*ledger, error) {
+	if tokenID == "" {
+		tokenID = common.PRVCoinID.String()
+	}
+	l, ok := c.ledgers[tokenID]
+	if !ok {
+		return nil, fmt.Errorf("token %s is not registered", tokenID)
+	}
+	return l, nil
+}
+
+// OutputCoins returns every coin of the owner of paymentAddress for tokenID, spent ones included.
+// Coins whose details are encrypted are decrypted with readonlyKey when it is given.
+func (c *Chain) OutputCoins(paymentAddress, readonlyKey, tokenID string) ([]*privacy.OutputCoin, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment address: %v", err)
+	}
+	var viewingKey *privacy.ViewingKey
+	if readonlyKey != "" {
+		readonlyWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readonly key: %v", err)
+		}
+		viewingKey = &readonlyWallet.KeySet.ReadonlyKey
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, err := c.ledger(tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make([]*privacy.OutputCoin, 0)
+	for _, stored := range l.coins[string(keyWallet.KeySet.PaymentAddress.Pk)] {
+		coin := new(privacy.OutputCoin)
+		if err := coin.SetBytes(stored.Bytes()); err != nil {
+			return nil, err
+		}
+		if coin.CoinDetailsEncrypted != nil && !coin.CoinDetailsEncrypted.IsNil() && viewingKey != nil {
+			if err := coin.Decrypt(*viewingKey); err != nil {
+				continue
+			}
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+// HasSerialNumbers tells for every serial number whether it was spent on tokenID
+func (c *Chain) HasSerialNumbers(serialNumbers [][]byte, tokenID string) ([]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, err := c.ledger(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]bool, len(serialNumbers))
+	for i, sn := range serialNumbers {
+		result[i] = l.serialNumbers[string(sn)]
+	}
+	return result, nil
+}
+
+// HasSNDerivators tells for every SND whether an output coin already uses it
+func (c *Chain) HasSNDerivators(snds [][]byte) []bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]bool, len(snds))
+	for i, snd := range snds {
+		result[i] = c.snds[string(snd)]
+	}
+	return result
+}
+
+// RandomCommitments builds the rings hiding the commitments of the coins to spend, like the randomcommitments RPC.
+// It returns CommitmentRingSize commitments per coin, the own one at a random place of its ring. The ledger is
+// padded with decoy commitments while it has fewer than a ring.
+func (c *Chain) RandomCommitments(shardID byte, commitments [][]byte, tokenID string) (indices []uint64, myIndices []uint64, ring [][]byte, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, err := c.ledger(tokenID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for len(l.commitments[shardID]) < privacy.CommitmentRingSize {
+		c.addCommitment(l, shardID, privacy.RandomPoint().ToBytesS())
+	}
+
+	for i, commitment := range commitments {
+		myIndex, ok := l.commitmentIndex[shardID][string(commitment)]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("commitment %s not found in shard %d", base58.Base58Check{}.Encode(commitment, common.ZeroByte), shardID)
+		}
+
+		position := c.rand.Intn(privacy.CommitmentRingSize)
+		picked := map[uint64]bool{myIndex: true}
+		for j := 0; j < privacy.CommitmentRingSize; j++ {
+			index := myIndex
+			if j != position {
+				for picked[index] {
+					index = uint64(c.rand.Intn(len(l.commitments[shardID])))
+				}
+				picked[index] = true
+			}
+			indices = append(indices, index)
+			ring = append(ring, l.commitments[shardID][index])
+		}
+		myIndices = append(myIndices, uint64(i*privacy.CommitmentRingSize+position))
+	}
+	return indices, myIndices, ring, nil
+}
+
+// EstimateFeePerKb is the answer of estimatefeewithestimator, never less than defaultFee
+func (c *Chain) EstimateFeePerKb(defaultFee int64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if defaultFee > 0 && uint64(defaultFee) > c.feePerKb {
+		return uint64(defaultFee)
+	}
+	return c.feePerKb
+}
+
+// rawTx is a transaction as serialized by the SDK. Metadata is kept raw, it is parsed only to hash the transaction.
+type rawTx struct {
+	Version              int8              `json:"Version"`
+	Type                 string            `json:"Type"`
+	LockTime             int64             `json:"LockTime"`
+	Fee                  uint64            `json:"Fee"`
+	Info                 []byte            `json:"Info"`
+	SigPubKey            []byte            `json:"SigPubKey"`
+	Proof                *zkp.PaymentProof `json:"Proof"`
+	PubKeyLastByteSender byte              `json:"PubKeyLastByteSender"`
+	Metadata             json.RawMessage   `json:"Metadata"`
+	TxTokenPrivacyData   *struct {
+		TxNormal       transaction.Tx
+		PropertyID     common.Hash
+		PropertyName   string
+		PropertySymbol string
+		Type           int
+		Mintable       bool
+		Amount         uint64
+	} `json:"TxTokenPrivacyData"`
+}
+
+// hash returns the id of the transaction; when its metadata type is not registered the raw metadata is hashed in place of it
+func (raw *rawTx) hash() string {
+	tx := transaction.Tx{Version: raw.Version, LockTime: raw.LockTime, Fee: raw.Fee, Proof: raw.Proof}
+	meta, err := metadata.ParseMetadata(raw.Metadata)
+	if err == nil {
+		tx.Metadata = meta
+	}
+	record := tx.String()
+	if err != nil {
+		record += common.HashH(raw.Metadata).String()
+	}
+	if data := raw.TxTokenPrivacyData; data != nil {
+		tokenData := transaction.TxPrivacyTokenData{
+			TxNormal:       data.TxNormal,
+			PropertyID:     data.PropertyID,
+			PropertyName:   data.PropertyName,
+			PropertySymbol: data.PropertySymbol,
+			Type:           data.Type,
+			Mintable:       data.Mintable,
+			Amount:         data.Amount,
+		}
+		tokenDataHash, _ := tokenData.Hash()
+		record += tokenDataHash.String()
+	}
+	return common.HashH([]byte(record)).String()
+}
+
+// SendTransaction accepts a transaction serialized as by the SDK: the JSON of the transaction, base58 check encoded.
+// Privacy token transactions are accepted too, the token of a CustomTokenInit one is registered.
+// The transaction is rejected when one of its serial numbers was already spent.
+func (c *Chain) SendTransaction(base58Data string) (*Tx, error) {
+	data, _, err := base58.Base58Check{}.Decode(base58Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base58 data: %v", err)
+	}
+	var raw rawTx
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %v", err)
+	}
+
+	tx := &Tx{
+		ID:      raw.hash(),
+		ShardID: common.GetShardIDFromLastByte(raw.PubKeyLastByteSender),
+		Fee:     raw.Fee,
+		raw:     raw,
+	}
+	if len(raw.Metadata) > 0 && string(raw.Metadata) != "null" {
+		tx.Metadata = raw.Metadata
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// a node answers double spend for a tx in a block, its serial numbers are spent
+	known, exists := c.txs[tx.ID]
+	if exists && known.BlockHeight == 0 {
+		return nil, fmt.Errorf("transaction %s already exists", tx.ID)
+	}
+	if dropped, ok := c.dropped[tx.ID]; ok {
+		delete(c.dropped, tx.ID)
+		c.txs[tx.ID] = dropped
+		c.mine(dropped)
+		return dropped, nil
+	}
+
+	prv := c.ledgers[common.PRVCoinID.String()]
+	if err := checkSerialNumbers(prv, raw.Proof); err != nil {
+		return nil, err
+	}
+
+	var tokenLedger *ledger
+	var tokenProof *zkp.PaymentProof
+	if data := raw.TxTokenPrivacyData; data != nil {
+		tx.TokenID = data.PropertyID.String()
+		tx.TokenFee = data.TxNormal.Fee
+		tokenProof = data.TxNormal.Proof
+		if l, ok := c.ledgers[tx.TokenID]; ok {
+			tokenLedger = l
+			if err := checkSerialNumbers(tokenLedger, tokenProof); err != nil {
+				return nil, err
+			}
+		} else if data.Type != transaction.CustomTokenInit {
+			return nil, fmt.Errorf("token %s is not registered", tx.TokenID)
+		}
+	}
+
+	if exists {
+		return nil, fmt.Errorf("transaction %s already exists", tx.ID)
+	}
+
+	c.apply(prv, raw.Proof)
+	if data := raw.TxTokenPrivacyData; data != nil {
+		if tokenLedger == nil {
+			token := c.registerToken(tx.TokenID, data.PropertyName, data.PropertySymbol)
+			token.Amount = data.Amount
+			tokenLedger = c.ledgers[tx.TokenID]
+		}
+		c.apply(tokenLedger, tokenProof)
+	}
+	c.txs[tx.ID] = tx
+	c.mine(tx)
+	return tx, nil
+}
+
+// mine adds tx to a block of its own unless the chain holds the transactions in the mempool, c.mu must be held
+func (c *Chain) mine(tx *Tx) {
+	if c.hold {
+		return
+	}
+	c.height++
+	tx.BlockHeight = c.height
+	c.settleTrade(tx)
+}
+
+// checkSerialNumbers rejects a proof spending a coin twice
+func checkSerialNumbers(l *ledger, proof *zkp.PaymentProof) error {
+	if proof == nil {
+		return nil
+	}
+	spent := make(map[string]bool)
+	for _, coin := range proof.GetInputCoins() {
+		sn := string(coin.CoinDetails.GetSerialNumber().ToBytesS())
+		if l.serialNumbers[sn] || spent[sn] {
+			return fmt.Errorf("%w: %s", rpcclient.ErrDoubleSpend,
+				base58.Base58Check{}.Encode(coin.CoinDetails.GetSerialNumber().ToBytesS(), common.ZeroByte))
+		}
+		spent[sn] = true
+	}
+	return nil
+}
+
+// apply spends the input coins of proof and stores its output coins
+func (c *Chain) apply(l *ledger, proof *zkp.PaymentProof) {
+	if proof == nil {
+		return
+	}
+	for _, coin := range proof.GetInputCoins() {
+		l.serialNumbers[string(coin.CoinDetails.GetSerialNumber().ToBytesS())] = true
+	}
+	for _, coin := range proof.GetOutputCoins() {
+		c.addOutputCoin(l, coin)
+	}
+}
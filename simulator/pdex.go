@@ -0,0 +1,146 @@
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// BurningAddress is the address answered by getburningaddress, the coins sent to it are burnt
+const BurningAddress = "12RxahVABnAVCGP3LGwCn8jkQxgw7z1x14wztHzn455TTVpi1wBq9YGwkRMQg3J4e657AbAnCvYCJSdA9czBUNuCKwGSRQt55Xwz8WA"
+
+// Statuses of a trade answered by getpdetradestatus, in the order of constant.PDexTradeStatus
+const (
+	TradePending = iota
+	TradeAccepted
+	TradeRefunded
+)
+
+// TradeStatus returns the status of the trade requested by the transaction txID, false when the chain
+// does not know the transaction
+func (c *Chain) TradeStatus(txID string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.txs[txID]; !ok {
+		return 0, false
+	}
+	return c.tradeStatuses[txID], true
+}
+
+// settleTrade settles the trade requested by tx as the beacon would once it is in a block: the trader
+// is paid the bought tokens and the pools move, or the sold amount is refunded when it buys less than
+// the minimum acceptable amount. c.mu must be held.
+func (c *Chain) settleTrade(tx *Tx) {
+	if tx.Metadata == nil {
+		return
+	}
+	if _, settled := c.tradeStatuses[tx.ID]; settled {
+		return
+	}
+	meta, err := metadata.ParseMetadata(tx.Metadata)
+	if err != nil {
+		return
+	}
+	trade, ok := meta.(*metadata.PDETradeRequest)
+	if !ok {
+		return
+	}
+	keyWallet, err := wallet.Base58CheckDeserialize(trade.TraderAddressStr)
+	if err != nil {
+		return
+	}
+	trader, err := new(privacy.Point).FromBytesS(keyWallet.KeySet.PaymentAddress.Pk)
+	if err != nil {
+		return
+	}
+
+	received, err := c.trade(trade.TokenIDToSellStr, trade.TokenIDToBuyStr, trade.SellAmount, trade.MinAcceptableAmount)
+	if err != nil {
+		c.tradeStatuses[tx.ID] = TradeRefunded
+		// the trading fee is paid in PRV, along with the amount sold when it is PRV
+		prv := common.PRVCoinID.String()
+		if trade.TokenIDToSellStr == prv {
+			c.mint(trader, prv, trade.SellAmount+trade.TradingFee)
+			return
+		}
+		c.mint(trader, trade.TokenIDToSellStr, trade.SellAmount)
+		c.mint(trader, prv, trade.TradingFee)
+		return
+	}
+	c.tradeStatuses[tx.ID] = TradeAccepted
+	c.mint(trader, trade.TokenIDToBuyStr, received)
+}
+
+// trade sells sellAmount of sellTokenID for buyTokenID in the pools, through the PRV pools when neither
+// token is PRV. The pools are left untouched when it fails. c.mu must be held.
+func (c *Chain) trade(sellTokenID, buyTokenID string, sellAmount, minAmount uint64) (uint64, error) {
+	prv := common.PRVCoinID.String()
+	hops := []string{sellTokenID, buyTokenID}
+	if sellTokenID != prv && buyTokenID != prv {
+		hops = []string{sellTokenID, prv, buyTokenID}
+	}
+
+	// the pool values after each hop, applied once every hop succeeded
+	type poolValue struct {
+		value *uint64
+		after uint64
+	}
+	var updates []poolValue
+	amount := sellAmount
+	for i := 0; i+1 < len(hops); i++ {
+		sellPool, buyPool, err := c.poolValues(hops[i], hops[i+1])
+		if err != nil {
+			return 0, err
+		}
+		received, sellPoolAfter, buyPoolAfter := tradeAmount(*sellPool, *buyPool, amount)
+		if received == 0 {
+			return 0, fmt.Errorf("selling %d of %s buys nothing", amount, hops[i])
+		}
+		updates = append(updates, poolValue{sellPool, sellPoolAfter}, poolValue{buyPool, buyPoolAfter})
+		amount = received
+	}
+	if amount < minAmount {
+		return 0, fmt.Errorf("the trade buys %d, less than %d", amount, minAmount)
+	}
+
+	for _, update := range updates {
+		*update.value = update.after
+	}
+	return amount, nil
+}
+
+// poolValues returns the values of sellTokenID and buyTokenID in their pool pair, c.mu must be held
+func (c *Chain) poolValues(sellTokenID, buyTokenID string) (sellPool *uint64, buyPool *uint64, err error) {
+	token1, token2 := sellTokenID, buyTokenID
+	if token2 < token1 {
+		token1, token2 = token2, token1
+	}
+	poolPair, ok := c.poolPairs[token1+"-"+token2]
+	if !ok || poolPair.Token1PoolValue == 0 || poolPair.Token2PoolValue == 0 {
+		return nil, nil, fmt.Errorf("no pool pair of %s and %s", sellTokenID, buyTokenID)
+	}
+	if poolPair.Token1IDStr == sellTokenID {
+		return &poolPair.Token1PoolValue, &poolPair.Token2PoolValue, nil
+	}
+	return &poolPair.Token2PoolValue, &poolPair.Token1PoolValue, nil
+}
+
+// tradeAmount returns what selling sellAmount to a pool holding sellPool and buyPool buys, and the pool
+// values after it. The product of the pool values is kept, rounding up in favor of the pool as the beacon does.
+func tradeAmount(sellPool, buyPool, sellAmount uint64) (received, sellPoolAfter, buyPoolAfter uint64) {
+	invariant := new(big.Int).Mul(new(big.Int).SetUint64(sellPool), new(big.Int).SetUint64(buyPool))
+	newSellPool := new(big.Int).Add(new(big.Int).SetUint64(sellPool), new(big.Int).SetUint64(sellAmount))
+	newBuyPool, remainder := new(big.Int).QuoRem(invariant, newSellPool, new(big.Int))
+	if remainder.Sign() > 0 {
+		newBuyPool.Add(newBuyPool, big.NewInt(1))
+	}
+	if !newSellPool.IsUint64() || newBuyPool.Uint64() >= buyPool {
+		return 0, sellPool, buyPool
+	}
+	return buyPool - newBuyPool.Uint64(), newSellPool.Uint64(), newBuyPool.Uint64()
+}
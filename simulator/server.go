@@ -0,0 +1,571 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+)
+
+// Error codes answered by the simulator
+const (
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeRejectTx       = -1001
+	ErrCodeTxNotFound     = -1002
+)
+
+// Simulator serves a Chain over JSON-RPC on a local httptest server
+type Simulator struct {
+	*Chain
+	server *httptest.Server
+}
+
+/*
+New starts a simulator on a local port, point the SDK at URL and Close it at the end of the test.
+
+Example:
+
+	sim := simulator.New()
+	defer sim.Close()
+	sim.Fund(paymentAddress, common.PRVCoinID.String(), 1e9)
+
+	publicIncognito := incognitoclient.NewPublicIncognito(nil, sim.URL())
+*/
+func New() *Simulator {
+	s := &Simulator{Chain: NewChain()}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// URL is the endpoint of the simulator
+func (s *Simulator) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down
+func (s *Simulator) Close() {
+	s.server.Close()
+}
+
+type request struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Id     json.RawMessage     `json:"Id"`
+	Result interface{}         `json:"Result"`
+	Error  *rpcclient.RPCError `json:"Error"`
+}
+
+// ServeHTTP answers a JSON-RPC call or batch of calls
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	var batch []request
+	if err := json.Unmarshal(body, &batch); err == nil {
+		responses := make([]response, len(batch))
+		for i, req := range batch {
+			responses[i] = s.Chain.answer(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(s.Chain.answer(req))
+}
+
+func (c *Chain) answer(req request) response {
+	resp := response{Id: req.Id}
+	if len(resp.Id) == 0 {
+		resp.Id = json.RawMessage("null")
+	}
+
+	var params []json.RawMessage
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &rpcclient.RPCError{Code: ErrCodeInvalidParams, Message: "params must be an array"}
+			return resp
+		}
+	}
+
+	result, err := c.Handle(req.Method, params)
+	if err != nil {
+		var rpcErr *rpcclient.RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcclient.RPCError{Code: ErrCodeInvalidParams, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+// Handle answers the RPC method with its positional params, the error is an *rpcclient.RPCError when
+// the method is unknown or the chain rejected a transaction
+func (c *Chain) Handle(method string, params []json.RawMessage) (interface{}, error) {
+	switch method {
+	case "listoutputcoins":
+		return c.handleListOutputCoins(params)
+	case "hasserialnumbers":
+		return c.handleHasSerialNumbers(params)
+	case "hassnderivators":
+		return c.handleHasSNDerivators(params)
+	case "randomcommitments":
+		return c.handleRandomCommitments(params)
+	case "estimatefeewithestimator":
+		return c.handleEstimateFeeWithEstimator(params)
+	case "sendtransaction":
+		return c.handleSendTransaction(params)
+	case "sendrawprivacycustomtokentransaction":
+		return c.handleSendRawPrivacyCustomTokenTransaction(params)
+	case "getblockcount":
+		return c.BestBlockHeight(), nil
+	case "gettransactionbyhash":
+		return c.handleGetTransactionByHash(params)
+	case "getblockchaininfo":
+		return c.handleGetBlockChainInfo()
+	case "getpdestate":
+		return c.handleGetPDEState(params)
+	case "getburningaddress":
+		return BurningAddress, nil
+	case "getpdetradestatus":
+		return c.handleGetPDETradeStatus(params)
+	case "createandsendtxwithprvcrosspooltradereq":
+		return c.handleCreateAndSendTxWithPRVTradeReq(params)
+	case "createandsendtxwithptokencrosspooltradereq":
+		return c.handleCreateAndSendTxWithPTokenTradeReq(params)
+	}
+	return nil, &rpcclient.RPCError{Code: ErrCodeMethodNotFound, Message: fmt.Sprintf("method %s not found", method)}
+}
+
+// Call answers method in memory, making the chain an rpcclient.Transport. The trade methods build their
+// transactions through it, as a node builds them against its own state.
+func (c *Chain) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(c.answer(request{Method: method, Params: rawParams}))
+}
+
+// decodeParams unmarshals the first params into targets, the ones after len(params) are left untouched
+func decodeParams(params []json.RawMessage, required int, targets ...interface{}) error {
+	if len(params) < required {
+		return fmt.Errorf("expected at least %d params, got %d", required, len(params))
+	}
+	for i, target := range targets {
+		if i >= len(params) {
+			break
+		}
+		if err := json.Unmarshal(params[i], target); err != nil {
+			return fmt.Errorf("param #%d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// amount is an amount taken by the methods a node builds transactions for, sent as a decimal string or a number
+type amount uint64
+
+func (a *amount) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*a = amount(value)
+	return nil
+}
+
+// uint64s returns amounts as the SDK builds transactions from them
+func uint64s(amounts map[string]amount) map[string]uint64 {
+	values := make(map[string]uint64, len(amounts))
+	for key, value := range amounts {
+		values[key] = uint64(value)
+	}
+	return values
+}
+
+func decodeBase58List(strs []string) ([][]byte, error) {
+	list := make([][]byte, len(strs))
+	for i, str := range strs {
+		b, _, err := base58.Base58Check{}.Decode(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base58 %q: %v", str, err)
+		}
+		list[i] = b
+	}
+	return list, nil
+}
+
+func encodeBase58(b []byte) string {
+	return base58.Base58Check{}.Encode(b, common.ZeroByte)
+}
+
+func (c *Chain) handleListOutputCoins(params []json.RawMessage) (interface{}, error) {
+	var from, to int
+	var keys []struct {
+		PaymentAddress string `json:"PaymentAddress"`
+		ReadonlyKey    string `json:"ReadonlyKey"`
+	}
+	var tokenID string
+	if err := decodeParams(params, 3, &from, &to, &keys, &tokenID); err != nil {
+		return nil, err
+	}
+
+	result := rpcclient.ListOutputCoins{Outputs: make(map[string][]rpcclient.OutCoin)}
+	for _, key := range keys {
+		coins, err := c.OutputCoins(key.PaymentAddress, key.ReadonlyKey, tokenID)
+		if err != nil {
+			return nil, err
+		}
+
+		outCoins := make([]rpcclient.OutCoin, len(coins))
+		for i, coin := range coins {
+			outCoins[i] = newOutCoin(coin)
+		}
+		name := key.ReadonlyKey
+		if name == "" {
+			name = key.PaymentAddress
+		}
+		result.Outputs[name] = outCoins
+	}
+	return result, nil
+}
+
+func newOutCoin(coin *privacy.OutputCoin) rpcclient.OutCoin {
+	details := coin.CoinDetails
+	outCoin := rpcclient.OutCoin{
+		PublicKey:      encodeBase58(details.GetPublicKey().ToBytesS()),
+		CoinCommitment: encodeBase58(details.GetCoinCommitment().ToBytesS()),
+		SNDerivator:    encodeBase58(details.GetSNDerivator().ToBytesS()),
+		Value:          strconv.FormatUint(details.GetValue(), 10),
+		Info:           encodeBase58(details.GetInfo()),
+	}
+	if details.GetRandomness() != nil {
+		outCoin.Randomness = encodeBase58(details.GetRandomness().ToBytesS())
+	}
+	if coin.CoinDetailsEncrypted != nil && !coin.CoinDetailsEncrypted.IsNil() {
+		outCoin.CoinDetailsEncrypted = encodeBase58(coin.CoinDetailsEncrypted.Bytes())
+	}
+	return outCoin
+}
+
+func (c *Chain) handleHasSerialNumbers(params []json.RawMessage) (interface{}, error) {
+	var paymentAddress, tokenID string
+	var serialNumberStrs []string
+	if err := decodeParams(params, 2, &paymentAddress, &serialNumberStrs, &tokenID); err != nil {
+		return nil, err
+	}
+
+	serialNumbers, err := decodeBase58List(serialNumberStrs)
+	if err != nil {
+		return nil, err
+	}
+	return c.HasSerialNumbers(serialNumbers, tokenID)
+}
+
+func (c *Chain) handleHasSNDerivators(params []json.RawMessage) (interface{}, error) {
+	var paymentAddress string
+	var sndStrs []string
+	if err := decodeParams(params, 2, &paymentAddress, &sndStrs); err != nil {
+		return nil, err
+	}
+
+	snds, err := decodeBase58List(sndStrs)
+	if err != nil {
+		return nil, err
+	}
+	return c.HasSNDerivators(snds), nil
+}
+
+func (c *Chain) handleRandomCommitments(params []json.RawMessage) (interface{}, error) {
+	var paymentAddress, tokenID string
+	var outCoins []rpcclient.OutCoin
+	if err := decodeParams(params, 2, &paymentAddress, &outCoins, &tokenID); err != nil {
+		return nil, err
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment address: %v", err)
+	}
+	pk := keyWallet.KeySet.PaymentAddress.Pk
+	shardID := common.GetShardIDFromLastByte(pk[len(pk)-1])
+
+	commitmentStrs := make([]string, len(outCoins))
+	for i, outCoin := range outCoins {
+		commitmentStrs[i] = outCoin.CoinCommitment
+	}
+	commitments, err := decodeBase58List(commitmentStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	indices, myIndices, ring, err := c.RandomCommitments(shardID, commitments, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	result := rpcclient.RandomCommitmentResult{
+		CommitmentIndices:  indices,
+		MyCommitmentIndexs: myIndices,
+		Commitments:        make([]string, len(ring)),
+	}
+	for i, commitment := range ring {
+		result.Commitments[i] = encodeBase58(commitment)
+	}
+	return result, nil
+}
+
+func (c *Chain) handleEstimateFeeWithEstimator(params []json.RawMessage) (interface{}, error) {
+	var defaultFee int64
+	if err := decodeParams(params, 1, &defaultFee); err != nil {
+		return nil, err
+	}
+	return rpcclient.EstimateFeeResult{EstimateFeeCoinPerKb: c.EstimateFeePerKb(defaultFee)}, nil
+}
+
+func (c *Chain) handleSendTransaction(params []json.RawMessage) (interface{}, error) {
+	tx, err := c.sendTransaction(params)
+	if err != nil {
+		return nil, err
+	}
+	return rpcclient.CreateTransactionResult{TxID: tx.ID, ShardID: tx.ShardID}, nil
+}
+
+func (c *Chain) handleSendRawPrivacyCustomTokenTransaction(params []json.RawMessage) (interface{}, error) {
+	tx, err := c.sendTransaction(params)
+	if err != nil {
+		return nil, err
+	}
+	if tx.TokenID == "" {
+		return nil, &rpcclient.RPCError{Code: ErrCodeRejectTx, Message: "not a privacy token transaction"}
+	}
+
+	c.mu.Lock()
+	token := *c.tokens[tx.TokenID]
+	c.mu.Unlock()
+	return rpcclient.CreateTransactionTokenResult{
+		TxID:        tx.ID,
+		ShardID:     tx.ShardID,
+		TokenID:     token.ID,
+		TokenName:   token.Name,
+		TokenAmount: token.Amount,
+	}, nil
+}
+
+// handleGetTransactionByHash answers the fields of a fullnode's answer the SDK decodes
+func (c *Chain) handleGetTransactionByHash(params []json.RawMessage) (interface{}, error) {
+	var txHash string
+	if err := decodeParams(params, 1, &txHash); err != nil {
+		return nil, err
+	}
+	tx, ok := c.Transaction(txHash)
+	if !ok {
+		return nil, &rpcclient.RPCError{Code: ErrCodeTxNotFound, Message: fmt.Sprintf("transaction %s not found", txHash)}
+	}
+
+	raw := tx.raw
+	detail := rpcclient.TransactionDetail{
+		ShardID:     tx.ShardID,
+		Hash:        tx.ID,
+		Version:     raw.Version,
+		Type:        raw.Type,
+		LockTime:    time.Unix(raw.LockTime, 0).Format(common.DateOutputFormat),
+		Fee:         raw.Fee,
+		IsPrivacy:   raw.Proof != nil && len(raw.Proof.GetOneOfManyProof()) > 0,
+		Proof:       raw.Proof,
+		SigPubKey:   encodeBase58(raw.SigPubKey),
+		Metadata:    string(tx.Metadata),
+		BlockHeight: tx.BlockHeight,
+		IsInBlock:   tx.BlockHeight != 0,
+		IsInMempool: tx.BlockHeight == 0,
+		Info:        string(raw.Info),
+	}
+	if raw.TxTokenPrivacyData != nil {
+		tokenData, err := json.Marshal(raw.TxTokenPrivacyData)
+		if err != nil {
+			return nil, err
+		}
+		detail.PrivacyCustomTokenData = string(tokenData)
+	}
+	return detail, nil
+}
+
+// handleGetBlockChainInfo answers the best block height of the beacon, shard -1, and of every shard
+func (c *Chain) handleGetBlockChainInfo() (interface{}, error) {
+	type bestBlock struct {
+		Height uint64
+	}
+	height := c.BestBlockHeight()
+	bestBlocks := map[string]bestBlock{"-1": {Height: height}}
+	for shardID := 0; shardID < common.MaxShardNumber; shardID++ {
+		bestBlocks[strconv.Itoa(shardID)] = bestBlock{Height: height}
+	}
+	return map[string]interface{}{"ChainName": "simulator", "BestBlocks": bestBlocks}, nil
+}
+
+// handleGetPDEState answers the pool pairs of the pDEX, it has no contribution, share nor trading fee
+func (c *Chain) handleGetPDEState(params []json.RawMessage) (interface{}, error) {
+	var req struct {
+		BeaconHeight uint64
+	}
+	if err := decodeParams(params, 1, &req); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"WaitingPDEContributions": map[string]interface{}{},
+		"PDEPoolPairs":            c.PDEPoolPairs(req.BeaconHeight),
+		"PDEShares":               map[string]uint64{},
+		"PDETradingFees":          map[string]uint64{},
+		"BeaconTimeStamp":         0,
+	}, nil
+}
+
+// handleGetPDETradeStatus answers the status of the trade requested by a transaction, pending until it is in a block
+func (c *Chain) handleGetPDETradeStatus(params []json.RawMessage) (interface{}, error) {
+	var req struct {
+		TxRequestIDStr string
+	}
+	if err := decodeParams(params, 1, &req); err != nil {
+		return nil, err
+	}
+	status, ok := c.TradeStatus(req.TxRequestIDStr)
+	if !ok {
+		return nil, &rpcclient.RPCError{Code: ErrCodeTxNotFound, Message: fmt.Sprintf("transaction %s not found", req.TxRequestIDStr)}
+	}
+	return status, nil
+}
+
+// tradeRequest is the metadata param of the trade methods, the token part is set when a token is sold
+type tradeRequest struct {
+	TokenIDToBuyStr     string
+	TokenIDToSellStr    string
+	SellAmount          amount
+	MinAcceptableAmount amount
+	TradingFee          amount
+	TraderAddressStr    string
+
+	Privacy        bool
+	TokenID        string
+	TokenTxType    int
+	TokenName      string
+	TokenSymbol    string
+	TokenAmount    amount
+	TokenReceivers map[string]amount
+	TokenFee       amount
+}
+
+// handleCreateAndSendTxWithPRVTradeReq builds the PRV sell with the private key of the trader and sends it, as a node does
+func (c *Chain) handleCreateAndSendTxWithPRVTradeReq(params []json.RawMessage) (interface{}, error) {
+	var privateKey string
+	var receivers map[string]amount
+	var feePerKb, hasPrivacy int
+	var req tradeRequest
+	if err := decodeParams(params, 5, &privateKey, &receivers, &feePerKb, &hasPrivacy, &req); err != nil {
+		return nil, err
+	}
+
+	meta := map[string]interface{}{
+		"TokenIDToBuyStr":     req.TokenIDToBuyStr,
+		"TokenIDToSellStr":    req.TokenIDToSellStr,
+		"SellAmount":          uint64(req.SellAmount),
+		"MinAcceptableAmount": uint64(req.MinAcceptableAmount),
+		"TradingFee":          uint64(req.TradingFee),
+		"TraderAddressStr":    req.TraderAddressStr,
+	}
+	txParams := []interface{}{privateKey, uint64s(receivers), feePerKb, hasPrivacy, meta}
+	data, err := incognito.CreateAndSendTxWithPRVTradeReqWithContext(context.Background(), rpcclient.NewHttpClientWithTransport(c), txParams, incognito.WithCoinReservation(nil))
+	if err != nil {
+		return nil, err
+	}
+	return c.sendBuiltTransaction(data)
+}
+
+// handleCreateAndSendTxWithPTokenTradeReq builds the token sell with the private key of the trader and sends it, as a node does
+func (c *Chain) handleCreateAndSendTxWithPTokenTradeReq(params []json.RawMessage) (interface{}, error) {
+	var privateKey string
+	var receivers map[string]amount
+	var feePerKb, hasPrivacy int
+	var req tradeRequest
+	var info string
+	var hasPrivacyToken int
+	if err := decodeParams(params, 5, &privateKey, &receivers, &feePerKb, &hasPrivacy, &req, &info, &hasPrivacyToken); err != nil {
+		return nil, err
+	}
+
+	meta := map[string]interface{}{
+		"Privacy":             req.Privacy,
+		"TokenID":             req.TokenID,
+		"TokenTxType":         req.TokenTxType,
+		"TokenName":           req.TokenName,
+		"TokenSymbol":         req.TokenSymbol,
+		"TokenAmount":         uint64(req.TokenAmount),
+		"TokenReceivers":      uint64s(req.TokenReceivers),
+		"TokenFee":            uint64(req.TokenFee),
+		"TokenIDToBuyStr":     req.TokenIDToBuyStr,
+		"TokenIDToSellStr":    req.TokenIDToSellStr,
+		"SellAmount":          uint64(req.SellAmount),
+		"MinAcceptableAmount": uint64(req.MinAcceptableAmount),
+		"TradingFee":          uint64(req.TradingFee),
+		"TraderAddressStr":    req.TraderAddressStr,
+	}
+	txParams := []interface{}{privateKey, uint64s(receivers), feePerKb, hasPrivacy, meta, info, hasPrivacyToken}
+	data, err := incognito.CreateAndSendTxWithPTokenTradeReqWithContext(context.Background(), rpcclient.NewHttpClientWithTransport(c), txParams, incognito.WithCoinReservation(nil))
+	if err != nil {
+		return nil, err
+	}
+	return c.sendBuiltTransaction(data)
+}
+
+// sendBuiltTransaction sends the transaction built by an incognito CreateAndSend function, its params for sendtransaction
+func (c *Chain) sendBuiltTransaction(data interface{}) (interface{}, error) {
+	rawParams, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+	return c.handleSendTransaction(params)
+}
+
+func (c *Chain) sendTransaction(params []json.RawMessage) (*Tx, error) {
+	var base58Data string
+	if err := decodeParams(params, 1, &base58Data); err != nil {
+		return nil, err
+	}
+
+	tx, err := c.SendTransaction(base58Data)
+	if err != nil {
+		return nil, &rpcclient.RPCError{Code: ErrCodeRejectTx, Message: "reject transaction", StackTrace: err.Error()}
+	}
+	return tx, nil
+}
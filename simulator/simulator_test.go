@@ -0,0 +1,960 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/incognito"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/constant"
+	"github.com/incognitochain/go-incognito-sdk/incognitoclient/entity"
+	"github.com/incognitochain/go-incognito-sdk/mempool"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/rpcserver/rpcservice"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowTestsEnv opts in to the tests taking minutes, e.g. SIMULATOR_SLOW_TESTS=1 go test ./simulator/
+const slowTestsEnv = "SIMULATOR_SLOW_TESTS"
+
+// skipUnlessSlowTests skips the rest of t unless slowTestsEnv is set, reason tells why it is slow
+func skipUnlessSlowTests(t *testing.T, reason string) {
+	if os.Getenv(slowTestsEnv) == "" || testing.Short() {
+		t.Skipf("%s, set %s to run it", reason, slowTestsEnv)
+	}
+}
+
+// testTokenID is the token registered by registerToken
+const testTokenID = "ffd8d42dc40a8d166ea4848baf8b5f6e9fe0e9c30d60062eb7d44a8df9e00854"
+
+// registerToken registers testTokenID on sim and returns it
+func registerToken(t *testing.T, sim *Simulator) string {
+	assert.NoError(t, sim.RegisterToken(testTokenID, "Ether", "pETH"))
+	return testTokenID
+}
+
+// newWallet returns the keys of a new account
+func newWallet(t *testing.T) *wallet.KeySerializedData {
+	keys, err := incognito.CreateNewWallet()
+	assert.NoError(t, err)
+	return keys
+}
+
+// newFundedWallet returns the keys of a new account, given a coin of tokenID on sim for each of amounts
+func newFundedWallet(t *testing.T, sim *Simulator, tokenID string, amounts ...uint64) *wallet.KeySerializedData {
+	keys := newWallet(t)
+	for _, amount := range amounts {
+		assert.NoError(t, sim.Fund(keys.PaymentAddress, tokenID, amount))
+	}
+	return keys
+}
+
+// newClientWallet returns the wallet of the client package talking to sim
+func newClientWallet(sim *Simulator, opts ...incognitoclient.WalletOption) *incognitoclient.Wallet {
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	return incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public), opts...)
+}
+
+func TestSendPRVEndToEnd(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	receiver := newWallet(t)
+
+	w := newClientWallet(sim)
+
+	balance, err := w.GetBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000000), balance)
+
+	// build a spend of the only coin without sending nor reserving it, to replay it once the coin is spent
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	params := []interface{}{sender.PrivateKey, map[string]uint64{receiver.PaymentAddress: 1000}, 5, 1}
+	raw, err := incognito.CreateAndSendTxWithContext(context.Background(), rpcClient, params, incognito.WithCoinReservation(nil))
+	assert.NoError(t, err)
+
+	txID, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 300000, 0, "")
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.True(t, tx.Fee > 0)
+
+	balance, err = w.GetBalance(receiver.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(300000), balance)
+	balance, err = w.GetBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000-300000-tx.Fee, balance)
+
+	var result rpcclient.SendRawTxRes
+	assert.NoError(t, rpcClient.RPCCall("sendtransaction", raw, &result))
+	assert.True(t, errors.Is(result.RPCError, rpcclient.ErrDoubleSpend))
+}
+
+func TestHandleUnknownMethod(t *testing.T) {
+	_, err := NewChain().Handle("getbeaconbeststatedetail", nil)
+
+	var rpcErr *rpcclient.RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, ErrCodeMethodNotFound, rpcErr.Code)
+}
+
+func TestSendTokenEndToEnd(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	assert.NoError(t, sim.Fund(sender.PaymentAddress, tokenID, 5000))
+	receiver := newWallet(t)
+
+	w := newClientWallet(sim)
+
+	txID, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, tokenID, 2000, 0, prv)
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Equal(t, tokenID, tx.TokenID)
+
+	balance, err := w.GetBalance(receiver.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2000), balance)
+	balance, err = w.GetBalance(sender.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3000), balance)
+}
+
+func TestOfflineSigning(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	receiver := newWallet(t)
+
+	// the online machine only knows the payment address and the readonly key
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	unsignedTx, err := incognito.CreateUnsignedTx(rpcClient, sender.PaymentAddress, sender.ReadonlyKey, map[string]uint64{receiver.PaymentAddress: 1000}, 5, true, "")
+	assert.NoError(t, err)
+	bundle, err := json.Marshal(unsignedTx)
+	assert.NoError(t, err)
+
+	// the air-gapped machine
+	var carried transaction.UnsignedTx
+	assert.NoError(t, json.Unmarshal(bundle, &carried))
+	_, err = incognito.SignUnsignedTx(&carried, receiver.PrivateKey)
+	assert.Error(t, err)
+	// a token transfer needs a TxCustomTokenPrivacy, which Sign does not build
+	tokenTx := carried
+	tokenTx.TokenID = common.Hash{1}.String()
+	_, err = incognito.SignUnsignedTx(&tokenTx, sender.PrivateKey)
+	assert.Error(t, err)
+	signed, err := incognito.SignUnsignedTx(&carried, sender.PrivateKey)
+	assert.NoError(t, err)
+
+	var result rpcclient.SendRawTxRes
+	assert.NoError(t, rpcClient.RPCCall("sendtransaction", []interface{}{signed.Base58CheckData}, &result))
+	assert.Nil(t, result.RPCError)
+	tx, ok := sim.Transaction(signed.TxID)
+	assert.True(t, ok)
+	assert.Equal(t, unsignedTx.Fee, tx.Fee)
+
+	balance, err := incognito.GetBalance(rpcClient, receiver.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance)
+	balance, err = incognito.GetBalance(rpcClient, sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000-1000-tx.Fee, balance)
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	sender := newFundedWallet(t, sim, common.PRVCoinID.String(), 1000000)
+	receiver := newWallet(t)
+
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	unsignedTx, err := incognito.CreateUnsignedTx(rpcClient, sender.PaymentAddress, sender.ReadonlyKey, map[string]uint64{receiver.PaymentAddress: 1000}, 5, true, "")
+	assert.NoError(t, err)
+	signed, err := incognito.SignUnsignedTx(unsignedTx, sender.PrivateKey)
+	assert.NoError(t, err)
+
+	// without a key only the public parts are read
+	decoded, err := incognito.DecodeTransaction(signed.Base58CheckData, "")
+	assert.NoError(t, err)
+	assert.Equal(t, signed.TxID, decoded.TxID)
+	assert.Equal(t, unsignedTx.Fee, decoded.Fee)
+	assert.True(t, decoded.HasPrivacy)
+	assert.Len(t, decoded.InputCoins, len(unsignedTx.InputCoins))
+	assert.NotEmpty(t, decoded.InputCoins[0].SerialNumber)
+	assert.Len(t, decoded.OutputCoins, 2)
+	for _, coin := range decoded.OutputCoins {
+		assert.False(t, coin.Mine)
+		assert.Zero(t, coin.Value)
+	}
+
+	// the receiver decrypts the coin sent to it
+	decoded, err = incognito.DecodeTransaction(signed.Base58CheckData, receiver.ReadonlyKey)
+	assert.NoError(t, err)
+	var received uint64
+	for _, coin := range decoded.OutputCoins {
+		if coin.Mine {
+			assert.True(t, coin.Decrypted)
+			received += coin.Value
+		}
+	}
+	assert.Equal(t, uint64(1000), received)
+
+	// and so does the sender for its change
+	decoded, err = incognito.DecodeTransaction(signed.Base58CheckData, sender.ReadonlyKey)
+	assert.NoError(t, err)
+	var change uint64
+	for _, coin := range decoded.OutputCoins {
+		if coin.Mine {
+			change += coin.Value
+		}
+	}
+	assert.Equal(t, 1000000-1000-unsignedTx.Fee, change)
+
+	// the same from the node once sent
+	var result rpcclient.SendRawTxRes
+	assert.NoError(t, rpcClient.RPCCall("sendtransaction", []interface{}{signed.Base58CheckData}, &result))
+	assert.Nil(t, result.RPCError)
+	fetched, err := incognito.GetDecodedTransaction(rpcClient, signed.TxID, receiver.ReadonlyKey)
+	assert.NoError(t, err)
+	assert.Equal(t, signed.TxID, fetched.TxID)
+	assert.Equal(t, decoded.LockTime, fetched.LockTime)
+	assert.Equal(t, decoded.SigPubKey, fetched.SigPubKey)
+	assert.Equal(t, decoded.InputCoins, fetched.InputCoins)
+	received = 0
+	for _, coin := range fetched.OutputCoins {
+		if coin.Mine {
+			received += coin.Value
+		}
+	}
+	assert.Equal(t, uint64(1000), received)
+
+	_, err = incognito.GetDecodedTransaction(rpcClient, "unknown", "")
+	var rpcErr *rpcclient.RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, ErrCodeTxNotFound, rpcErr.Code)
+}
+
+func TestSendWithCoinSelector(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 100000, 200000, 300000, 400000)
+	receiver := newWallet(t)
+
+	w := newClientWallet(sim)
+
+	// the fewest coins: the largest one pays alone
+	txID, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 350000, 0, "", incognitoclient.WithCoinSelector(incognitoclient.MinInputsSelector{}))
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Len(t, tx.raw.Proof.GetInputCoins(), 1)
+
+	// consolidation spends every coin left, the change of the first tx included
+	txID, err = w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 1000, 0, "", incognitoclient.WithCoinSelector(incognitoclient.ConsolidationSelector{}))
+	assert.NoError(t, err)
+	tx, ok = sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Len(t, tx.raw.Proof.GetInputCoins(), 4)
+
+	balance, err := w.GetBalance(receiver.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(351000), balance)
+}
+
+func TestSendWithCoinReservation(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 500000, 500000)
+	receiver := newWallet(t)
+
+	w := newClientWallet(sim)
+
+	// a tx built but not sent yet keeps its coin reserved, the next send spends the other coin
+	reservation := rpcservice.DefaultCoinLocker.NewReservation()
+	defer reservation.Release()
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	params := []interface{}{sender.PrivateKey, map[string]uint64{receiver.PaymentAddress: 300000}, 5, 1}
+	raw, err := incognito.CreateAndSendTxWithContext(context.Background(), rpcClient, params, incognito.WithCoinReservation(reservation))
+	assert.NoError(t, err)
+	assert.Len(t, reservation.TxIDs(), 1)
+
+	balance, err := w.GetAccountBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500000), balance.Available)
+	assert.Equal(t, uint64(500000), balance.Pending)
+
+	_, err = w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 300000, 0, "")
+	assert.NoError(t, err)
+
+	var result rpcclient.SendRawTxRes
+	assert.NoError(t, rpcClient.RPCCall("sendtransaction", raw, &result))
+	assert.Nil(t, result.RPCError)
+
+	balance, err = w.GetAccountBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), balance.Pending)
+	balance, err = w.GetAccountBalance(receiver.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(600000), balance.Available)
+
+	// with no reservation given, a tx built reserves its coins in rpcservice.DefaultCoinLocker too
+	other := newFundedWallet(t, sim, prv, 500000)
+	params = []interface{}{other.PrivateKey, map[string]uint64{receiver.PaymentAddress: 300000}, 5, 1}
+	_, err = incognito.CreateAndSendTxWithContext(context.Background(), rpcClient, params)
+	assert.NoError(t, err)
+	balance, err = w.GetAccountBalance(other.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), balance.Available)
+	assert.Equal(t, uint64(500000), balance.Pending)
+	_, err = incognito.CreateAndSendTxWithContext(context.Background(), rpcClient, params)
+	assert.Error(t, err)
+}
+
+// lostSendTransport loses the sendtransaction calls before they reach the node
+type lostSendTransport struct {
+	rpcclient.Transport
+}
+
+func (transport lostSendTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	if method == "sendtransaction" {
+		return nil, errors.New("connection reset by peer")
+	}
+	return transport.Transport.Call(ctx, method, params)
+}
+
+func TestSendKeepsCoinsReservedWhenUnclear(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 500000, 500000)
+	receiver := newWallet(t)
+
+	transport := lostSendTransport{rpcclient.NewHTTPTransport(http.DefaultClient, sim.URL())}
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL(), incognitoclient.WithTransport(transport))
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public))
+
+	// the node may have the tx whose send failed without an answer, its coin stays reserved
+	_, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 300000, 0, "")
+	assert.Error(t, err)
+
+	balance, err := w.GetAccountBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500000), balance.Available)
+	assert.Equal(t, uint64(500000), balance.Pending)
+
+	// a payout whose tx may have been sent is not reported as failed
+	result, err := w.SendBatch(sender.PrivateKey, prv, []entity.Payout{{PaymentAddress: receiver.PaymentAddress, Amount: 1000}})
+	assert.Error(t, err)
+	assert.Equal(t, 0, result.Failed)
+	assert.Equal(t, 1, result.Unknown)
+	assert.Equal(t, entity.PayoutUnknown, result.Results[0].Status)
+	assert.NotEmpty(t, result.Results[0].TxID)
+	assert.Empty(t, result.TxIDs)
+}
+
+func TestTxTracker(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+	sim.HoldInMempool(true)
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	receiver := newWallet(t)
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	tracker := incognitoclient.NewTxTracker(public, incognitoclient.TxTrackerConfig{
+		PollInterval:  10 * time.Millisecond,
+		Confirmations: 2,
+		MissedPolls:   1,
+	})
+	defer tracker.Close()
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public), incognitoclient.WithTxTracker(tracker))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	txID, err := w.SendTokenWithContext(ctx, sender.PrivateKey, receiver.PaymentAddress, prv, 1000, 0, "")
+	assert.NoError(t, err)
+
+	updates, err := tracker.Watch(txID)
+	assert.NoError(t, err)
+
+	// the dropped tx is sent again from its raw data
+	assert.NoError(t, sim.DropTransaction(txID))
+	for update := range updates {
+		if update.Rebroadcasts == 1 {
+			break
+		}
+	}
+	_, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+
+	height := sim.MineBlock()
+	sim.MineBlock()
+	update, err := tracker.WaitForConfirmation(ctx, txID)
+	assert.NoError(t, err)
+	assert.Equal(t, incognitoclient.TxConfirmed, update.Status)
+	assert.Equal(t, height, update.BlockHeight)
+	assert.Equal(t, uint64(2), update.Confirmations)
+	assert.Equal(t, 1, update.Rebroadcasts)
+
+	// a tx the node does not know and that can not be sent again is dropped
+	update, err = tracker.WaitForConfirmation(ctx, common.HashH([]byte("unknown")).String())
+	assert.True(t, errors.Is(err, rpcclient.ErrTxDropped))
+	assert.Equal(t, incognitoclient.TxDropped, update.Status)
+}
+
+// laggingTransport answers the first hidden gettransactionbyhash calls as a node not indexing the tx yet
+type laggingTransport struct {
+	rpcclient.Transport
+	mu     sync.Mutex
+	hidden int
+}
+
+func (transport *laggingTransport) Call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	transport.mu.Lock()
+	hide := method == "gettransactionbyhash" && transport.hidden > 0
+	if hide {
+		transport.hidden--
+	}
+	transport.mu.Unlock()
+	if hide {
+		return json.Marshal(response{Id: json.RawMessage("1"), Error: &rpcclient.RPCError{Code: ErrCodeTxNotFound, Message: "transaction not found"}})
+	}
+	return transport.Transport.Call(ctx, method, params)
+}
+
+func TestTxTrackerRebroadcastOfTxInBlock(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	sender := newFundedWallet(t, sim, common.PRVCoinID.String(), 1000000)
+	receiver := newWallet(t)
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	raw, err := incognito.CreateAndSendTx(rpcClient, []interface{}{sender.PrivateKey, map[string]uint64{receiver.PaymentAddress: 1000}, 5, 1})
+	assert.NoError(t, err)
+	var result rpcclient.SendRawTxRes
+	assert.NoError(t, rpcClient.RPCCall("sendtransaction", raw, &result))
+	txID := result.Result.TxID
+
+	// the tx is in a block the node does not report yet, sending it again is answered double spend
+	for _, hidden := range []int{1, 2} {
+		transport := &laggingTransport{Transport: rpcclient.NewHTTPTransport(http.DefaultClient, sim.URL()), hidden: hidden}
+		tracker := rpcclient.NewTxTracker(rpcclient.NewHttpClientWithTransport(transport), rpcclient.TxTrackerConfig{
+			PollInterval: 10 * time.Millisecond,
+			MissedPolls:  1,
+		})
+		assert.NoError(t, tracker.Track(txID, &rpcclient.RawTx{Method: "sendtransaction", Params: raw}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		update, err := tracker.WaitForConfirmation(ctx, txID)
+		cancel()
+		tracker.Close()
+		assert.NoError(t, err, "hidden %d", hidden)
+		assert.Equal(t, rpcclient.TxConfirmed, update.Status)
+	}
+}
+
+func TestConsolidate(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	owner := newWallet(t)
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, sim.Fund(owner.PaymentAddress, prv, 100000))
+	}
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, sim.Fund(owner.PaymentAddress, tokenID, 1000))
+	}
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	tracker := incognitoclient.NewTxTracker(public, incognitoclient.TxTrackerConfig{PollInterval: 10 * time.Millisecond})
+	defer tracker.Close()
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public), incognitoclient.WithTxTracker(tracker))
+	ctx := context.Background()
+
+	// 10 coins are merged by 3 txs, then 4 by 1, then 2 by 1
+	var rounds []entity.ConsolidationProgress
+	result, err := w.ConsolidateWithContext(ctx, owner.PrivateKey, prv, entity.ConsolidationConfig{
+		BatchSize:  3,
+		OnProgress: func(progress entity.ConsolidationProgress) { rounds = append(rounds, progress) },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Rounds)
+	assert.Len(t, result.TxIDs, 5)
+	assert.Equal(t, 10, result.CoinsBefore)
+	assert.Equal(t, 1, result.CoinsAfter)
+	assert.Len(t, rounds, 3)
+	assert.Equal(t, []int{4, 2, 1}, []int{rounds[0].Coins, rounds[1].Coins, rounds[2].Coins})
+	assert.Equal(t, result.FeeSpent, rounds[2].FeeSpent)
+
+	balance, err := w.GetBalance(owner.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000-result.FeeSpent, balance)
+
+	result, err = w.ConsolidateWithContext(ctx, owner.PrivateKey, tokenID, entity.ConsolidationConfig{Sequential: true, BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Rounds)
+	assert.Equal(t, 1, result.CoinsAfter)
+	balance, err = w.GetBalance(owner.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4000), balance)
+}
+
+func TestMaxPrivacyOutputsCountsChange(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	sender := newFundedWallet(t, sim, common.PRVCoinID.String(), 1000000)
+
+	receivers := map[string]uint64{}
+	for len(receivers) < transaction.MaxPrivacyOutputs {
+		receivers[newWallet(t).PaymentAddress] = 100
+	}
+
+	// the change takes one more output than the tx allows
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	_, err := incognito.CreateAndSendTxWithContext(context.Background(), rpcClient, []interface{}{sender.PrivateKey, receivers, 5, 1})
+	assert.True(t, errors.Is(err, rpcclient.ErrTxTooLarge))
+}
+
+func TestSendBatch(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	assert.NoError(t, sim.Fund(sender.PaymentAddress, tokenID, 5000))
+	var receivers []*wallet.KeySerializedData
+	for i := 0; i < 4; i++ {
+		receivers = append(receivers, newWallet(t))
+	}
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	tracker := incognitoclient.NewTxTracker(public, incognitoclient.TxTrackerConfig{PollInterval: 10 * time.Millisecond})
+	defer tracker.Close()
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public), incognitoclient.WithTxTracker(tracker))
+	ctx := context.Background()
+
+	// 40 payouts take 2 txs, the invalid one is not sent
+	var payouts []entity.Payout
+	for i := 0; i < 40; i++ {
+		payouts = append(payouts, entity.Payout{PaymentAddress: receivers[i%4].PaymentAddress, Amount: 100, Memo: "payout"})
+	}
+	payouts = append(payouts, entity.Payout{PaymentAddress: "invalid", Amount: 100})
+	result, err := w.SendBatchWithContext(ctx, sender.PrivateKey, prv, payouts)
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Len(t, result.TxIDs, 2)
+	assert.Error(t, result.Results[40].Err)
+	assert.Equal(t, entity.PayoutFailed, result.Results[40].Status)
+	assert.Equal(t, entity.PayoutPaid, result.Results[0].Status)
+	assert.Equal(t, result.TxIDs[0], result.Results[0].TxID)
+	assert.Equal(t, result.TxIDs[1], result.Results[39].TxID)
+	for _, receiver := range receivers {
+		balance, err := w.GetBalance(receiver.PrivateKey, prv)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1000), balance)
+	}
+
+	result, err = w.SendBatchWithContext(ctx, sender.PrivateKey, tokenID, []entity.Payout{
+		{PaymentAddress: receivers[0].PaymentAddress, Amount: 1000},
+		{PaymentAddress: receivers[1].PaymentAddress, Amount: 1500, Memo: "invoice 42"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result.TxIDs, 1)
+	balance, err := w.GetBalance(receivers[1].PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1500), balance)
+	balance, err = w.GetBalance(sender.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2500), balance)
+}
+
+func TestEncryptedMemo(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	assert.NoError(t, sim.Fund(sender.PaymentAddress, tokenID, 5000))
+	receiver := newWallet(t)
+	other := newWallet(t)
+
+	w := newClientWallet(sim)
+
+	// a plain memo laid out as an encrypted one is read as is
+	lookalike := "\xe1" + strings.Repeat("public", 20)
+	_, err := w.SendBatch(sender.PrivateKey, prv, []entity.Payout{
+		{PaymentAddress: receiver.PaymentAddress, Amount: 100, Memo: "deposit 1042", EncryptMemo: true},
+		{PaymentAddress: receiver.PaymentAddress, Amount: 200, Memo: "public"},
+		{PaymentAddress: receiver.PaymentAddress, Amount: 400, Memo: lookalike},
+	})
+	assert.NoError(t, err)
+	_, err = w.SendBatch(sender.PrivateKey, tokenID, []entity.Payout{
+		{PaymentAddress: receiver.PaymentAddress, Amount: 300, Memo: "deposit 1043", EncryptMemo: true},
+	})
+	assert.NoError(t, err)
+
+	coins, err := w.GetIncomingCoins(receiver.PaymentAddress, receiver.ReadonlyKey, prv)
+	assert.NoError(t, err)
+	memos := make(map[uint64]entity.IncomingCoin)
+	for _, coin := range coins {
+		memos[coin.Value] = coin
+	}
+	assert.Equal(t, "deposit 1042", memos[100].Memo)
+	assert.True(t, memos[100].EncryptedMemo)
+	assert.Equal(t, "public", memos[200].Memo)
+	assert.False(t, memos[200].EncryptedMemo)
+	assert.Equal(t, lookalike, memos[400].Memo)
+	assert.False(t, memos[400].EncryptedMemo)
+
+	coins, err = w.GetIncomingCoins(receiver.PaymentAddress, receiver.ReadonlyKey, tokenID)
+	assert.NoError(t, err)
+	assert.Len(t, coins, 1)
+	assert.Equal(t, "deposit 1043", coins[0].Memo)
+	assert.True(t, coins[0].EncryptedMemo)
+
+	// the memo is unreadable without the readonly key of the receiver
+	receiverKey, err := wallet.Base58CheckDeserialize(receiver.PaymentAddress)
+	assert.NoError(t, err)
+	otherKey, err := wallet.Base58CheckDeserialize(other.ReadonlyKey)
+	assert.NoError(t, err)
+	info, err := privacy.EncryptCoinInfo([]byte("deposit 1042"), receiverKey.KeySet.PaymentAddress.Tk)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(info), "deposit 1042")
+	_, err = privacy.DecryptCoinInfo(info, otherKey.KeySet.ReadonlyKey.Rk)
+	assert.True(t, errors.Is(err, privacy.ErrNotEncryptedCoinInfo))
+
+	_, err = privacy.EncryptCoinInfo(make([]byte, privacy.MaxSizeEncryptedMemo+1), receiverKey.KeySet.PaymentAddress.Tk)
+	assert.Error(t, err)
+	result, err := w.SendBatch(sender.PrivateKey, prv, []entity.Payout{
+		{PaymentAddress: receiver.PaymentAddress, Amount: 100, Memo: string(make([]byte, privacy.MaxSizeEncryptedMemo+1)), EncryptMemo: true},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Failed)
+}
+
+func TestLocalFeeEstimator(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	sender := newFundedWallet(t, sim, prv, 1000000)
+	receiver := newWallet(t)
+	// the node asks for a fee out of all proportion
+	sim.SetFeePerKb(50000)
+
+	estimator := incognitoclient.NewFeeEstimator(mempool.DefaultEstimateFeeMaxRollback, mempool.DefaultEstimateFeeMinRegisteredBlocks, 0)
+	estimators := make(map[byte]*incognitoclient.FeeEstimator)
+	for shardID := 0; shardID < common.MaxShardNumber; shardID++ {
+		estimators[byte(shardID)] = estimator
+	}
+	w := newClientWallet(sim, incognitoclient.WithFeeEstimators(estimators))
+
+	// too few blocks registered, the node answers
+	txID, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 1000, 0, "")
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), tx.Fee%50000)
+
+	for height := uint64(1); height <= mempool.DefaultEstimateFeeMinRegisteredBlocks; height++ {
+		txs := []*incognitoclient.FeeTxDesc{{Hash: common.Hash{byte(height)}, FeePerKb: 20}}
+		assert.NoError(t, estimator.RegisterBlock(&incognitoclient.FeeBlock{Hash: common.Hash{0xb0, byte(height)}, Height: height, Txs: txs}))
+	}
+	txID, err = w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 1000, 0, "")
+	assert.NoError(t, err)
+	tx, ok = sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.True(t, tx.Fee > 0 && tx.Fee < 50000)
+	assert.Equal(t, uint64(0), tx.Fee%20)
+
+	balance, err := w.GetBalance(receiver.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2000), balance)
+}
+
+func TestTokenFee(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	unpooledTokenID := "4584d5e9b2fc0337dfb17f4b5bb025e5b82c38cfa4f54e8a3d4fcdd03954ff82"
+	assert.NoError(t, sim.RegisterToken(unpooledTokenID, "Dai", "pDAI"))
+	// the sender holds no PRV
+	sender := newFundedWallet(t, sim, tokenID, 5000)
+	assert.NoError(t, sim.Fund(sender.PaymentAddress, unpooledTokenID, 5000))
+	receiver := newWallet(t)
+	// 1 PRV is worth 3 tokens
+	sim.SetPDEPoolPair(tokenID, prv, 3000000, 1000000)
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	block := incognitoclient.NewBlockInfo(public)
+	w := incognitoclient.NewWallet(public, block)
+	pdex := incognitoclient.NewPDex(public, block)
+
+	converted, err := pdex.ConvertPRVToToken(100, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(330), converted)
+	_, err = pdex.ConvertPRVToToken(100, unpooledTokenID)
+	assert.True(t, errors.Is(pkgerrors.Cause(err), mempool.ErrNoPRVPoolPair))
+
+	fee, err := w.EstimateTokenFee(sender.PrivateKey, tokenID, map[string]uint64{receiver.PaymentAddress: 1000})
+	assert.NoError(t, err)
+	assert.True(t, fee > 0)
+	_, err = w.EstimateTokenFee(sender.PrivateKey, prv, map[string]uint64{receiver.PaymentAddress: 1000})
+	assert.Error(t, err)
+
+	txID, err := w.SendTokenWithTokenFee(sender.PrivateKey, receiver.PaymentAddress, tokenID, 1000)
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), tx.Fee)
+	assert.Equal(t, fee, tx.TokenFee)
+	balance, err := w.GetBalance(sender.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5000-1000-fee, balance)
+	balance, err = w.GetBalance(receiver.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance)
+
+	_, err = w.SendTokenWithTokenFee(sender.PrivateKey, receiver.PaymentAddress, unpooledTokenID, 1000)
+	assert.Error(t, err)
+}
+
+func TestSendTokenWithPolicy(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	receiver := newWallet(t)
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	// an account holding its tokens in more coins than a tx spends
+	newDustyAccount := func() *wallet.KeySerializedData {
+		dusty := newFundedWallet(t, sim, prv, 1000000)
+		for i := 0; i < transaction.MaxInputCoins+10; i++ {
+			assert.NoError(t, sim.Fund(dusty.PaymentAddress, tokenID, 100))
+		}
+		return dusty
+	}
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	tracker := incognitoclient.NewTxTracker(public, incognitoclient.TxTrackerConfig{PollInterval: 10 * time.Millisecond})
+	defer tracker.Close()
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public), incognitoclient.WithTxTracker(tracker))
+	ctx := context.Background()
+	amount := uint64(100 * (transaction.MaxInputCoins + 5))
+
+	dusty := newDustyAccount()
+	result, err := w.SendTokenWithPolicyWithContext(ctx, dusty.PrivateKey, receiver.PaymentAddress, tokenID, amount, entity.OversizeFail)
+	assert.True(t, errors.Is(pkgerrors.Cause(err), rpcclient.ErrTxTooLarge))
+	assert.Empty(t, result.PaymentTxIDs)
+
+	skipUnlessSlowTests(t, "proving txs of hundreds of coins takes minutes")
+	result, err = w.SendTokenWithPolicyWithContext(ctx, dusty.PrivateKey, receiver.PaymentAddress, tokenID, amount, entity.OversizeSplit)
+	assert.NoError(t, err)
+	assert.Empty(t, result.ConsolidationTxIDs)
+	assert.True(t, len(result.PaymentTxIDs) > 1)
+	assert.Len(t, result.Amounts, len(result.PaymentTxIDs))
+	sum := uint64(0)
+	for _, paid := range result.Amounts {
+		sum += paid
+	}
+	assert.Equal(t, amount, sum)
+	balance, err := w.GetBalance(receiver.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, amount, balance)
+
+	dusty = newDustyAccount()
+	result, err = w.SendTokenWithPolicyWithContext(ctx, dusty.PrivateKey, receiver.PaymentAddress, tokenID, amount, entity.OversizeConsolidate)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.ConsolidationTxIDs)
+	assert.Len(t, result.PaymentTxIDs, 1)
+	balance, err = w.GetBalance(receiver.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*amount, balance)
+	balance, err = w.GetBalance(dusty.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), balance)
+}
+
+func TestPlan(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	sender := newFundedWallet(t, sim, prv, 500000, 500000)
+	assert.NoError(t, sim.Fund(sender.PaymentAddress, tokenID, 8000))
+	receiver := newWallet(t)
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	w := incognitoclient.NewWallet(public, incognitoclient.NewBlockInfo(public))
+
+	// the plan of a send has the fee of the tx sent
+	plan, err := w.PlanSendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 300000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(300000), plan.PaymentAmount)
+	assert.False(t, plan.Insufficient)
+	txID, err := w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, 300000, 0, "")
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Equal(t, plan.Fee, tx.Fee)
+
+	// coins worth the amount but not the fee are not enough
+	balance, err := w.GetBalance(sender.PrivateKey, prv)
+	assert.NoError(t, err)
+	plan, err = w.PlanSendToken(sender.PrivateKey, receiver.PaymentAddress, prv, balance)
+	assert.NoError(t, err)
+	assert.True(t, plan.Insufficient)
+	_, err = w.SendToken(sender.PrivateKey, receiver.PaymentAddress, prv, balance, 0, "")
+	assert.True(t, errors.Is(pkgerrors.Cause(err), rpcclient.ErrNotEnoughCoin), "%v", err)
+
+	plan, err = w.PlanBurnCentralizedToken(sender.PrivateKey, -1, map[string]interface{}{
+		"TokenID":        tokenID,
+		"Privacy":        true,
+		"TokenTxType":    transaction.CustomTokenTransfer,
+		"TokenName":      "Ether",
+		"TokenSymbol":    "pETH",
+		"TokenAmount":    uint64(5000),
+		"TokenReceivers": map[string]uint64{receiver.PaymentAddress: 5000},
+		"TokenFee":       uint64(0),
+	})
+	assert.NoError(t, err)
+	assert.True(t, plan.Fee > 0)
+	assert.Equal(t, tokenID, plan.Token.TokenID)
+	assert.Equal(t, uint64(5000), plan.Token.PaymentAmount)
+	assert.Equal(t, uint64(3000), plan.Token.Change)
+
+	validator := newWallet(t)
+	staker := newFundedWallet(t, sim, prv, 2000000000000)
+	stakePlan, err := incognitoclient.NewStake(public).PlanStaking(staker.PaymentAddress, staker.PrivateKey, validator.PaymentAddress, validator.ValidatorKey, receiver.PaymentAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1750000000000), stakePlan.PaymentAmount)
+	assert.Equal(t, 2000000000000-1750000000000-stakePlan.Fee, stakePlan.Change)
+}
+
+func TestStaking(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	staker := newFundedWallet(t, sim, prv, 2000000000000)
+	validator := newWallet(t)
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	block := incognitoclient.NewBlockInfo(public)
+	burningAddress, err := block.GetBurningAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, BurningAddress, burningAddress)
+
+	txID, err := incognitoclient.NewStake(public).Staking(staker.PaymentAddress, staker.PrivateKey, validator.PaymentAddress, validator.ValidatorKey, burningAddress)
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.True(t, tx.BlockHeight > 0)
+	meta, err := metadata.ParseMetadata(tx.Metadata)
+	assert.NoError(t, err)
+	staking, ok := meta.(*metadata.StakingMetadata)
+	assert.True(t, ok)
+	assert.Equal(t, metadata.ShardStakingMeta, staking.Type)
+	assert.Equal(t, uint64(1750000000000), staking.StakingAmountShard)
+
+	balance, err := newClientWallet(sim).GetBalance(staker.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000000000000-1750000000000-tx.Fee, balance)
+}
+
+func TestTradePDex(t *testing.T) {
+	sim := New()
+	defer sim.Close()
+
+	prv := common.PRVCoinID.String()
+	tokenID := registerToken(t, sim)
+	trader := newFundedWallet(t, sim, prv, 1000000)
+	assert.NoError(t, sim.Fund(trader.PaymentAddress, tokenID, 5000))
+	// 1 PRV is worth 3 tokens
+	sim.SetPDEPoolPair(tokenID, prv, 3000000, 1000000)
+
+	public := incognitoclient.NewPublicIncognito(nil, sim.URL())
+	block := incognitoclient.NewBlockInfo(public)
+	pdex := incognitoclient.NewPDex(public, block)
+	w := newClientWallet(sim)
+
+	// selling 100000 PRV to the pool buys 3000000 - ceil(3000000*1000000/1100000) tokens
+	txID, err := pdex.TradePDex(trader.PrivateKey, tokenID, 10, prv, 100000, 272727, trader.PaymentAddress, prv, 0)
+	assert.NoError(t, err)
+	tx, ok := sim.Transaction(txID)
+	assert.True(t, ok)
+	meta, err := metadata.ParseMetadata(tx.Metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, metadata.PDETradeRequestMeta, meta.GetType())
+	status, err := pdex.GetPDexTradeStatus(txID)
+	assert.NoError(t, err)
+	assert.Equal(t, constant.PDexTradeSuccess, status)
+
+	balance, err := w.GetBalance(trader.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5000+272727), balance)
+	prvBalance, err := w.GetBalance(trader.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000-100000-10-tx.Fee, prvBalance)
+	for _, poolPair := range sim.PDEPoolPairs(sim.BestBlockHeight()) {
+		assert.Equal(t, prv, poolPair.Token1IDStr)
+		assert.Equal(t, uint64(1100000), poolPair.Token1PoolValue)
+		assert.Equal(t, uint64(3000000-272727), poolPair.Token2PoolValue)
+	}
+
+	// selling 1000 tokens buys about 400 PRV, less than the minimum: the tokens and the trading fee are refunded
+	txID, err = pdex.TradePDex(trader.PrivateKey, prv, 10, tokenID, 1000, 1000, trader.PaymentAddress, prv, 0)
+	assert.NoError(t, err)
+	tx, ok = sim.Transaction(txID)
+	assert.True(t, ok)
+	assert.Equal(t, tokenID, tx.TokenID)
+	status, err = pdex.GetPDexTradeStatus(txID)
+	assert.NoError(t, err)
+	assert.Equal(t, constant.PDexTradeRefunded, status)
+
+	balance, err = w.GetBalance(trader.PrivateKey, tokenID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5000+272727), balance)
+	balance, err = w.GetBalance(trader.PrivateKey, prv)
+	assert.NoError(t, err)
+	assert.Equal(t, prvBalance-tx.Fee, balance)
+}
@@ -1,5 +1,7 @@
 package transaction
 
+import "github.com/incognitochain/go-incognito-sdk/privacy/privacy_util"
+
 const (
 	// txVersion is the current latest supported transaction version.
 	txVersion                        = 1
@@ -18,3 +20,12 @@ const (
 )
 
 const MaxSizeInfo = 512
+
+// MaxInputCoins is the most coins a tx can spend, MaxPaymentInfos the most it can pay.
+// MaxPrivacyOutputs is the most output coins, change included, of a tx with privacy, as many as the
+// aggregated range proof of its outputs verifies.
+const (
+	MaxInputCoins     = 255
+	MaxPaymentInfos   = 254
+	MaxPrivacyOutputs = privacy_util.MaxOutputCoin
+)
@@ -0,0 +1,323 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/privacy/zkp"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/pkg/errors"
+)
+
+// DecodedTx is a human-readable view of a tx, points are base58 check encoded
+type DecodedTx struct {
+	TxID       string
+	Version    int8
+	Type       string
+	LockTime   int64
+	Fee        uint64
+	Info       string
+	ShardID    byte
+	SigPubKey  string
+	HasPrivacy bool
+
+	// InputCoins only have their serial number when the tx has privacy
+	InputCoins        []DecodedInputCoin
+	OutputCoins       []DecodedOutputCoin
+	CommitmentIndices []uint64 `json:",omitempty"`
+
+	Metadata *DecodedMetadata  `json:",omitempty"`
+	Token    *DecodedTokenData `json:",omitempty"`
+
+	// Proof is the payment proof the coins above are read from
+	Proof *zkp.PaymentProof `json:"-"`
+}
+
+type DecodedInputCoin struct {
+	SerialNumber string
+	PublicKey    string `json:",omitempty"`
+	Value        uint64
+}
+
+type DecodedOutputCoin struct {
+	PublicKey      string
+	CoinCommitment string
+	SNDerivator    string
+	Value          uint64
+	Info           string `json:",omitempty"`
+	// Mine is set when the coin was sent to the viewing key given to the decoder
+	Mine bool
+	// Decrypted is set when Value was decrypted with the viewing key
+	Decrypted bool
+}
+
+// DecodedMetadata is the metadata of a tx, Fields are its JSON fields
+type DecodedMetadata struct {
+	Type   int
+	Fields map[string]interface{}
+	// Metadata is the metadata decoded into the struct registered for Type, nil when none is registered
+	Metadata metadata.Metadata `json:"-"`
+}
+
+// DecodedTokenData is the token part of a TxCustomTokenPrivacy, Tx is the token tx
+type DecodedTokenData struct {
+	PropertyID     string
+	PropertyName   string
+	PropertySymbol string
+	Type           int
+	Mintable       bool
+	Amount         uint64
+	Tx             *DecodedTx
+}
+
+// rawTx is the JSON of a Tx or a TxCustomTokenPrivacy with the metadata left undecoded
+type rawTx struct {
+	Version              int8              `json:"Version"`
+	Type                 string            `json:"Type"`
+	LockTime             int64             `json:"LockTime"`
+	Fee                  uint64            `json:"Fee"`
+	Info                 []byte            `json:"Info"`
+	SigPubKey            []byte            `json:"SigPubKey"`
+	Sig                  []byte            `json:"Sig"`
+	Proof                *zkp.PaymentProof `json:"Proof"`
+	PubKeyLastByteSender byte              `json:"PubKeyLastByteSender"`
+	Metadata             json.RawMessage   `json:"Metadata"`
+	TxTokenPrivacyData   *rawTokenData     `json:"TxTokenPrivacyData"`
+}
+
+type rawTokenData struct {
+	TxNormal       rawTx
+	PropertyID     common.Hash
+	PropertyName   string
+	PropertySymbol string
+	Type           int
+	Mintable       bool
+	Amount         uint64
+}
+
+/*
+DecodeRawTx decodes base58CheckData, as returned in CreateTransactionResult or sent with sendtransaction,
+of a PRV or a privacy token tx. If viewingKey is not nil, the output coins sent to it are marked Mine
+and the values hidden by privacy decrypted.
+TxID is not computed for a tx whose metadata type is not registered, see metadata.RegisterMetadata.
+
+Example:
+
+	keyWallet, _ := wallet.Base58CheckDeserialize(readonlyKey)
+	decoded, err := transaction.DecodeRawTx(result.Base58CheckData, &keyWallet.KeySet.ReadonlyKey)
+	if err != nil {
+		return err
+	}
+	fmt.Println(decoded.Fee, decoded.OutputCoins)
+*/
+func DecodeRawTx(base58CheckData string, viewingKey *privacy.ViewingKey) (*DecodedTx, error) {
+	data, _, err := base58.Base58Check{}.Decode(base58CheckData)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base58 check data")
+	}
+	var raw rawTx
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid tx")
+	}
+
+	decoded, err := raw.decode(viewingKey)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Metadata == nil {
+		decoded.TxID = raw.hash(nil).String()
+	} else if decoded.Metadata.Metadata != nil {
+		decoded.TxID = raw.hash(decoded.Metadata.Metadata).String()
+	}
+	return decoded, nil
+}
+
+// DecodeTxDetail decodes the tx answered by gettransactionbyhash, see DecodeRawTx
+func DecodeTxDetail(detail *rpcclient.TransactionDetail, viewingKey *privacy.ViewingKey) (*DecodedTx, error) {
+	raw := rawTx{
+		Version: detail.Version,
+		Type:    detail.Type,
+		Fee:     detail.Fee,
+		Info:    []byte(detail.Info),
+		Proof:   detail.Proof,
+		// the sender shard id is stored in place of the last byte of its public key
+		PubKeyLastByteSender: detail.ShardID,
+	}
+	if lockTime, err := time.ParseInLocation(common.DateOutputFormat, detail.LockTime, time.Local); err == nil {
+		raw.LockTime = lockTime.Unix()
+	}
+	if len(detail.SigPubKey) > 0 {
+		sigPubKey, _, err := base58.Base58Check{}.Decode(detail.SigPubKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid SigPubKey")
+		}
+		raw.SigPubKey = sigPubKey
+	}
+	if len(detail.Metadata) > 0 {
+		raw.Metadata = json.RawMessage(detail.Metadata)
+	}
+	if len(detail.PrivacyCustomTokenData) > 0 {
+		raw.TxTokenPrivacyData = new(rawTokenData)
+		err := json.Unmarshal([]byte(detail.PrivacyCustomTokenData), raw.TxTokenPrivacyData)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid PrivacyCustomTokenData")
+		}
+	}
+
+	decoded, err := raw.decode(viewingKey)
+	if err != nil {
+		return nil, err
+	}
+	decoded.TxID = detail.Hash
+	return decoded, nil
+}
+
+func (raw rawTx) hasMetadata() bool {
+	return len(raw.Metadata) > 0 && !bytes.Equal(raw.Metadata, []byte("null"))
+}
+
+// hash returns the id of the tx, meta is its parsed metadata
+func (raw rawTx) hash(meta metadata.Metadata) *common.Hash {
+	tx := Tx{Version: raw.Version, LockTime: raw.LockTime, Fee: raw.Fee, Proof: raw.Proof, Metadata: meta}
+	if raw.TxTokenPrivacyData == nil {
+		return tx.Hash()
+	}
+	tokenData := raw.TxTokenPrivacyData
+	tokenTx := TxCustomTokenPrivacy{
+		Tx: tx,
+		TxPrivacyTokenData: TxPrivacyTokenData{
+			TxNormal:       Tx{Version: tokenData.TxNormal.Version, LockTime: tokenData.TxNormal.LockTime, Fee: tokenData.TxNormal.Fee, Proof: tokenData.TxNormal.Proof},
+			PropertyID:     tokenData.PropertyID,
+			PropertyName:   tokenData.PropertyName,
+			PropertySymbol: tokenData.PropertySymbol,
+			Type:           tokenData.Type,
+			Mintable:       tokenData.Mintable,
+			Amount:         tokenData.Amount,
+		},
+	}
+	return tokenTx.Hash()
+}
+
+func (raw rawTx) decode(viewingKey *privacy.ViewingKey) (*DecodedTx, error) {
+	decoded := &DecodedTx{
+		Version:  raw.Version,
+		Type:     raw.Type,
+		LockTime: raw.LockTime,
+		Fee:      raw.Fee,
+		Info:     string(raw.Info),
+		ShardID:  raw.PubKeyLastByteSender,
+		Proof:    raw.Proof,
+	}
+	if len(raw.SigPubKey) > 0 {
+		decoded.SigPubKey = base58.Base58Check{}.Encode(raw.SigPubKey, common.ZeroByte)
+	}
+
+	if raw.Proof != nil {
+		proof := raw.Proof
+		decoded.HasPrivacy = len(proof.GetOneOfManyProof()) > 0
+		decoded.CommitmentIndices = proof.GetCommitmentIndices()
+		for _, inputCoin := range proof.GetInputCoins() {
+			decoded.InputCoins = append(decoded.InputCoins, decodeInputCoin(inputCoin.CoinDetails))
+		}
+		for _, outputCoin := range proof.GetOutputCoins() {
+			decoded.OutputCoins = append(decoded.OutputCoins, decodeOutputCoin(outputCoin, decoded.HasPrivacy, viewingKey))
+		}
+	}
+
+	if raw.hasMetadata() {
+		decoded.Metadata = new(DecodedMetadata)
+		err := json.Unmarshal(raw.Metadata, &decoded.Metadata.Fields)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid metadata")
+		}
+		if metaType, ok := decoded.Metadata.Fields["Type"].(float64); ok {
+			decoded.Metadata.Type = int(metaType)
+		}
+		meta, err := metadata.ParseMetadata(raw.Metadata)
+		if err != nil && errors.Cause(err) != metadata.ErrUnknownMetadataType {
+			return nil, errors.Wrap(err, "invalid metadata")
+		}
+		decoded.Metadata.Metadata = meta
+	}
+
+	if tokenData := raw.TxTokenPrivacyData; tokenData != nil {
+		tokenTx, err := tokenData.TxNormal.decode(viewingKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid token tx")
+		}
+		decoded.Token = &DecodedTokenData{
+			PropertyID:     tokenData.PropertyID.String(),
+			PropertyName:   tokenData.PropertyName,
+			PropertySymbol: tokenData.PropertySymbol,
+			Type:           tokenData.Type,
+			Mintable:       tokenData.Mintable,
+			Amount:         tokenData.Amount,
+			Tx:             tokenTx,
+		}
+	}
+	return decoded, nil
+}
+
+func encodePoint(point *privacy.Point) string {
+	if point == nil {
+		return ""
+	}
+	return base58.Base58Check{}.Encode(point.ToBytesS(), common.ZeroByte)
+}
+
+func decodeInputCoin(coin *privacy.Coin) DecodedInputCoin {
+	return DecodedInputCoin{
+		SerialNumber: encodePoint(coin.GetSerialNumber()),
+		PublicKey:    encodePoint(coin.GetPublicKey()),
+		Value:        coin.GetValue(),
+	}
+}
+
+// decodeOutputCoin reads outputCoin, decrypting its value when it has privacy and was sent to viewingKey
+func decodeOutputCoin(outputCoin *privacy.OutputCoin, hasPrivacy bool, viewingKey *privacy.ViewingKey) DecodedOutputCoin {
+	coin := outputCoin.CoinDetails
+	decoded := DecodedOutputCoin{
+		PublicKey:      encodePoint(coin.GetPublicKey()),
+		CoinCommitment: encodePoint(coin.GetCoinCommitment()),
+		Info:           string(coin.GetInfo()),
+	}
+	if coin.GetSNDerivator() != nil {
+		decoded.SNDerivator = base58.Base58Check{}.Encode(coin.GetSNDerivator().ToBytesS(), common.ZeroByte)
+	}
+	if !hasPrivacy {
+		decoded.Value = coin.GetValue()
+	}
+	if viewingKey == nil || coin.GetPublicKey() == nil || !bytes.Equal(coin.GetPublicKey().ToBytesS(), viewingKey.Pk) {
+		return decoded
+	}
+	decoded.Mine = true
+	if !hasPrivacy || outputCoin.CoinDetailsEncrypted == nil || outputCoin.CoinDetailsEncrypted.IsNil() {
+		return decoded
+	}
+
+	// decrypt a copy, the proof is left as received
+	decrypted := &privacy.OutputCoin{
+		CoinDetails:          new(privacy.Coin).Init(),
+		CoinDetailsEncrypted: outputCoin.CoinDetailsEncrypted,
+	}
+	decrypted.CoinDetails.SetPublicKey(coin.GetPublicKey())
+	decrypted.CoinDetails.SetSNDerivator(coin.GetSNDerivator())
+	if err := decrypted.Decrypt(*viewingKey); err != nil {
+		common.Log.Warnf("Cannot decrypt output coin %v: %v", decoded.CoinCommitment, err)
+		return decoded
+	}
+	// the commitment only opens to the right value and randomness
+	if err := decrypted.CoinDetails.CommitAll(); err != nil || !privacy.IsPointEqual(decrypted.CoinDetails.GetCoinCommitment(), coin.GetCoinCommitment()) {
+		common.Log.Warnf("Decrypted output coin %v does not open its commitment", decoded.CoinCommitment)
+		return decoded
+	}
+	decoded.Value = decrypted.CoinDetails.GetValue()
+	decoded.Decrypted = true
+	return decoded
+}
@@ -1,10 +1,10 @@
 package transaction
 
 import (
+	"context"
 	"encoding/base64"
-	"fmt"
+	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
-	"github.com/incognitochain/go-incognito-sdk/common/base58"
 	"github.com/incognitochain/go-incognito-sdk/incognitokey"
 	"github.com/incognitochain/go-incognito-sdk/metadata"
 	"github.com/incognitochain/go-incognito-sdk/privacy"
@@ -12,7 +12,6 @@ import (
 	"github.com/incognitochain/go-incognito-sdk/rpcclient"
 	"github.com/incognitochain/go-incognito-sdk/wallet"
 	"github.com/pkg/errors"
-	"math/big"
 	"strconv"
 	"time"
 )
@@ -74,26 +73,15 @@ func NewTxPrivacyInitParams(
 }
 
 func (tx *Tx) Init(params *TxPrivacyInitParams, client *rpcclient.HttpClient, keyWallet *wallet.KeyWallet) error {
+	return tx.InitWithContext(context.Background(), params, client, keyWallet)
+}
+
+// InitWithContext is Init bound to ctx, it stops fetching commitments and checking SNDs once ctx is done
+func (tx *Tx) InitWithContext(ctx context.Context, params *TxPrivacyInitParams, client *rpcclient.HttpClient, keyWallet *wallet.KeyWallet) error {
 	tx.Version = txVersion
 	var err error
-	if len(params.inputCoins) > 255 {
-		return errors.New("Input coin is very larger")
-	}
-	if len(params.paymentInfo) > 254 {
-		return errors.New("Payment info is very larger")
-	}
-	limitFee := uint64(0)
-	estimateTxSizeParam := NewEstimateTxSizeParam(
-		len(params.inputCoins),
-		len(params.paymentInfo),
-		params.hasPrivacy,
-		nil,
-		nil,
-		limitFee,
-	)
-
-	if txSize := EstimateTxSize(estimateTxSizeParam); txSize > common.MaxTxSize {
-		return errors.New(fmt.Sprintf("Estimate tx size overload, maximum = %v", common.MaxTxSize))
+	if err := checkTxPrivacyInitParams(params); err != nil {
+		return err
 	}
 
 	if params.tokenID == nil {
@@ -126,10 +114,6 @@ func (tx *Tx) Init(params *TxPrivacyInitParams, client *rpcclient.HttpClient, ke
 	lenTxInfo := len(params.info)
 
 	if lenTxInfo > 0 {
-		if lenTxInfo > MaxSizeInfo {
-			return errors.New(fmt.Sprintf("Len Tx Info overload, maximum = %v", MaxSizeInfo))
-		}
-
 		tx.Info = params.info
 	}
 
@@ -139,7 +123,7 @@ func (tx *Tx) Init(params *TxPrivacyInitParams, client *rpcclient.HttpClient, ke
 	// set tx type
 	tx.Type = common.TxNormalType
 
-	fmt.Println(fmt.Sprintf("==> Init Tx len(inputCoins) = %v fee = %v hasPrivacy = %v", len(params.inputCoins), params.fee, params.hasPrivacy))
+	common.Log.Debugf("Init tx len(inputCoins) = %v fee = %v hasPrivacy = %v", len(params.inputCoins), params.fee, params.hasPrivacy)
 
 	if len(params.inputCoins) == 0 && params.fee == 0 && !params.hasPrivacy {
 		tx.Fee = params.fee
@@ -152,205 +136,24 @@ func (tx *Tx) Init(params *TxPrivacyInitParams, client *rpcclient.HttpClient, ke
 		return nil
 	}
 
-	var commitmentIndexs []uint64   // array index random of commitments in transactionStateDB
-	var myCommitmentIndexs []uint64 // index in array index random of commitment in transactionStateDB
-	var commitments []string
-
-	if params.hasPrivacy {
-		if len(params.inputCoins) == 0 {
-			return errors.New("Input coins is empty")
-		}
-
-		paymentAddrStr := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
-		commitmentIndexs, myCommitmentIndexs, commitments, err = rpcclient.RandomCommitmentsProcess(client, params.outputCoins, paymentAddrStr, params.tokenID)
-
-		// Check number of list of random commitments, list of random commitment indices
-		if len(commitmentIndexs) != len(params.inputCoins)*privacy.CommitmentRingSize {
-			return errors.New("Random commitments")
-		}
-
-		if len(myCommitmentIndexs) != len(params.inputCoins) {
-			return errors.New("Number of list my commitment indices must be equal to number of input coins")
-		}
+	// fetch what the proof needs from the network, then prove and sign offline
+	unsignedTx, err := prepareUnsignedTx(ctx, client, params, senderFullKey.PaymentAddress, tx.LockTime)
+	if err != nil {
+		return err
 	}
 
 	// Calculate execution time for creating payment proof
 	startPrivacy := time.Now()
 
-	// Calculate sum of all output coins' value
-	sumOutputValue := uint64(0)
-	for _, p := range params.paymentInfo {
-		sumOutputValue += p.Amount
-	}
-
-	// Calculate sum of all input coins' value
-	sumInputValue := uint64(0)
-	for _, coin := range params.inputCoins {
-		sumInputValue += coin.CoinDetails.GetValue()
-	}
-
-	// Calculate over balance, it will be returned to sender
-	overBalance := int64(sumInputValue - sumOutputValue - params.fee)
-
-	// Check if sum of input coins' value is at least sum of output coins' value and tx fee
-	if overBalance < 0 {
-		return errors.New(fmt.Sprintf("Input value less than output value. sumInputValue=%d sumOutputValue=%d fee=%d", sumInputValue, sumOutputValue, params.fee))
-	}
-
-	// if overBalance > 0, create a new payment info with pk is sender's pk and amount is overBalance
-	if overBalance > 0 {
-		changePaymentInfo := new(privacy.PaymentInfo)
-		changePaymentInfo.Amount = uint64(overBalance)
-		changePaymentInfo.PaymentAddress = senderFullKey.PaymentAddress
-		params.paymentInfo = append(params.paymentInfo, changePaymentInfo)
-	}
-
-	// create new output coins
-	outputCoins := make([]*privacy.OutputCoin, len(params.paymentInfo))
-
-	// create SNDs for output coins
-	ok := true
-	sndOuts := make([]*privacy.Scalar, 0)
-
-	for ok {
-		for i := 0; i < len(params.paymentInfo); i++ {
-			sndOut := privacy.RandomScalar()
-			for {
-				keyWalletTmp := new(wallet.KeyWallet)
-				keyWalletTmp.KeySet.PaymentAddress = params.paymentInfo[i].PaymentAddress
-				paymentAddrTmpStr := keyWalletTmp.Base58CheckSerialize(wallet.PaymentAddressType)
-
-				ok1, err := rpcclient.CheckSNDerivatorExistence(client, paymentAddrTmpStr, []*privacy.Scalar{sndOut})
-				if err != nil {
-					fmt.Println(errors.Wrap(err, "rpcclient.CheckSNDerivatorExistence").Error())
-				}
-				// if sndOut existed, then re-random it
-				if ok1[0] {
-					sndOut = privacy.RandomScalar()
-				} else {
-					//fmt.Println("break 3 RandomScalar")
-					break
-				}
-			}
-
-			sndOuts = append(sndOuts, sndOut)
-		}
-
-		// if sndOuts has two elements that have same value, then re-generates it
-		ok = privacy.CheckDuplicateScalarArray(sndOuts)
-		if ok {
-			sndOuts = make([]*privacy.Scalar, 0)
-		}
-	}
-
-	// create new output coins with info: Pk, value, last byte of pk, snd
-	for i, pInfo := range params.paymentInfo {
-		outputCoins[i] = new(privacy.OutputCoin)
-		outputCoins[i].CoinDetails = new(privacy.Coin)
-		outputCoins[i].CoinDetails.SetValue(pInfo.Amount)
-		if len(pInfo.Message) > 0 {
-			if len(pInfo.Message) > privacy.MaxSizeInfoCoin {
-				return errors.New(fmt.Sprintf("Len pInfo.Message is overload, maximum = %v", privacy.MaxSizeInfoCoin))
-			}
-		}
-		outputCoins[i].CoinDetails.SetInfo(pInfo.Message)
-
-		PK, err := new(privacy.Point).FromBytesS(pInfo.PaymentAddress.Pk)
-		if err != nil {
-			return errors.Wrap(err, "DecompressPaymentAddress")
-		}
-		outputCoins[i].CoinDetails.SetPublicKey(PK)
-		outputCoins[i].CoinDetails.SetSNDerivator(sndOuts[i])
-	}
-
-	// assign fee tx
-	tx.Fee = params.fee
-
-	// create zero knowledge proof of payment
-	tx.Proof = &zkp.PaymentProof{}
-
-	// get list of commitments for proving one-out-of-many from commitmentIndexs
-	commitmentProving := make([]*privacy.Point, len(commitments))
-	for i, cmIndex := range commitments {
-		temp, _, err := base58.Base58Check{}.Decode(cmIndex)
-		if err != nil {
-			return errors.Wrap(err, "GetCommitment")
-		}
-
-		commitmentProving[i] = new(privacy.Point)
-		commitmentProving[i], err = commitmentProving[i].FromBytesS(temp)
-		if err != nil {
-			return errors.Wrap(err, "GetCommitment")
-		}
-	}
-
-	// prepare witness for proving
-	witness := new(zkp.PaymentWitness)
-	paymentWitnessParam := zkp.PaymentWitnessParam{
-		HasPrivacy:              params.hasPrivacy,
-		PrivateKey:              new(privacy.Scalar).FromBytesS(*params.senderSK),
-		InputCoins:              params.inputCoins,
-		OutputCoins:             outputCoins,
-		PublicKeyLastByteSender: pkLastByteSender,
-		Commitments:             commitmentProving,
-		CommitmentIndices:       commitmentIndexs,
-		MyCommitmentIndices:     myCommitmentIndexs,
-		Fee:                     params.fee,
-	}
-
-	err = witness.Init(paymentWitnessParam)
-	if err.(*privacy.PrivacyError) != nil {
-		return errors.Wrap(err, "witness.Init")
-	}
-
-	tx.Proof, err = witness.Prove(params.hasPrivacy)
-	if err.(*privacy.PrivacyError) != nil {
-		return errors.Wrap(err, "witness.Prove")
-	}
-
-	// set private key for signing tx
-	if params.hasPrivacy {
-		randSK := witness.GetRandSecretKey()
-		tx.sigPrivKey = append(*params.senderSK, randSK.ToBytesS()...)
-
-		// encrypt coin details (Randomness)
-		// hide information of output coins except coin commitments, public key, snDerivators
-		for i := 0; i < len(tx.Proof.GetOutputCoins()); i++ {
-			err = tx.Proof.GetOutputCoins()[i].Encrypt(params.paymentInfo[i].PaymentAddress.Tk)
-			if err.(*privacy.PrivacyError) != nil {
-				return errors.Wrap(err, "EncryptOutput")
-			}
-			tx.Proof.GetOutputCoins()[i].CoinDetails.SetSerialNumber(nil)
-			tx.Proof.GetOutputCoins()[i].CoinDetails.SetValue(0)
-			tx.Proof.GetOutputCoins()[i].CoinDetails.SetRandomness(nil)
-		}
-
-		// hide information of input coins except serial number of input coins
-		for i := 0; i < len(tx.Proof.GetInputCoins()); i++ {
-			tx.Proof.GetInputCoins()[i].CoinDetails.SetCoinCommitment(nil)
-			tx.Proof.GetInputCoins()[i].CoinDetails.SetValue(0)
-			tx.Proof.GetInputCoins()[i].CoinDetails.SetSNDerivator(nil)
-			tx.Proof.GetInputCoins()[i].CoinDetails.SetPublicKey(nil)
-			tx.Proof.GetInputCoins()[i].CoinDetails.SetRandomness(nil)
-		}
-
-	} else {
-		tx.sigPrivKey = []byte{}
-		randSK := big.NewInt(0)
-		tx.sigPrivKey = append(*params.senderSK, randSK.Bytes()...)
-	}
-
-	// sign tx
-	tx.PubKeyLastByteSender = common.GetShardIDFromLastByte(pkLastByteSender)
-	err = tx.signTx()
+	err = tx.proveAndSign(unsignedTx, params.senderSK)
 	if err != nil {
-		return errors.Wrap(err, "SignTx")
+		return err
 	}
 
 	elapsedPrivacy := time.Since(startPrivacy)
 	elapsed := time.Since(start)
-	fmt.Println(fmt.Sprintf("Creating payment proof time %s", elapsedPrivacy))
-	fmt.Println(fmt.Sprintf("Successfully creating normal tx %+v in %s time", *tx.Hash(), elapsed))
+	common.Log.Debugf("Creating payment proof time %s", elapsedPrivacy)
+	common.Log.Debugf("Successfully creating normal tx %+v in %s time", *tx.Hash(), elapsed)
 	return nil
 }
 
@@ -392,6 +195,29 @@ func (tx *Tx) Hash() *common.Hash {
 	return &hash
 }
 
+// UnmarshalJSON decodes Metadata into the struct registered for its type, see metadata.ParseMetadata.
+// The metadata of a type with no registered struct is kept as a metadata.RawMetadata.
+func (tx *Tx) UnmarshalJSON(data []byte) error {
+	type txAlias Tx
+	temp := &struct {
+		*txAlias
+		Metadata json.RawMessage
+	}{txAlias: (*txAlias)(tx)}
+	err := json.Unmarshal(data, temp)
+	if err != nil {
+		return err
+	}
+	tx.Metadata, err = metadata.ParseMetadata(temp.Metadata)
+	if errors.Cause(err) == metadata.ErrUnknownMetadataType {
+		tx.Metadata, err = metadata.NewRawMetadata(temp.Metadata)
+	}
+	if err != nil {
+		return errors.Wrap(err, "metadata.ParseMetadata")
+	}
+	tx.cachedHash = nil
+	return nil
+}
+
 func (tx Tx) String() string {
 	record := strconv.Itoa(int(tx.Version))
 
@@ -0,0 +1,69 @@
+package transaction_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/metadata"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxMetadata(t *testing.T) {
+	account, err := wallet.CreateNewAccount()
+	assert.NoError(t, err)
+	keySet := &account.Key.KeySet
+	tokenID := common.Hash{1, 2, 3}
+
+	trade, _ := metadata.NewPDETradeRequest(tokenID.String(), common.PRVIDStr, 100, 90, 1, account.Key.Base58CheckSerialize(wallet.PaymentAddressType), metadata.PDETradeRequestMeta)
+	burning, _ := metadata.NewBurningRequest(keySet.PaymentAddress, 100, tokenID, "pETH", "d5808ba261c91d640a2d4149e8cdb3fd4512efe4", metadata.BurningForDepositToSCRequestMeta)
+	withdraw := &metadata.WithDrawRewardRequest{
+		PaymentAddress: keySet.PaymentAddress,
+		MetadataBase:   metadata.MetadataBase{Type: metadata.WithDrawRewardRequestMeta},
+		TokenID:        common.PRVCoinID,
+		Version:        1,
+	}
+
+	for _, meta := range []metadata.Metadata{trade, burning, withdraw} {
+		// a tx carrying the metadata keeps its hash through JSON
+		tx := transaction.Tx{}
+		params := transaction.NewTxPrivacyInitParams(&keySet.PrivateKey, nil, nil, nil, 0, false, nil, meta, nil)
+		assert.NoError(t, tx.Init(params, nil, nil))
+		txBytes, err := json.Marshal(tx)
+		assert.NoError(t, err)
+		var unmarshalled transaction.Tx
+		assert.NoError(t, json.Unmarshal(txBytes, &unmarshalled))
+		assert.Equal(t, meta, unmarshalled.Metadata)
+		assert.Equal(t, tx.Hash(), unmarshalled.Hash())
+
+		decoded, err := transaction.DecodeRawTx(base58.Base58Check{}.Encode(txBytes, common.ZeroByte), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, tx.Hash().String(), decoded.TxID)
+		assert.Equal(t, meta, decoded.Metadata.Metadata)
+	}
+
+	// a tx whose metadata type has no registered struct still decodes
+	tx := transaction.Tx{}
+	assert.NoError(t, tx.Init(transaction.NewTxPrivacyInitParams(&keySet.PrivateKey, nil, nil, nil, 0, false, nil, withdraw, nil), nil, nil))
+	txBytes, err := json.Marshal(tx)
+	assert.NoError(t, err)
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(txBytes, &fields))
+	fields["Metadata"] = json.RawMessage(`{"Type":105,"Rates":{"BTC":1}}`)
+	txBytes, err = json.Marshal(fields)
+	assert.NoError(t, err)
+	var portalTx transaction.Tx
+	assert.NoError(t, json.Unmarshal(txBytes, &portalTx))
+	raw, ok := portalTx.Metadata.(*metadata.RawMetadata)
+	assert.True(t, ok)
+	assert.Equal(t, metadata.PortalExchangeRatesMeta, raw.GetType())
+	assert.JSONEq(t, string(fields["Metadata"]), string(raw.Raw))
+
+	// metadata breaking the rules of the chain is refused before the tx is built
+	sameTokens, _ := metadata.NewPDETradeRequest(common.PRVIDStr, common.PRVIDStr, 100, 90, 1, account.Key.Base58CheckSerialize(wallet.PaymentAddressType), metadata.PDETradeRequestMeta)
+	params := transaction.NewTxPrivacyInitParams(&keySet.PrivateKey, nil, nil, nil, 0, false, nil, sameTokens, nil)
+	assert.Error(t, new(transaction.Tx).Init(params, nil, nil))
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/incognitochain/go-incognito-sdk/common"
 	"github.com/incognitochain/go-incognito-sdk/metadata"
@@ -123,10 +124,16 @@ func (txCustomTokenPrivacy *TxCustomTokenPrivacy) Hash() *common.Hash {
 
 // Init -  build normal tx component and privacy custom token data
 func (txCustomTokenPrivacy *TxCustomTokenPrivacy) Init(params *TxPrivacyTokenInitParams, client *rpcclient.HttpClient, keyWallet *wallet.KeyWallet) error {
+	return txCustomTokenPrivacy.InitWithContext(context.Background(), params, client, keyWallet)
+}
+
+// InitWithContext is Init bound to ctx, both the PRV fee tx and the token tx are built with it
+func (txCustomTokenPrivacy *TxCustomTokenPrivacy) InitWithContext(ctx context.Context, params *TxPrivacyTokenInitParams, client *rpcclient.HttpClient, keyWallet *wallet.KeyWallet) error {
 	var err error
 	// init data for tx PRV for fee
 	normalTx := Tx{}
-	err = normalTx.Init(
+	err = normalTx.InitWithContext(
+		ctx,
 		NewTxPrivacyInitParams(
 			params.senderKey,
 			params.paymentInfo,
@@ -156,7 +163,7 @@ func (txCustomTokenPrivacy *TxCustomTokenPrivacy) Init(params *TxPrivacyTokenIni
 		params.hasPrivacyCoin, nil, params.tokenParams, limitFee)
 
 	if txSize := EstimateTxSize(estimateTxSizeParam); txSize > common.MaxTxSize {
-		return errors.New("Size of tx info exceed max size of tx")
+		return errors.Wrapf(rpcclient.ErrTxTooLarge, "estimate tx size %v exceed max size of tx %v", txSize, common.MaxTxSize)
 	}
 
 	// check action type and create privacy custom toke data
@@ -179,7 +186,8 @@ func (txCustomTokenPrivacy *TxCustomTokenPrivacy) Init(params *TxPrivacyTokenIni
 				Mintable:       params.tokenParams.Mintable,
 			}
 
-			err := temp.Init(
+			err := temp.InitWithContext(
+				ctx,
 				NewTxPrivacyInitParams(
 					params.senderKey,
 					params.tokenParams.Receiver,
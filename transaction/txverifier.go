@@ -0,0 +1,107 @@
+package transaction
+
+import (
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/pkg/errors"
+)
+
+// DecodeCommitments decodes base58 check encoded coin commitments, as answered by randomcommitments
+func DecodeCommitments(commitments []string) ([]*privacy.Point, error) {
+	points := make([]*privacy.Point, len(commitments))
+	for i, commitment := range commitments {
+		temp, _, err := base58.Base58Check{}.Decode(commitment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode commitment %v", i)
+		}
+		points[i], err = new(privacy.Point).FromBytesS(temp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode commitment %v", i)
+		}
+	}
+	return points, nil
+}
+
+// verifySig checks the Schnorr signature of tx over its hash with SigPubKey
+func (tx *Tx) verifySig() (bool, error) {
+	if len(tx.Sig) == 0 || len(tx.SigPubKey) == 0 {
+		return false, errors.New("tx is not signed")
+	}
+	pk, err := new(privacy.Point).FromBytesS(tx.SigPubKey)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid SigPubKey")
+	}
+	verifyKey := new(privacy.SchnorrPublicKey)
+	verifyKey.Set(pk)
+
+	signature := new(privacy.SchnSignature)
+	err = signature.SetBytes(tx.Sig)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid Sig")
+	}
+
+	// the cached hash may predate a change of the tx, hash it again
+	hash := common.HashH([]byte(tx.String()))
+	if !verifyKey.Verify(signature, hash[:]) {
+		return false, errors.New("invalid signature")
+	}
+	return true, nil
+}
+
+/*
+Verify checks tx as a node does before accepting it, without asking one: the Schnorr signature,
+then the payment proof, that is for each input the one-out-of-many membership in its ring and the serial number proof,
+then the range proof of the outputs and that the inputs pay the outputs and the fee.
+commitments are the coin commitments at tx.Proof.GetCommitmentIndices(), in the same order; they are
+only needed by a tx with privacy, DecodeCommitments decodes them from the answer of randomcommitments.
+Whether the serial numbers were already spent is left to the node.
+
+Example:
+
+	commitments, err := transaction.DecodeCommitments(unsignedTx.Commitments)
+	if err != nil {
+		return err
+	}
+	if ok, err := tx.Verify(commitments); !ok {
+		return err
+	}
+*/
+func (tx *Tx) Verify(commitments []*privacy.Point) (bool, error) {
+	valid, err := tx.verifySig()
+	if !valid {
+		return false, err
+	}
+
+	if tx.Proof == nil {
+		if tx.Fee != 0 {
+			return false, errors.New("tx pays a fee without proof")
+		}
+		return true, nil
+	}
+
+	hasPrivacy := len(tx.Proof.GetOneOfManyProof()) > 0
+	valid, privacyErr := tx.Proof.Verify(hasPrivacy, tx.SigPubKey, tx.Fee, tx.PubKeyLastByteSender, commitments)
+	if !valid {
+		return false, errors.Wrap(privacyErr, "tx.Proof.Verify")
+	}
+	return true, nil
+}
+
+// Verify checks the PRV tx paying the fee with commitments, see Tx.Verify, and the token tx with tokenCommitments.
+// The token tx of a CustomTokenInit tx mints its coins, it has no proof to check.
+func (txCustomTokenPrivacy *TxCustomTokenPrivacy) Verify(commitments []*privacy.Point, tokenCommitments []*privacy.Point) (bool, error) {
+	valid, err := txCustomTokenPrivacy.Tx.Verify(commitments)
+	if !valid {
+		return false, errors.Wrap(err, "verify PRV tx")
+	}
+
+	if txCustomTokenPrivacy.TxPrivacyTokenData.Type == CustomTokenInit {
+		return true, nil
+	}
+	valid, err = txCustomTokenPrivacy.TxPrivacyTokenData.TxNormal.Verify(tokenCommitments)
+	if !valid {
+		return false, errors.Wrap(err, "verify token tx")
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package transaction_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/simulator"
+	"github.com/incognitochain/go-incognito-sdk/transaction"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAccount returns the key of a new account, funded with amount PRV on sim when amount is not 0
+func newAccount(t *testing.T, sim *simulator.Simulator, amount uint64) *wallet.KeyWallet {
+	account, err := wallet.CreateNewAccount()
+	assert.NoError(t, err)
+	if amount > 0 {
+		assert.NoError(t, sim.Fund(account.Key.Base58CheckSerialize(wallet.PaymentAddressType), common.PRVCoinID.String(), amount))
+	}
+	return &account.Key
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	sim := simulator.New()
+	defer sim.Close()
+
+	sender := newAccount(t, sim, 1000000)
+	receiver := newAccount(t, sim, 0)
+
+	rpcClient := rpcclient.NewHttpClient(sim.URL(), "", "", 0)
+	inputCoins, err := rpcclient.GetUnspentOutputCoins(rpcClient, sender, &common.PRVCoinID)
+	assert.NoError(t, err)
+
+	for _, hasPrivacy := range []bool{true, false} {
+		unsignedTx, err := transaction.PrepareUnsignedTx(rpcClient, &transaction.UnsignedTxParams{
+			Sender:       sender.Base58CheckSerialize(wallet.PaymentAddressType),
+			PaymentInfos: []*privacy.PaymentInfo{{PaymentAddress: receiver.KeySet.PaymentAddress, Amount: 1000}},
+			InputCoins:   inputCoins,
+			Fee:          100,
+			HasPrivacy:   hasPrivacy,
+		})
+		assert.NoError(t, err)
+		signed, err := unsignedTx.Sign(&sender.KeySet.PrivateKey)
+		assert.NoError(t, err)
+		commitments, err := transaction.DecodeCommitments(unsignedTx.Commitments)
+		assert.NoError(t, err)
+
+		// verify the tx as received by a node
+		data, err := json.Marshal(signed)
+		assert.NoError(t, err)
+		var tx transaction.Tx
+		assert.NoError(t, json.Unmarshal(data, &tx))
+		ok, err := tx.Verify(commitments)
+		assert.True(t, ok, "hasPrivacy %v", hasPrivacy)
+		assert.NoError(t, err)
+
+		// the signature covers the fee
+		tampered := tx
+		tampered.Fee++
+		ok, err = tampered.Verify(commitments)
+		assert.False(t, ok)
+		assert.Error(t, err)
+
+		if hasPrivacy {
+			// the coins spent are not in a ring made of other commitments
+			other := make([]*privacy.Point, len(commitments))
+			for i := range other {
+				other[i] = privacy.RandomPoint()
+			}
+			ok, err = tx.Verify(other)
+			assert.False(t, ok)
+			assert.Error(t, err)
+		}
+	}
+}
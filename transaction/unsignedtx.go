@@ -0,0 +1,480 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk/common"
+	"github.com/incognitochain/go-incognito-sdk/common/base58"
+	"github.com/incognitochain/go-incognito-sdk/incognitokey"
+	"github.com/incognitochain/go-incognito-sdk/privacy"
+	"github.com/incognitochain/go-incognito-sdk/privacy/zkp"
+	"github.com/incognitochain/go-incognito-sdk/rpcclient"
+	"github.com/incognitochain/go-incognito-sdk/wallet"
+	"github.com/pkg/errors"
+)
+
+// UnsignedTxVersion is the version of the UnsignedTx format, Sign refuses other versions
+const UnsignedTxVersion = 1
+
+// UnsignedTx is everything building a tx needs from the network, fetched by PrepareUnsignedTx on an online
+// watch-only machine: the input coins, the ring of decoy commitments and the output SNDs.
+// It carries no secret and is marshalled to JSON to be carried to an air-gapped machine, where Sign
+// builds the payment proof and the signature without network access.
+// Only PRV transfers are supported: Sign builds a Tx, never the TxCustomTokenPrivacy a token transfer needs,
+// so PrepareUnsignedTx and Sign refuse any other TokenID.
+type UnsignedTx struct {
+	BundleVersion int    `json:"BundleVersion"`
+	LockTime      int64  `json:"LockTime"`
+	Fee           uint64 `json:"Fee"`
+	Info          []byte `json:"Info"`
+	HasPrivacy    bool   `json:"HasPrivacy"`
+	TokenID       string `json:"TokenID"`
+	// Sender is the payment address of the sender, Sign checks the private key matches it
+	Sender     string              `json:"Sender"`
+	InputCoins []rpcclient.OutCoin `json:"InputCoins"`
+	// Outputs include the change sent back to Sender
+	Outputs             []UnsignedOutput `json:"Outputs"`
+	CommitmentIndices   []uint64         `json:"CommitmentIndices"`
+	MyCommitmentIndices []uint64         `json:"MyCommitmentIndices"`
+	Commitments         []string         `json:"Commitments"`
+}
+
+// UnsignedOutput is an output coin of an UnsignedTx, its SND was checked unused on the network
+type UnsignedOutput struct {
+	PaymentAddress string `json:"PaymentAddress"`
+	Amount         uint64 `json:"Amount"`
+	Message        []byte `json:"Message"`
+	SNDerivator    string `json:"SNDerivator"`
+}
+
+// UnsignedTxParams are the params of PrepareUnsignedTx, no private key is needed
+type UnsignedTxParams struct {
+	// Sender is the payment address of the sender, the change goes back to it
+	Sender       string
+	PaymentInfos []*privacy.PaymentInfo
+	// InputCoins are the coins spent, with the value and randomness listed with the readonly key of Sender
+	InputCoins []*privacy.OutputCoin
+	Fee        uint64
+	HasPrivacy bool
+	// TokenID is the token spent, nil is PRV; only PRV is supported, see UnsignedTx
+	TokenID *common.Hash
+	Info    []byte
+}
+
+func PrepareUnsignedTx(client *rpcclient.HttpClient, params *UnsignedTxParams) (*UnsignedTx, error) {
+	return PrepareUnsignedTxWithContext(context.Background(), client, params)
+}
+
+// PrepareUnsignedTxWithContext fetches the decoy commitments of the input coins and reserves unused SNDs for
+// the outputs, it is the online half of Tx.Init
+func PrepareUnsignedTxWithContext(ctx context.Context, client *rpcclient.HttpClient, params *UnsignedTxParams) (*UnsignedTx, error) {
+	if params.TokenID != nil && !params.TokenID.IsEqual(&common.PRVCoinID) {
+		return nil, errors.Errorf("unsigned tx of token %s is not supported, only PRV", params.TokenID.String())
+	}
+	senderWallet, err := wallet.Base58CheckDeserialize(params.Sender)
+	if err != nil {
+		return nil, errors.Wrap(err, "sender payment address")
+	}
+	if len(senderWallet.KeySet.PaymentAddress.Pk) == 0 {
+		return nil, errors.New("sender payment address is invalid")
+	}
+
+	initParams := NewTxPrivacyInitParams(
+		nil,
+		params.PaymentInfos,
+		ConvertOutputCoinToInputCoin(params.InputCoins),
+		params.InputCoins,
+		params.Fee,
+		params.HasPrivacy,
+		params.TokenID,
+		nil,
+		params.Info,
+	)
+	if err := checkTxPrivacyInitParams(initParams); err != nil {
+		return nil, err
+	}
+	if initParams.tokenID == nil {
+		prvCoinID := common.PRVCoinID
+		initParams.tokenID = &prvCoinID
+	}
+
+	return prepareUnsignedTx(ctx, client, initParams, senderWallet.KeySet.PaymentAddress, 0)
+}
+
+// checkTxPrivacyInitParams checks the limits of the node on the number of coins, the tx size and the info size
+func checkTxPrivacyInitParams(params *TxPrivacyInitParams) error {
+	if len(params.inputCoins) > MaxInputCoins {
+		return errors.Wrapf(rpcclient.ErrTxTooLarge, "%d input coins, maximum = %d", len(params.inputCoins), MaxInputCoins)
+	}
+	if len(params.paymentInfo) > MaxPaymentInfos {
+		return errors.Wrapf(rpcclient.ErrTxTooLarge, "%d payment infos, maximum = %d", len(params.paymentInfo), MaxPaymentInfos)
+	}
+	limitFee := uint64(0)
+	estimateTxSizeParam := NewEstimateTxSizeParam(
+		len(params.inputCoins),
+		len(params.paymentInfo),
+		params.hasPrivacy,
+		nil,
+		nil,
+		limitFee,
+	)
+
+	if txSize := EstimateTxSize(estimateTxSizeParam); txSize > common.MaxTxSize {
+		return errors.Wrapf(rpcclient.ErrTxTooLarge, "estimate tx size %v overload, maximum = %v", txSize, common.MaxTxSize)
+	}
+	if len(params.info) > MaxSizeInfo {
+		return errors.New(fmt.Sprintf("Len Tx Info overload, maximum = %v", MaxSizeInfo))
+	}
+	if params.metaData != nil {
+		if err := params.metaData.Validate(); err != nil {
+			return errors.Wrap(err, "invalid metadata")
+		}
+	}
+	return nil
+}
+
+// prepareUnsignedTx fetches the random commitments and the SNDs of a tx spending params.inputCoins,
+// a zero lockTime is set to now
+func prepareUnsignedTx(ctx context.Context, client *rpcclient.HttpClient, params *TxPrivacyInitParams, sender privacy.PaymentAddress, lockTime int64) (*UnsignedTx, error) {
+	if lockTime == 0 {
+		lockTime = time.Now().Unix()
+	}
+	senderStr := paymentAddressString(sender)
+	unsignedTx := &UnsignedTx{
+		BundleVersion: UnsignedTxVersion,
+		LockTime:      lockTime,
+		Fee:           params.fee,
+		Info:          params.info,
+		HasPrivacy:    params.hasPrivacy,
+		TokenID:       params.tokenID.String(),
+		Sender:        senderStr,
+		InputCoins:    make([]rpcclient.OutCoin, len(params.inputCoins)),
+	}
+	for i, coin := range params.inputCoins {
+		unsignedTx.InputCoins[i] = rpcclient.NewOutCoin(&privacy.OutputCoin{CoinDetails: coin.CoinDetails})
+	}
+
+	if params.hasPrivacy {
+		if len(params.inputCoins) == 0 {
+			return nil, errors.New("Input coins is empty")
+		}
+
+		var err error
+		unsignedTx.CommitmentIndices, unsignedTx.MyCommitmentIndices, unsignedTx.Commitments, err = rpcclient.RandomCommitmentsProcessWithContext(ctx, client, params.outputCoins, senderStr, params.tokenID)
+		if err != nil {
+			return nil, errors.Wrap(err, "rpcclient.RandomCommitmentsProcess")
+		}
+
+		// Check number of list of random commitments, list of random commitment indices
+		if len(unsignedTx.CommitmentIndices) != len(params.inputCoins)*privacy.CommitmentRingSize {
+			return nil, errors.New("Random commitments")
+		}
+
+		if len(unsignedTx.MyCommitmentIndices) != len(params.inputCoins) {
+			return nil, errors.New("Number of list my commitment indices must be equal to number of input coins")
+		}
+	}
+
+	// Calculate sum of all output coins' value
+	sumOutputValue := uint64(0)
+	for _, p := range params.paymentInfo {
+		sumOutputValue += p.Amount
+	}
+
+	// Calculate sum of all input coins' value
+	sumInputValue := uint64(0)
+	for _, coin := range params.inputCoins {
+		sumInputValue += coin.CoinDetails.GetValue()
+	}
+
+	// Calculate over balance, it will be returned to sender
+	overBalance := int64(sumInputValue - sumOutputValue - params.fee)
+
+	// Check if sum of input coins' value is at least sum of output coins' value and tx fee
+	if overBalance < 0 {
+		return nil, errors.Wrapf(rpcclient.ErrNotEnoughCoin, "input value less than output value. sumInputValue=%d sumOutputValue=%d fee=%d", sumInputValue, sumOutputValue, params.fee)
+	}
+
+	// if overBalance > 0, create a new payment info with pk is sender's pk and amount is overBalance
+	paymentInfos := params.paymentInfo
+	if overBalance > 0 {
+		changePaymentInfo := new(privacy.PaymentInfo)
+		changePaymentInfo.Amount = uint64(overBalance)
+		changePaymentInfo.PaymentAddress = sender
+		paymentInfos = append(paymentInfos[:len(paymentInfos):len(paymentInfos)], changePaymentInfo)
+	}
+	if params.hasPrivacy && len(paymentInfos) > MaxPrivacyOutputs {
+		return nil, errors.Wrapf(rpcclient.ErrTxTooLarge, "%d outputs with privacy, change included, maximum = %d", len(paymentInfos), MaxPrivacyOutputs)
+	}
+
+	// create SNDs for output coins, all of them are checked against the network in one batched request
+	paymentAddrStrs := make([]string, len(paymentInfos))
+	for i, pInfo := range paymentInfos {
+		if len(pInfo.Message) > privacy.MaxSizeInfoCoin {
+			return nil, errors.New(fmt.Sprintf("Len pInfo.Message is overload, maximum = %v", privacy.MaxSizeInfoCoin))
+		}
+		paymentAddrStrs[i] = paymentAddressString(pInfo.PaymentAddress)
+	}
+
+	sndOuts, err := newSNDerivators(ctx, client, paymentAddrStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTx.Outputs = make([]UnsignedOutput, len(paymentInfos))
+	for i, pInfo := range paymentInfos {
+		unsignedTx.Outputs[i] = UnsignedOutput{
+			PaymentAddress: paymentAddrStrs[i],
+			Amount:         pInfo.Amount,
+			Message:        pInfo.Message,
+			SNDerivator:    base58.Base58Check{}.Encode(sndOuts[i].ToBytesS(), common.ZeroByte),
+		}
+	}
+	return unsignedTx, nil
+}
+
+// newSNDerivators returns a random SND for each of paymentAddrStrs, unused on the network and all different
+func newSNDerivators(ctx context.Context, client *rpcclient.HttpClient, paymentAddrStrs []string) ([]*privacy.Scalar, error) {
+	sndOuts := make([]*privacy.Scalar, len(paymentAddrStrs))
+	for i := range sndOuts {
+		sndOuts[i] = privacy.RandomScalar()
+	}
+	// indexes of the SNDs not checked yet
+	unchecked := make([]int, len(sndOuts))
+	for i := range unchecked {
+		unchecked[i] = i
+	}
+
+	for len(unchecked) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "create SNDs")
+		}
+
+		// if sndOuts has two elements that have same value, then re-generates it
+		for privacy.CheckDuplicateScalarArray(sndOuts) {
+			for i := range sndOuts {
+				sndOuts[i] = privacy.RandomScalar()
+			}
+			unchecked = unchecked[:0]
+			for i := range sndOuts {
+				unchecked = append(unchecked, i)
+			}
+		}
+
+		addrs := make([]string, len(unchecked))
+		snds := make([]*privacy.Scalar, len(unchecked))
+		for j, i := range unchecked {
+			addrs[j] = paymentAddrStrs[i]
+			snds[j] = sndOuts[i]
+		}
+		existed, err := rpcclient.CheckSNDerivatorsExistenceWithContext(ctx, client, addrs, snds)
+		if err != nil {
+			return nil, errors.Wrap(err, "rpcclient.CheckSNDerivatorsExistence")
+		}
+
+		// if sndOut existed, then re-random it and check it again
+		stillUnchecked := make([]int, 0)
+		for j, i := range unchecked {
+			if existed[j] {
+				sndOuts[i] = privacy.RandomScalar()
+				stillUnchecked = append(stillUnchecked, i)
+			}
+		}
+		unchecked = stillUnchecked
+	}
+	return sndOuts, nil
+}
+
+func paymentAddressString(paymentAddress privacy.PaymentAddress) string {
+	keyWallet := new(wallet.KeyWallet)
+	keyWallet.KeySet.PaymentAddress = paymentAddress
+	return keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+}
+
+/*
+Sign builds the payment proof of the unsigned tx and signs it with the private key of the sender.
+It makes no network call, so it runs on an air-gapped machine; the input coins may have been spent since
+the tx was prepared, the node then rejects the tx with rpcclient.ErrDoubleSpend.
+
+Example:
+
+	tx, err := unsignedTx.Sign(&keyWallet.KeySet.PrivateKey)
+	if err != nil {
+		return err
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+*/
+func (unsignedTx *UnsignedTx) Sign(senderSK *privacy.PrivateKey) (*Tx, error) {
+	if unsignedTx.BundleVersion != UnsignedTxVersion {
+		return nil, errors.Errorf("unsigned tx version %d is not supported, expected %d", unsignedTx.BundleVersion, UnsignedTxVersion)
+	}
+	if unsignedTx.TokenID != common.PRVCoinID.String() {
+		return nil, errors.Errorf("unsigned tx of token %s is not supported, only PRV", unsignedTx.TokenID)
+	}
+
+	tx := &Tx{
+		Version:  txVersion,
+		Type:     common.TxNormalType,
+		LockTime: unsignedTx.LockTime,
+		Info:     unsignedTx.Info,
+	}
+	if tx.Info == nil {
+		tx.Info = []byte{}
+	}
+	if err := tx.proveAndSign(unsignedTx, senderSK); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// proveAndSign builds the payment proof of unsignedTx and signs tx with senderSK, the fields of tx not
+// covered by the proof (lock time, info, metadata) are set by the caller
+func (tx *Tx) proveAndSign(unsignedTx *UnsignedTx, senderSK *privacy.PrivateKey) error {
+	// create sender's key set from sender's spending key
+	senderFullKey := incognitokey.KeySet{}
+	err := senderFullKey.InitFromPrivateKey(senderSK)
+	if err != nil {
+		return errors.Wrap(err, "senderFullKey.InitFromPrivateKey")
+	}
+	senderWallet, err := wallet.Base58CheckDeserialize(unsignedTx.Sender)
+	if err != nil {
+		return errors.Wrap(err, "sender payment address")
+	}
+	if !bytes.Equal(senderWallet.KeySet.PaymentAddress.Pk, senderFullKey.PaymentAddress.Pk) {
+		return errors.New("private key does not belong to the sender of the unsigned tx")
+	}
+	// get public key last byte of sender
+	pkLastByteSender := senderFullKey.PaymentAddress.Pk[len(senderFullKey.PaymentAddress.Pk)-1]
+
+	// input coins, their serial numbers are derived from the private key
+	inputOutputCoins, err := rpcclient.NewOutputCoinsFromResponse(unsignedTx.InputCoins)
+	if err != nil {
+		return errors.Wrap(err, "input coins")
+	}
+	inputCoins := ConvertOutputCoinToInputCoin(inputOutputCoins)
+	for _, coin := range inputCoins {
+		coin.CoinDetails.SetSerialNumber(
+			new(privacy.Point).Derive(
+				privacy.PedCom.G[privacy.PedersenPrivateKeyIndex],
+				new(privacy.Scalar).FromBytesS(*senderSK),
+				coin.CoinDetails.GetSNDerivator()))
+	}
+
+	// create new output coins with info: Pk, value, last byte of pk, snd
+	paymentInfos := make([]*privacy.PaymentInfo, len(unsignedTx.Outputs))
+	outputCoins := make([]*privacy.OutputCoin, len(unsignedTx.Outputs))
+	for i, output := range unsignedTx.Outputs {
+		receiverWallet, err := wallet.Base58CheckDeserialize(output.PaymentAddress)
+		if err != nil {
+			return errors.Wrapf(err, "output #%d payment address", i)
+		}
+		paymentInfos[i] = &privacy.PaymentInfo{
+			PaymentAddress: receiverWallet.KeySet.PaymentAddress,
+			Amount:         output.Amount,
+			Message:        output.Message,
+		}
+		sndBytes, _, err := base58.Base58Check{}.Decode(output.SNDerivator)
+		if err != nil {
+			return errors.Wrapf(err, "output #%d SND", i)
+		}
+
+		outputCoins[i] = new(privacy.OutputCoin)
+		outputCoins[i].CoinDetails = new(privacy.Coin)
+		outputCoins[i].CoinDetails.SetValue(output.Amount)
+		if len(output.Message) > privacy.MaxSizeInfoCoin {
+			return errors.New(fmt.Sprintf("Len pInfo.Message is overload, maximum = %v", privacy.MaxSizeInfoCoin))
+		}
+		outputCoins[i].CoinDetails.SetInfo(output.Message)
+
+		PK, err := new(privacy.Point).FromBytesS(receiverWallet.KeySet.PaymentAddress.Pk)
+		if err != nil {
+			return errors.Wrap(err, "DecompressPaymentAddress")
+		}
+		outputCoins[i].CoinDetails.SetPublicKey(PK)
+		outputCoins[i].CoinDetails.SetSNDerivator(new(privacy.Scalar).FromBytesS(sndBytes))
+	}
+
+	// assign fee tx
+	tx.Fee = unsignedTx.Fee
+
+	// create zero knowledge proof of payment
+	tx.Proof = &zkp.PaymentProof{}
+
+	// get list of commitments for proving one-out-of-many from commitmentIndexs
+	commitmentProving, err := DecodeCommitments(unsignedTx.Commitments)
+	if err != nil {
+		return errors.Wrap(err, "GetCommitment")
+	}
+
+	// prepare witness for proving
+	witness := new(zkp.PaymentWitness)
+	paymentWitnessParam := zkp.PaymentWitnessParam{
+		HasPrivacy:              unsignedTx.HasPrivacy,
+		PrivateKey:              new(privacy.Scalar).FromBytesS(*senderSK),
+		InputCoins:              inputCoins,
+		OutputCoins:             outputCoins,
+		PublicKeyLastByteSender: pkLastByteSender,
+		Commitments:             commitmentProving,
+		CommitmentIndices:       unsignedTx.CommitmentIndices,
+		MyCommitmentIndices:     unsignedTx.MyCommitmentIndices,
+		Fee:                     unsignedTx.Fee,
+	}
+
+	err = witness.Init(paymentWitnessParam)
+	if err.(*privacy.PrivacyError) != nil {
+		return errors.Wrap(err, "witness.Init")
+	}
+
+	tx.Proof, err = witness.Prove(unsignedTx.HasPrivacy)
+	if err.(*privacy.PrivacyError) != nil {
+		return errors.Wrap(err, "witness.Prove")
+	}
+
+	// set private key for signing tx
+	if unsignedTx.HasPrivacy {
+		randSK := witness.GetRandSecretKey()
+		tx.sigPrivKey = append(*senderSK, randSK.ToBytesS()...)
+
+		// encrypt coin details (Randomness)
+		// hide information of output coins except coin commitments, public key, snDerivators
+		for i := 0; i < len(tx.Proof.GetOutputCoins()); i++ {
+			err = tx.Proof.GetOutputCoins()[i].Encrypt(paymentInfos[i].PaymentAddress.Tk)
+			if err.(*privacy.PrivacyError) != nil {
+				return errors.Wrap(err, "EncryptOutput")
+			}
+			tx.Proof.GetOutputCoins()[i].CoinDetails.SetSerialNumber(nil)
+			tx.Proof.GetOutputCoins()[i].CoinDetails.SetValue(0)
+			tx.Proof.GetOutputCoins()[i].CoinDetails.SetRandomness(nil)
+		}
+
+		// hide information of input coins except serial number of input coins
+		for i := 0; i < len(tx.Proof.GetInputCoins()); i++ {
+			tx.Proof.GetInputCoins()[i].CoinDetails.SetCoinCommitment(nil)
+			tx.Proof.GetInputCoins()[i].CoinDetails.SetValue(0)
+			tx.Proof.GetInputCoins()[i].CoinDetails.SetSNDerivator(nil)
+			tx.Proof.GetInputCoins()[i].CoinDetails.SetPublicKey(nil)
+			tx.Proof.GetInputCoins()[i].CoinDetails.SetRandomness(nil)
+		}
+
+	} else {
+		tx.sigPrivKey = []byte{}
+		randSK := big.NewInt(0)
+		tx.sigPrivKey = append(*senderSK, randSK.Bytes()...)
+	}
+
+	// sign tx
+	tx.PubKeyLastByteSender = common.GetShardIDFromLastByte(pkLastByteSender)
+	err = tx.signTx()
+	if err != nil {
+		return errors.Wrap(err, "SignTx")
+	}
+	return nil
+}
@@ -68,7 +68,7 @@ func CreateNewAccount() (*AccountWallet, error) {
 	lastByte := childKey.KeySet.PaymentAddress.Pk[len(childKey.KeySet.PaymentAddress.Pk)-1]
 	shardId := common.GetShardIDFromLastByte(lastByte)
 
-	fmt.Println(fmt.Sprintf("Generating wallet with shardId %v and Index %v", shardId, 0))
+	common.Log.Debugf("Generating wallet with shardId %v and Index %v", shardId, 0)
 
 	masterAccount.Child = append(masterAccount.Child, account)
 
@@ -102,7 +102,7 @@ func CreateNewAccountByShardId(shardId int) (*AccountWallet, error) {
 		childKey, _ = masterAccount.Key.NewChildKey(uint32(newIndex))
 		lastByte := childKey.KeySet.PaymentAddress.Pk[len(childKey.KeySet.PaymentAddress.Pk)-1]
 		if common.GetShardIDFromLastByte(lastByte) == shardIDByte {
-			fmt.Println(fmt.Sprintf("Generating wallet with shardId %v and Index %v", shardId, newIndex))
+			common.Log.Debugf("Generating wallet with shardId %v and Index %v", shardId, newIndex)
 			break
 		}
 		newIndex += 1